@@ -14,12 +14,31 @@ import (
 	"benchmark-client/internal/config"
 	"benchmark-client/internal/conformance"
 	"benchmark-client/internal/orchestrator"
+	"benchmark-client/internal/selftest"
+	"benchmark-client/internal/summary"
 )
 
+// main only exits: run returns the code so its defers (signal handling, the
+// self-test server, orchestrator cleanup) finish before the process ends.
 func main() {
 	os.Exit(run())
 }
 
+// exitCode maps a run error from the orchestrator to cli's exit codes. Errors
+// that aren't an interruption or benchmark failure are infrastructure.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return cli.ExitOK
+	case errors.Is(err, orchestrator.ErrInterrupted), errors.Is(err, context.Canceled):
+		return cli.ExitInterrupted
+	case errors.Is(err, orchestrator.ErrFailures):
+		return cli.ExitFailures
+	default:
+		return cli.ExitInfra
+	}
+}
+
 func run() int {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -27,10 +46,23 @@ func run() int {
 	cliOpts, err := cli.ParseFlags(os.Args[1:])
 	if err != nil {
 		if errors.Is(err, cli.ErrHelp) {
-			return 0
+			return cli.ExitOK
 		}
 		cli.Failf("Failed to parse flags: %v", err)
-		return 1
+		return cli.ExitConfig
+	}
+
+	if cliOpts != nil {
+		if cliOpts.NDJSON {
+			cli.EnableNDJSON()
+		}
+		if err = cli.SetLatencyFormat(cliOpts.LatencyUnit, cliOpts.LatencyPrec); err != nil {
+			cli.Failf("Failed to parse flags: %v", err)
+			return cli.ExitConfig
+		}
+	}
+	if (cliOpts != nil && cliOpts.NoColor) || !cli.ColorSupported() {
+		cli.DisableColor()
 	}
 
 	// Conformance mode runs plain HTTP against a base URL — no config, docker, or metrics.
@@ -43,20 +75,51 @@ func run() int {
 		configFile = cliOpts.ConfigFile
 	}
 
+	// Self-test mode is target mode against the built-in in-process server, so
+	// the client pipeline can be tried or checked without Docker.
+	if cliOpts != nil && cliOpts.SelfTest {
+		return runSelfTest(ctx, cliOpts, configFile)
+	}
+
+	if cliOpts != nil && cliOpts.Lint {
+		return runLint(configFile)
+	}
+
 	// Target mode benchmarks one externally-managed server: no roster, no
 	// containers, no compose stacks, no metrics DB (calibration gate, PLAN §7.6).
 	if cliOpts != nil && cliOpts.Target != "" {
 		cfg, target, loadErr := config.LoadTarget(configFile, cliOpts.Target)
 		if loadErr != nil {
 			cli.Failf("Failed to load configuration: %v", loadErr)
-			return 1
+			return cli.ExitConfig
+		}
+		printConfigWarnings(cfg)
+		if !applyTagFilter([]*config.ResolvedServer{target}, cliOpts.TagFilter) {
+			return cli.ExitConfig
+		}
+		if !applyRunOverrides(cfg, []*config.ResolvedServer{target}, runtimeOptions(cliOpts)) {
+			return cli.ExitConfig
+		}
+		if done, code := dumpResolved(cliOpts, []*config.ResolvedServer{target}); done {
+			return code
 		}
 		cfg.Print(1)
-		if runErr := orchestrator.RunTarget(ctx, cfg, target, cliOpts.Target, resultsDir(cliOpts)); runErr != nil {
+		if cliOpts.Smoke {
+			if smokeErr := orchestrator.RunTargetSmoke(ctx, cfg, target, cliOpts.Target); smokeErr != nil {
+				cli.Failf("Smoke test failed: %v", smokeErr)
+				return exitCode(smokeErr)
+			}
+			return cli.ExitOK
+		}
+		dir := resultsDir(cliOpts)
+		if !checkResultsDir(dir) {
+			return cli.ExitConfig
+		}
+		if runErr := orchestrator.RunTarget(ctx, cfg, target, cliOpts.Target, dir, orchestratorOptions(cliOpts)); runErr != nil {
 			cli.Failf("Benchmark failed: %v", runErr)
-			return 1
+			return exitCode(runErr)
 		}
-		return 0
+		return cli.ExitOK
 	}
 
 	// Roster is discovered from servers/*/bench.json relative to the repo root
@@ -65,13 +128,14 @@ func run() int {
 	cfg, resolvedServers, err := config.Load(configFile, serversDir)
 	if err != nil {
 		cli.Failf("Failed to load configuration: %v", err)
-		return 1
+		return cli.ExitConfig
 	}
+	printConfigWarnings(cfg)
 
 	opts, err := getRuntimeOptions(cliOpts, config.GetServerNames(resolvedServers))
 	if err != nil {
 		cli.Failf("Failed to get options: %v", err)
-		return 1
+		return cli.ExitConfig
 	}
 
 	var invalidServers []string
@@ -81,20 +145,206 @@ func run() int {
 	}
 	if len(resolvedServers) == 0 {
 		cli.Failf("No valid servers selected")
-		return 1
+		return cli.ExitConfig
+	}
+	if !applyTagFilter(resolvedServers, opts.Tags) {
+		return cli.ExitConfig
+	}
+	if !applyRunOverrides(cfg, resolvedServers, opts) {
+		return cli.ExitConfig
+	}
+	if done, code := dumpResolved(cliOpts, resolvedServers); done {
+		return code
+	}
+
+	if !checkBaselines(cliOpts) {
+		return cli.ExitConfig
+	}
+	dir := resultsDir(cliOpts)
+	if !checkResultsDir(dir) {
+		return cli.ExitConfig
 	}
 
 	cfg.Print(len(resolvedServers))
 
 	repoRoot := ".."
-	noMetrics := cliOpts != nil && cliOpts.NoMetrics
-	orch := orchestrator.New(cfg, resolvedServers, repoRoot, resultsDir(cliOpts), noMetrics)
+	orch := orchestrator.New(cfg, resolvedServers, repoRoot, dir, orchestratorOptions(cliOpts))
 
 	if err := orch.Run(ctx); err != nil {
 		cli.Failf("Benchmark failed: %v", err)
-		return 1
+		return exitCode(err)
+	}
+	return cli.ExitOK
+}
+
+// runLint prints --lint's findings as warnings and fails when there are any,
+// so a CI step can gate on the config staying free of dead entries.
+func runLint(configFile string) int {
+	findings, err := config.Lint(configFile)
+	if err != nil {
+		cli.Failf("Failed to load configuration: %v", err)
+		return cli.ExitConfig
+	}
+	for _, finding := range findings {
+		cli.Warnf("%s", finding)
+	}
+	if len(findings) > 0 {
+		cli.Failf("Lint found %d issue(s) in %s", len(findings), configFile)
+		return cli.ExitConfig
+	}
+	cli.Infof("Lint found no issues in %s", configFile)
+	return cli.ExitOK
+}
+
+// runSelfTest runs target mode against the built-in selftest server, started
+// for the run and stopped after it.
+func runSelfTest(ctx context.Context, cliOpts *cli.Options, configFile string) int {
+	srv := selftest.Start()
+	defer srv.Close()
+
+	cfg, target, err := config.LoadTarget(configFile, srv.URL)
+	if err != nil {
+		cli.Failf("Failed to load configuration: %v", err)
+		return cli.ExitConfig
+	}
+	printConfigWarnings(cfg)
+	target.Name = selftest.ServerName
+	if !applyTagFilter([]*config.ResolvedServer{target}, cliOpts.TagFilter) {
+		return cli.ExitConfig
+	}
+	if !applyRunOverrides(cfg, []*config.ResolvedServer{target}, runtimeOptions(cliOpts)) {
+		return cli.ExitConfig
+	}
+	if done, code := dumpResolved(cliOpts, []*config.ResolvedServer{target}); done {
+		return code
+	}
+	cfg.Print(1)
+	if cliOpts.Smoke {
+		if smokeErr := orchestrator.RunTargetSmoke(ctx, cfg, target, srv.URL); smokeErr != nil {
+			cli.Failf("Smoke test failed: %v", smokeErr)
+			return exitCode(smokeErr)
+		}
+		return cli.ExitOK
+	}
+	dir := resultsDir(cliOpts)
+	if !checkResultsDir(dir) {
+		return cli.ExitConfig
+	}
+	if runErr := orchestrator.RunSelfTest(ctx, cfg, target, srv.URL, dir, orchestratorOptions(cliOpts)); runErr != nil {
+		cli.Failf("Self-test failed: %v", runErr)
+		return exitCode(runErr)
 	}
-	return 0
+	return cli.ExitOK
+}
+
+// applyTagFilter narrows servers to the tagged endpoints, warning about tags
+// no endpoint declares. It reports false when nothing is left to run.
+func applyTagFilter(servers []*config.ResolvedServer, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	if unknown := config.ApplyTagFilter(servers, tags); len(unknown) > 0 {
+		cli.Warnf("Unknown tags ignored: %s", strings.Join(unknown, ", "))
+	}
+	if !servers[0].HasWork() {
+		cli.Failf("No endpoints match --tag-filter=%s", strings.Join(tags, ","))
+		return false
+	}
+	cli.Infof("Tag filter: %s", strings.Join(tags, ", "))
+	return true
+}
+
+// printConfigWarnings prints what the config resolved with but probably
+// didn't mean, such as a query key set in both an endpoint's path and its
+// query map.
+func printConfigWarnings(cfg *config.Config) {
+	for _, warning := range cfg.Warnings {
+		cli.Warnf("%s", warning)
+	}
+}
+
+// applyRunOverrides applies the --profile/--duration/--concurrency overrides
+// before the configuration prints, naming the profile when one was chosen,
+// then splits total_duration over the endpoints left to run. It reports false
+// when the split fails.
+func applyRunOverrides(cfg *config.Config, servers []*config.ResolvedServer, opts *config.RuntimeOptions) bool {
+	config.ApplyRunOverrides(cfg, servers, opts)
+	if opts.Profile != "" {
+		cli.Infof("Profile: %s", opts.Profile)
+	}
+	if err := config.ApplyTotalDuration(cfg, servers); err != nil {
+		cli.Failf("Failed to load configuration: %v", err)
+		return false
+	}
+	if cfg.Benchmark.TotalDuration > 0 && cfg.Benchmark.DurationPerEndpoint == 0 {
+		for _, s := range servers {
+			cli.Infof("%s: %s per weight unit of total_duration", s.Name, s.DurationPerEndpoint)
+		}
+	}
+	return true
+}
+
+// dumpResolved writes --dump-resolved and reports whether run should return
+// now with code: on a write failure, or after the dump under --dump-only.
+func dumpResolved(cliOpts *cli.Options, servers []*config.ResolvedServer) (bool, int) {
+	if cliOpts == nil || cliOpts.DumpResolved == "" {
+		return false, cli.ExitOK
+	}
+	if err := config.DumpResolved(cliOpts.DumpResolved, servers); err != nil {
+		cli.Failf("Failed to dump resolved configuration: %v", err)
+		return true, cli.ExitConfig
+	}
+	cli.Infof("Resolved configuration written to %s", cliOpts.DumpResolved)
+	return cliOpts.DumpOnly, cli.ExitOK
+}
+
+// baselineDir is the store behind --save-baseline and --compare-baseline,
+// next to the timestamped results directories, or beside --results-dir.
+func baselineDir(cliOpts *cli.Options) string {
+	return filepath.Join(filepath.Dir(resultsDir(cliOpts)), "baselines")
+}
+
+// checkBaselines fails before the run rather than after it: a
+// --compare-baseline that can't be read, or a --save-baseline that exists
+// and is neither confirmed nor forced. It reports false to stop.
+func checkBaselines(cliOpts *cli.Options) bool {
+	if cliOpts == nil {
+		return true
+	}
+	if cliOpts.CompareBaseline != "" {
+		if _, err := summary.LoadBaseline(baselineDir(cliOpts), cliOpts.CompareBaseline); err != nil {
+			cli.Failf("Failed to load baseline: %v", err)
+			return false
+		}
+	}
+	if cliOpts.SaveBaseline == "" || cliOpts.Force {
+		return true
+	}
+	path, err := summary.BaselinePath(baselineDir(cliOpts), cliOpts.SaveBaseline)
+	if err != nil {
+		cli.Failf("Failed to parse flags: %v", err)
+		return false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return true
+	}
+	overwrite, err := cli.ConfirmOverwriteBaseline(cliOpts.SaveBaseline)
+	if err != nil || !overwrite {
+		cli.Failf("Baseline %s already exists at %s; pass --force to overwrite it", cliOpts.SaveBaseline, path)
+		return false
+	}
+	return true
+}
+
+// checkResultsDir confirms the results directory takes writes before a
+// benchmark that would otherwise finish with nowhere to save. It reports
+// false to stop.
+func checkResultsDir(dir string) bool {
+	if err := summary.CheckResultsDir(dir); err != nil {
+		cli.Failf("Results directory %s is not writable: %v", dir, err)
+		return false
+	}
+	return true
 }
 
 func resultsDir(cliOpts *cli.Options) string {
@@ -104,11 +354,40 @@ func resultsDir(cliOpts *cli.Options) string {
 	return filepath.Join("..", "results", time.Now().UTC().Format("20060102-150405"))
 }
 
+func orchestratorOptions(cliOpts *cli.Options) orchestrator.Options {
+	if cliOpts == nil {
+		return orchestrator.Options{}
+	}
+	return orchestrator.Options{
+		NoMetrics:    cliOpts.NoMetrics,
+		MarkdownPath: cliOpts.MarkdownFile,
+		Pull:         cliOpts.Pull,
+		Smoke:        cliOpts.Smoke,
+		LeakCheck:    cliOpts.LeakCheck,
+		RawLatencies: cliOpts.RawLatencies,
+		Timeseries:   cliOpts.Timeseries,
+		CompactJSON:  cliOpts.CompactJSON,
+		FailOnError:  cliOpts.FailOnError,
+
+		BaselineDir:     baselineDir(cliOpts),
+		SaveBaseline:    cliOpts.SaveBaseline,
+		CompareBaseline: cliOpts.CompareBaseline,
+		Ranking: summary.RankOptions{
+			SortBy: cliOpts.SortBy,
+			Desc:   cliOpts.SortDesc,
+			Asc:    cliOpts.SortAsc,
+			Top:    cliOpts.Top,
+
+			Methodology: cliOpts.Methodology,
+			Interim:     cliOpts.Interim,
+			BarChart:    cliOpts.BarChart,
+		},
+	}
+}
+
 func getRuntimeOptions(cliOpts *cli.Options, availableServers []string) (*config.RuntimeOptions, error) {
 	if cliOpts != nil {
-		return &config.RuntimeOptions{
-			Servers: cliOpts.Servers,
-		}, nil
+		return runtimeOptions(cliOpts), nil
 	}
 
 	cli.PrintBanner()
@@ -117,7 +396,22 @@ func getRuntimeOptions(cliOpts *cli.Options, availableServers []string) (*config
 		return nil, err
 	}
 
+	return runtimeOptions(opts), nil
+}
+
+func runtimeOptions(opts *cli.Options) *config.RuntimeOptions {
 	return &config.RuntimeOptions{
-		Servers: opts.Servers,
-	}, nil
+		Servers:      opts.Servers,
+		Tags:         opts.TagFilter,
+		Profile:      opts.Profile,
+		Duration:     opts.Duration,
+		Concurrency:  opts.Concurrency,
+		Warmup:       opts.Warmup,
+		WarmupPause:  opts.WarmupPause,
+		Cooldown:     opts.Cooldown,
+		ConnStats:    opts.ConnStats,
+		ExportWarmup: opts.ExportWarmup,
+		TracePhases:  opts.TracePhases,
+		BestWindow:   opts.BestWindow,
+	}
 }
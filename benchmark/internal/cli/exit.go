@@ -0,0 +1,12 @@
+package cli
+
+// Process exit codes, so CI can tell a broken config from broken
+// infrastructure from a server that failed the benchmark. printHelp documents
+// them; keep the two in sync.
+const (
+	ExitOK          = 0
+	ExitConfig      = 1 // flags, config file, server or tag selection, --dump-resolved
+	ExitInfra       = 2 // Docker, compose, metrics DB, database resets, result export
+	ExitFailures    = 3 // benchmark failures (--fail-on-error, abort_below_success_rate, smoke, conformance)
+	ExitInterrupted = 4 // SIGINT/SIGTERM
+)
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/json/v2"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ndjson   bool
+	eventsMu sync.Mutex
+	eventOut io.Writer = os.Stdout
+)
+
+// EnableNDJSON switches stdout to the --ndjson event stream: the human output
+// is dropped and failures and warnings move to stderr.
+func EnableNDJSON() {
+	ndjson = true
+	out = io.Discard
+	errOut = os.Stderr
+}
+
+// NDJSON reports whether the --ndjson event stream is on.
+func NDJSON() bool {
+	return ndjson
+}
+
+type event struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data,omitempty"`
+}
+
+// Event writes one newline-delimited JSON event when --ndjson is on. data is
+// marshaled as-is with opts, so callers pass the existing result structs.
+func Event(kind string, data any, opts ...json.Options) {
+	if !ndjson {
+		return
+	}
+	line, err := json.Marshal(event{Event: kind, Time: time.Now().UTC(), Data: data}, opts...)
+	if err != nil {
+		Failf("Failed to encode %s event: %v", kind, err)
+		return
+	}
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	_, _ = eventOut.Write(append(line, '\n'))
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"strings"
+	"testing"
+)
+
+// Not parallel: EnableNDJSON switches the package-level writers.
+func TestEventStream(t *testing.T) {
+	var events, human, errs bytes.Buffer
+	prevOut, prevErrOut, prevEvents := out, errOut, eventOut
+	t.Cleanup(func() {
+		ndjson, out, errOut, eventOut = false, prevOut, prevErrOut, prevEvents
+	})
+
+	out, eventOut = &human, &events
+	Event("ignored", nil)
+	if events.Len() != 0 {
+		t.Fatalf("event written without --ndjson: %q", events.String())
+	}
+
+	EnableNDJSON()
+	errOut = &errs
+	eventOut = &events
+	Infof("table row")
+	Warnf("careful")
+	Event("server_started", map[string]string{"server": "go-chi"})
+	Event("server_completed", map[string]int{"count": 3})
+
+	if human.Len() != 0 {
+		t.Errorf("human output not suppressed: %q", human.String())
+	}
+	if !strings.Contains(errs.String(), "careful") {
+		t.Errorf("warning not on the error stream: %q", errs.String())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(events.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d event lines, want 2: %q", len(lines), events.String())
+	}
+	var first struct {
+		Event string            `json:"event"`
+		Data  map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("event is not JSON: %v", err)
+	}
+	if first.Event != "server_started" || first.Data["server"] != "go-chi" {
+		t.Errorf("first event: got %+v", first)
+	}
+}
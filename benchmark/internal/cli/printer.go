@@ -2,6 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,52 +21,125 @@ const (
 	Indent = "  "
 )
 
+// out receives the human-readable output and errOut the failures and
+// warnings; both are stdout unless --ndjson takes stdout over (EnableNDJSON).
+var (
+	out    io.Writer = os.Stdout
+	errOut io.Writer = os.Stdout
+)
+
+// color is false once DisableColor ran: no ANSI escape sequences (banner
+// gradient, progress redraws) and ASCII in place of the symbols and box
+// drawing, so CI logs and non-UTF-8 terminals stay readable.
+var color = true
+
+var asciiReplacer = strings.NewReplacer(
+	SymbolPass, "+", SymbolFail, "x", SymbolArrow, "->", SymbolDot, "*", SymbolWarning, "!", SymbolInfo, "i",
+	"✅", "+", "❌", "x",
+	"─", "-", "━", "=", "═", "=", "│", "|", "║", "|",
+	"┌", "+", "└", "+", "╔", "+", "╗", "+", "╚", "+", "╝", "+",
+	"—", "-", "·", ".", "µ", "u", "×", "x", "≈", "~", "±", "+/-",
+	// Bar-chart blocks: whole cells become '#', the eighth-cell tips drop.
+	"█", "#", "▉", "", "▊", "", "▋", "", "▌", "", "▍", "", "▎", "", "▏", "",
+)
+
+// asciiWriter transliterates the glyphs this package and its callers print
+// to ASCII; anything else (server names, response bodies) passes through.
+type asciiWriter struct {
+	w io.Writer
+}
+
+func (a asciiWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(a.w, asciiReplacer.Replace(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ColorSupported reports whether stdout looks able to render colors and
+// Unicode: a terminal, NO_COLOR (https://no-color.org) unset and TERM not
+// "dumb".
+func ColorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// TerminalWidth is the width scaled output (--bar-chart) fits: $COLUMNS when
+// it holds a positive number, else 80.
+func TerminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return 80
+}
+
+// DisableColor switches the output to plain ASCII (--no-color, or
+// !ColorSupported). Call it once at startup, after EnableNDJSON.
+func DisableColor() {
+	color = false
+	out = asciiWriter{out}
+	errOut = asciiWriter{errOut}
+}
+
+// Printf writes free-form human output, e.g. the summary tables.
+func Printf(format string, args ...any) {
+	fmt.Fprintf(out, format, args...)
+}
+
+// Println writes one free-form line of human output.
+func Println(line string) {
+	fmt.Fprintln(out, line)
+}
+
 func Header(title string) {
 	width := 60
 	padding := (width - len(title) - 2) / 2
 	border := strings.Repeat("═", width)
 
-	fmt.Println()
-	fmt.Printf("╔%s╗\n", border)
-	fmt.Printf("║%s %s %s║\n", strings.Repeat(" ", padding), title, strings.Repeat(" ", width-padding-len(title)-2))
-	fmt.Printf("╚%s╝\n", border)
-	fmt.Println()
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "╔%s╗\n", border)
+	fmt.Fprintf(out, "║%s %s %s║\n", strings.Repeat(" ", padding), title, strings.Repeat(" ", width-padding-len(title)-2))
+	fmt.Fprintf(out, "╚%s╝\n", border)
+	fmt.Fprintln(out)
 }
 
 func Section(title string) {
-	fmt.Printf("\n━━ %s ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n", title)
+	fmt.Fprintf(out, "\n━━ %s ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n", title)
 }
 
 func ServerHeader(name string) {
-	fmt.Printf("\n┌─ %s %s\n", name, strings.Repeat("─", 58-len(name)))
+	fmt.Fprintf(out, "\n┌─ %s %s\n", name, strings.Repeat("─", 58-len(name)))
 }
 
 func ServerFooter() {
-	fmt.Println("└" + strings.Repeat("─", 60))
+	fmt.Fprintln(out, "└"+strings.Repeat("─", 60))
 }
 
 func Infof(format string, args ...any) {
-	fmt.Printf("%s%s %s\n", Indent, SymbolInfo, fmt.Sprintf(format, args...))
+	fmt.Fprintf(out, "%s%s %s\n", Indent, SymbolInfo, fmt.Sprintf(format, args...))
 }
 
 func Successf(format string, args ...any) {
-	fmt.Printf("%s%s %s\n", Indent, SymbolPass, fmt.Sprintf(format, args...))
+	fmt.Fprintf(out, "%s%s %s\n", Indent, SymbolPass, fmt.Sprintf(format, args...))
 }
 
 func Failf(format string, args ...any) {
-	fmt.Printf("%s%s %s\n", Indent, SymbolFail, fmt.Sprintf(format, args...))
+	fmt.Fprintf(errOut, "%s%s %s\n", Indent, SymbolFail, fmt.Sprintf(format, args...))
 }
 
 func Warnf(format string, args ...any) {
-	fmt.Printf("%s%s %s\n", Indent, SymbolWarning, fmt.Sprintf(format, args...))
+	fmt.Fprintf(errOut, "%s%s %s\n", Indent, SymbolWarning, fmt.Sprintf(format, args...))
 }
 
 func Linef(format string, args ...any) {
-	fmt.Printf("%s%s\n", Indent, fmt.Sprintf(format, args...))
+	fmt.Fprintf(out, "%s%s\n", Indent, fmt.Sprintf(format, args...))
 }
 
 func KeyValue(key, value string) {
-	fmt.Printf("%s%-20s %s\n", Indent, key+":", value)
+	fmt.Fprintf(out, "%s%-20s %s\n", Indent, key+":", value)
 }
 
 func KeyValuePairs(pairs ...string) {
@@ -74,7 +150,7 @@ func KeyValuePairs(pairs ...string) {
 	for i := 0; i < len(pairs); i += 2 {
 		parts = append(parts, fmt.Sprintf("%s: %s", pairs[i], pairs[i+1]))
 	}
-	fmt.Printf("%s%s\n", Indent, strings.Join(parts, "  │  "))
+	fmt.Fprintf(out, "%s%s\n", Indent, strings.Join(parts, "  │  "))
 }
 
 func StatusLinef(status bool, format string, args ...any) {
@@ -82,11 +158,11 @@ func StatusLinef(status bool, format string, args ...any) {
 	if !status {
 		symbol = SymbolFail
 	}
-	fmt.Printf("%s%s %s\n", Indent, symbol, fmt.Sprintf(format, args...))
+	fmt.Fprintf(out, "%s%s %s\n", Indent, symbol, fmt.Sprintf(format, args...))
 }
 
 func Progress(current, label string, details string) {
-	fmt.Printf("%s[%s] %-12s %s\n", Indent, current, label, details)
+	fmt.Fprintf(out, "%s[%s] %-12s %s\n", Indent, current, label, details)
 }
 
 func TableHeader(columns ...string) {
@@ -96,12 +172,12 @@ func TableHeader(columns ...string) {
 		header = append(header, col)
 		separator = append(separator, strings.Repeat("─", len(col)))
 	}
-	fmt.Printf("%s%s\n", Indent, strings.Join(header, "  "))
-	fmt.Printf("%s%s\n", Indent, strings.Join(separator, "──"))
+	fmt.Fprintf(out, "%s%s\n", Indent, strings.Join(header, "  "))
+	fmt.Fprintf(out, "%s%s\n", Indent, strings.Join(separator, "──"))
 }
 
 func Blank() {
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 func FormatDuration(d time.Duration) string {
@@ -114,17 +190,66 @@ func FormatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm%ds", int(d.Minutes()), int(d.Seconds())%60)
 }
 
+// LatencyUnits are the accepted --latency-unit values; "auto" picks ns, µs,
+// or ms per value.
+var LatencyUnits = []string{"auto", "ns", "us", "ms", "s"}
+
+// latencyUnit and latencyPrecision are set once from the command line before
+// any output; precision -1 keeps each unit's default.
+var (
+	latencyUnit      = "auto"
+	latencyPrecision = -1
+)
+
+// SetLatencyFormat forces every FormatLatency call to one unit and/or a fixed
+// number of decimals, for tables that must line up across rows.
+func SetLatencyFormat(unit string, precision int) error {
+	if unit == "" {
+		unit = "auto"
+	}
+	if !slices.Contains(LatencyUnits, unit) {
+		return fmt.Errorf("latency unit must be one of %s, got %q", strings.Join(LatencyUnits, ", "), unit)
+	}
+	if precision > 9 {
+		return fmt.Errorf("latency precision must be 0-9, got %d", precision)
+	}
+	latencyUnit, latencyPrecision = unit, precision
+	return nil
+}
+
 func FormatLatency[T int64 | time.Duration](t T) string {
-	ns := int64(t)
-	if ns < 1000 {
-		return fmt.Sprintf("%5dns", ns)
+	return formatLatency(int64(t), latencyUnit, latencyPrecision)
+}
+
+func formatLatency(ns int64, unit string, precision int) string {
+	if unit == "auto" {
+		switch {
+		case ns < 1000:
+			unit = "ns"
+		case ns < 1_000_000:
+			unit = "us"
+		default:
+			unit = "ms"
+		}
+	}
+
+	var value float64
+	var suffix string
+	defaultPrecision := 2
+	switch unit {
+	case "ns":
+		value, suffix, defaultPrecision = float64(ns), "ns", 0
+	case "us":
+		value, suffix, defaultPrecision = float64(ns)/1000, "µs", 1
+	case "s":
+		value, suffix, defaultPrecision = float64(ns)/1e9, "s", 3
+	default:
+		value, suffix = float64(ns)/1_000_000, "ms"
 	}
-	if ns < 1_000_000 {
-		us := float64(ns) / 1000
-		return fmt.Sprintf("%5.1fµs", us)
+	if precision < 0 {
+		precision = defaultPrecision
 	}
-	ms := float64(ns) / 1_000_000
-	return fmt.Sprintf("%5.2fms", ms)
+	return fmt.Sprintf("%5.*f%s", precision, value, suffix)
 }
 
 func FormatMemory(bytes float64) string {
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatLatency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ns        int64
+		unit      string
+		precision int
+		want      string
+	}{
+		{512, "auto", -1, "  512ns"},
+		{123_456, "auto", -1, "123.5µs"},
+		{2_500_000, "auto", -1, " 2.50ms"},
+		{123_456, "ms", 3, "0.123ms"},
+		{2_500_000, "us", 0, " 2500µs"},
+		{1_500_000_000, "s", -1, "1.500s"},
+		{123_456, "auto", 3, "123.456µs"},
+		{42, "ns", 2, "42.00ns"},
+	}
+	for _, tt := range tests {
+		if got := formatLatency(tt.ns, tt.unit, tt.precision); got != tt.want {
+			t.Errorf("formatLatency(%d, %q, %d): got %q, want %q", tt.ns, tt.unit, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestASCIIWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := asciiWriter{&buf}
+	fmt.Fprintf(w, "╔═══╗\n║ %s ║\n╚═══╝\n", "BENCHMARK SUMMARY")
+	fmt.Fprintf(w, "━━ Configuration ━━\n┌─ go/chi ──\n└──\n")
+	fmt.Fprintf(w, "%s %s %s %s %s %s\n", SymbolPass, SymbolFail, SymbolArrow, SymbolDot, SymbolWarning, SymbolInfo)
+	fmt.Fprintf(w, "a: 1  │  b: 2 — 12.5µs ≈ tied, 3 × weight, ±2%% ✅ ❌ ·\n")
+
+	got := buf.String()
+	for i, r := range got {
+		if r > 0x7f || r == 0x1b {
+			t.Fatalf("non-ASCII %q at byte %d in %q", r, i, got)
+		}
+	}
+	if !strings.Contains(got, "+ x -> * ! i") || !strings.Contains(got, "12.5us ~ tied") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRenderBannerWithoutColor(t *testing.T) {
+	t.Parallel()
+
+	plain := renderBanner(false)
+	if strings.ContainsRune(plain, 0x1b) || strings.ContainsFunc(plain, func(r rune) bool { return r > 0x7f }) {
+		t.Errorf("plain banner has escapes or non-ASCII: %q", plain)
+	}
+	if !strings.Contains(plain, "BENCH") {
+		t.Errorf("plain banner: got %q, want the name", plain)
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Profile is a named preset of run-size overrides. Zero fields keep the
+// config file's value, so "standard" is the config exactly as written.
+type Profile struct {
+	Name        string
+	Description string
+	Duration    time.Duration // duration_per_endpoint
+	Concurrency int           // benchmark.concurrency
+	Warmup      time.Duration // fixed warmup_duration (replaces warmup_until_stable)
+	WarmupPause time.Duration // warmup_pause
+	Cooldown    time.Duration // server_cooldown
+}
+
+// Profiles are the accepted --profile values, in prompt order.
+//
+//	quick     2s per endpoint, 50 workers, 1s warmup, 1s pause, 2s cooldown
+//	standard  config values unchanged
+//	thorough  30s per endpoint, 400 workers, 10s warmup (pause/cooldown from config)
+var Profiles = []Profile{
+	{
+		Name:        "quick",
+		Description: "2s/endpoint, 50 workers, short warmup — a fast sanity pass",
+		Duration:    2 * time.Second,
+		Concurrency: 50,
+		Warmup:      time.Second,
+		WarmupPause: time.Second,
+		Cooldown:    2 * time.Second,
+	},
+	{
+		Name:        "standard",
+		Description: "config values as written",
+	},
+	{
+		Name:        "thorough",
+		Description: "30s/endpoint, 400 workers, 10s warmup — publishable numbers",
+		Duration:    30 * time.Second,
+		Concurrency: 400,
+		Warmup:      10 * time.Second,
+	},
+}
+
+// LookupProfile returns the profile called name.
+func LookupProfile(name string) (Profile, error) {
+	for _, p := range Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	names := make([]string, len(Profiles))
+	for i, p := range Profiles {
+		names[i] = p.Name
+	}
+	return Profile{}, fmt.Errorf("--profile must be one of %s, got %q", strings.Join(names, ", "), name)
+}
+
+// applyProfile fills the options a profile sets. Explicit --duration and
+// --concurrency flags win regardless of flag order.
+func (o *Options) applyProfile(p Profile) {
+	o.Profile = p.Name
+	if o.Duration == 0 {
+		o.Duration = p.Duration
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = p.Concurrency
+	}
+	o.Warmup = p.Warmup
+	o.WarmupPause = p.WarmupPause
+	o.Cooldown = p.Cooldown
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFlagsProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args            []string
+		wantDuration    time.Duration
+		wantConcurrency int
+		wantWarmup      time.Duration
+	}{
+		{[]string{"--profile=quick"}, 2 * time.Second, 50, time.Second},
+		{[]string{"--profile=standard"}, 0, 0, 0},
+		{[]string{"--profile=thorough"}, 30 * time.Second, 400, 10 * time.Second},
+		// Explicit flags win over the profile regardless of order.
+		{[]string{"--concurrency=10", "--profile=quick"}, 2 * time.Second, 10, time.Second},
+		{[]string{"--profile=thorough", "--duration=1m"}, time.Minute, 400, 10 * time.Second},
+		{[]string{"--duration=3s"}, 3 * time.Second, 0, 0},
+	}
+
+	for _, tt := range tests {
+		opts, err := ParseFlags(tt.args)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		if opts.Duration != tt.wantDuration || opts.Concurrency != tt.wantConcurrency || opts.Warmup != tt.wantWarmup {
+			t.Errorf("%v: got duration %v concurrency %d warmup %v, want %v %d %v", tt.args,
+				opts.Duration, opts.Concurrency, opts.Warmup, tt.wantDuration, tt.wantConcurrency, tt.wantWarmup)
+		}
+	}
+
+	if _, err := ParseFlags([]string{"--profile=huge"}); err == nil {
+		t.Error("unknown profile: expected an error")
+	}
+}
@@ -2,117 +2,184 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
 var spinnerChars = []rune{'⠋', '⠙', '⠹', '⠸', '⠼', '⠴', '⠦', '⠧', '⠇', '⠏'}
 
-type ProgressSpinner struct {
+// ProgressBoard is the single renderer behind every progress line: it draws
+// one spinner line per server being benchmarked and redraws them together on
+// each tick. Updates and redraws all take its lock, so servers running at the
+// same time — or callbacks fired from different goroutines — never interleave
+// their escape sequences on the terminal.
+type ProgressBoard struct {
 	mu           sync.Mutex
+	w            io.Writer // nil = out, read at draw time so --ndjson still silences it
+	lines        []*ServerProgress
+	drawn        int // lines on screen from the last render
 	spinnerIndex int
+	running      bool
+	stopCh       chan struct{}
+	doneCh       chan struct{}
+}
+
+// ServerProgress is one server's line on a ProgressBoard, from Track until
+// Stop.
+type ServerProgress struct {
+	board        *ProgressBoard
+	name         string
 	startTime    time.Time
 	message      string
 	endpointDone int
 	endpointTot  int
 	sequenceDone int
 	sequenceTot  int
-	running      bool
-	stopCh       chan struct{}
-	doneCh       chan struct{}
 }
 
-func NewProgressSpinner() *ProgressSpinner {
-	return &ProgressSpinner{
-		stopCh: make(chan struct{}),
-		doneCh: make(chan struct{}),
-	}
+var progressBoard = &ProgressBoard{}
+
+// TrackProgress adds a line for server to the shared progress board, starting
+// its renderer if this is the only line.
+func TrackProgress(server string, endpointCount, sequenceCount int) *ServerProgress {
+	return progressBoard.Track(server, endpointCount, sequenceCount)
 }
 
-func (p *ProgressSpinner) Start(endpointCount, sequenceCount int) {
-	p.mu.Lock()
-	p.startTime = time.Now()
-	p.endpointTot = endpointCount
-	p.sequenceTot = sequenceCount
-	p.endpointDone = 0
-	p.sequenceDone = 0
-	p.message = ""
-	p.running = true
-	p.mu.Unlock()
-
-	go p.run()
+func (b *ProgressBoard) Track(server string, endpointCount, sequenceCount int) *ServerProgress {
+	p := &ServerProgress{
+		board:       b,
+		name:        server,
+		startTime:   time.Now(),
+		endpointTot: endpointCount,
+		sequenceTot: sequenceCount,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, p)
+	if !b.running {
+		b.running = true
+		b.stopCh = make(chan struct{})
+		b.doneCh = make(chan struct{})
+		go b.run(b.stopCh, b.doneCh)
+	}
+	return p
 }
 
-func (p *ProgressSpinner) run() {
-	defer close(p.doneCh)
+func (b *ProgressBoard) run(stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-p.stopCh:
-			p.clearLine()
+		case <-stopCh:
 			return
 		case <-ticker.C:
-			p.render()
+			b.render()
 		}
 	}
 }
 
-func (p *ProgressSpinner) render() {
-	p.mu.Lock()
-	if !p.running {
-		p.mu.Unlock()
+// render redraws every line in place. Without color there are no cursor
+// escapes to redraw with, so nothing is drawn: a log gets no spinner frames.
+func (b *ProgressBoard) render() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.lines) == 0 || !color {
 		return
 	}
 
-	spinner := spinnerChars[p.spinnerIndex]
-	p.spinnerIndex = (p.spinnerIndex + 1) % len(spinnerChars)
+	spinner := spinnerChars[b.spinnerIndex]
+	b.spinnerIndex = (b.spinnerIndex + 1) % len(spinnerChars)
+
+	rendered := make([]string, len(b.lines))
+	for i, p := range b.lines {
+		rendered[i] = p.line(spinner, len(b.lines) > 1)
+	}
+	fmt.Fprint(b.writer(), b.clearSequence()+strings.Join(rendered, "\n"))
+	b.drawn = len(rendered)
+}
+
+// clearSequence erases the lines the last render drew, leaving the cursor at
+// the start of the first. Callers hold b.mu.
+func (b *ProgressBoard) clearSequence() string {
+	if b.drawn == 0 {
+		return "\r\033[K"
+	}
+	return "\r\033[K" + strings.Repeat("\033[1A\033[K", b.drawn-1)
+}
+
+func (b *ProgressBoard) writer() io.Writer {
+	if b.w != nil {
+		return b.w
+	}
+	return out
+}
 
+// line formats p's status; the server name is shown only when several lines
+// share the board.
+func (p *ServerProgress) line(spinner rune, named bool) string {
 	elapsed := time.Since(p.startTime)
 	mins := int(elapsed.Minutes())
 	secs := int(elapsed.Seconds()) % 60
 
-	line := fmt.Sprintf("%s  %c %s  [%d/%d endpoints]  [%d/%d sequences]  elapsed: %dm%02ds",
+	message := p.message
+	if named {
+		message = fmt.Sprintf("[%s] %s", p.name, message)
+	}
+	return fmt.Sprintf("%s  %c %s  [%d/%d endpoints]  [%d/%d sequences]  elapsed: %dm%02ds",
 		Indent,
 		spinner,
-		p.message,
+		message,
 		p.endpointDone, p.endpointTot,
 		p.sequenceDone, p.sequenceTot,
 		mins, secs,
 	)
-	p.mu.Unlock()
-
-	fmt.Printf("\r\033[K%s", line)
-}
-
-func (p *ProgressSpinner) clearLine() {
-	fmt.Print("\r\033[K")
 }
 
-func (p *ProgressSpinner) UpdateEndpoint(method, path string, done int) {
-	p.mu.Lock()
+func (p *ServerProgress) UpdateEndpoint(method, path string, done int) {
+	p.board.mu.Lock()
 	p.message = fmt.Sprintf("Testing %s %s...", method, path)
 	p.endpointDone = done
-	p.mu.Unlock()
+	p.board.mu.Unlock()
 }
 
-func (p *ProgressSpinner) UpdateSequence(seqName string, done int) {
-	p.mu.Lock()
+func (p *ServerProgress) UpdateSequence(seqName string, done int) {
+	p.board.mu.Lock()
 	p.message = fmt.Sprintf("Testing sequence %s...", seqName)
 	p.sequenceDone = done
-	p.mu.Unlock()
+	p.board.mu.Unlock()
 }
 
-func (p *ProgressSpinner) Stop() {
-	p.mu.Lock()
-	if !p.running {
-		p.mu.Unlock()
+// Stop removes p's line and clears the board so the caller's next output
+// starts on a clean line; the renderer exits with the last line. Stopping
+// twice is a no-op.
+func (p *ServerProgress) Stop() {
+	b := p.board
+	b.mu.Lock()
+	i := slices.Index(b.lines, p)
+	if i < 0 {
+		b.mu.Unlock()
+		return
+	}
+	b.lines = slices.Delete(b.lines, i, i+1)
+	if color {
+		fmt.Fprint(b.writer(), b.clearSequence())
+	}
+	b.drawn = 0
+
+	if len(b.lines) > 0 {
+		b.mu.Unlock()
 		return
 	}
-	p.running = false
-	p.mu.Unlock()
+	b.running = false
+	stopCh, doneCh := b.stopCh, b.doneCh
+	b.mu.Unlock()
 
-	close(p.stopCh)
-	<-p.doneCh
+	close(stopCh)
+	<-doneCh
 }
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgressBoardConcurrentServers(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	board := &ProgressBoard{w: &buf}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			p := board.Track(fmt.Sprintf("server-%d", i), 50, 5)
+			for done := range 50 {
+				p.UpdateEndpoint("GET", "/items", done)
+				if done%10 == 0 {
+					p.UpdateSequence("crud", done/10)
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+			p.Stop()
+			p.Stop()
+		})
+	}
+	wg.Wait()
+
+	board.mu.Lock()
+	defer board.mu.Unlock()
+	if len(board.lines) != 0 || board.running {
+		t.Errorf("after every Stop: got %d lines, running %v, want 0 and false", len(board.lines), board.running)
+	}
+	if !strings.HasSuffix(buf.String(), "\r\033[K") {
+		t.Errorf("output must end cleared, got tail %q", buf.String()[max(0, buf.Len()-20):])
+	}
+}
+
+func TestProgressBoardNamesLinesWhenShared(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	board := &ProgressBoard{w: &buf}
+	a := board.Track("alpha", 3, 0)
+	b := board.Track("beta", 2, 0)
+	a.UpdateEndpoint("GET", "/a", 1)
+	b.UpdateEndpoint("POST", "/b", 2)
+	board.render()
+	a.Stop()
+	board.render()
+	b.Stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "[alpha] Testing GET /a...") || !strings.Contains(out, "[beta] Testing POST /b...") {
+		t.Errorf("shared board should prefix server names, got %q", out)
+	}
+	if !strings.Contains(out, "\033[1A") {
+		t.Errorf("redrawing two lines should move the cursor up, got %q", out)
+	}
+	if strings.Count(out, "Testing POST /b") == strings.Count(out, "[beta] Testing POST /b") {
+		t.Errorf("a board with one line left should drop the name prefix, got %q", out)
+	}
+}
@@ -3,7 +3,11 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
@@ -13,6 +17,7 @@ type Options struct {
 	Servers      []string // empty means all servers
 	Conformance  bool     // run the contract suite instead of the benchmark
 	NoMetrics    bool     // run without the metrics DB (results JSON still written)
+	FailOnError  bool     // exit 3 when any server errored or saw failed requests
 	BaseURL      string   // base URL for conformance runs
 	ContractDir  string   // contract cases directory for conformance runs
 	TestFilesDir string   // upload fixtures directory for conformance runs
@@ -21,8 +26,51 @@ type Options struct {
 	Target       string   // benchmark one externally-managed server at this base URL (no containers, no metrics)
 	ConfigFile   string   // config file path override (default ../config/config.json)
 	ResultsDir   string   // results output directory override (default ../results/<timestamp>)
+	MarkdownFile string   // also write the final summary as GitHub-flavored Markdown to this path
+	TagFilter    []string // only run endpoints carrying at least one of these tags
+	SortBy       string   // final summary ranking key, one of SortKeys (default avg)
+	SortDesc     bool     // rank by descending SortBy value
+	SortAsc      bool     // rank by ascending SortBy value, e.g. lowest RPS first
+	Top          int      // show only the first N ranked servers (0 = all)
+	Pull         bool     // docker pull missing server images instead of failing
+	Smoke        bool     // send one request per testcase and flow, report, and skip the load phase
+	SelfTest     bool     // benchmark the built-in in-process server instead of containers
+	LeakCheck    bool     // warn if goroutines or open fds grow across a server's run
+	RawLatencies string   // write per-request latency CSVs to this directory
+	Timeseries   string   // write per-second latency percentiles per endpoint to this directory
+	CompactJSON  bool     // write result files without indentation
+	ConnStats    bool     // trace new vs reused connections and connection queue delay per endpoint
+	ExportWarmup bool     // also write warmup latencies to the metrics DB tagged phase=warmup
+	TracePhases  bool     // break latency into DNS/connect/TLS/TTFB per endpoint
+	BestWindow   bool     // report each endpoint's lowest-P50 one-second window
+	Methodology  bool     // footnote how each endpoint's numbers were produced in the final summary
+	Interim      bool     // print the rankings so far after each server completes
+	BarChart     bool     // draw avg latency per server as bars after the final rankings
+	NDJSON       bool     // emit newline-delimited JSON events on stdout instead of the tables
+	LatencyUnit  string   // force latency output to one of LatencyUnits (default auto)
+	LatencyPrec  int      // fixed latency decimals; -1 keeps the unit default
+	Profile      string   // applied Profiles entry name, empty when none
+	DumpResolved string   // write the fully-resolved servers as JSON to this path
+	DumpOnly     bool     // exit after --dump-resolved instead of running
+	Lint         bool     // report dead endpoints, flows, captures and per_database settings, then exit
+	NoColor      bool     // plain ASCII output without ANSI colors (also automatic off a terminal or with NO_COLOR)
+
+	// Baseline store, baselines/ beside the results directory.
+	SaveBaseline    string // copy this run's results.json into the baseline store under this name
+	CompareBaseline string // compare this run against the stored baseline of this name
+	Force           bool   // overwrite an existing --save-baseline without asking
+
+	// Run-size overrides from --duration/--concurrency and Profile; zero keeps the config value.
+	Duration    time.Duration
+	Concurrency int
+	Warmup      time.Duration
+	WarmupPause time.Duration
+	Cooldown    time.Duration
 }
 
+// SortKeys are the accepted --sort-by values.
+var SortKeys = []string{"avg", "p95", "p99", "rps", "mem", "cpu", "tail-ratio", "efficiency"}
+
 var bannerLines = []string{
 	"██████╗ ███████╗███╗   ██╗ ██████╗██╗  ██╗",
 	"██╔══██╗██╔════╝████╗  ██║██╔════╝██║  ██║",
@@ -70,8 +118,18 @@ func getGradientColor(t float64) [3]float64 {
 }
 
 func PrintBanner() {
-	fmt.Println()
+	fmt.Fprint(out, renderBanner(color))
+}
+
+// renderBanner draws the gradient banner, or without color just the name as
+// plain text.
+func renderBanner(colored bool) string {
+	if !colored {
+		return "\n" + Indent + "BENCH\n\n"
+	}
 
+	var b strings.Builder
+	b.WriteString("\n")
 	height := len(bannerLines)
 	width := 0
 	for _, line := range bannerLines {
@@ -93,9 +151,10 @@ func PrintBanner() {
 			))
 			result.WriteString(style.Render(string(r)))
 		}
-		fmt.Println(result.String())
+		b.WriteString(result.String() + "\n")
 	}
-	fmt.Println()
+	b.WriteString("\n")
+	return b.String()
 }
 
 func PromptOptions(availableServers []string) (*Options, error) {
@@ -103,6 +162,11 @@ func PromptOptions(availableServers []string) (*Options, error) {
 
 	var serverMode string
 	var selectedServers []string
+	profileName := "standard"
+	profileOptions := make([]huh.Option[string], len(Profiles))
+	for i, p := range Profiles {
+		profileOptions[i] = huh.NewOption(fmt.Sprintf("%s — %s", p.Name, p.Description), p.Name)
+	}
 	serverOptions := make([]huh.Option[string], len(availableServers))
 	for i, s := range availableServers {
 		serverOptions[i] = huh.NewOption(s, s)
@@ -124,6 +188,12 @@ func PromptOptions(availableServers []string) (*Options, error) {
 				Options(serverOptions...).
 				Value(&selectedServers),
 		).WithHideFunc(func() bool { return serverMode != "select" }),
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select a run profile").
+				Options(profileOptions...).
+				Value(&profileName),
+		),
 	).WithTheme(huh.ThemeCatppuccin()).WithKeyMap(huh.NewDefaultKeyMap())
 
 	if err := form.Run(); err != nil {
@@ -137,6 +207,12 @@ func PromptOptions(availableServers []string) (*Options, error) {
 		opts.Servers = selectedServers
 	}
 
+	profile, err := LookupProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	opts.applyProfile(profile)
+
 	return &opts, nil
 }
 
@@ -147,9 +223,10 @@ func ParseFlags(args []string) (*Options, error) {
 		return nil, nil
 	}
 
-	opts := Options{}
+	opts := Options{LatencyPrec: -1}
 	hasExplicitFlags := false
 	var unknownFlags []string
+	var profile *Profile
 
 	for _, arg := range args {
 		switch {
@@ -166,6 +243,9 @@ func ParseFlags(args []string) (*Options, error) {
 		case arg == "--conformance":
 			opts.Conformance = true
 			hasExplicitFlags = true
+		case arg == "--fail-on-error":
+			opts.FailOnError = true
+			hasExplicitFlags = true
 		case arg == "--no-metrics":
 			opts.NoMetrics = true
 			hasExplicitFlags = true
@@ -194,12 +274,165 @@ func ParseFlags(args []string) (*Options, error) {
 				return nil, errors.New("--target requires a URL")
 			}
 			hasExplicitFlags = true
+		case arg == "--self-test":
+			opts.SelfTest = true
+			hasExplicitFlags = true
 		case strings.HasPrefix(arg, "--config="):
 			opts.ConfigFile = strings.TrimSpace(strings.TrimPrefix(arg, "--config="))
 			hasExplicitFlags = true
 		case strings.HasPrefix(arg, "--results-dir="):
 			opts.ResultsDir = strings.TrimSpace(strings.TrimPrefix(arg, "--results-dir="))
 			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--markdown="):
+			opts.MarkdownFile = strings.TrimSpace(strings.TrimPrefix(arg, "--markdown="))
+			if opts.MarkdownFile == "" {
+				return nil, errors.New("--markdown requires a file path")
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--tag-filter="):
+			for s := range strings.SplitSeq(strings.TrimPrefix(arg, "--tag-filter="), ",") {
+				if trimmed := strings.TrimSpace(s); trimmed != "" {
+					opts.TagFilter = append(opts.TagFilter, trimmed)
+				}
+			}
+			if len(opts.TagFilter) == 0 {
+				return nil, errors.New("--tag-filter requires at least one tag")
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--sort-by="):
+			opts.SortBy = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(arg, "--sort-by=")))
+			if !slices.Contains(SortKeys, opts.SortBy) {
+				return nil, fmt.Errorf("--sort-by must be one of %s, got %q", strings.Join(SortKeys, ", "), opts.SortBy)
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--latency-unit="):
+			opts.LatencyUnit = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(arg, "--latency-unit=")))
+			if opts.LatencyUnit == "µs" {
+				opts.LatencyUnit = "us"
+			}
+			if !slices.Contains(LatencyUnits, opts.LatencyUnit) {
+				return nil, fmt.Errorf("--latency-unit must be one of %s, got %q", strings.Join(LatencyUnits, ", "), opts.LatencyUnit)
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--latency-precision="):
+			prec, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(arg, "--latency-precision=")))
+			if err != nil || prec < 0 || prec > 9 {
+				return nil, fmt.Errorf("--latency-precision must be 0-9, got %q", strings.TrimPrefix(arg, "--latency-precision="))
+			}
+			opts.LatencyPrec = prec
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--raw-latencies="):
+			opts.RawLatencies = strings.TrimSpace(strings.TrimPrefix(arg, "--raw-latencies="))
+			if opts.RawLatencies == "" {
+				return nil, errors.New("--raw-latencies requires a directory")
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--timeseries="):
+			opts.Timeseries = strings.TrimSpace(strings.TrimPrefix(arg, "--timeseries="))
+			if opts.Timeseries == "" {
+				return nil, errors.New("--timeseries requires a directory")
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--dump-resolved="):
+			opts.DumpResolved = strings.TrimSpace(strings.TrimPrefix(arg, "--dump-resolved="))
+			if opts.DumpResolved == "" {
+				return nil, errors.New("--dump-resolved requires a file path")
+			}
+			hasExplicitFlags = true
+		case arg == "--dump-only":
+			opts.DumpOnly = true
+			hasExplicitFlags = true
+		case arg == "--lint":
+			opts.Lint = true
+			hasExplicitFlags = true
+		case arg == "--ndjson":
+			opts.NDJSON = true
+			hasExplicitFlags = true
+		case arg == "--no-color":
+			opts.NoColor = true
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--save-baseline="):
+			opts.SaveBaseline = strings.TrimSpace(strings.TrimPrefix(arg, "--save-baseline="))
+			if opts.SaveBaseline == "" {
+				return nil, errors.New("--save-baseline requires a name")
+			}
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--compare-baseline="):
+			opts.CompareBaseline = strings.TrimSpace(strings.TrimPrefix(arg, "--compare-baseline="))
+			if opts.CompareBaseline == "" {
+				return nil, errors.New("--compare-baseline requires a name")
+			}
+			hasExplicitFlags = true
+		case arg == "--force":
+			opts.Force = true
+			hasExplicitFlags = true
+		case arg == "--compact-json":
+			opts.CompactJSON = true
+			hasExplicitFlags = true
+		case arg == "--conn-stats":
+			opts.ConnStats = true
+			hasExplicitFlags = true
+		case arg == "--export-warmup":
+			opts.ExportWarmup = true
+			hasExplicitFlags = true
+		case arg == "--trace-phases":
+			opts.TracePhases = true
+			hasExplicitFlags = true
+		case arg == "--best-window":
+			opts.BestWindow = true
+			hasExplicitFlags = true
+		case arg == "--show-methodology":
+			opts.Methodology = true
+			hasExplicitFlags = true
+		case arg == "--interim-rankings":
+			opts.Interim = true
+			hasExplicitFlags = true
+		case arg == "--bar-chart":
+			opts.BarChart = true
+			hasExplicitFlags = true
+		case arg == "--leak-check":
+			opts.LeakCheck = true
+			hasExplicitFlags = true
+		case arg == "--smoke":
+			opts.Smoke = true
+			hasExplicitFlags = true
+		case arg == "--pull":
+			opts.Pull = true
+			hasExplicitFlags = true
+		case arg == "--sort-desc":
+			opts.SortDesc = true
+			hasExplicitFlags = true
+		case arg == "--sort-asc":
+			opts.SortAsc = true
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--profile="):
+			p, err := LookupProfile(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(arg, "--profile="))))
+			if err != nil {
+				return nil, err
+			}
+			profile = &p
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--duration="):
+			d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(arg, "--duration=")))
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("--duration must be a positive duration (e.g. 10s), got %q", strings.TrimPrefix(arg, "--duration="))
+			}
+			opts.Duration = d
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--concurrency="):
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(arg, "--concurrency=")))
+			if err != nil || n < 1 || n > 10000 {
+				return nil, fmt.Errorf("--concurrency must be 1-10000, got %q", strings.TrimPrefix(arg, "--concurrency="))
+			}
+			opts.Concurrency = n
+			hasExplicitFlags = true
+		case strings.HasPrefix(arg, "--top="):
+			top, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(arg, "--top=")))
+			if err != nil || top < 1 {
+				return nil, fmt.Errorf("--top must be a positive integer, got %q", strings.TrimPrefix(arg, "--top="))
+			}
+			opts.Top = top
+			hasExplicitFlags = true
 		case arg == "--help" || arg == "-h":
 			printHelp()
 			return nil, ErrHelp
@@ -212,6 +445,36 @@ func ParseFlags(args []string) (*Options, error) {
 		return nil, fmt.Errorf("unknown flags: %s", strings.Join(unknownFlags, ", "))
 	}
 
+	if profile != nil {
+		opts.applyProfile(*profile)
+	}
+
+	if opts.NDJSON && (opts.Conformance || opts.Smoke) {
+		return nil, errors.New("--ndjson cannot be combined with --conformance or --smoke")
+	}
+
+	if opts.SortDesc && opts.SortAsc {
+		return nil, errors.New("--sort-desc cannot be combined with --sort-asc")
+	}
+
+	if opts.DumpOnly && opts.DumpResolved == "" {
+		return nil, errors.New("--dump-only requires --dump-resolved=PATH")
+	}
+	if opts.Lint && (opts.Target != "" || opts.SelfTest || opts.Smoke || opts.Conformance) {
+		return nil, errors.New("--lint only checks the config; it cannot be combined with --target, --self-test, --smoke or --conformance")
+	}
+	if opts.DumpResolved != "" && opts.Conformance {
+		return nil, errors.New("--dump-resolved cannot be combined with --conformance")
+	}
+
+	if (opts.SaveBaseline != "" || opts.CompareBaseline != "") && (opts.Target != "" || opts.SelfTest || opts.Smoke || opts.Conformance || opts.DumpOnly) {
+		return nil, errors.New("--save-baseline and --compare-baseline need a full run's results.json; they cannot be combined with --target, --self-test, --smoke, --conformance or --dump-only")
+	}
+
+	if opts.SelfTest && (opts.Target != "" || opts.Conformance || len(opts.Servers) > 0) {
+		return nil, errors.New("--self-test cannot be combined with --target, --servers or --conformance")
+	}
+
 	if opts.Target != "" {
 		if opts.Conformance || len(opts.Servers) > 0 {
 			return nil, errors.New("--target cannot be combined with --servers or --conformance")
@@ -235,22 +498,104 @@ Options:
   --servers=a,b,c    Only benchmark specific servers (comma-separated)
   --conformance      Run the contract conformance suite instead of the benchmark
   --no-metrics       Run without the metrics DB (results JSON still written)
+  --fail-on-error    Exit 3 when any server errored or any request failed (CI gate)
   --base-url=URL     Base URL for --conformance (default http://localhost:8080)
   --contract-dir=DIR Contract cases directory for --conformance (default ../contract)
   --test-files-dir=DIR Upload fixtures directory for --conformance (default ../contract/test-files)
   --skip-suite=a,b   Contract suites to load but not run (per-server gating, e.g. web)
   --jwt-secret=SECRET Shared HS256 secret for the web suite's $jwt matcher (default dev secret)
   --target=URL       Benchmark one externally-managed server at URL (no containers, no metrics DB)
-  --config=PATH      Config file override (default ../config/config.json)
+  --self-test        Benchmark a built-in in-process server (no Docker) to try the tool or check the client
+  --config=PATH      Config file override (default ../config/config.json); upload fixtures resolve relative to it
   --results-dir=DIR  Results output directory override (default ../results/<timestamp>)
+  --smoke            Send one request per endpoint and flow, report pass/fail, skip the load phase
+  --leak-check       Warn if goroutines or open fds grow across a server's run (debug)
+  --conn-stats       Count new vs reused keep-alive connections per endpoint and the p99 time requests
+                     queued for one in the client (adds tracing overhead)
+  --export-warmup    Also write warmup latencies to the metrics DB with phase=warmup
+  --trace-phases     Break latency into DNS, connect, TLS and time-to-first-byte per endpoint in the
+                     results JSON (adds tracing overhead)
+  --best-window      Also report each endpoint's quietest 1s window (lowest P50, at least 20
+                     requests) in the results JSON, for hosts with intermittent interference
+  --pull             docker pull missing server images before failing (registry-hosted images)
+  --ndjson           Emit JSON-lines events (server_started, endpoint_completed, server_completed,
+                     run_completed) on stdout instead of the tables; failures and warnings go to stderr
+  --no-color         Plain ASCII output without colors or box drawing (automatic when stdout is not a
+                     terminal, NO_COLOR is set or TERM=dumb)
+  --markdown=PATH    Also write the final summary as Markdown (for pasting into PRs)
+  --raw-latencies=DIR Also write every request latency as CSV (<server>__<endpoint>.csv) to DIR
+  --timeseries=DIR   Also write per-second p50/p95/p99 per endpoint as CSV (<server>__timeseries.csv) to DIR
+  --save-baseline=NAME Copy this run's results.json to baselines/NAME.json beside the results directory
+                     (../results/baselines, or next to --results-dir; asks before overwriting, --force
+                     overwrites without asking)
+  --compare-baseline=NAME Compare avg, p99 and RPS per server against baseline NAME after the run
+  --force            Overwrite an existing --save-baseline without asking
+  --compact-json     Write the results JSON files without indentation (smaller; default is pretty-printed)
+  --dump-resolved=PATH Write the fully-resolved servers, testcases and flows as JSON to PATH (after
+                     defaults, per_database expansion, variations and overrides), then run
+  --dump-only        Exit after --dump-resolved instead of running (no Docker needed)
+  --lint             Check the config without Docker and warn about endpoints and flows that never
+                     run, captures no later step uses, and per_database endpoints with no databases;
+                     exits 1 when anything is found (for CI)
+  --tag-filter=a,b   Only run endpoints tagged with any of these tags (unknown tags warn)
+  --sort-by=KEY      Order the summary rankings by avg|p95|p99|rps|mem|cpu|tail-ratio|efficiency,
+                     best first (default avg; rps and efficiency rank highest first, the rest lowest;
+                     tail-ratio is mean endpoint p99/p50, lower is more predictable; efficiency is
+                     mean endpoint RPS per MB of memory)
+  --sort-desc        Rank by descending value (e.g. --sort-by=mem --sort-desc for most memory first)
+  --sort-asc         Rank by ascending value (e.g. --sort-by=rps --sort-asc for lowest RPS first)
+  --top=N            Show only the first N servers in the summary rankings
+  --bar-chart        Draw each ranked server's avg latency as a bar after the rankings, scaled to
+                     $COLUMNS (the slowest server spans the width; '#' bars with --no-color)
+  --interim-rankings Print the rankings of the servers finished so far after each one (same
+                     --sort-by/--top), for watching long runs; the final summary still follows
+  --show-methodology Footnote each endpoint's sample count, exact or estimated percentiles, warmup
+                     and measured time after the summary tables
+  --latency-unit=U   Print every latency in one unit: auto|ns|us|ms|s (default auto; JSON stays ns)
+  --latency-precision=N Fixed decimals for printed latencies (0-9, default per unit)
+  --profile=NAME     Run-size preset: quick (2s/endpoint, 50 workers, 1s warmup, 1s pause, 2s cooldown),
+                     standard (config as written), thorough (30s/endpoint, 400 workers, 10s warmup)
+  --duration=D       Override duration_per_endpoint (wins over --profile)
+  --concurrency=N    Override benchmark.concurrency (wins over --profile)
   --help, -h         Show this help message
 
 Interactive mode:
   Run without flags to use interactive selection.
 
+Exit codes:
+  0  Success
+  1  Usage or configuration error (flags, config file, server selection, --dump-resolved,
+     --lint findings)
+  2  Docker or infrastructure error (images, compose, metrics DB, resets, result export)
+  3  Benchmark failures: with --fail-on-error, a server errored or a request failed; also
+     abort_below_success_rate, failed --smoke checks and failed --conformance cases
+  4  Interrupted (SIGINT/SIGTERM)
+
 Examples:
   benchmark                                            # Interactive mode
   benchmark --servers=go-chi,go-gin                    # Benchmark specific servers
+  benchmark --tag-filter=read,auth                     # Only endpoints tagged read or auth
+  benchmark --servers=go-chi --smoke                   # Validate the config against one server
+  benchmark --sort-by=p99 --top=5                      # Five lowest-p99 servers
+  benchmark --profile=quick --concurrency=10           # Quick pass with 10 workers
   benchmark --conformance --base-url=http://localhost:8080  # Run the contract gate
-  benchmark --target=http://localhost:8080 --config=../config/calibration.json  # External target`)
+  benchmark --target=http://localhost:8080 --config=../config/calibration.json  # External target
+  benchmark --self-test --profile=quick                # Demo run without Docker
+  benchmark --save-baseline=main                       # Record a baseline once...
+  benchmark --compare-baseline=main                    # ...and compare every later run to it`)
+}
+
+// ConfirmOverwriteBaseline asks whether to replace the existing baseline
+// name. Without a terminal on stdin there is nobody to ask: it returns false
+// and the caller points at --force.
+func ConfirmOverwriteBaseline(name string) (bool, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 || NDJSON() {
+		return false, nil
+	}
+	overwrite := false
+	err := huh.NewConfirm().
+		Title(fmt.Sprintf("Baseline %q already exists. Overwrite it after this run?", name)).
+		Value(&overwrite).
+		Run()
+	return overwrite, err
 }
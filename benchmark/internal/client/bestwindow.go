@@ -0,0 +1,61 @@
+package client
+
+import (
+	"slices"
+	"time"
+)
+
+// BestWindowWidth is the width of the windows --best-window compares.
+const BestWindowWidth = time.Second
+
+// minBestWindowRequests keeps a near-empty window (the tail of the run, a
+// stall) from winning just because its few requests happened to be fast.
+const minBestWindowRequests = 20
+
+// WindowStats is the latency of one BestWindowWidth window of an endpoint's
+// measured run, Start seconds into it.
+type WindowStats struct {
+	Start time.Duration `json:"start"`
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+}
+
+// BestWindow splits latencies into width-wide windows by EndpointOffset and
+// returns the window with the lowest P50: the endpoint's latency while the
+// machine was least disturbed, for shared hosts where interference comes and
+// goes. Windows with fewer than minBestWindowRequests requests don't compete,
+// and nil means fewer than two windows did, when there is nothing to choose.
+func BestWindow(latencies []TimedLatency, width time.Duration) *WindowStats {
+	byWindow := make(map[int64][]time.Duration)
+	for _, l := range latencies {
+		i := int64(max(l.EndpointOffset, 0) / width)
+		byWindow[i] = append(byWindow[i], l.Duration)
+	}
+
+	var best *WindowStats
+	candidates := 0
+	for i, durations := range byWindow {
+		if len(durations) < minBestWindowRequests {
+			continue
+		}
+		candidates++
+		slices.Sort(durations)
+		w := &WindowStats{
+			Start: time.Duration(i) * width,
+			Count: len(durations),
+			P50:   Percentile(durations, 50),
+			P95:   Percentile(durations, 95),
+			P99:   Percentile(durations, 99),
+		}
+		// Ties go to the earlier window so the result doesn't depend on map order.
+		if best == nil || w.P50 < best.P50 || (w.P50 == best.P50 && w.Start < best.Start) {
+			best = w
+		}
+	}
+	if candidates < 2 {
+		return nil
+	}
+	return best
+}
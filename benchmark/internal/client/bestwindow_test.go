@@ -0,0 +1,43 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBestWindow(t *testing.T) {
+	t.Parallel()
+
+	// Five seconds at ~10ms with a quiet third second at ~2ms, and a final
+	// second holding too few requests to compete despite being faster still.
+	var latencies []TimedLatency
+	add := func(second, n int, d time.Duration) {
+		for i := range n {
+			offset := time.Duration(second)*time.Second + time.Duration(i)*time.Millisecond
+			latencies = append(latencies, TimedLatency{EndpointOffset: offset, Duration: d + time.Duration(i%5)*time.Microsecond})
+		}
+	}
+	for second := range 5 {
+		d := 10 * time.Millisecond
+		if second == 2 {
+			d = 2 * time.Millisecond
+		}
+		add(second, 100, d)
+	}
+	add(5, minBestWindowRequests-1, time.Millisecond)
+
+	best := BestWindow(latencies, BestWindowWidth)
+	if best == nil {
+		t.Fatal("got nil, want the quiet window")
+	}
+	if best.Start != 2*time.Second || best.Count != 100 {
+		t.Errorf("window: got start %v count %d, want 2s and 100", best.Start, best.Count)
+	}
+	if best.P50 < 2*time.Millisecond || best.P50 > 2*time.Millisecond+5*time.Microsecond || best.P99 < best.P50 {
+		t.Errorf("percentiles: got p50 %v p99 %v, want ~2ms", best.P50, best.P99)
+	}
+
+	if got := BestWindow(latencies[:100], BestWindowWidth); got != nil {
+		t.Errorf("single window: got %+v, want nil", got)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/hex"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"benchmark-client/internal/config"
+)
+
+// renderDynamicJSON renders a dynamic endpoint's JSON body for one request.
+func renderDynamicJSON(d *config.DynamicBody) string {
+	seq := d.Seq.Add(1)
+	var b strings.Builder
+	renderTemplate(&b, d.JSON, seq)
+	return b.String()
+}
+
+// renderDynamicForm renders a dynamic endpoint's form fields for one request
+// and encodes them like the cached form body.
+func renderDynamicForm(d *config.DynamicBody) string {
+	seq := d.Seq.Add(1)
+	values := make(url.Values, len(d.Form))
+	var b strings.Builder
+	for field, t := range d.Form {
+		b.Reset()
+		renderTemplate(&b, t, seq)
+		values.Set(field, b.String())
+	}
+	return values.Encode()
+}
+
+// renderTemplate appends t to b. Every {{seq}} in one request gets the same
+// value; {{rand_int}} and {{uuid}} are drawn fresh at each occurrence.
+func renderTemplate(b *strings.Builder, t *config.BodyTemplate, seq uint64) {
+	for _, part := range t.Parts {
+		b.WriteString(part.Literal)
+		switch part.Token {
+		case config.TokenRandInt:
+			b.WriteString(strconv.FormatInt(rand.Int64N(1<<31), 10)) //nolint:gosec // test data generation, not security-sensitive
+		case config.TokenUUID:
+			writeUUID(b)
+		case config.TokenSeq:
+			b.WriteString(strconv.FormatUint(seq, 10))
+		}
+	}
+}
+
+// writeUUID writes a random version 4 UUID. math/rand is enough here: the
+// values only need to differ, not be unpredictable.
+func writeUUID(b *strings.Builder) {
+	var raw [16]byte
+	hi, lo := rand.Uint64(), rand.Uint64() //nolint:gosec // test data generation, not security-sensitive
+	for i := range 8 {
+		raw[i] = byte(hi >> (56 - 8*i))
+		raw[8+i] = byte(lo >> (56 - 8*i))
+	}
+	raw[6] = raw[6]&0x0f | 0x40 // version 4
+	raw[8] = raw[8]&0x3f | 0x80 // RFC 4122 variant
+
+	var text [36]byte
+	hex.Encode(text[0:8], raw[0:4])
+	text[8] = '-'
+	hex.Encode(text[9:13], raw[4:6])
+	text[13] = '-'
+	hex.Encode(text[14:18], raw[6:8])
+	text[18] = '-'
+	hex.Encode(text[19:23], raw[8:10])
+	text[23] = '-'
+	hex.Encode(text[24:], raw[10:])
+	b.Write(text[:])
+}
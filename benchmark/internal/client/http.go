@@ -1,21 +1,210 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"sync"
 	"time"
 )
 
-func NewHTTPTransport(workers int) *http.Transport {
+// defaultDialTimeout bounds connecting when benchmark.connect_timeout is unset.
+const defaultDialTimeout = 5 * time.Second
+
+// NewHTTPTransport sizes the keep-alive pool for workers concurrent requests.
+// maxConns > 0 (benchmark.max_conns) instead caps connections per host, so
+// workers beyond it wait for a free connection like a pool-limited client.
+// connectTimeout > 0 (benchmark.connect_timeout) bounds both the dial and the
+// wait for response headers; reading the body stays under the request's own
+// deadline.
+func NewHTTPTransport(workers, maxConns int, connectTimeout time.Duration) *http.Transport {
+	idle := workers * 2
+	if maxConns > 0 {
+		idle = maxConns
+	}
+	dialTimeout := defaultDialTimeout
+	if connectTimeout > 0 {
+		dialTimeout = connectTimeout
+	}
 	return &http.Transport{
 		DialContext: (&net.Dialer{
-			Timeout:   5 * time.Second,
+			Timeout:   dialTimeout,
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
-		MaxIdleConns:        workers * 2,
-		MaxIdleConnsPerHost: workers * 2,
-		IdleConnTimeout:     90 * time.Second,
-		DisableCompression:  true,
-		ForceAttemptHTTP2:   false,
+		ResponseHeaderTimeout: connectTimeout,
+		MaxIdleConns:          idle,
+		MaxIdleConnsPerHost:   idle,
+		MaxConnsPerHost:       maxConns,
+		IdleConnTimeout:       90 * time.Second,
+		DisableCompression:    true,
+		ForceAttemptHTTP2:     false,
+	}
+}
+
+// readBody reads at most limit bytes of body. One extra byte is requested so
+// a response that exceeds the cap is reported as truncated instead of being
+// indistinguishable from one that is exactly limit bytes long.
+func readBody(body io.Reader, limit int64) (data []byte, truncated bool, err error) {
+	data, err = io.ReadAll(io.LimitReader(body, limit+1))
+	if int64(len(data)) > limit {
+		return data[:limit], true, err
+	}
+	return data, false, err
+}
+
+// bodyTooLargeError is returned instead of validating a truncated body.
+func bodyTooLargeError(limit int64) error {
+	return fmt.Errorf("response exceeded max_body_bytes (%d)", limit)
+}
+
+// errRequestTimeout is the cancellation cause of a per-request deadline. It
+// is deliberately not context.DeadlineExceeded: a request that outlives
+// RequestTimeout is a failure of the server, while an endpoint window or
+// shutdown expiring mid-request is a benign cancellation, and both would
+// otherwise surface as the same DeadlineExceeded.
+var errRequestTimeout = errors.New("request timeout")
+
+// withRequestTimeout derives the per-request context, tagging its deadline
+// with errRequestTimeout so classifyRequestTimeout can tell it apart.
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, timeout, errRequestTimeout)
+}
+
+// classifyRequestTimeout replaces an error caused by the request's own
+// deadline with a "request timeout" failure that isBenchmarkContextCancellation
+// does not treat as a cancellation. net/http reports the context cause, but
+// body reads and ctx.Err() checks report plain DeadlineExceeded, so both are
+// matched. Deadlines inherited from the parent (endpoint duration, shutdown)
+// pass through unchanged.
+func classifyRequestTimeout(reqCtx context.Context, err error, timeout time.Duration) error {
+	if err == nil || !errors.Is(context.Cause(reqCtx), errRequestTimeout) {
+		return err
+	}
+	if !errors.Is(err, errRequestTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %s", errRequestTimeout, timeout)
+}
+
+// classifyConnectTimeout labels a transport timeout — the dial or the wait
+// for response headers outlasting connect_timeout — so it reads apart from
+// the request_timeout cap on the whole exchange. The request's own deadline
+// and inherited cancellations pass through unchanged.
+func classifyConnectTimeout(reqCtx context.Context, err error, timeout time.Duration) error {
+	if err == nil || timeout <= 0 || reqCtx.Err() != nil {
+		return err
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+	return fmt.Errorf("connect timeout after %s: %w", timeout, err)
+}
+
+// connCounter tallies, per endpoint, whether each request got a fresh or a
+// pooled keep-alive connection (--conn-stats). A low reuse share at high
+// concurrency means the idle pool is churning. It also keeps how long each
+// request queued for its connection: under max_conns, or past what the pool
+// can serve, requests wait in the transport before the server sees them.
+type connCounter struct {
+	mu     sync.Mutex
+	counts map[string]*connTally
+
+	// The waits are kept like latencies, bounded by max_samples and the
+	// estimator, so --conn-stats adds no per-request memory of its own.
+	maxSamples int
+	estimator  string
+}
+
+type connTally struct {
+	fresh, reused int
+	waits         *latencyReservoir // GetConn to GotConn, less the request's own dial
+}
+
+// connWait is one request's connection acquisition. Dial hooks run on the
+// transport's dial goroutine, hence the lock.
+type connWait struct {
+	mu                  sync.Mutex
+	getConn             time.Time
+	dialStart, dialDone time.Time
+}
+
+// trace returns ctx instrumented to count endpoint's connection.
+func (c *connCounter) trace(ctx context.Context, endpoint string) context.Context {
+	var w connWait
+	dialStarted := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.dialStart.IsZero() {
+			w.dialStart = time.Now()
+		}
+	}
+	dialDone := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.dialDone = time.Now()
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			w.getConn = time.Now()
+		},
+		DNSStart:         func(httptrace.DNSStartInfo) { dialStarted() },
+		ConnectStart:     func(string, string) { dialStarted() },
+		ConnectDone:      func(string, string, error) { dialDone() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) { dialDone() },
+		GotConn: func(info httptrace.GotConnInfo) {
+			w.mu.Lock()
+			wait := time.Since(w.getConn)
+			if !info.Reused && w.dialDone.After(w.dialStart) {
+				wait -= w.dialDone.Sub(w.dialStart)
+			}
+			w.mu.Unlock()
+			c.record(endpoint, info.Reused, max(wait, 0))
+		},
+	})
+}
+
+func (c *connCounter) record(endpoint string, reused bool, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]*connTally)
+	}
+	n := c.counts[endpoint]
+	if n == nil {
+		n = &connTally{waits: newLatencyReservoir(c.maxSamples, c.estimator)}
+		c.counts[endpoint] = n
+	}
+	if reused {
+		n.reused++
+	} else {
+		n.fresh++
+	}
+	n.waits.add(TimedLatency{Duration: wait})
+}
+
+func (c *connCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = nil
+}
+
+// apply moves endpoint's counts and p99 queue delay onto stats and forgets them.
+func (c *connCounter) apply(endpoint string, stats *Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.counts[endpoint]
+	delete(c.counts, endpoint)
+	if n == nil || stats == nil {
+		return
 	}
+	stats.NewConns, stats.ReusedConns = n.fresh, n.reused
+	stats.QueueDelayP99 = n.waits.stats(n.waits.seen, 0).P99
 }
@@ -161,7 +161,7 @@ type dispatchOutcome struct {
 // arrival into a dropped iteration instead of delaying the clock.
 func (s *Suite) runOpenTestcases(testcases []*config.Testcase) *runOutcome {
 	load := s.server.Load
-	sched := newArrivalSchedule(load, s.server.DurationPerEndpoint)
+	sched := newArrivalSchedule(load, s.server.EndpointWindow(testcases[0]))
 	start := time.Now()
 
 	// The window extends past the schedule by one request timeout so requests
@@ -259,6 +259,11 @@ func (s *Suite) runOpenTestcases(testcases []*config.Testcase) *runOutcome {
 			lags = append(lags, r.scheduleLag)
 			continue
 		}
+		if s.anomalousLatency(r.latency) {
+			outcome.anomalousCount++
+			lags = append(lags, r.scheduleLag)
+			continue
+		}
 
 		count++
 		lags = append(lags, r.scheduleLag)
@@ -275,6 +280,12 @@ func (s *Suite) runOpenTestcases(testcases []*config.Testcase) *runOutcome {
 	elapsed := time.Since(start)
 	totalRequests := count + outcome.failureCount
 
+	outcome.elapsed = elapsed
+	if s.server.DiscardFirst > 0 {
+		latencies = discardLeading(latencies, s.server.DiscardFirst)
+		responses = discardLeading(responses, s.server.DiscardFirst)
+		outcome.timedLatencies = discardLeading(outcome.timedLatencies, s.server.DiscardFirst)
+	}
 	outcome.stats = CalculateStats(latencies, count, totalRequests, elapsed)
 
 	open := &OpenStats{
@@ -5,6 +5,8 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -121,6 +123,10 @@ func TestArrivalScheduleSilentThenRampAndDownRamp(t *testing.T) {
 
 func newTestSuite(t *testing.T, handler http.HandlerFunc, load config.LoadConfig, window time.Duration) (*Suite, []*config.Testcase) {
 	t.Helper()
+	// Cleanups run last-in first-out: the server stops, waiting out its
+	// handlers, before the suite drops its connections.
+	var suite *Suite
+	t.Cleanup(func() { suite.Close() })
 	srv := httptest.NewServer(handler)
 	t.Cleanup(srv.Close)
 
@@ -130,6 +136,7 @@ func newTestSuite(t *testing.T, handler http.HandlerFunc, load config.LoadConfig
 		Concurrency:         4,
 		Load:                load,
 		DurationPerEndpoint: window,
+		MaxBodyBytes:        1 << 20,
 	}
 	testcases := []*config.Testcase{{
 		EndpointName:   "root",
@@ -137,11 +144,10 @@ func newTestSuite(t *testing.T, handler http.HandlerFunc, load config.LoadConfig
 		Path:           "/",
 		RequestURI:     "/",
 		Method:         "GET",
-		ExpectedStatus: 200,
+		ExpectedStatus: config.ExactStatus(200),
 	}}
-	suite := NewSuite(context.Background(), server, srv.URL, nil)
+	suite = NewSuite(context.Background(), server, srv.URL, nil)
 	suite.serverStartTime = time.Now()
-	t.Cleanup(suite.Close)
 	return suite, testcases
 }
 
@@ -259,3 +265,264 @@ func TestClosedLoopUnchanged(t *testing.T) {
 		t.Error("rps not computed in closed mode")
 	}
 }
+
+func TestClosedLoopSpreadsAcrossBaseUrls(t *testing.T) {
+	t.Parallel()
+
+	var hits [2]atomic.Int64
+	hosts := make([]string, len(hits))
+	for i := range hits {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits[i].Add(1)
+		}))
+		t.Cleanup(srv.Close)
+		hosts[i] = srv.URL
+	}
+
+	server := &config.ResolvedServer{
+		Name:                "test",
+		BaseUrls:            hosts,
+		RequestTimeout:      2 * time.Second,
+		Concurrency:         4,
+		Load:                config.LoadConfig{Mode: config.LoadModeClosed},
+		DurationPerEndpoint: 200 * time.Millisecond,
+		MaxBodyBytes:        1 << 20,
+	}
+	testcases := []*config.Testcase{{
+		EndpointName:   "root",
+		Name:           "root",
+		Path:           "/",
+		RequestURI:     "/",
+		Method:         "GET",
+		ExpectedStatus: config.ExactStatus(200),
+	}}
+	suite := NewSuite(context.Background(), server, hosts[0], nil)
+	suite.serverStartTime = time.Now()
+	t.Cleanup(suite.Close)
+
+	outcome := suite.runTestcases(testcases)
+	if outcome.failureCount > 0 {
+		t.Fatalf("unexpected failures: %s", outcome.lastError)
+	}
+	a, b := hits[0].Load(), hits[1].Load()
+	if a == 0 || b == 0 || math.Abs(float64(a-b)) > 4 {
+		t.Errorf("host hits: got %d and %d, want an even round-robin split", a, b)
+	}
+}
+
+func TestClosedLoopClassifiesRequestTimeouts(t *testing.T) {
+	t.Parallel()
+
+	hang := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	// RequestTimeout shorter than the window: the request's own deadline
+	// fires first, so every hung request is a failure, not a cancellation.
+	suite, testcases := newTestSuite(t, hang, config.LoadConfig{Mode: config.LoadModeClosed}, 300*time.Millisecond)
+	suite.server.RequestTimeout = 50 * time.Millisecond
+	outcome := suite.runTestcases(testcases)
+	if outcome.failureCount == 0 || !strings.Contains(outcome.lastError, "request timeout after 50ms") {
+		t.Errorf("request timeouts: got %d failures, last error %q", outcome.failureCount, outcome.lastError)
+	}
+
+	// Window shorter than RequestTimeout: in-flight requests are cut by the
+	// endpoint deadline and stay benign cancellations.
+	suite, testcases = newTestSuite(t, hang, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+	suite.server.RequestTimeout = 5 * time.Second
+	outcome = suite.runTestcases(testcases)
+	if outcome.failureCount != 0 || outcome.canceledCount == 0 {
+		t.Errorf("window expiry: got %d failures, %d canceled, want only cancellations", outcome.failureCount, outcome.canceledCount)
+	}
+}
+
+func TestMaxConnsCapsConnectionsBelowWorkers(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, peak atomic.Int64
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	server := &config.ResolvedServer{
+		Name:                "test",
+		RequestTimeout:      2 * time.Second,
+		Concurrency:         8,
+		MaxConns:            2,
+		Load:                config.LoadConfig{Mode: config.LoadModeClosed},
+		DurationPerEndpoint: 150 * time.Millisecond,
+		MaxBodyBytes:        1 << 20,
+	}
+	suite := NewSuite(context.Background(), server, srv.URL, nil)
+	suite.serverStartTime = time.Now()
+	t.Cleanup(suite.Close)
+	testcases := []*config.Testcase{{
+		EndpointName: "root", Name: "root", Path: "/", RequestURI: "/", Method: "GET",
+		ExpectedStatus: config.ExactStatus(200),
+	}}
+
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	if result.Stats.Count == 0 {
+		t.Fatal("no successful requests")
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak in-flight at the server: got %d, want at most max_conns 2 with 8 workers", got)
+	}
+}
+
+func TestConnStatsQueueDelayUnderMaxConns(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	server := &config.ResolvedServer{
+		Name:                "test",
+		RequestTimeout:      2 * time.Second,
+		Concurrency:         8,
+		MaxConns:            1,
+		ConnStats:           true,
+		Load:                config.LoadConfig{Mode: config.LoadModeClosed},
+		DurationPerEndpoint: 150 * time.Millisecond,
+		MaxBodyBytes:        1 << 20,
+	}
+	suite := NewSuite(context.Background(), server, srv.URL, nil)
+	suite.serverStartTime = time.Now()
+	t.Cleanup(suite.Close)
+	testcases := []*config.Testcase{{
+		EndpointName: "root", Name: "root", Path: "/", RequestURI: "/", Method: "GET",
+		ExpectedStatus: config.ExactStatus(200),
+	}}
+
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	if result.Stats.Count == 0 {
+		t.Fatal("no successful requests")
+	}
+	// Eight workers share one connection, so most wait several 2ms requests for it.
+	if got := result.Stats.QueueDelayP99; got < 4*time.Millisecond || got > result.Stats.P99 {
+		t.Errorf("queue delay p99: got %v, want several requests' wait, within latency p99 %v", got, result.Stats.P99)
+	}
+}
+
+func TestRequestBudgetStopsAtExactCount(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantCount   int
+		wantFailure int
+	}{
+		{"successes", okHandler, 200, 0},
+		{"never succeeds", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) }, 0, 200},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			// The hour-long window would time the test out if the budget were ignored.
+			suite, testcases := newTestSuite(t, tc.handler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Hour)
+			suite.server.RequestsPerEndpoint = 200
+
+			result := suite.runEndpoint("root", "/", "GET", testcases)
+			if result.Stats.Count != tc.wantCount || result.FailureCount != tc.wantFailure {
+				t.Errorf("got %d successes and %d failures, want %d and %d",
+					result.Stats.Count, result.FailureCount, tc.wantCount, tc.wantFailure)
+			}
+			if result.DurationMs <= 0 {
+				t.Errorf("got duration %dms, want the measured window reported", result.DurationMs)
+			}
+		})
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	t.Parallel()
+
+	notFound := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) }
+	var served atomic.Int64
+	failsAfterWarm := func(w http.ResponseWriter, _ *http.Request) {
+		if served.Add(1) > 20 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	cases := []struct {
+		name           string
+		handler        http.HandlerFunc
+		window         time.Duration
+		wantFailedFast bool
+	}{
+		// The hour-long window would time the test out if fail_fast didn't stop it.
+		{"always 404", notFound, time.Hour, true},
+		{"fails after successes", failsAfterWarm, 200 * time.Millisecond, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			suite, testcases := newTestSuite(t, tc.handler, config.LoadConfig{Mode: config.LoadModeClosed}, tc.window)
+			suite.server.FailFast = 10
+
+			result := suite.runEndpoint("root", "/", "GET", testcases)
+			if result.FailedFast != tc.wantFailedFast {
+				t.Fatalf("failed fast: got %v, want %v", result.FailedFast, tc.wantFailedFast)
+			}
+			if tc.wantFailedFast {
+				if result.FailureCount != 10 || result.Stats.Count != 0 {
+					t.Errorf("got %d failures and %d successes, want 10 and 0", result.FailureCount, result.Stats.Count)
+				}
+				if result.StatusCounts[http.StatusNotFound] == 0 || result.LastError == "" {
+					t.Errorf("partial result should keep the 404s and last error, got %v / %q", result.StatusCounts, result.LastError)
+				}
+			} else if result.Stats.Count != 20 || result.FailureCount <= 10 {
+				t.Errorf("got %d successes and %d failures, want 20 and the rest of the window", result.Stats.Count, result.FailureCount)
+			}
+		})
+	}
+}
+
+func TestAnomalousLatency(t *testing.T) {
+	t.Parallel()
+
+	suite := &Suite{server: &config.ResolvedServer{RequestTimeout: time.Second}}
+	cases := []struct {
+		latency time.Duration
+		want    bool
+	}{
+		{-time.Millisecond, true},
+		{0, false},
+		{900 * time.Millisecond, false},
+		{2 * time.Second, false}, // a slow tail stays in up to twice the timeout
+		{2*time.Second + time.Nanosecond, true},
+		{time.Hour, true},
+	}
+	for _, tc := range cases {
+		if got := suite.anomalousLatency(tc.latency); got != tc.want {
+			t.Errorf("anomalousLatency(%v): got %v, want %v", tc.latency, got, tc.want)
+		}
+	}
+
+	unbounded := &Suite{server: &config.ResolvedServer{}}
+	if unbounded.anomalousLatency(time.Hour) || !unbounded.anomalousLatency(-1) {
+		t.Error("without a request_timeout only negative samples are anomalous")
+	}
+}
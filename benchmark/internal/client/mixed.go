@@ -0,0 +1,347 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+// Mixed mode (benchmark.mixed_mode) replaces the one-endpoint-at-a-time loop
+// with a single closed-loop worker pool that draws every endpoint by weight
+// for one shared window, approximating realistic mixed traffic. It stresses
+// the server differently: endpoints contend for the same connections,
+// goroutines, and DB pools at once, so per-endpoint numbers are not
+// comparable with sequential runs. The window is duration_per_endpoint ×
+// endpoint count (under total_duration, the endpoints' summed shares),
+// keeping the wall-clock budget of a sequential run.
+
+type mixedEndpoint struct {
+	name      string
+	path      string
+	method    string
+	testcases []*config.Testcase
+	weight    int
+	current   int // smooth weighted round-robin state
+	next      int // round-robin index into testcases
+
+	stopped atomic.Bool // fail_fast gave up on the endpoint; the picker skips it
+}
+
+// mixedPicker is only touched by the feeder goroutine, so it needs no lock;
+// only the endpoints' stopped flags are set from the results loop.
+type mixedPicker struct {
+	endpoints   []*mixedEndpoint
+	totalWeight int
+	replay      []mixedWork // replay_file: the trace in order, replacing the weighted draw
+	nextReplay  int
+}
+
+func newMixedPicker(names []string, endpointTestcases map[string][]*config.Testcase) *mixedPicker {
+	p := &mixedPicker{endpoints: make([]*mixedEndpoint, 0, len(names))}
+	for _, name := range names {
+		testcases := endpointTestcases[name]
+		first := testcases[0]
+		weight := max(first.Weight, 1)
+		p.endpoints = append(p.endpoints, &mixedEndpoint{
+			name:      name,
+			path:      first.Path,
+			method:    first.Method,
+			testcases: testcases,
+			weight:    weight,
+		})
+		p.totalWeight += weight
+	}
+	return p
+}
+
+// pick implements smooth weighted round-robin (as in nginx): over every run of
+// totalWeight picks each endpoint is chosen exactly weight times, interleaved
+// rather than in bursts, with no randomness to skew short windows. Stopped
+// endpoints are skipped; with none left it returns -1.
+func (p *mixedPicker) pick() (int, *config.Testcase) {
+	if len(p.replay) > 0 {
+		for range p.replay {
+			work := p.replay[p.nextReplay%len(p.replay)]
+			p.nextReplay++
+			if !p.endpoints[work.endpoint].stopped.Load() {
+				return work.endpoint, work.tc
+			}
+		}
+		return -1, nil
+	}
+	best, totalWeight := -1, 0
+	for i, ep := range p.endpoints {
+		if ep.stopped.Load() {
+			continue
+		}
+		ep.current += ep.weight
+		totalWeight += ep.weight
+		if best < 0 || ep.current > p.endpoints[best].current {
+			best = i
+		}
+	}
+	if best < 0 {
+		return -1, nil
+	}
+	ep := p.endpoints[best]
+	ep.current -= totalWeight
+	tc := ep.testcases[ep.next%len(ep.testcases)]
+	ep.next++
+	return best, tc
+}
+
+// allStopped reports whether fail_fast has dropped every endpoint.
+func (p *mixedPicker) allStopped() bool {
+	for _, ep := range p.endpoints {
+		if !ep.stopped.Load() {
+			return false
+		}
+	}
+	return true
+}
+
+// keepMixedWarmup adds a mixed warmup window's requests to each endpoint's
+// count and keeps its latencies per endpoint when server.ExportWarmup is set;
+// otherwise the window's results are discarded.
+func (s *Suite) keepMixedWarmup(picker *mixedPicker, outcomes []*runOutcome, counts map[string]*WarmupCount) {
+	for i, ep := range picker.endpoints {
+		if counts[ep.name] == nil {
+			counts[ep.name] = &WarmupCount{}
+		}
+		counts[ep.name].add(outcomes[i].stats.Count, outcomes[i].failureCount)
+		if s.server.ExportWarmup {
+			s.keepWarmup(ep.name, ep.method, outcomes[i].timedLatencies)
+		}
+	}
+}
+
+type mixedWork struct {
+	endpoint int
+	tc       *config.Testcase
+}
+
+// runMixed warms up and then measures all endpoints concurrently (or replays
+// the trace, see replay.go). Each latency is attributed to its endpoint, so
+// per-endpoint results and timedResults keep their usual shape; the blended
+// stats across every request are kept on the suite (MixedStats).
+func (s *Suite) runMixed(names []string, endpointTestcases map[string][]*config.Testcase) []EndpointResult {
+	if len(names) == 0 {
+		return nil
+	}
+
+	if s.progress != nil && s.progress.OnEndpoint != nil {
+		if s.server.Replay {
+			s.progress.OnEndpoint("REPLAY", fmt.Sprintf("%d requests", len(s.server.Testcases)), 0)
+		} else {
+			s.progress.OnEndpoint("MIXED", fmt.Sprintf("%d endpoints", len(names)), 0)
+		}
+	}
+
+	var warmup *WarmupResult
+	warmupCounts := make(map[string]*WarmupCount)
+	if s.server.WarmupStable != nil {
+		warmup = s.warmupUntilStable(func(window time.Duration) (time.Duration, int) {
+			picker := s.newPicker(names, endpointTestcases)
+			outcomes, blended := s.runMixedWindow(picker, window)
+			s.keepMixedWarmup(picker, outcomes, warmupCounts)
+			return blended.P50, blended.Count
+		})
+	} else if s.server.WarmupDuration > 0 {
+		picker := s.newPicker(names, endpointTestcases)
+		outcomes, _ := s.runMixedWindow(picker, s.server.WarmupDuration)
+		s.keepMixedWarmup(picker, outcomes, warmupCounts)
+	}
+	if s.warmupEnabled() {
+		if s.ctx.Err() != nil {
+			return nil
+		}
+		if s.server.WarmupPause > 0 {
+			time.Sleep(s.server.WarmupPause)
+		}
+	}
+
+	picker := s.newPicker(names, endpointTestcases)
+	var window time.Duration
+	for _, name := range names {
+		window += s.server.EndpointWindow(endpointTestcases[name][0])
+	}
+	if s.server.Replay {
+		window = s.server.DurationPerEndpoint
+	}
+	s.statuses.reset()
+	s.bytes.reset()
+	s.cached.reset()
+	s.conns.reset()
+	s.phases.reset()
+	s.sse.reset()
+	s.measureStart()
+	outcomes, blended := s.runMixedWindow(picker, window)
+	s.measureEnd()
+	s.mixedStats = blended
+
+	results := make([]EndpointResult, 0, len(picker.endpoints))
+	for i, ep := range picker.endpoints {
+		outcome := outcomes[i]
+		s.conns.apply(ep.name, outcome.stats)
+		s.timedResults = append(s.timedResults, TimedResult{
+			Endpoint:  ep.name,
+			Method:    ep.method,
+			Latencies: outcome.timedLatencies,
+		})
+		results = append(results, EndpointResult{
+			Name:          ep.name,
+			Path:          ep.path,
+			Method:        ep.method,
+			Tags:          ep.testcases[0].Tags,
+			Warmup:        warmup, // one shared warmup covers every endpoint
+			WarmupCount:   warmupCounts[ep.name],
+			Stats:         outcome.stats,
+			FailureCount:  outcome.failureCount,
+			CanceledCount: outcome.canceledCount,
+			LastError:     outcome.lastError,
+			StatusCounts:  s.statuses.take(ep.name),
+			DurationMs:    outcome.elapsed.Milliseconds(),
+			ResponseBytes: s.bytes.take(ep.name),
+			Phases:        s.phases.take(ep.name),
+			BestWindow:    s.bestWindow(outcome.timedLatencies),
+			SSE:           s.sse.take(ep.name),
+			Expected:      expectedFor(ep.testcases[0]),
+			FailedFast:    outcome.failedFast,
+
+			AnomalousCount: outcome.anomalousCount,
+			CachedCount:    s.cached.take(ep.name),
+		})
+	}
+	for i := range results {
+		s.endpointDone(&results[i])
+	}
+	return results
+}
+
+func (s *Suite) runMixedWindow(picker *mixedPicker, window time.Duration) (outcomes []*runOutcome, blended *Stats) {
+	workers := s.server.Concurrency
+	windowStart := time.Now()
+
+	ctx, cancel := context.WithTimeout(s.ctx, window)
+	defer cancel()
+
+	workCh := make(chan mixedWork)
+	go func() {
+		defer close(workCh)
+		for ctx.Err() == nil {
+			endpoint, tc := picker.pick()
+			if endpoint < 0 {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case workCh <- mixedWork{endpoint: endpoint, tc: tc}:
+			}
+		}
+	}()
+
+	type result struct {
+		endpoint     int
+		latency      time.Duration
+		serverOffset time.Duration
+		windowOffset time.Duration
+		err          error
+	}
+	resultsCh := make(chan result, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for work := range workCh {
+				requestStart := time.Now()
+				latency, err := s.executeTestcase(ctx, work.tc)
+				resultsCh <- result{
+					endpoint:     work.endpoint,
+					latency:      latency,
+					serverOffset: requestStart.Sub(s.serverStartTime),
+					windowOffset: requestStart.Sub(windowStart),
+					err:          err,
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	// Each endpoint and the blend get their own reservoir, so max_samples
+	// and the estimator bound memory here as in a closed-loop endpoint run.
+	outcomes = make([]*runOutcome, len(picker.endpoints))
+	reservoirs := make([]*latencyReservoir, len(picker.endpoints))
+	for i := range outcomes {
+		outcomes[i] = &runOutcome{}
+		reservoirs[i] = newLatencyReservoir(s.server.MaxSamples, s.server.Estimator)
+	}
+	all := newLatencyReservoir(s.server.MaxSamples, s.server.Estimator)
+	var allFailures int
+
+	for r := range resultsCh {
+		outcome := outcomes[r.endpoint]
+		if outcome.failedFast {
+			// In flight when fail_fast stopped the endpoint; not part of its
+			// first N, as in runTestcases.
+			outcome.canceledCount++
+			continue
+		}
+		if r.err != nil {
+			if isBenchmarkContextCancellation(ctx, r.err) {
+				outcome.canceledCount++
+				continue
+			}
+			outcome.failureCount++
+			outcome.lastError = r.err.Error()
+			allFailures++
+			// fail_fast per endpoint, as in runTestcases: once its first N
+			// requests all failed the endpoint is dropped from the draw, and
+			// the window ends when no endpoint is left.
+			if failFast := s.server.FailFast; failFast > 0 && reservoirs[r.endpoint].seen == 0 &&
+				outcome.failureCount >= failFast {
+				outcome.failedFast = true
+				picker.endpoints[r.endpoint].stopped.Store(true)
+				if picker.allStopped() {
+					cancel()
+				}
+			}
+			continue
+		}
+		if s.anomalousLatency(r.latency) {
+			outcome.anomalousCount++
+			continue
+		}
+
+		tl := TimedLatency{
+			ServerOffset:   r.serverOffset,
+			EndpointOffset: r.windowOffset,
+			Duration:       r.latency,
+		}
+		reservoirs[r.endpoint].add(tl)
+		all.add(tl)
+	}
+
+	// Every endpoint shares the one window, so its rate is over the full window.
+	elapsed := time.Since(windowStart)
+	for i, outcome := range outcomes {
+		reservoir := reservoirs[i]
+		outcome.elapsed = elapsed
+		reservoir.discardFirst(s.server.DiscardFirst)
+		outcome.stats = reservoir.stats(reservoir.seen+outcome.failureCount, elapsed)
+		outcome.timedLatencies = reservoir.timed()
+	}
+	all.discardFirst(s.server.DiscardFirst)
+	blended = all.stats(all.seen+allFailures, elapsed)
+	return outcomes, blended
+}
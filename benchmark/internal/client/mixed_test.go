@@ -0,0 +1,166 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestMixedPickerHonorsWeights(t *testing.T) {
+	t.Parallel()
+
+	endpointTestcases := map[string][]*config.Testcase{
+		"heavy": {{EndpointName: "heavy", Path: "/heavy", Weight: 3}},
+		"light": {{EndpointName: "light", Path: "/light", Weight: 1}},
+	}
+	picker := newMixedPicker([]string{"heavy", "light"}, endpointTestcases)
+
+	counts := make(map[string]int)
+	run := 0
+	for range 40 {
+		idx, tc := picker.pick()
+		counts[picker.endpoints[idx].name]++
+		if tc.EndpointName != picker.endpoints[idx].name {
+			t.Fatalf("pick returned %s's testcase for endpoint %s", tc.EndpointName, picker.endpoints[idx].name)
+		}
+		if tc.EndpointName == "heavy" {
+			run++
+			if run > 3 {
+				t.Fatal("smooth round-robin should interleave, got 4 heavy picks in a row")
+			}
+		} else {
+			run = 0
+		}
+	}
+	if counts["heavy"] != 30 || counts["light"] != 10 {
+		t.Errorf("picks: got %v, want heavy=30 light=10", counts)
+	}
+}
+
+func TestMixedModeAttributesLatenciesPerEndpoint(t *testing.T) {
+	t.Parallel()
+
+	suite, _ := newTestSuite(t, okHandler, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+	suite.server.MixedMode = true
+	endpointTestcases := map[string][]*config.Testcase{
+		"a": {{EndpointName: "a", Path: "/a", RequestURI: "/a", Method: http.MethodGet, ExpectedStatus: config.ExactStatus(200), Weight: 1}},
+		"b": {{EndpointName: "b", Path: "/b", RequestURI: "/b", Method: http.MethodGet, ExpectedStatus: config.ExactStatus(200), Weight: 1}},
+	}
+
+	results := suite.runMixed([]string{"a", "b"}, endpointTestcases)
+
+	if len(results) != 2 || results[0].Name != "a" || results[1].Name != "b" {
+		t.Fatalf("results: got %+v, want a then b", results)
+	}
+	timed := suite.GetTimedResults()
+	if len(timed) != 2 {
+		t.Fatalf("timed results: got %d, want one per endpoint", len(timed))
+	}
+	var total int
+	for i, r := range results {
+		if r.Stats == nil || r.Stats.Count == 0 {
+			t.Fatalf("%s: no successful requests", r.Name)
+		}
+		if r.FailureCount != 0 {
+			t.Errorf("%s: unexpected failures %d (last: %s)", r.Name, r.FailureCount, r.LastError)
+		}
+		if timed[i].Endpoint != r.Name || len(timed[i].Latencies) != r.Stats.Count {
+			t.Errorf("%s: timed latencies misattributed (%s, %d vs %d)",
+				r.Name, timed[i].Endpoint, len(timed[i].Latencies), r.Stats.Count)
+		}
+		total += r.Stats.Count
+	}
+
+	blended := suite.MixedStats()
+	if blended == nil || blended.Count != total {
+		t.Errorf("blended stats: got %+v, want count %d", blended, total)
+	}
+}
+
+func TestMixedModeMaxSamplesBoundsKeptLatencies(t *testing.T) {
+	t.Parallel()
+
+	suite, _ := newTestSuite(t, okHandler, config.LoadConfig{Mode: config.LoadModeClosed}, 300*time.Millisecond)
+	suite.server.MixedMode = true
+	suite.server.MaxSamples = 1000
+	endpointTestcases := map[string][]*config.Testcase{
+		"a": {{EndpointName: "a", Path: "/a", RequestURI: "/a", Method: http.MethodGet, ExpectedStatus: config.ExactStatus(200), Weight: 1}},
+	}
+
+	results := suite.runMixed([]string{"a"}, endpointTestcases)
+	stats := results[0].Stats
+	kept := len(suite.GetTimedResults()[0].Latencies)
+	if stats.Count == 0 || kept > suite.server.MaxSamples || kept > stats.Count {
+		t.Fatalf("kept %d of %d latencies, want at most max_samples %d", kept, stats.Count, suite.server.MaxSamples)
+	}
+	if stats.Count > suite.server.MaxSamples && stats.Sampled != suite.server.MaxSamples {
+		t.Errorf("sampled: got %d over %d requests, want the %d reservoir reported", stats.Sampled, stats.Count, suite.server.MaxSamples)
+	}
+	if blended := suite.MixedStats(); blended.Count != stats.Count || (blended.Count > suite.server.MaxSamples && blended.Sampled != suite.server.MaxSamples) {
+		t.Errorf("blended: got count %d sampled %d, want the endpoint's %d through its own reservoir", blended.Count, blended.Sampled, stats.Count)
+	}
+}
+
+func TestMixedModeHonorsTDigestEstimator(t *testing.T) {
+	t.Parallel()
+
+	suite, _ := newTestSuite(t, okHandler, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+	suite.server.MixedMode = true
+	suite.server.Estimator = config.EstimatorTDigest
+	suite.server.MaxSamples = config.DefaultDigestSamples
+	endpointTestcases := map[string][]*config.Testcase{
+		"a": {{EndpointName: "a", Path: "/a", RequestURI: "/a", Method: http.MethodGet, ExpectedStatus: config.ExactStatus(200), Weight: 1}},
+	}
+
+	results := suite.runMixed([]string{"a"}, endpointTestcases)
+	if stats := results[0].Stats; stats.Count == 0 || stats.Estimator != config.EstimatorTDigest {
+		t.Errorf("endpoint: got estimator %q over %d requests, want %q", stats.Estimator, stats.Count, config.EstimatorTDigest)
+	}
+	if blended := suite.MixedStats(); blended.Estimator != config.EstimatorTDigest {
+		t.Errorf("blended: got estimator %q, want %q", blended.Estimator, config.EstimatorTDigest)
+	}
+}
+
+func TestMixedModeFailFast(t *testing.T) {
+	t.Parallel()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	testcase := func(name string) []*config.Testcase {
+		return []*config.Testcase{{EndpointName: name, Path: "/" + name, RequestURI: "/" + name, Method: http.MethodGet, ExpectedStatus: config.ExactStatus(200), Weight: 1}}
+	}
+
+	t.Run("drops the failing endpoint only", func(t *testing.T) {
+		t.Parallel()
+		suite, _ := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+		suite.server.MixedMode = true
+		suite.server.FailFast = 10
+
+		results := suite.runMixed([]string{"bad", "good"}, map[string][]*config.Testcase{"bad": testcase("bad"), "good": testcase("good")})
+		bad, good := results[0], results[1]
+		if !bad.FailedFast || bad.Stats.Count != 0 || bad.FailureCount != 10 {
+			t.Errorf("bad: failed fast %v after %d failures, want stopped at exactly 10", bad.FailedFast, bad.FailureCount)
+		}
+		if good.FailedFast || good.Stats.Count == 0 {
+			t.Errorf("good: failed fast %v with %d successes, want it run for the window", good.FailedFast, good.Stats.Count)
+		}
+	})
+
+	t.Run("ends the window when every endpoint failed", func(t *testing.T) {
+		t.Parallel()
+		// The hour-long window would time the test out if fail_fast didn't end it.
+		suite, _ := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Hour)
+		suite.server.MixedMode = true
+		suite.server.FailFast = 10
+
+		results := suite.runMixed([]string{"bad"}, map[string][]*config.Testcase{"bad": testcase("bad")})
+		if !results[0].FailedFast || results[0].FailureCount != 10 {
+			t.Errorf("got failed fast %v after %d failures, want true after exactly 10", results[0].FailedFast, results[0].FailureCount)
+		}
+	})
+}
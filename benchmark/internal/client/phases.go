@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PhaseStats splits request latency into connection setup and server time
+// (--trace-phases). DNS, Connect and TLS cover only the requests that opened a
+// connection, so on a warm keep-alive pool their counts stay small; TTFB —
+// from the moment the client asks for a connection until the first response
+// byte — covers every request.
+type PhaseStats struct {
+	DNS     *PhaseTiming `json:"dns,omitempty"`
+	Connect *PhaseTiming `json:"connect,omitempty"`
+	TLS     *PhaseTiming `json:"tls,omitempty"`
+	TTFB    *PhaseTiming `json:"ttfb,omitempty"`
+}
+
+// PhaseTiming summarizes one phase across an endpoint's requests.
+type PhaseTiming struct {
+	Count int           `json:"count"`
+	Avg   time.Duration `json:"avg"`
+	P50   time.Duration `json:"p50"`
+	P99   time.Duration `json:"p99"`
+	High  time.Duration `json:"high"`
+}
+
+const (
+	phaseDNS = iota
+	phaseConnect
+	phaseTLS
+	phaseTTFB
+	phaseCount
+)
+
+// phaseCounter collects per-endpoint phase samples for --trace-phases. Like
+// connCounter it is reset before each measured window so warmup requests
+// don't leak into the breakdown.
+type phaseCounter struct {
+	mu      sync.Mutex
+	samples map[string]*[phaseCount][]time.Duration
+}
+
+// requestPhases holds one request's in-progress phase start times. Dial hooks
+// run on the transport's dial goroutine — possibly several in parallel for a
+// dual-stack host — hence the lock.
+type requestPhases struct {
+	mu           sync.Mutex
+	getConn      time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+}
+
+// trace returns ctx instrumented to record endpoint's request phases. It
+// composes with any trace already on ctx (--conn-stats).
+func (c *phaseCounter) trace(ctx context.Context, endpoint string) context.Context {
+	var r requestPhases
+	mark := func(at *time.Time) {
+		r.mu.Lock()
+		*at = time.Now()
+		r.mu.Unlock()
+	}
+	since := func(at *time.Time, phase int) {
+		r.mu.Lock()
+		start := *at
+		r.mu.Unlock()
+		if !start.IsZero() {
+			c.record(endpoint, phase, time.Since(start))
+		}
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn:              func(string) { mark(&r.getConn) },
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&r.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(&r.dnsStart, phaseDNS) },
+		ConnectStart:         func(string, string) { mark(&r.connectStart) },
+		ConnectDone:          func(_, _ string, err error) { phaseDone(err, func() { since(&r.connectStart, phaseConnect) }) },
+		TLSHandshakeStart:    func() { mark(&r.tlsStart) },
+		TLSHandshakeDone:     func(_ tls.ConnectionState, err error) { phaseDone(err, func() { since(&r.tlsStart, phaseTLS) }) },
+		GotFirstResponseByte: func() { since(&r.getConn, phaseTTFB) },
+	})
+}
+
+// phaseDone records a phase only when it succeeded; a failed dial or handshake
+// surfaces as the request's error instead.
+func phaseDone(err error, record func()) {
+	if err == nil {
+		record()
+	}
+}
+
+func (c *phaseCounter) record(endpoint string, phase int, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.samples == nil {
+		c.samples = make(map[string]*[phaseCount][]time.Duration)
+	}
+	s := c.samples[endpoint]
+	if s == nil {
+		s = &[phaseCount][]time.Duration{}
+		c.samples[endpoint] = s
+	}
+	s[phase] = append(s[phase], d)
+}
+
+func (c *phaseCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = nil
+}
+
+// take summarizes endpoint's phases and forgets them; nil when not traced.
+func (c *phaseCounter) take(endpoint string) *PhaseStats {
+	c.mu.Lock()
+	s := c.samples[endpoint]
+	delete(c.samples, endpoint)
+	c.mu.Unlock()
+	if s == nil {
+		return nil
+	}
+	return &PhaseStats{
+		DNS:     summarizePhase(s[phaseDNS]),
+		Connect: summarizePhase(s[phaseConnect]),
+		TLS:     summarizePhase(s[phaseTLS]),
+		TTFB:    summarizePhase(s[phaseTTFB]),
+	}
+}
+
+func summarizePhase(samples []time.Duration) *PhaseTiming {
+	if len(samples) == 0 {
+		return nil
+	}
+	slices.Sort(samples)
+	var sum time.Duration
+	for _, d := range samples {
+		sum += d
+	}
+	return &PhaseTiming{
+		Count: len(samples),
+		Avg:   sum / time.Duration(len(samples)),
+		P50:   Percentile(samples, 50),
+		P99:   Percentile(samples, 99),
+		High:  samples[len(samples)-1],
+	}
+}
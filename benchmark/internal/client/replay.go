@@ -0,0 +1,42 @@
+package client
+
+import "benchmark-client/internal/config"
+
+// Replay (benchmark.replay_file) runs a recorded trace through mixed mode's
+// worker pool: instead of drawing endpoints by weight, the feeder hands out
+// the trace's requests in file order, starting over at the end, for one
+// duration_per_endpoint window. Each request counts toward its "METHOD path"
+// route, so results aggregate by route while the blended stats cover the
+// whole trace.
+
+// newReplayPicker groups the trace's testcases by route in first-appearance
+// order and keeps the line order for pick.
+func newReplayPicker(testcases []*config.Testcase) *mixedPicker {
+	p := &mixedPicker{replay: make([]mixedWork, 0, len(testcases))}
+	routes := make(map[string]int)
+	for _, tc := range testcases {
+		i, ok := routes[tc.EndpointName]
+		if !ok {
+			i = len(p.endpoints)
+			routes[tc.EndpointName] = i
+			p.endpoints = append(p.endpoints, &mixedEndpoint{
+				name:   tc.EndpointName,
+				path:   tc.Path,
+				method: tc.Method,
+				weight: 1,
+			})
+			p.totalWeight++
+		}
+		p.endpoints[i].testcases = append(p.endpoints[i].testcases, tc)
+		p.replay = append(p.replay, mixedWork{endpoint: i, tc: tc})
+	}
+	return p
+}
+
+// newPicker is the feeder's picker for one mixed or replay window.
+func (s *Suite) newPicker(names []string, endpointTestcases map[string][]*config.Testcase) *mixedPicker {
+	if s.server.Replay {
+		return newReplayPicker(s.server.Testcases)
+	}
+	return newMixedPicker(names, endpointTestcases)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestReplayPickerFollowsTraceOrder(t *testing.T) {
+	t.Parallel()
+
+	trace := []*config.Testcase{
+		{EndpointName: "GET /a", Name: "line 1", Path: "/a", Method: http.MethodGet},
+		{EndpointName: "POST /b", Name: "line 2", Path: "/b", Method: http.MethodPost},
+		{EndpointName: "GET /a", Name: "line 3", Path: "/a", Method: http.MethodGet},
+	}
+	picker := newReplayPicker(trace)
+
+	if len(picker.endpoints) != 2 || picker.endpoints[0].name != "GET /a" || len(picker.endpoints[0].testcases) != 2 {
+		t.Fatalf("routes: got %d, want GET /a (2 lines) then POST /b", len(picker.endpoints))
+	}
+	for i := range 7 {
+		idx, tc := picker.pick()
+		if want := trace[i%len(trace)]; tc != want || picker.endpoints[idx].name != want.EndpointName {
+			t.Fatalf("pick %d: got %s on %s, want %s, looping the trace in order", i, tc.Name, picker.endpoints[idx].name, want.Name)
+		}
+	}
+}
+
+func TestReplayAggregatesByRoute(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.Method+" "+r.URL.RequestURI()]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+	suite, _ := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+	suite.server.Replay = true
+	twoXX := config.StatusMatcher{Class: 2}
+	suite.server.Testcases = []*config.Testcase{
+		{EndpointName: "GET /users", Path: "/users", RequestURI: "/users?page=1", Method: http.MethodGet, ExpectedStatus: twoXX},
+		{EndpointName: "GET /users", Path: "/users", RequestURI: "/users?page=2", Method: http.MethodGet, ExpectedStatus: twoXX},
+		{EndpointName: "DELETE /users/1", Path: "/users/1", RequestURI: "/users/1", Method: http.MethodDelete, ExpectedStatus: twoXX},
+	}
+
+	results, err := suite.RunAll()
+	if err != nil {
+		t.Fatalf("RunAll: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "GET /users" || results[1].Name != "DELETE /users/1" {
+		t.Fatalf("results: got %+v, want GET /users then DELETE /users/1", results)
+	}
+	users, deletes := results[0].Stats.Count, results[1].Stats.Count
+	if deletes == 0 || users < 2*deletes-suite.server.Concurrency || users > 2*deletes+suite.server.Concurrency {
+		t.Errorf("counts: got %d GET /users and %d DELETE, want about two to one as in the trace", users, deletes)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["GET /users?page=1"] == 0 || hits["GET /users?page=2"] == 0 {
+		t.Errorf("every trace line should be sent with its query, got %v", hits)
+	}
+	if blended := suite.MixedStats(); blended == nil || blended.Count != users+deletes {
+		t.Errorf("blended stats: got %+v, want count %d", blended, users+deletes)
+	}
+}
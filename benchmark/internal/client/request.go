@@ -23,12 +23,23 @@ func BuildRequest(ctx context.Context, baseURL string, tc *config.Testcase) (*ht
 		// No request body — bodyReader stays nil and no Content-Type is set.
 
 	case config.RequestTypeJSON:
-		if tc.Body != "" {
+		if tc.Dynamic != nil {
+			bodyReader = strings.NewReader(renderDynamicJSON(tc.Dynamic))
+			contentType = "application/json"
+		} else if tc.Body != "" {
 			bodyReader = strings.NewReader(tc.Body)
 			contentType = "application/json"
 		}
+		if bodyReader != nil && tc.ContentType != "" {
+			contentType = tc.ContentType
+		}
 
 	case config.RequestTypeForm:
+		if tc.Dynamic != nil {
+			bodyReader = strings.NewReader(renderDynamicForm(tc.Dynamic))
+			contentType = "application/x-www-form-urlencoded"
+			break
+		}
 		if tc.CachedFormBody != "" {
 			bodyReader = strings.NewReader(tc.CachedFormBody)
 			contentType = "application/x-www-form-urlencoded"
@@ -50,6 +61,10 @@ func BuildRequest(ctx context.Context, baseURL string, tc *config.Testcase) (*ht
 		}
 	}
 
+	if tc.ChunkedRequest && bodyReader != nil {
+		bodyReader = chunked(bodyReader)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, tc.Method, baseURL+tc.RequestURI, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -66,10 +81,17 @@ func BuildRequest(ctx context.Context, baseURL string, tc *config.Testcase) (*ht
 	if req.Header.Get("Accept") == "" {
 		if tc.ExpectedText != "" {
 			req.Header.Set("Accept", "text/plain")
-		} else if tc.ExpectedBody != nil {
+		} else if tc.ExpectedBody != nil || tc.ExpectValidJSON {
 			req.Header.Set("Accept", "application/json")
 		}
 	}
 
 	return req, nil
 }
+
+// chunked hides r's concrete type so http.NewRequest cannot derive a
+// Content-Length; with an unknown length the transport sends the body with
+// Transfer-Encoding: chunked (chunked_request).
+func chunked(r io.Reader) io.Reader {
+	return struct{ io.Reader }{r}
+}
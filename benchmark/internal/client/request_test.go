@@ -0,0 +1,254 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestExecuteTestcaseChunkedRequest(t *testing.T) {
+	t.Parallel()
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	part, _ := mw.CreateFormFile("file", "test.txt")
+	_, _ = part.Write([]byte("Hello, World!\n"))
+	_ = mw.Close()
+
+	cases := []struct {
+		name string
+		tc   config.Testcase
+		want string // body the server must read back
+	}{
+		{
+			name: "json",
+			tc:   config.Testcase{RequestType: config.RequestTypeJSON, Body: `{"key":"value"}`},
+			want: `{"key":"value"}`,
+		},
+		{
+			name: "form",
+			tc:   config.Testcase{RequestType: config.RequestTypeForm, CachedFormBody: "age=25&name=John"},
+			want: "age=25&name=John",
+		},
+		{
+			name: "multipart",
+			tc: config.Testcase{
+				RequestType:         config.RequestTypeMultipart,
+				CachedMultipartBody: multipartBody.String(),
+				CachedContentType:   mw.FormDataContentType(),
+			},
+			want: "Hello, World!\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The handler echoes what it read so body validation covers the
+			// full round trip; multipart echoes the uploaded file's content.
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if r.ContentLength != -1 || r.Header.Get("Content-Length") != "" {
+					http.Error(w, "request carried a Content-Length", http.StatusBadRequest)
+					return
+				}
+				if len(r.TransferEncoding) != 1 || r.TransferEncoding[0] != "chunked" {
+					http.Error(w, "request was not chunked", http.StatusBadRequest)
+					return
+				}
+				var echo []byte
+				if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+					file, _, err := r.FormFile("file")
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+					echo, _ = io.ReadAll(file)
+				} else {
+					echo, _ = io.ReadAll(r.Body)
+				}
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write(echo)
+			}
+			suite, testcases := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+			testcase := tc.tc
+			testcase.EndpointName = testcases[0].EndpointName
+			testcase.Method = http.MethodPost
+			testcase.RequestURI = "/"
+			testcase.ExpectedStatus = config.ExactStatus(200)
+			testcase.ExpectedText = tc.want
+			testcase.ChunkedRequest = true
+
+			if _, err := suite.executeTestcase(context.Background(), &testcase); err != nil {
+				t.Errorf("chunked %s request: %v", tc.name, err)
+			}
+		})
+	}
+}
+
+func TestBuildRequestRunDefaults(t *testing.T) {
+	t.Parallel()
+
+	server := loadTarget(t, `{
+		"benchmark": {"user_agent": "bench/1.0", "default_accept": "application/cbor"},
+		"endpoints": {
+			"plain": {"route": "GET /plain", "expect": {"text": "ok"}},
+			"custom": {"route": "GET /custom", "headers": {"user-agent": "curl/8.0", "Accept": "text/html"}}
+		}
+	}`)
+
+	want := map[string][2]string{
+		"plain":  {"bench/1.0", "application/cbor"},
+		"custom": {"curl/8.0", "text/html"},
+	}
+	for _, tc := range server.Testcases {
+		req, err := BuildRequest(context.Background(), "http://localhost:8080", tc)
+		if err != nil {
+			t.Fatalf("%s: BuildRequest: %v", tc.EndpointName, err)
+		}
+		w := want[tc.EndpointName]
+		if got := req.Header.Get("User-Agent"); got != w[0] {
+			t.Errorf("%s User-Agent: got %q, want %q", tc.EndpointName, got, w[0])
+		}
+		if got := req.Header.Get("Accept"); got != w[1] {
+			t.Errorf("%s Accept: got %q, want %q", tc.EndpointName, got, w[1])
+		}
+	}
+}
+
+func TestBuildRequestContentType(t *testing.T) {
+	t.Parallel()
+
+	server := loadTarget(t, `{
+		"endpoints": {
+			"plain": {"route": "POST /plain", "body": {"a": 1}},
+			"vnd": {"route": "POST /vnd", "body": {"a": 1}, "content_type": "application/vnd.api+json"},
+			"explicit": {
+				"route": "POST /explicit", "body": {"a": 1},
+				"content_type": "application/vnd.api+json",
+				"headers": {"content-type": "application/merge-patch+json"}
+			}
+		}
+	}`)
+
+	want := map[string]string{
+		"plain":    "application/json",
+		"vnd":      "application/vnd.api+json",
+		"explicit": "application/merge-patch+json",
+	}
+	for _, tc := range server.Testcases {
+		req, err := BuildRequest(context.Background(), "http://localhost:8080", tc)
+		if err != nil {
+			t.Fatalf("%s: BuildRequest: %v", tc.EndpointName, err)
+		}
+		if got := req.Header.Get("Content-Type"); got != want[tc.EndpointName] {
+			t.Errorf("%s Content-Type: got %q, want %q", tc.EndpointName, got, want[tc.EndpointName])
+		}
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != `{"a":1}` {
+			t.Errorf("%s body: got %s, want {\"a\":1}", tc.EndpointName, body)
+		}
+	}
+}
+
+func TestBuildRequestDynamicBody(t *testing.T) {
+	t.Parallel()
+
+	server := loadTarget(t, `{
+		"endpoints": {
+			"create": {
+				"route": "POST /items",
+				"dynamic": true,
+				"body": {"seq": "{{seq}}", "n": "{{rand_int}}", "id": "{{uuid}}", "note": "item-{{seq}}"}
+			},
+			"login": {"route": "POST /login", "dynamic": true, "form_data": {"user": "u{{seq}}", "nonce": "{{rand_int}}"}},
+			"static": {"route": "POST /static", "body": {"seq": "{{seq}}"}}
+		}
+	}`)
+	testcases := make(map[string]*config.Testcase)
+	for _, tc := range server.Testcases {
+		testcases[tc.EndpointName] = tc
+	}
+	read := func(tc *config.Testcase) string {
+		t.Helper()
+		req, err := BuildRequest(context.Background(), "http://localhost:8080", tc)
+		if err != nil {
+			t.Fatalf("BuildRequest: %v", err)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+		return string(body)
+	}
+
+	uuids := make(map[string]bool)
+	for want := 1.0; want <= 3; want++ {
+		var got struct {
+			Seq  any    `json:"seq"`
+			N    any    `json:"n"`
+			Id   string `json:"id"`
+			Note string `json:"note"`
+		}
+		if err := json.Unmarshal([]byte(read(testcases["create"])), &got); err != nil {
+			t.Fatalf("dynamic JSON body must stay valid JSON: %v", err)
+		}
+		if got.Seq != want {
+			t.Errorf("{{seq}}: got %v, want %v as a number", got.Seq, want)
+		}
+		if n, ok := got.N.(float64); !ok || n < 0 || n >= 1<<31 || n != float64(int64(n)) {
+			t.Errorf("{{rand_int}}: got %v, want an integer in [0, 2^31)", got.N)
+		}
+		if !uuidV4.MatchString(got.Id) || uuids[got.Id] {
+			t.Errorf("{{uuid}}: got %q, want a fresh v4 UUID", got.Id)
+		}
+		uuids[got.Id] = true
+		if wantNote := fmt.Sprintf("item-%d", int(want)); got.Note != wantNote {
+			t.Errorf("{{seq}} inside a string: got %q, want %q", got.Note, wantNote)
+		}
+	}
+
+	form, err := url.ParseQuery(read(testcases["login"]))
+	if err != nil {
+		t.Fatalf("parse form: %v", err)
+	}
+	if form.Get("user") != "u1" {
+		t.Errorf("form {{seq}}: got %q, want u1", form.Get("user"))
+	}
+	if _, err := strconv.ParseInt(form.Get("nonce"), 10, 32); err != nil {
+		t.Errorf("form {{rand_int}}: got %q, want an integer", form.Get("nonce"))
+	}
+
+	if got := read(testcases["static"]); got != `{"seq":"{{seq}}"}` {
+		t.Errorf("without dynamic the body is sent as written: got %s", got)
+	}
+}
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func loadTarget(t *testing.T, cfgJSON string) *config.ResolvedServer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, server, err := config.LoadTarget(path, "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	return server
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json/v2"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"benchmark-client/internal/config"
@@ -14,27 +15,47 @@ import (
 // keys (last wins) so the validator's judgment is unchanged by the v2 move.
 var respOpts = jsontext.AllowDuplicateNames(true)
 
+// LooksCached reports a response that was probably served from a cache rather
+// than by the server under test: for an endpoint expecting 200, a 304 Not
+// Modified or a cacheHeader whose value contains "hit" (X-Cache: HIT).
+func LooksCached(tc *config.Testcase, resp *http.Response, cacheHeader string) bool {
+	if !tc.ExpectedStatus.Matches(http.StatusOK) || tc.ExpectedStatus.Matches(http.StatusNotModified) {
+		return false
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return true
+	}
+	return strings.Contains(strings.ToLower(resp.Header.Get(cacheHeader)), "hit")
+}
+
 func ValidateResponse(tc *config.Testcase, resp *http.Response, body []byte) error {
-	if resp.StatusCode != tc.ExpectedStatus {
-		return fmt.Errorf("unexpected status code: got %d, want %d (body: %s)",
+	if resp.StatusCode == http.StatusNotModified && !tc.ExpectedStatus.Matches(resp.StatusCode) {
+		return fmt.Errorf("unexpected status code: got 304, want %s (possibly cached: Not Modified)", tc.ExpectedStatus)
+	}
+	if !tc.ExpectedStatus.Matches(resp.StatusCode) {
+		return fmt.Errorf("unexpected status code: got %d, want %s (body: %s)",
 			resp.StatusCode, tc.ExpectedStatus, truncate(body, 200))
 	}
 
-	for key, expectedValue := range tc.ExpectedHeaders {
+	for key, expected := range tc.ExpectedHeaders {
 		actualValue := strings.TrimSpace(resp.Header.Get(key))
-
-		if strings.EqualFold(key, "Content-Type") {
-			if !strings.Contains(actualValue, expectedValue) {
-				return fmt.Errorf("unexpected header %s: got %q, want substring %q",
-					key, actualValue, expectedValue)
-			}
-			continue
+		if !expected.Matches(actualValue) {
+			return fmt.Errorf("unexpected header %s: got %q, want %s", key, actualValue, expected)
+		}
+	}
+	for _, key := range tc.AbsentHeaders {
+		if values := resp.Header.Values(key); len(values) > 0 {
+			return fmt.Errorf("unexpected header %s: present (%q), want absent", key, strings.Join(values, ", "))
 		}
+	}
 
-		if actualValue != expectedValue {
-			return fmt.Errorf("unexpected header %s: got %q, want %q",
-				key, actualValue, expectedValue)
+	// A HEAD response has no body; load rejects body expectations on it, so
+	// only a status-only success_when is left to check.
+	if tc.Method == http.MethodHead {
+		if tc.SuccessWhen != nil {
+			return validateSuccessWhen(tc.SuccessWhen, resp.StatusCode, nil)
 		}
+		return nil
 	}
 
 	if tc.ExpectedBody != nil {
@@ -45,12 +66,67 @@ func ValidateResponse(tc *config.Testcase, resp *http.Response, body []byte) err
 		if err := validateTextBody(tc.ExpectedText, body); err != nil {
 			return err
 		}
+	} else if tc.ExpectValidJSON && !jsontext.Value(body).IsValid(respOpts) {
+		return fmt.Errorf("response is not valid JSON (body: %s)", truncate(body, 200))
+	}
+
+	if len(tc.ExpectedJSONPaths) > 0 {
+		if err := validateJSONPaths(tc.ExpectedJSONPaths, body); err != nil {
+			return err
+		}
+	}
+
+	if tc.SuccessWhen != nil {
+		return validateSuccessWhen(tc.SuccessWhen, resp.StatusCode, body)
 	}
 
 	return nil
 }
 
+// validateSuccessWhen applies expect.success_when, decoding the body only when
+// the rule reads it. A body that doesn't parse is not an error here: the rule's
+// path comparisons just fail.
+func validateSuccessWhen(rule *config.SuccessRule, status int, body []byte) error {
+	var document any
+	hasBody := false
+	if rule.NeedsBody() {
+		hasBody = json.Unmarshal(body, &document, respOpts) == nil
+	}
+	if !rule.Holds(status, document, hasBody) {
+		return fmt.Errorf("success_when %s: not satisfied (status %d, body: %s)", rule.Expr, status, truncate(body, 200))
+	}
+	return nil
+}
+
+// validateJSONPaths checks each expect.json_path assertion in path order and
+// reports the first miss with the value actually found there.
+func validateJSONPaths(assertions []config.JSONPathAssertion, body []byte) error {
+	var document any
+	if err := json.Unmarshal(body, &document, respOpts); err != nil {
+		return fmt.Errorf("failed to parse response as JSON for json_path: %w (body: %s)", err, truncate(body, 200))
+	}
+	for i := range assertions {
+		a := &assertions[i]
+		got, ok := a.Lookup(document)
+		if !ok {
+			return fmt.Errorf("json_path %s: not present (body: %s)", a.Path, truncate(body, 200))
+		}
+		if a.Present || jsonMatch(a.Want, got) {
+			continue
+		}
+		actual, err := json.Marshal(got, json.Deterministic(true))
+		if err != nil {
+			actual = []byte(fmt.Sprint(got))
+		}
+		return fmt.Errorf("json_path %s: got %s, want %v", a.Path, truncate(actual, 200), a.Want)
+	}
+	return nil
+}
+
 func validateJSONBody(expected any, actual []byte) error {
+	if _, ok := config.OneOf(expected); ok {
+		return validateJSONValue(expected, actual)
+	}
 	switch exp := expected.(type) {
 	case map[string]any:
 		var actualBody map[string]any
@@ -75,19 +151,26 @@ func validateJSONBody(expected any, actual []byte) error {
 		}
 
 	default:
-		var actualValue any
-		if err := json.Unmarshal(actual, &actualValue, respOpts); err != nil {
-			return fmt.Errorf("failed to parse response as JSON: %w (body: %s)",
-				err, truncate(actual, 200))
-		}
-		if !jsonMatch(exp, actualValue) {
-			return fmt.Errorf("JSON value mismatch: got %v, want %v", actualValue, exp)
-		}
+		return validateJSONValue(exp, actual)
 	}
 
 	return nil
 }
 
+// validateJSONValue matches a body that is expected to be any JSON value,
+// including a top-level $oneOf.
+func validateJSONValue(expected any, actual []byte) error {
+	var actualValue any
+	if err := json.Unmarshal(actual, &actualValue, respOpts); err != nil {
+		return fmt.Errorf("failed to parse response as JSON: %w (body: %s)",
+			err, truncate(actual, 200))
+	}
+	if !jsonMatch(expected, actualValue) {
+		return fmt.Errorf("JSON value mismatch: got %v, want %v", actualValue, expected)
+	}
+	return nil
+}
+
 func validateTextBody(expected string, actual []byte) error {
 	actualText := strings.TrimSpace(string(actual))
 	expectedText := strings.TrimSpace(expected)
@@ -107,6 +190,9 @@ func truncate(body []byte, maxLen int) string {
 }
 
 func jsonMatch(want, got any) bool {
+	if options, ok := config.OneOf(want); ok {
+		return slices.ContainsFunc(options, func(option any) bool { return jsonMatch(option, got) })
+	}
 	switch w := want.(type) {
 	case map[string]any:
 		g, ok := got.(map[string]any)
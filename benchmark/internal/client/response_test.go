@@ -0,0 +1,465 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestValidateResponseStatusMatcher(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		expect  config.StatusMatcher
+		status  int
+		wantErr string
+	}{
+		{name: "exact match", expect: config.ExactStatus(200), status: 200},
+		{name: "class range accepts 204", expect: config.StatusMatcher{Class: 2}, status: 204},
+		{name: "class range rejects 301", expect: config.StatusMatcher{Class: 2}, status: 301, wantErr: "got 301, want 2xx"},
+		{name: "list accepts member", expect: config.StatusMatcher{Codes: []int{200, 201, 204}}, status: 201},
+		{
+			name:    "list mismatch reports actual status",
+			expect:  config.StatusMatcher{Codes: []int{200, 201, 204}},
+			status:  202,
+			wantErr: "got 202, want one of 200, 201, 204",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			testcase := &config.Testcase{ExpectedStatus: tc.expect}
+			err := ValidateResponse(testcase, &http.Response{StatusCode: tc.status, Header: http.Header{}}, nil)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error: got %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteTestcaseMaxBodyBytes(t *testing.T) {
+	t.Parallel()
+
+	large := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+	}
+	suite, testcases := newTestSuite(t, large, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	suite.server.MaxBodyBytes = 16
+
+	unvalidated := testcases[0]
+	if _, err := suite.executeTestcase(context.Background(), unvalidated); err != nil {
+		t.Errorf("body over the cap without body validation should pass, got %v", err)
+	}
+
+	validated := *testcases[0]
+	validated.ExpectedText = strings.Repeat("x", 64)
+	_, err := suite.executeTestcase(context.Background(), &validated)
+	if err == nil || !strings.Contains(err.Error(), "response exceeded max_body_bytes (16)") {
+		t.Errorf("body validation over the cap: got %v, want max_body_bytes error", err)
+	}
+
+	suite.server.MaxBodyBytes = 64
+	if _, err := suite.executeTestcase(context.Background(), &validated); err != nil {
+		t.Errorf("body exactly at the cap must not count as truncated, got %v", err)
+	}
+}
+
+func TestExecuteTestcaseValidJSON(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "object", body: `{"id": 7, "items": [1, 2]}`},
+		{name: "scalar", body: `42`},
+		{name: "truncated object", body: `{"id": 7,`, wantErr: `response is not valid JSON (body: {"id": 7,)`},
+		{name: "plain text", body: `ok`, wantErr: "response is not valid JSON (body: ok)"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			handler := func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.body))
+			}
+			suite, testcases := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+			testcase := *testcases[0]
+			testcase.ExpectValidJSON = true
+
+			_, err := suite.executeTestcase(context.Background(), &testcase)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("error: got %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResponseJSONPath(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"status": "ok", "data": [{"id": 1, "tags": ["a"]}, {"id": 2, "owner": null}], "meta.v": 3}`)
+	cases := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr string
+	}{
+		{name: "member", path: "$.status", want: "ok"},
+		{name: "array index", path: "$.data[1].id", want: 2.0},
+		{name: "nested index", path: "$.data[0].tags[0]", want: "a"},
+		{name: "partial object", path: "$.data[0]", want: map[string]any{"id": 1.0}},
+		{name: "present", path: "$.data[0].id", want: config.JSONPathPresent},
+		{name: "present null", path: "$.data[1].owner", want: config.JSONPathPresent},
+		{name: "quoted member", path: "$['meta.v']", want: 3.0},
+		{name: "mismatch reports actual", path: "$.data[0].id", want: 2.0, wantErr: "json_path $.data[0].id: got 1, want 2"},
+		{name: "mismatch object", path: "$.data[1]", want: map[string]any{"id": 1.0}, wantErr: `json_path $.data[1]: got {"id":2,"owner":null}`},
+		{name: "index out of range", path: "$.data[2].id", want: config.JSONPathPresent, wantErr: "json_path $.data[2].id: not present"},
+		{name: "member of array", path: "$.data.id", want: config.JSONPathPresent, wantErr: "json_path $.data.id: not present"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assertion, err := config.NewJSONPathAssertion(tc.path, tc.want)
+			if err != nil {
+				t.Fatalf("compile %s: %v", tc.path, err)
+			}
+			testcase := &config.Testcase{
+				ExpectedStatus:    config.ExactStatus(200),
+				ExpectedJSONPaths: []config.JSONPathAssertion{assertion},
+			}
+			err = ValidateResponse(testcase, &http.Response{StatusCode: 200, Header: http.Header{}}, body)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error: got %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSuccessWhenCountsErrorBodiesAsFailures(t *testing.T) {
+	t.Parallel()
+
+	// Every other response is a 200 carrying {"ok": false}.
+	var n atomic.Int64
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		if n.Add(1)%2 == 0 {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"quota"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}
+	suite, testcases := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, 200*time.Millisecond)
+	rule, err := config.NewSuccessRule("status == 200 && $.ok == true")
+	if err != nil {
+		t.Fatalf("NewSuccessRule: %v", err)
+	}
+	testcases[0].SuccessWhen = rule
+
+	outcome := suite.runTestcases(testcases)
+	if outcome.failureCount == 0 || !strings.Contains(outcome.lastError, "success_when") {
+		t.Fatalf("failures: got %d (last: %q), want success_when failures", outcome.failureCount, outcome.lastError)
+	}
+	if rate := outcome.stats.SuccessRate; rate < 0.45 || rate > 0.55 {
+		t.Errorf("success rate: got %.3f, want ~0.5", rate)
+	}
+}
+
+// A HEAD endpoint passes or fails on status and headers alone: its response
+// has no body, so even a body expectation slipped past load is not checked.
+func TestExecuteTestcaseHead(t *testing.T) {
+	t.Parallel()
+
+	server := loadTarget(t, `{
+		"endpoints": {
+			"root": {"route": "HEAD /", "expect": {"headers": {"x-version": "regex:^v[0-9]+$"}}}
+		}
+	}`)
+	var version atomic.Value
+	version.Store("v2")
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("X-Version", version.Load().(string))
+		_, _ = w.Write([]byte("dropped for HEAD"))
+	}
+	suite, _ := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+
+	testcase := *server.Testcases[0]
+	testcase.ExpectedText = "never compared"
+	if _, err := suite.executeTestcase(context.Background(), &testcase); err != nil {
+		t.Errorf("HEAD with matching status and headers: got %v", err)
+	}
+
+	version.Store("latest")
+	_, err := suite.executeTestcase(context.Background(), &testcase)
+	if want := `unexpected header X-Version: got "latest", want match for regex "^v[0-9]+$"`; err == nil || err.Error() != want {
+		t.Errorf("HEAD with a wrong header: got %v, want %q", err, want)
+	}
+}
+
+func TestExpectBodyFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	filesDir := filepath.Join(root, "contract", "test-files")
+	configDir := filepath.Join(root, "config")
+	for _, dir := range []string{filesDir, configDir} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := `{"users": [{"id": 1, "name": "ada"}, {"id": 2, "name": "alan"}], "total": 2}`
+	if err := os.WriteFile(filepath.Join(filesDir, "users.json"), []byte(expected), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(configDir, "config.json")
+	cfgJSON := `{"endpoints": {"users": {"route": "GET /", "expect": {"body_file": "users.json"}}}}`
+	if err := os.WriteFile(path, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, server, err := config.LoadTarget(path, "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+
+	var body atomic.Value
+	body.Store(`{"users": [{"id": 1, "name": "ada", "admin": true}, {"id": 2, "name": "alan"}], "total": 2, "page": 1}`)
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}
+	suite, _ := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	testcase := server.Testcases[0]
+	if _, err := suite.executeTestcase(context.Background(), testcase); err != nil {
+		t.Errorf("response matching the file partially: got %v", err)
+	}
+
+	body.Store(`{"users": [{"id": 1, "name": "ada"}], "total": 1}`)
+	if _, err := suite.executeTestcase(context.Background(), testcase); err == nil {
+		t.Error("response differing from the file: got nil error")
+	}
+}
+
+func TestAbsentHeadersFailLeakingServer(t *testing.T) {
+	t.Parallel()
+
+	server := loadTarget(t, `{
+		"endpoints": {
+			"root": {"route": "GET /", "expect": {"absent_headers": ["server", " x-powered-by "]}}
+		}
+	}`)
+	absent := server.Testcases[0].AbsentHeaders
+	if want := []string{"Server", "X-Powered-By"}; !slices.Equal(absent, want) {
+		t.Fatalf("absent headers: got %v, want %v", absent, want)
+	}
+
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Server", "nginx/1.25.3")
+		_, _ = w.Write([]byte("ok"))
+	}
+	suite, testcases := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+	testcases[0].AbsentHeaders = absent
+
+	outcome := suite.runTestcases(testcases)
+	if outcome.stats.SuccessRate != 0 {
+		t.Errorf("success rate: got %.3f, want 0", outcome.stats.SuccessRate)
+	}
+	if want := `unexpected header Server: present ("nginx/1.25.3"), want absent`; outcome.lastError != want {
+		t.Errorf("last error: got %q, want %q", outcome.lastError, want)
+	}
+}
+
+func TestValidateResponseOneOf(t *testing.T) {
+	t.Parallel()
+
+	oneOf := func(values ...any) map[string]any { return map[string]any{config.OneOfKey: values} }
+	cases := []struct {
+		name    string
+		body    string
+		want    any
+		wantErr bool
+	}{
+		{name: "first value", body: `{"status": "active"}`, want: map[string]any{"status": oneOf("active", "pending")}},
+		{name: "second value", body: `{"status": "pending", "id": 1}`, want: map[string]any{"status": oneOf("active", "pending")}},
+		{name: "not in set", body: `{"status": "deleted"}`, want: map[string]any{"status": oneOf("active", "pending")}, wantErr: true},
+		{name: "partial objects", body: `{"user": {"role": "admin", "id": 7}}`, want: map[string]any{"user": oneOf(map[string]any{"role": "owner"}, map[string]any{"role": "admin"})}},
+		{name: "plain array stays exact", body: `{"tags": ["active"]}`, want: map[string]any{"tags": []any{"active", "pending"}}, wantErr: true},
+		{name: "top level", body: `3`, want: oneOf(1.0, 2.0, 3.0)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			testcase := &config.Testcase{ExpectedStatus: config.ExactStatus(200), ExpectedBody: tc.want}
+			err := ValidateResponse(testcase, &http.Response{StatusCode: 200, Header: http.Header{}}, []byte(tc.body))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("error: got %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePartialMatchOneOf(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]any{"status": map[string]any{config.OneOfKey: []any{"active", "pending"}}}
+	if err := validatePartialMatch(expected, map[string]any{"status": "pending"}); err != nil {
+		t.Errorf("match: got %v, want nil", err)
+	}
+	err := validatePartialMatch(expected, map[string]any{"status": "deleted"})
+	if want := `field "status": got deleted, want one of [active pending]`; err == nil || err.Error() != want {
+		t.Errorf("non-match: got %v, want %q", err, want)
+	}
+}
+
+func TestExecuteTestcaseConnectTimeout(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow-headers", func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow-body", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	server := &config.ResolvedServer{
+		Name:           "test",
+		RequestTimeout: 2 * time.Second,
+		ConnectTimeout: 100 * time.Millisecond,
+		Concurrency:    1,
+		MaxBodyBytes:   1 << 20,
+	}
+	suite := NewSuite(context.Background(), server, srv.URL, nil)
+	t.Cleanup(suite.Close)
+	testcase := func(path string) *config.Testcase {
+		return &config.Testcase{EndpointName: path, Path: path, RequestURI: path, Method: "GET", ExpectedStatus: config.ExactStatus(200)}
+	}
+
+	_, err := suite.executeTestcase(context.Background(), testcase("/slow-headers"))
+	if err == nil || !strings.Contains(err.Error(), "connect timeout after 100ms") {
+		t.Errorf("slow headers: got %v, want connect timeout", err)
+	}
+
+	// Once the headers arrive, only request_timeout bounds the body read.
+	if _, err := suite.executeTestcase(context.Background(), testcase("/slow-body")); err != nil {
+		t.Errorf("slow body: got %v, want success under request_timeout", err)
+	}
+}
+
+func TestFollowRedirects(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	run := func(followRedirects string) error {
+		t.Helper()
+		server := loadTarget(t, `{
+			"benchmark": {`+followRedirects+`},
+			"endpoints": {"old": {"route": "GET /old", "expect": {"status": 301, "headers": {"location": "/new"}}}}
+		}`)
+		suite := NewSuite(context.Background(), server, srv.URL, nil)
+		t.Cleanup(suite.Close)
+		_, err := suite.executeTestcase(context.Background(), server.Testcases[0])
+		return err
+	}
+
+	if err := run(`"follow_redirects": false`); err != nil {
+		t.Errorf("redirect not followed: got %v, want the 301 validated", err)
+	}
+	if err := run(``); err == nil {
+		t.Error("redirect followed by default: got nil, want the final 200 to fail expect.status 301")
+	}
+}
+
+func TestExecuteTestcaseFlagsCachedResponses(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hit":
+			w.Header().Set("X-Cache", "HIT from edge")
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	server := loadTarget(t, `{"endpoints": {
+		"hit": {"route": "GET /hit"},
+		"miss": {"route": "GET /miss"},
+		"not-modified": {"route": "GET /not-modified"}
+	}}`)
+	suite := NewSuite(context.Background(), server, srv.URL, nil)
+	t.Cleanup(suite.Close)
+
+	for _, tc := range server.Testcases {
+		_, err := suite.executeTestcase(context.Background(), tc)
+		switch tc.EndpointName {
+		case "not-modified":
+			if err == nil || !strings.Contains(err.Error(), "possibly cached") {
+				t.Errorf("%s: got %v, want a possibly cached status error", tc.EndpointName, err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("%s: got %v, want success", tc.EndpointName, err)
+			}
+		}
+	}
+
+	for endpoint, want := range map[string]int{"hit": 1, "miss": 0, "not-modified": 1} {
+		if got := suite.cached.take(endpoint); got != want {
+			t.Errorf("cached %s = %d, want %d", endpoint, got, want)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package client
 import (
 	"bytes"
 	"context"
+	"encoding/json/jsontext"
 	"encoding/json/v2"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -16,18 +18,57 @@ import (
 	"benchmark-client/internal/config"
 )
 
+// jsonNumber is a number in a flow response kept as its JSON literal, so a
+// captured 64-bit id is forwarded exactly instead of rounding through float64.
+type jsonNumber string
+
+// flowRespOpts decode flow responses like respOpts, but with every number
+// as a jsonNumber.
+var flowRespOpts = json.JoinOptions(respOpts, json.WithUnmarshalers(
+	json.UnmarshalFromFunc(func(dec *jsontext.Decoder, v *any) error {
+		if dec.PeekKind() != '0' {
+			return errors.ErrUnsupported
+		}
+		literal, err := dec.ReadValue()
+		if err != nil {
+			return err
+		}
+		*v = jsonNumber(literal)
+		return nil
+	}),
+))
+
+// equals compares n with an expected number: a float64 from the config or an
+// int from a generated var.
+func (n jsonNumber) equals(want any) bool {
+	switch w := want.(type) {
+	case float64:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return err == nil && f == w
+	case int:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		return err == nil && i == int64(w)
+	}
+	return false
+}
+
 type SequenceResult struct {
 	SequenceId      string
 	Database        string
 	TotalDuration   time.Duration
 	StepDurations   []time.Duration
+	Skipped         []bool // steps whose when condition wasn't met; nil when none were
 	Success         bool
 	FailedStep      int
 	Error           string
 	ContextCanceled bool
 }
 
-func RunSequence(ctx context.Context, client *http.Client, baseUrl string, seq *config.ResolvedSequence, workerId, cycleNum int, timeout time.Duration) SequenceResult {
+func RunSequence(
+	ctx context.Context, client *http.Client, baseUrl string, seq *config.ResolvedSequence,
+	workerId, cycleNum int, timeout time.Duration, maxBodyBytes int64,
+) SequenceResult {
+	seq = seq.PickDatabase(rand.Float64()) //nolint:gosec // traffic mix, not security-sensitive
 	result := SequenceResult{
 		SequenceId:    seq.Id,
 		Database:      seq.Database,
@@ -42,8 +83,17 @@ func RunSequence(ctx context.Context, client *http.Client, baseUrl string, seq *
 	var totalDuration time.Duration
 
 	for i, endpoint := range seq.Endpoints {
-		stepCtx, cancel := context.WithTimeout(ctx, timeout)
-		stepDuration, err := executeSequenceStep(stepCtx, client, baseUrl, endpoint, vars, captured)
+		if len(endpoint.When) > 0 && !conditionMet(endpoint.When, vars, captured) {
+			if result.Skipped == nil {
+				result.Skipped = make([]bool, len(seq.Endpoints))
+			}
+			result.Skipped[i] = true
+			continue
+		}
+
+		stepCtx, cancel := withRequestTimeout(ctx, timeout)
+		stepDuration, err := executeSequenceStep(stepCtx, client, baseUrl, endpoint, vars, captured, maxBodyBytes)
+		err = classifyRequestTimeout(stepCtx, err, timeout)
 		cancel()
 
 		result.StepDurations[i] = stepDuration
@@ -65,6 +115,40 @@ func RunSequence(ctx context.Context, client *http.Client, baseUrl string, seq *
 	return result
 }
 
+// seedWorkerId is negative so vars generated for seeding (e.g. emails) never
+// collide with those of measured sequence workers, which count from 0.
+const seedWorkerId = -1
+
+// RunSeedSequences runs each seed sequence exactly once, in order, to load
+// reference data after the database reset and before any warmup. Nothing is
+// recorded: seeding is setup, not measurement. The first failing step aborts
+// with an error naming the sequence and step.
+func RunSeedSequences(ctx context.Context, baseUrl string, seqs []*config.ResolvedSequence, timeout time.Duration, maxBodyBytes int64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+
+	transport := NewHTTPTransport(1, 0, 0)
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport}
+	baseUrl = strings.TrimRight(baseUrl, "/")
+
+	for _, seq := range seqs {
+		result := RunSequence(ctx, httpClient, baseUrl, seq, seedWorkerId, 0, timeout, maxBodyBytes)
+		if result.Success {
+			continue
+		}
+		name := seq.Id
+		if seq.Database != "" {
+			name = fmt.Sprintf("%s/%s", seq.Id, seq.Database)
+		}
+		return fmt.Errorf("seed flow %s failed at step %d (%s): %s",
+			name, result.FailedStep+1, seq.Endpoints[result.FailedStep].Name, result.Error)
+	}
+
+	return nil
+}
+
 func generateVars(varDefs map[string]config.VarConfig, workerId, cycleNum int) map[string]any {
 	vars := make(map[string]any)
 
@@ -100,7 +184,10 @@ func generateVars(varDefs map[string]config.VarConfig, workerId, cycleNum int) m
 	return vars
 }
 
-func executeSequenceStep(ctx context.Context, client *http.Client, baseUrl string, endpoint *config.ResolvedSequenceEndpoint, vars map[string]any, captured map[string]string) (time.Duration, error) {
+func executeSequenceStep(
+	ctx context.Context, client *http.Client, baseUrl string, endpoint *config.ResolvedSequenceEndpoint,
+	vars map[string]any, captured map[string]string, maxBodyBytes int64,
+) (time.Duration, error) {
 	path := replacePlaceholdersInString(endpoint.Path, vars, captured)
 	url := baseUrl + path
 
@@ -112,6 +199,9 @@ func executeSequenceStep(ctx context.Context, client *http.Client, baseUrl strin
 			return 0, fmt.Errorf("failed to marshal body: %w", err)
 		}
 		bodyReader = bytes.NewReader(bodyBytes)
+		if endpoint.ChunkedRequest {
+			bodyReader = chunked(bodyReader)
+		}
 	}
 
 	req, err := http.NewRequestWithContext(ctx, endpoint.Method, url, bodyReader)
@@ -133,7 +223,7 @@ func executeSequenceStep(ctx context.Context, client *http.Client, baseUrl strin
 		return time.Since(start), fmt.Errorf("request failed: %w", err)
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	body, truncated, err := readBody(resp.Body, maxBodyBytes)
 	closeErr := resp.Body.Close()
 	duration := time.Since(start)
 
@@ -147,14 +237,17 @@ func executeSequenceStep(ctx context.Context, client *http.Client, baseUrl strin
 		return duration, ctx.Err()
 	}
 
-	if resp.StatusCode != endpoint.ExpectedStatus {
-		return duration, fmt.Errorf("status %d, want %d: %s", resp.StatusCode, endpoint.ExpectedStatus, truncate(body, 200))
+	if !endpoint.ExpectedStatus.Matches(resp.StatusCode) {
+		return duration, fmt.Errorf("status %d, want %s: %s", resp.StatusCode, endpoint.ExpectedStatus, truncate(body, 200))
 	}
 
 	var respData any
 	needsParse := len(endpoint.Capture) > 0 || endpoint.ExpectedBody != nil
+	if needsParse && truncated {
+		return duration, bodyTooLargeError(maxBodyBytes)
+	}
 	if needsParse {
-		if err := json.Unmarshal(body, &respData, respOpts); err != nil {
+		if err := json.Unmarshal(body, &respData, flowRespOpts); err != nil {
 			return duration, fmt.Errorf("failed to parse response: %w", err)
 		}
 	}
@@ -166,8 +259,14 @@ func executeSequenceStep(ctx context.Context, client *http.Client, baseUrl strin
 		}
 		for varName, fieldName := range endpoint.Capture {
 			val, ok := respMap[fieldName]
-			if !ok {
-				return duration, fmt.Errorf("capture field %q not found in response", fieldName)
+			if !ok || val == nil {
+				if endpoint.OptionalCaptures[varName] {
+					delete(captured, varName)
+					continue
+				}
+				if !ok {
+					return duration, fmt.Errorf("capture field %q not found in response", fieldName)
+				}
 			}
 			captured[varName] = anyToString(val)
 		}
@@ -183,6 +282,20 @@ func executeSequenceStep(ctx context.Context, client *http.Client, baseUrl strin
 	return duration, nil
 }
 
+// conditionMet reports whether every variable a step's when condition names
+// is a generated var that wasn't omitted, or a capture with a non-empty value.
+func conditionMet(when []string, vars map[string]any, captured map[string]string) bool {
+	for _, name := range when {
+		if v, ok := vars[name]; ok && v != nil {
+			continue
+		}
+		if captured[name] == "" {
+			return false
+		}
+	}
+	return true
+}
+
 func replacePlaceholdersInString(s string, vars map[string]any, captured map[string]string) string {
 	result := s
 	for key, val := range vars {
@@ -237,6 +350,14 @@ func validatePartialMatch(expected, actual any) error {
 	if expected == nil {
 		return nil
 	}
+	if options, ok := config.OneOf(expected); ok {
+		for _, option := range options {
+			if validatePartialMatch(option, actual) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("got %v, want one of %v", actual, options)
+	}
 
 	switch exp := expected.(type) {
 	case map[string]any:
@@ -270,6 +391,12 @@ func validatePartialMatch(expected, actual any) error {
 			}
 		}
 	default:
+		if n, ok := actual.(jsonNumber); ok {
+			if !n.equals(expected) {
+				return fmt.Errorf("got %v, want %v", actual, expected)
+			}
+			return nil
+		}
 		if !reflect.DeepEqual(expected, actual) {
 			return fmt.Errorf("got %v, want %v", actual, expected)
 		}
@@ -281,6 +408,8 @@ func anyToString(v any) string {
 	switch val := v.(type) {
 	case string:
 		return val
+	case jsonNumber:
+		return string(val)
 	case float64:
 		if val == float64(int64(val)) {
 			return strconv.FormatInt(int64(val), 10)
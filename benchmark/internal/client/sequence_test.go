@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestRunSeedSequences(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	seed := &config.ResolvedSequence{
+		Id:       "seed",
+		Database: "postgres",
+		Endpoints: []*config.ResolvedSequenceEndpoint{
+			{Name: "insert", Method: "POST", Path: "/rows", ExpectedStatus: config.ExactStatus(201)},
+			{Name: "insert_more", Method: "POST", Path: "/rows", ExpectedStatus: config.StatusMatcher{Class: 2}},
+		},
+	}
+	if err := RunSeedSequences(context.Background(), srv.URL, []*config.ResolvedSequence{seed}, time.Second, 1<<20); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("seed requests: got %d, want exactly one cycle (2)", got)
+	}
+
+	broken := &config.ResolvedSequence{
+		Id: "seed",
+		Endpoints: []*config.ResolvedSequenceEndpoint{
+			{Name: "insert", Method: "POST", Path: "/rows", ExpectedStatus: config.ExactStatus(201)},
+			{Name: "break", Method: "POST", Path: "/broken", ExpectedStatus: config.ExactStatus(201)},
+		},
+	}
+	err := RunSeedSequences(context.Background(), srv.URL, []*config.ResolvedSequence{broken}, time.Second, 1<<20)
+	if err == nil || !strings.Contains(err.Error(), "step 2 (break)") || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("failing seed: got %v, want step 2 (break) with status 500", err)
+	}
+}
+
+func TestRunSequenceSkipsUnmetWhen(t *testing.T) {
+	t.Parallel()
+
+	var deletes atomic.Int32
+	var createBody atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes.Add(1)
+			if r.URL.Path != "/items/7" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createBody.Load().(string)))
+	}))
+	t.Cleanup(srv.Close)
+
+	seq := &config.ResolvedSequence{
+		Id: "items",
+		Endpoints: []*config.ResolvedSequenceEndpoint{
+			{
+				Name: "create", Method: "POST", Path: "/items", ExpectedStatus: config.ExactStatus(200),
+				Capture: map[string]string{"id": "id"}, OptionalCaptures: map[string]bool{"id": true},
+			},
+			{Name: "delete", Method: "DELETE", Path: "/items/{id}", ExpectedStatus: config.ExactStatus(204), When: []string{"id"}},
+		},
+	}
+
+	createBody.Store(`{"name": "no id"}`)
+	result := RunSequence(context.Background(), srv.Client(), srv.URL, seq, 0, 0, time.Second, 1<<20)
+	if !result.Success || result.Skipped == nil || !result.Skipped[1] {
+		t.Fatalf("uncaptured id: got %+v, want success with delete skipped", result)
+	}
+	if result.StepDurations[1] != 0 || deletes.Load() != 0 {
+		t.Errorf("skipped step: got duration %s and %d deletes, want 0 and none", result.StepDurations[1], deletes.Load())
+	}
+
+	createBody.Store(`{"id": 7}`)
+	result = RunSequence(context.Background(), srv.Client(), srv.URL, seq, 0, 1, time.Second, 1<<20)
+	if !result.Success || result.Skipped != nil || deletes.Load() != 1 {
+		t.Errorf("captured id: got %+v with %d deletes, want delete run once", result, deletes.Load())
+	}
+}
+
+func TestRunSequenceDatabaseWeights(t *testing.T) {
+	t.Parallel()
+
+	var hits sync.Map // path -> *atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := hits.LoadOrStore(r.URL.Path, &atomic.Int32{})
+		n.(*atomic.Int32).Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	choice := func(db string) *config.ResolvedSequence {
+		return &config.ResolvedSequence{
+			Id: "users", Database: db,
+			Endpoints: []*config.ResolvedSequenceEndpoint{{Name: "read", Method: "GET", Path: "/db/" + db + "/users", ExpectedStatus: config.ExactStatus(200)}},
+		}
+	}
+	seq := &config.ResolvedSequence{
+		Id:              "users",
+		Endpoints:       []*config.ResolvedSequenceEndpoint{{Name: "read", Method: "GET", Path: "/db/{database}/users"}},
+		DatabaseChoices: []*config.ResolvedSequence{choice("postgres"), choice("mongodb")},
+		DatabaseWeights: []float64{3, 1},
+	}
+
+	const runs = 2000
+	for i := range runs {
+		result := RunSequence(context.Background(), srv.Client(), srv.URL, seq, 0, i, time.Second, 1<<20)
+		if !result.Success || (result.Database != "postgres" && result.Database != "mongodb") {
+			t.Fatalf("run %d: got %+v, want success against a weighted database", i, result)
+		}
+	}
+	count := func(path string) int32 {
+		n, ok := hits.Load(path)
+		if !ok {
+			return 0
+		}
+		return n.(*atomic.Int32).Load()
+	}
+	if share := float64(count("/db/postgres/users")) / runs; share < 0.70 || share > 0.80 {
+		t.Errorf("postgres share: got %.3f, want ~0.75", share)
+	}
+	if got := count("/db/postgres/users") + count("/db/mongodb/users"); got != runs {
+		t.Errorf("requests: got %d, want %d, all to a chosen database", got, runs)
+	}
+}
+
+func TestRunSequenceCapturesExactIntegers(t *testing.T) {
+	t.Parallel()
+
+	// 2^53 + 1 has no float64 representation; it used to come back as ...992.
+	const id = "9007199254740993"
+	var readPath atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"id": ` + id + `, "version": 2}`))
+			return
+		}
+		readPath.Store(r.URL.Path)
+		_, _ = w.Write([]byte(`{"id": ` + id + `}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	seq := &config.ResolvedSequence{
+		Id: "items",
+		Endpoints: []*config.ResolvedSequenceEndpoint{
+			{
+				Name: "create", Method: "POST", Path: "/items", ExpectedStatus: config.ExactStatus(200),
+				ExpectedBody: map[string]any{"version": 2.0}, Capture: map[string]string{"itemId": "id"},
+			},
+			{Name: "read", Method: "GET", Path: "/items/{itemId}", ExpectedStatus: config.ExactStatus(200)},
+		},
+	}
+
+	result := RunSequence(context.Background(), srv.Client(), srv.URL, seq, 0, 0, time.Second, 1<<20)
+	if !result.Success {
+		t.Fatalf("got %+v, want success", result)
+	}
+	if got := readPath.Load(); got != "/items/"+id {
+		t.Errorf("captured id: got path %v, want /items/%s", got, id)
+	}
+}
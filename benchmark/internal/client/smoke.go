@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// SmokeCheck is the outcome of one smoke request (a testcase) or one flow
+// cycle. An empty Error means it passed.
+type SmokeCheck struct {
+	Name    string
+	Latency time.Duration
+	Error   string
+}
+
+// Smoke sends every resolved testcase exactly once and runs one cycle of each
+// flow, with the same request building and validation as the load phase, so a
+// misconfigured endpoint shows up in seconds instead of after a full run.
+// Nothing is recorded as measurement.
+func (s *Suite) Smoke() []SmokeCheck {
+	checks := make([]SmokeCheck, 0, len(s.server.Testcases)+len(s.server.Sequences))
+
+	for _, tc := range s.server.Testcases {
+		if s.ctx.Err() != nil {
+			return checks
+		}
+		name := fmt.Sprintf("%s %s", tc.Method, tc.Path)
+		if tc.Name != "default" {
+			name += " (" + tc.Name + ")"
+		}
+		latency, err := s.executeTestcase(s.ctx, tc)
+		check := SmokeCheck{Name: name, Latency: latency}
+		if err != nil {
+			check.Error = err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	for _, seq := range s.server.Sequences {
+		if s.ctx.Err() != nil {
+			return checks
+		}
+		name := "flow " + seq.Id
+		if seq.Database != "" {
+			name += "/" + seq.Database
+		}
+		result := RunSequence(s.ctx, s.httpClient, s.baseURL, seq, 0, 0, s.server.RequestTimeout, s.server.MaxBodyBytes)
+		check := SmokeCheck{Name: name, Latency: result.TotalDuration}
+		if !result.Success {
+			check.Error = fmt.Sprintf("step %d (%s): %s",
+				result.FailedStep+1, seq.Endpoints[result.FailedStep].Name, result.Error)
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestSmokeSendsOneRequestPerCheck(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	suite, testcases := newTestSuite(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("no such route"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}, config.LoadConfig{}, time.Second)
+
+	suite.server.Testcases = append(testcases, &config.Testcase{
+		EndpointName: "missing", Name: "default", Path: "/missing", RequestURI: "/missing",
+		Method: "GET", ExpectedStatus: config.ExactStatus(200),
+	})
+	suite.server.Sequences = []*config.ResolvedSequence{{
+		Id: "crud",
+		Endpoints: []*config.ResolvedSequenceEndpoint{
+			{Name: "create", Method: "POST", Path: "/", ExpectedStatus: config.ExactStatus(200)},
+			{Name: "read", Method: "GET", Path: "/missing", ExpectedStatus: config.ExactStatus(200)},
+		},
+	}}
+
+	checks := suite.Smoke()
+
+	if got := hits.Load(); got != 4 {
+		t.Errorf("requests: got %d, want 4 (one per testcase, one cycle of the flow)", got)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("checks: got %d, want 3", len(checks))
+	}
+	if checks[0].Error != "" || checks[0].Name != "GET / (root)" {
+		t.Errorf("root check: got %+v", checks[0])
+	}
+	if checks[1].Name != "GET /missing" || !strings.Contains(checks[1].Error, "no such route") {
+		t.Errorf("missing check should report the response snippet: got %+v", checks[1])
+	}
+	if checks[2].Name != "flow crud" || !strings.Contains(checks[2].Error, "step 2 (read)") {
+		t.Errorf("flow check: got %+v", checks[2])
+	}
+}
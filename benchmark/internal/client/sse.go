@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+// SSEStats is the event timing of a type "sse" endpoint. The endpoint's
+// Stats latency is each stream's time to first event, which includes the
+// server's setup; InterEvent is the steady state after it.
+type SSEStats struct {
+	Streams    int          `json:"streams"`               // streams read successfully, each with at least one event
+	Events     int          `json:"events"`                // events read across those streams
+	FirstEvent *PhaseTiming `json:"first_event,omitempty"` // request start to the first complete event
+	InterEvent *PhaseTiming `json:"inter_event,omitempty"` // gap between consecutive events of one stream
+}
+
+// sseCounter collects per-endpoint event timings. Like phaseCounter it is
+// reset before each measured window so warmup streams don't leak in.
+type sseCounter struct {
+	mu      sync.Mutex
+	samples map[string]*sseSamples
+}
+
+type sseSamples struct {
+	events int
+	first  []time.Duration
+	inter  []time.Duration
+}
+
+func (c *sseCounter) record(endpoint string, first time.Duration, gaps []time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.samples == nil {
+		c.samples = make(map[string]*sseSamples)
+	}
+	s := c.samples[endpoint]
+	if s == nil {
+		s = &sseSamples{}
+		c.samples[endpoint] = s
+	}
+	s.events += 1 + len(gaps)
+	s.first = append(s.first, first)
+	s.inter = append(s.inter, gaps...)
+}
+
+func (c *sseCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = nil
+}
+
+// take summarizes endpoint's streams and forgets them; nil for an endpoint
+// that isn't an event stream or never got an event.
+func (c *sseCounter) take(endpoint string) *SSEStats {
+	c.mu.Lock()
+	s := c.samples[endpoint]
+	delete(c.samples, endpoint)
+	c.mu.Unlock()
+	if s == nil {
+		return nil
+	}
+	return &SSEStats{
+		Streams:    len(s.first),
+		Events:     s.events,
+		FirstEvent: summarizePhase(s.first),
+		InterEvent: summarizePhase(s.inter),
+	}
+}
+
+// readEventStream reads tc's event stream until sse.events events arrive,
+// sse.max_duration passes, or the server ends it; then the body is closed,
+// which drops the connection mid-stream. A canceled ctx closes it too, via
+// the request. The latency is the time from start to the first event; only
+// streams read successfully feed the event timings.
+func (s *Suite) readEventStream(ctx context.Context, tc *config.Testcase, resp *http.Response, start time.Time) (time.Duration, error) {
+	defer resp.Body.Close()
+
+	if !tc.ExpectedStatus.Matches(resp.StatusCode) {
+		body, _, _ := readBody(resp.Body, 200)
+		return time.Since(start), ValidateResponse(tc, resp, body)
+	}
+	if err := ValidateResponse(tc, resp, nil); err != nil {
+		return time.Since(start), err
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return time.Since(start), fmt.Errorf("unexpected content type %q, want text/event-stream", resp.Header.Get("Content-Type"))
+	}
+
+	var windowDone atomic.Bool
+	if tc.SSE.MaxDuration > 0 {
+		timer := time.AfterFunc(tc.SSE.MaxDuration-time.Since(start), func() {
+			windowDone.Store(true)
+			_ = resp.Body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	events, err := readEvents(resp.Body, tc.SSE.Events, s.server.MaxBodyBytes)
+	if err != nil && windowDone.Load() {
+		err = nil
+	}
+	switch {
+	case errors.Is(err, bufio.ErrTooLong):
+		return time.Since(start), bodyTooLargeError(s.server.MaxBodyBytes)
+	case err != nil:
+		return time.Since(start), classifyRequestTimeout(ctx, fmt.Errorf("failed to read event stream: %w", err), s.server.RequestTimeout)
+	case len(events) == 0 && windowDone.Load():
+		return time.Since(start), fmt.Errorf("no event within sse.max_duration %s", tc.SSE.MaxDuration)
+	case len(events) == 0:
+		return time.Since(start), errors.New("event stream ended before its first event")
+	}
+
+	first := events[0].Sub(start)
+	gaps := make([]time.Duration, len(events)-1)
+	for i := range gaps {
+		gaps[i] = events[i+1].Sub(events[i])
+	}
+	s.sse.record(tc.EndpointName, first, gaps)
+	return first, nil
+}
+
+// readEvents reads up to want events from an event stream and returns when
+// each completed. An event completes at the blank line after one or more data
+// lines; comments and events without data are skipped, as EventSource does.
+// A line longer than limit fails the read. The server ending the stream
+// early is not an error: the events so far are returned.
+func readEvents(body io.Reader, want int, limit int64) ([]time.Time, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), int(min(limit, 1<<30)))
+
+	events := make([]time.Time, 0, want)
+	hasData := false
+	for len(events) < want && scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			if hasData {
+				events = append(events, time.Now())
+			}
+			hasData = false
+		case bytes.Equal(line, []byte("data")) || bytes.HasPrefix(line, []byte("data:")):
+			hasData = true
+		}
+	}
+	return events, scanner.Err()
+}
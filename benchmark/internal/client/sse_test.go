@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+// sseHandler sends a comment, then events every few milliseconds and, after
+// the last one, holds the stream open until the client hangs up, which it
+// reports on gone.
+func sseHandler(events int, gone chan<- struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprint(w, ": stream start\n\n")
+		w.(http.Flusher).Flush()
+		for i := range events {
+			time.Sleep(2 * time.Millisecond)
+			_, _ = fmt.Fprintf(w, "event: tick\ndata: %d\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+		if gone != nil {
+			close(gone)
+		}
+	}
+}
+
+// waitGone fails t unless the handler saw its stream closed within a second.
+func waitGone(t *testing.T, gone <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-gone:
+	case <-time.After(time.Second):
+		t.Error("connection still open after the read")
+	}
+}
+
+func TestExecuteTestcaseSSE(t *testing.T) {
+	t.Parallel()
+
+	gone := make(chan struct{})
+	suite, testcases := newTestSuite(t, sseHandler(10, gone), config.LoadConfig{}, time.Second)
+	tc := testcases[0]
+	tc.SSE = &config.SSEConfig{Events: 3}
+
+	latency, err := suite.executeTestcase(context.Background(), tc)
+	if err != nil {
+		t.Fatalf("executeTestcase: %v", err)
+	}
+	if latency <= 0 {
+		t.Errorf("latency: got %v, want the time to the first event", latency)
+	}
+	waitGone(t, gone)
+
+	stats := suite.sse.take("root")
+	if stats == nil || stats.Streams != 1 || stats.Events != 3 {
+		t.Fatalf("sse stats: got %+v, want 1 stream of 3 events", stats)
+	}
+	if stats.FirstEvent == nil || stats.FirstEvent.Count != 1 || stats.InterEvent == nil || stats.InterEvent.Count != 2 {
+		t.Errorf("timings: got first %+v inter %+v, want 1 and 2 samples", stats.FirstEvent, stats.InterEvent)
+	}
+}
+
+func TestExecuteTestcaseSSEMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	gone := make(chan struct{})
+	suite, testcases := newTestSuite(t, sseHandler(1, gone), config.LoadConfig{}, time.Second)
+	tc := testcases[0]
+	tc.SSE = &config.SSEConfig{Events: 5, MaxDuration: 50 * time.Millisecond}
+
+	start := time.Now()
+	if _, err := suite.executeTestcase(context.Background(), tc); err != nil {
+		t.Fatalf("one event within max_duration: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("read ran %v, want it stopped at max_duration", elapsed)
+	}
+	waitGone(t, gone)
+	if stats := suite.sse.take("root"); stats == nil || stats.Events != 1 {
+		t.Errorf("sse stats: got %+v, want 1 event", stats)
+	}
+
+	quietGone := make(chan struct{})
+	quiet, quietTestcases := newTestSuite(t, sseHandler(0, quietGone), config.LoadConfig{}, time.Second)
+	quietTestcases[0].SSE = tc.SSE
+	_, err := quiet.executeTestcase(context.Background(), quietTestcases[0])
+	if err == nil || !strings.Contains(err.Error(), "no event within sse.max_duration 50ms") {
+		t.Errorf("silent stream: got %v, want a max_duration error", err)
+	}
+	waitGone(t, quietGone)
+}
+
+func TestExecuteTestcaseSSEFailures(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "stream ends before an event",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = fmt.Fprint(w, ": bye\n\n")
+			},
+			wantErr: "event stream ended before its first event",
+		},
+		{
+			name:    "not an event stream",
+			handler: func(w http.ResponseWriter, _ *http.Request) { _, _ = fmt.Fprint(w, `{"ok": true}`) },
+			wantErr: "want text/event-stream",
+		},
+		{
+			name:    "unexpected status",
+			handler: func(w http.ResponseWriter, _ *http.Request) { http.Error(w, "nope", http.StatusServiceUnavailable) },
+			wantErr: "unexpected status code: got 503",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			suite, testcases := newTestSuite(t, tc.handler, config.LoadConfig{}, time.Second)
+			testcases[0].SSE = &config.SSEConfig{Events: 2}
+			_, err := suite.executeTestcase(context.Background(), testcases[0])
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
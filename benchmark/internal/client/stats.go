@@ -1,9 +1,14 @@
 package client
 
 import (
+	"cmp"
 	"math"
+	"math/rand/v2"
 	"slices"
+	"sync"
 	"time"
+
+	"benchmark-client/internal/config"
 )
 
 type Stats struct {
@@ -17,7 +22,28 @@ type Stats struct {
 	P95         time.Duration `json:"p95"`
 	P99         time.Duration `json:"p99"`
 	P999        time.Duration `json:"p999"`
+	StdDev      time.Duration `json:"stddev,omitempty"` // sample standard deviation, for the summary's tie check
 	SuccessRate float64       `json:"success_rate"`
+	Sampled     int           `json:"sampled,omitempty"`      // reservoir size when percentiles are estimated (max_samples)
+	Estimator   string        `json:"estimator,omitempty"`    // "tdigest" when percentiles come from the streaming digest
+	NewConns    int           `json:"new_conns,omitempty"`    // --conn-stats: requests that dialed a connection
+	ReusedConns int           `json:"reused_conns,omitempty"` // --conn-stats: requests on a pooled keep-alive connection
+	TailRatio   float64       `json:"tail_ratio,omitempty"`   // P99/P50, how far the tail stretches past the median (0 when P50 is 0)
+
+	// QueueDelayP99 is --conn-stats' p99 wait for a connection in the client
+	// transport, dialing excluded. It is part of the latency, so when it is
+	// a large share of P99 the client's pool, not the server, is the limit.
+	QueueDelayP99 time.Duration `json:"queue_delay_p99,omitempty"`
+}
+
+// ConnReuse is the share of requests served on a reused connection, or -1
+// when connections were not traced.
+func (s *Stats) ConnReuse() float64 {
+	total := s.NewConns + s.ReusedConns
+	if total == 0 {
+		return -1
+	}
+	return float64(s.ReusedConns) / float64(total)
 }
 
 // CalculateStats computes latency stats over the run's successful requests.
@@ -63,6 +89,154 @@ func CalculateStats(latencies []time.Duration, successCount, totalCount int, ela
 	stats.P95 = Percentile(latencies, 95)
 	stats.P99 = Percentile(latencies, 99)
 	stats.P999 = Percentile(latencies, 99.9)
+	stats.StdDev = StdDev(latencies, stats.Avg)
+	stats.TailRatio = TailRatio(stats.P50, stats.P99)
+	return stats
+}
+
+// TailRatio is p99/p50, the tail-latency amplification: 1 means the slow
+// requests are no slower than the median. It is 0 when p50 is, rather than
+// a division by zero.
+func TailRatio(p50, p99 time.Duration) float64 {
+	if p50 <= 0 {
+		return 0
+	}
+	return float64(p99) / float64(p50)
+}
+
+// StdDev is the sample standard deviation of latencies around mean (0 for
+// fewer than two latencies).
+func StdDev(latencies []time.Duration, mean time.Duration) time.Duration {
+	if len(latencies) < 2 {
+		return 0
+	}
+	var sumSq float64
+	for _, l := range latencies {
+		d := float64(l - mean)
+		sumSq += d * d
+	}
+	return time.Duration(math.Sqrt(sumSq / float64(len(latencies)-1)))
+}
+
+// latencyReservoir bounds the latencies one run keeps (benchmark.max_samples).
+// Under the cap it keeps everything; past it, Algorithm R replaces entries so
+// the kept set stays a uniform random sample of the whole run. Count, avg,
+// min, and max are tracked exactly on the side; percentiles (and the timed
+// latencies exported to metrics) come from the sample, so their error grows
+// toward the tail — with 100k samples p99 rests on ~1000 points but p99.9 on
+// only ~100. With a digest (benchmark.estimator "tdigest") every latency also
+// feeds it and the percentiles come from the digest instead, so the sample
+// only backs the histograms and exported raw latencies.
+type latencyReservoir struct {
+	limit     int // 0 = unbounded
+	seen      int
+	samples   []TimedLatency
+	total     time.Duration
+	low, high time.Duration
+	digest    *tdigest // nil for the exact estimator
+	discarded int      // leading successes dropped by discardFirst
+}
+
+func newLatencyReservoir(limit int, estimator string) *latencyReservoir {
+	capacity := 10000
+	if limit > 0 {
+		capacity = min(capacity, limit)
+	}
+	r := &latencyReservoir{limit: limit, samples: make([]TimedLatency, 0, capacity), low: time.Hour}
+	if estimator == config.EstimatorTDigest {
+		r.digest = newTDigest()
+	}
+	return r
+}
+
+func (r *latencyReservoir) add(tl TimedLatency) {
+	r.seen++
+	r.total += tl.Duration
+	r.low = min(r.low, tl.Duration)
+	r.high = max(r.high, tl.Duration)
+	if r.digest != nil {
+		r.digest.add(tl.Duration)
+	}
+
+	if r.limit == 0 || len(r.samples) < r.limit {
+		r.samples = append(r.samples, tl)
+		return
+	}
+	if j := rand.IntN(r.seen); j < r.limit {
+		r.samples[j] = tl
+	}
+}
+
+// sampled reports whether the cap was hit and the samples are a subset.
+func (r *latencyReservoir) sampled() bool {
+	return r.seen-r.discarded > len(r.samples)
+}
+
+// discardFirst drops the leading fraction of the run's successes from the
+// latency stats (benchmark.discard_first_percent); counts and RPS still cover
+// the whole window. A sampled run drops the same share of its kept samples,
+// taking its aggregates from what is left, and gives up the digest, which
+// can't forget what it saw.
+func (r *latencyReservoir) discardFirst(fraction float64) {
+	if fraction <= 0 || r.seen == 0 {
+		return
+	}
+	sampled := r.sampled()
+	r.samples = discardLeading(r.timed(), fraction)
+	r.discarded = int(float64(r.seen) * fraction)
+	if r.digest == nil {
+		return
+	}
+	r.digest = nil
+	if !sampled {
+		r.digest = newTDigest()
+		for _, tl := range r.samples {
+			r.digest.add(tl.Duration)
+		}
+	}
+}
+
+// discardLeading drops the first fraction of samples, which are in request
+// order, for benchmark.discard_first_percent.
+func discardLeading[T any](samples []T, fraction float64) []T {
+	return samples[int(float64(len(samples))*fraction):]
+}
+
+// timed returns the kept latencies in request order.
+func (r *latencyReservoir) timed() []TimedLatency {
+	if r.sampled() {
+		slices.SortFunc(r.samples, func(a, b TimedLatency) int {
+			return cmp.Compare(a.ServerOffset, b.ServerOffset)
+		})
+	}
+	return r.samples
+}
+
+// stats is CalculateStats over the kept sample with the exact aggregates
+// restored when sampling kicked in.
+func (r *latencyReservoir) stats(totalCount int, elapsed time.Duration) *Stats {
+	latencies := make([]time.Duration, len(r.samples))
+	for i, tl := range r.samples {
+		latencies[i] = tl.Duration
+	}
+	stats := CalculateStats(latencies, r.seen, totalCount, elapsed)
+	if r.sampled() {
+		if r.discarded == 0 {
+			stats.Avg = r.total / time.Duration(r.seen)
+			stats.Low = r.low
+			stats.High = r.high
+		}
+		stats.Sampled = len(r.samples)
+	}
+	if r.digest != nil && r.seen > 0 {
+		stats.P50 = r.digest.percentile(50)
+		stats.P95 = r.digest.percentile(95)
+		stats.P99 = r.digest.percentile(99)
+		stats.P999 = r.digest.percentile(99.9)
+		stats.TailRatio = TailRatio(stats.P50, stats.P99)
+		stats.Sampled = 0 // the digest saw every latency
+		stats.Estimator = config.EstimatorTDigest
+	}
 	return stats
 }
 
@@ -92,3 +266,136 @@ func Percentile(sorted []time.Duration, p float64) time.Duration {
 	frac := rank - float64(lo)
 	return sorted[lo] + time.Duration(frac*float64(sorted[hi]-sorted[lo]))
 }
+
+// HistogramBounds are the inclusive upper bounds of the exported latency
+// histogram: 1-2-5 steps from 100µs to 10s. Fixed bounds keep buckets
+// comparable across servers and runs, which a per-run adaptive layout would not.
+var HistogramBounds = []time.Duration{
+	100 * time.Microsecond, 200 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+}
+
+// HistogramBucket counts latencies in (previous bound, Le]. The final
+// overflow bucket has Le == 0 and counts everything above the last bound.
+type HistogramBucket struct {
+	Le    time.Duration
+	Count int
+}
+
+// LatencyHistogram buckets latencies over HistogramBounds. It always returns
+// len(HistogramBounds)+1 buckets in ascending order, overflow last, so every
+// endpoint exports the same rows even when most counts are zero.
+func LatencyHistogram(latencies []TimedLatency) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(HistogramBounds)+1)
+	for i, le := range HistogramBounds {
+		buckets[i].Le = le
+	}
+	for _, l := range latencies {
+		i, _ := slices.BinarySearch(HistogramBounds, l.Duration)
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+// statusCounter tallies response status codes per endpoint across the suite's
+// workers. Every response is counted, passing or not, so intermittent 5xx
+// show up next to the expected status.
+type statusCounter struct {
+	mu     sync.Mutex
+	counts map[string]map[int]int
+}
+
+func (c *statusCounter) record(endpoint string, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]map[int]int)
+	}
+	byStatus := c.counts[endpoint]
+	if byStatus == nil {
+		byStatus = make(map[int]int)
+		c.counts[endpoint] = byStatus
+	}
+	byStatus[status]++
+}
+
+// reset drops everything recorded so far, e.g. warmup responses.
+func (c *statusCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = nil
+}
+
+// take returns and forgets the counts for endpoint (nil if none).
+func (c *statusCounter) take(endpoint string) map[int]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	byStatus := c.counts[endpoint]
+	delete(c.counts, endpoint)
+	return byStatus
+}
+
+// hitCounter counts matching responses per endpoint, reset like
+// statusCounter so warmup traffic isn't counted.
+type hitCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *hitCounter) record(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[endpoint]++
+}
+
+func (c *hitCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = nil
+}
+
+// take returns and forgets the count for endpoint.
+func (c *hitCounter) take(endpoint string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.counts[endpoint]
+	delete(c.counts, endpoint)
+	return n
+}
+
+// byteCounter totals response body bytes per endpoint, reset like
+// statusCounter so warmup traffic isn't counted.
+type byteCounter struct {
+	mu    sync.Mutex
+	total map[string]int64
+}
+
+func (c *byteCounter) record(endpoint string, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.total == nil {
+		c.total = make(map[string]int64)
+	}
+	c.total[endpoint] += int64(n)
+}
+
+func (c *byteCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total = nil
+}
+
+// take returns and forgets the total for endpoint.
+func (c *byteCounter) take(endpoint string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.total[endpoint]
+	delete(c.total, endpoint)
+	return n
+}
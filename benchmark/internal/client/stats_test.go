@@ -1,8 +1,15 @@
 package client
 
 import (
+	"cmp"
+	"math"
+	"net/http"
+	"slices"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"benchmark-client/internal/config"
 )
 
 // Percentile uses linear interpolation (percentile_cont / R type-7). The
@@ -70,3 +77,312 @@ func TestCalculateStatsPopulatesP999(t *testing.T) {
 		t.Errorf("low/high = %v/%v, want 1ms/1s", stats.Low, stats.High)
 	}
 }
+
+func TestStdDev(t *testing.T) {
+	t.Parallel()
+
+	// 2,4,4,4,5,5,7,9ms: mean 5ms, squared deviations sum to 32ms², so the
+	// sample stddev is sqrt(32/7)ms.
+	latencies := []time.Duration{2, 4, 4, 4, 5, 5, 7, 9}
+	for i := range latencies {
+		latencies[i] *= time.Millisecond
+	}
+	want := time.Duration(math.Sqrt(32.0/7) * float64(time.Millisecond))
+	if got := StdDev(latencies, 5*time.Millisecond); got != want {
+		t.Errorf("StdDev: got %v, want %v", got, want)
+	}
+	if got := StdDev(latencies[:1], latencies[0]); got != 0 {
+		t.Errorf("single latency: got %v, want 0", got)
+	}
+}
+
+func TestTailRatio(t *testing.T) {
+	t.Parallel()
+
+	if got := TailRatio(2*time.Millisecond, 7*time.Millisecond); got != 3.5 {
+		t.Errorf("TailRatio: got %v, want 3.5", got)
+	}
+	if got := TailRatio(0, 7*time.Millisecond); got != 0 {
+		t.Errorf("zero p50: got %v, want 0", got)
+	}
+	if got := CalculateStats(nil, 0, 3, time.Second).TailRatio; got != 0 {
+		t.Errorf("no successes: got %v, want 0", got)
+	}
+}
+
+// Bounds are inclusive upper edges: a latency equal to a bound lands in that
+// bucket, one nanosecond more lands in the next, and anything past 10s goes
+// to the trailing overflow bucket.
+func TestLatencyHistogramBuckets(t *testing.T) {
+	t.Parallel()
+
+	at := func(ds ...time.Duration) []TimedLatency {
+		out := make([]TimedLatency, len(ds))
+		for i, d := range ds {
+			out[i] = TimedLatency{Duration: d}
+		}
+		return out
+	}
+
+	buckets := LatencyHistogram(at(
+		50*time.Microsecond, 100*time.Microsecond, // both ≤ 100µs
+		100*time.Microsecond+1, // first bucket above
+		3*time.Millisecond,     // (2ms, 5ms]
+		time.Minute,            // overflow
+	))
+
+	if len(buckets) != len(HistogramBounds)+1 {
+		t.Fatalf("bucket count: got %d, want %d", len(buckets), len(HistogramBounds)+1)
+	}
+	for i := 1; i < len(HistogramBounds); i++ {
+		if buckets[i].Le <= buckets[i-1].Le {
+			t.Fatalf("buckets not ascending at %d: %v <= %v", i, buckets[i].Le, buckets[i-1].Le)
+		}
+	}
+	want := map[time.Duration]int{100 * time.Microsecond: 2, 200 * time.Microsecond: 1, 5 * time.Millisecond: 1, 0: 1}
+	total := 0
+	for _, b := range buckets {
+		total += b.Count
+		if b.Count != want[b.Le] {
+			t.Errorf("bucket le=%v: got %d, want %d", b.Le, b.Count, want[b.Le])
+		}
+	}
+	if total != 5 {
+		t.Errorf("total count: got %d, want 5", total)
+	}
+	if last := buckets[len(buckets)-1]; last.Le != 0 {
+		t.Errorf("overflow bucket must be last with Le 0, got %v", last.Le)
+	}
+}
+
+func TestLatencyReservoirKeepsExactCounts(t *testing.T) {
+	t.Parallel()
+
+	const limit, total = 1000, 50_000
+	r := newLatencyReservoir(limit, config.EstimatorExact)
+	for i := range total {
+		r.add(TimedLatency{
+			ServerOffset: time.Duration(i) * time.Microsecond,
+			Duration:     time.Duration(i+1) * time.Microsecond,
+		})
+	}
+
+	stats := r.stats(total+10, time.Second)
+	if stats.Count != total || stats.TotalCount != total+10 {
+		t.Errorf("counts: got %d/%d, want %d/%d", stats.Count, stats.TotalCount, total, total+10)
+	}
+	if stats.Sampled != limit {
+		t.Errorf("sampled: got %d, want %d", stats.Sampled, limit)
+	}
+	if stats.Low != time.Microsecond || stats.High != total*time.Microsecond {
+		t.Errorf("min/max: got %v/%v, want exact 1µs/%v", stats.Low, stats.High, total*time.Microsecond)
+	}
+	if want := (total + 1) * time.Microsecond / 2; stats.Avg != want {
+		t.Errorf("avg: got %v, want exact %v", stats.Avg, want)
+	}
+	// Uniform sample of 1..50000µs: p50 near 25ms, well inside a loose band.
+	if stats.P50 < 20*time.Millisecond || stats.P50 > 30*time.Millisecond {
+		t.Errorf("p50 from reservoir: got %v, want ~25ms", stats.P50)
+	}
+
+	timed := r.timed()
+	if len(timed) != limit {
+		t.Fatalf("timed latencies: got %d, want %d", len(timed), limit)
+	}
+	if !slices.IsSortedFunc(timed, func(a, b TimedLatency) int { return cmp.Compare(a.ServerOffset, b.ServerOffset) }) {
+		t.Error("timed latencies should be in request order")
+	}
+}
+
+func TestLatencyReservoirUnbounded(t *testing.T) {
+	t.Parallel()
+
+	r := newLatencyReservoir(0, config.EstimatorExact)
+	for i := range 20_000 {
+		r.add(TimedLatency{Duration: time.Duration(i + 1)})
+	}
+	if stats := r.stats(20_000, time.Second); stats.Sampled != 0 || len(r.timed()) != 20_000 {
+		t.Errorf("unbounded reservoir: sampled %d, kept %d", stats.Sampled, len(r.timed()))
+	}
+}
+
+func TestLatencyReservoirDiscardFirst(t *testing.T) {
+	t.Parallel()
+
+	for _, estimator := range []string{config.EstimatorExact, config.EstimatorTDigest} {
+		r := newLatencyReservoir(0, estimator)
+		for i := range 1000 {
+			latency := time.Millisecond
+			if i < 100 {
+				latency = time.Second // ramp-up still under way after warmup
+			}
+			r.add(TimedLatency{ServerOffset: time.Duration(i) * time.Millisecond, Duration: latency})
+		}
+		r.discardFirst(0.10)
+
+		stats := r.stats(1000, time.Second)
+		if stats.Count != 1000 || stats.Rps != 1000 {
+			t.Errorf("%s: count %d, rps %v; want the whole window's 1000", estimator, stats.Count, stats.Rps)
+		}
+		if stats.High != time.Millisecond || stats.P99 > time.Millisecond+time.Microsecond {
+			t.Errorf("%s: max %v, p99 %v; want the first 10%% of samples gone", estimator, stats.High, stats.P99)
+		}
+		if len(r.timed()) != 900 {
+			t.Errorf("%s: kept %d timed latencies, want 900", estimator, len(r.timed()))
+		}
+	}
+}
+
+func TestRunEndpointStatusCounts(t *testing.T) {
+	t.Parallel()
+
+	var n atomic.Int64
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		if n.Add(1)%4 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	suite, testcases := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, 200*time.Millisecond)
+
+	// Responses before the measured window (e.g. warmup) must not leak in.
+	suite.statuses.record("root", http.StatusTeapot)
+
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	if _, ok := result.StatusCounts[http.StatusTeapot]; ok {
+		t.Errorf("status counts kept a pre-window response: %v", result.StatusCounts)
+	}
+	ok, failed := result.StatusCounts[http.StatusOK], result.StatusCounts[http.StatusInternalServerError]
+	if ok == 0 || failed == 0 {
+		t.Fatalf("status counts: got %v, want both 200 and 500", result.StatusCounts)
+	}
+	// A response cut off by the window's end is counted by status but then canceled.
+	if ok < result.Stats.Count || ok > result.Stats.Count+result.CanceledCount {
+		t.Errorf("200 count: got %d, want success count %d (+%d canceled)", ok, result.Stats.Count, result.CanceledCount)
+	}
+	if failed < result.FailureCount || failed > result.FailureCount+result.CanceledCount {
+		t.Errorf("500 count: got %d, want failure count %d (+%d canceled)", failed, result.FailureCount, result.CanceledCount)
+	}
+}
+
+func TestRunEndpointConnStats(t *testing.T) {
+	t.Parallel()
+
+	ok := func(http.ResponseWriter, *http.Request) {}
+	suite, testcases := newTestSuite(t, ok, config.LoadConfig{Mode: config.LoadModeClosed}, 200*time.Millisecond)
+
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	if result.Stats.NewConns != 0 || result.Stats.ReusedConns != 0 || result.Stats.ConnReuse() != -1 {
+		t.Errorf("untraced: got %d new %d reused, want none", result.Stats.NewConns, result.Stats.ReusedConns)
+	}
+
+	suite.server.ConnStats = true
+	result = suite.runEndpoint("root", "/", "GET", testcases)
+	stats := result.Stats
+	if stats.NewConns > suite.server.Concurrency {
+		t.Errorf("new conns: got %d, want at most one per worker (%d)", stats.NewConns, suite.server.Concurrency)
+	}
+	if stats.ReusedConns == 0 || stats.ConnReuse() < 0.9 {
+		t.Errorf("reused conns: got %d (%.2f reuse), want keep-alive reuse", stats.ReusedConns, stats.ConnReuse())
+	}
+}
+
+func TestConnCounterBoundsQueueWaits(t *testing.T) {
+	t.Parallel()
+
+	c := connCounter{maxSamples: 1000, estimator: config.EstimatorExact}
+	for i := range 50_000 {
+		c.record("root", true, time.Duration(i+1)*time.Microsecond)
+	}
+	if kept := len(c.counts["root"].waits.samples); kept != 1000 {
+		t.Errorf("kept %d queue waits, want the max_samples 1000", kept)
+	}
+	stats := &Stats{}
+	c.apply("root", stats)
+	// Uniform 1..50000µs: p99 near 49.5ms, well inside a loose band.
+	if stats.ReusedConns != 50_000 || stats.QueueDelayP99 < 45*time.Millisecond || stats.QueueDelayP99 > 50*time.Millisecond {
+		t.Errorf("got %d reused, queue p99 %v; want 50000 and about 49.5ms", stats.ReusedConns, stats.QueueDelayP99)
+	}
+}
+
+func TestRunEndpointTracePhases(t *testing.T) {
+	t.Parallel()
+
+	slow := func(http.ResponseWriter, *http.Request) { time.Sleep(2 * time.Millisecond) }
+	suite, testcases := newTestSuite(t, slow, config.LoadConfig{Mode: config.LoadModeClosed}, 200*time.Millisecond)
+
+	if result := suite.runEndpoint("root", "/", "GET", testcases); result.Phases != nil {
+		t.Errorf("untraced: got phases %+v, want nil", result.Phases)
+	}
+
+	suite.server.TracePhases = true
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	phases := result.Phases
+	if phases == nil || phases.TTFB == nil {
+		t.Fatalf("traced: got %+v, want a TTFB breakdown", phases)
+	}
+	if phases.TTFB.Count < result.Stats.Count {
+		t.Errorf("ttfb count: got %d, want at least every success (%d)", phases.TTFB.Count, result.Stats.Count)
+	}
+	if phases.TTFB.P50 < 2*time.Millisecond || phases.TTFB.P50 > phases.TTFB.High {
+		t.Errorf("ttfb p50: got %s (high %s), want >= the handler's 2ms", phases.TTFB.P50, phases.TTFB.High)
+	}
+	// The pool was warmed by the first run, so few (if any) new connections are dialed.
+	if phases.Connect != nil && phases.Connect.Count > suite.server.Concurrency {
+		t.Errorf("connect count: got %d, want at most one per worker (%d)", phases.Connect.Count, suite.server.Concurrency)
+	}
+	if phases.TLS != nil || phases.DNS != nil {
+		t.Errorf("plain-http loopback: got tls %+v dns %+v, want none", phases.TLS, phases.DNS)
+	}
+}
+
+func TestRunEndpointCountsResponseBytes(t *testing.T) {
+	t.Parallel()
+
+	body := func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("0123456789")) }
+	suite, testcases := newTestSuite(t, body, config.LoadConfig{Mode: config.LoadModeClosed}, 100*time.Millisecond)
+
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	if result.Stats == nil || result.Stats.TotalCount == 0 {
+		t.Fatalf("got stats %+v, want measured requests", result.Stats)
+	}
+	// Every response read in the window counts, including ones that straddle
+	// the window's end and are classified as canceled.
+	if want := int64(10 * result.Stats.TotalCount); result.ResponseBytes < want {
+		t.Errorf("got %d response bytes, want at least %d (10 per request)", result.ResponseBytes, want)
+	}
+}
+
+func TestRunEndpointMaxTotalConns(t *testing.T) {
+	t.Parallel()
+
+	const limit = 3
+	var inflight, peak, served atomic.Int64
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		// Every other request fails, so a slot leaked on the error path
+		// would starve the workers well before the window ends.
+		if served.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	suite, testcases := newTestSuite(t, handler, config.LoadConfig{Mode: config.LoadModeClosed}, 200*time.Millisecond)
+	suite.server.Concurrency = 16
+	suite.server.MaxTotalConns = limit
+	suite.inflight = make(chan struct{}, limit)
+
+	result := suite.runEndpoint("root", "/", "GET", testcases)
+	if got := peak.Load(); got > limit {
+		t.Errorf("peak in flight: got %d, want at most %d", got, limit)
+	}
+	if result.Stats.TotalCount < 50 || result.FailureCount == 0 {
+		t.Errorf("requests: got %d (%d failed), want the cap to keep serving past failures", result.Stats.TotalCount, result.FailureCount)
+	}
+}
@@ -4,19 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"benchmark-client/internal/config"
 )
 
+// ProgressCallbacks are invoked from the goroutine driving the suite, never
+// concurrently for one Suite; suites running side by side should report to a
+// shared renderer such as cli.ProgressBoard, which serializes their output.
 type ProgressCallbacks struct {
-	OnEndpoint func(method, path string, done int)
-	OnSequence func(seqName string, done int)
+	OnEndpoint     func(method, path string, done int)
+	OnEndpointDone func(result *EndpointResult) // after each measured endpoint (all at once in mixed mode)
+	OnSequence     func(seqName string, done int)
+	OnMeasureStart func() // a measured window (endpoint, mixed run or sequence) begins; warmup never does
+	OnMeasureEnd   func()
 }
 
 type Suite struct {
@@ -24,10 +30,21 @@ type Suite struct {
 	httpClient      *http.Client
 	transport       *http.Transport
 	server          *config.ResolvedServer
-	baseURL         string // runtime base (scheme://host:mappedPort), no trailing slash
+	baseURL         string   // runtime base (scheme://host:mappedPort), no trailing slash
+	baseURLs        []string // hosts requests round-robin across; just baseURL unless server.BaseUrls
+	nextHost        atomic.Uint64
 	serverStartTime time.Time
 	timedResults    []TimedResult
 	timedSequences  []TimedSequenceResult
+	warmupResults   []TimedResult // warmup requests, server.ExportWarmup only
+	mixedStats      *Stats        // blended stats across all endpoints, mixed mode only
+	statuses        statusCounter // measured-window response codes per endpoint
+	bytes           byteCounter   // measured-window response body bytes per endpoint
+	cached          hitCounter    // measured-window responses that look cached (LooksCached)
+	conns           connCounter   // measured-window new/reused connections, --conn-stats only
+	phases          phaseCounter  // measured-window DNS/connect/TLS/TTFB samples, --trace-phases only
+	sse             sseCounter    // measured-window event timings of sse endpoints
+	inflight        chan struct{} // max_total_conns slots, one per request in flight (nil = unlimited)
 	progress        *ProgressCallbacks
 }
 
@@ -37,34 +54,96 @@ func NewSuite(ctx context.Context, server *config.ResolvedServer, baseURL string
 	// The connection pool must match the mode's real parallelism: in open mode
 	// up to MaxInFlight requests run at once, and an undersized idle pool would
 	// measure connection churn instead of the server (go.md rule 31).
+	// max_conns overrides it on purpose to model a pool-limited client.
 	parallelism := server.Concurrency
 	if server.Load.Mode == config.LoadModeOpen {
 		parallelism = server.Load.MaxInFlight
 	}
-	transport := NewHTTPTransport(parallelism)
+	transport := NewHTTPTransport(parallelism, server.MaxConns, server.ConnectTimeout)
+
+	baseURL = strings.TrimRight(baseURL, "/")
+	baseURLs := []string{baseURL}
+	if len(server.BaseUrls) > 1 {
+		baseURLs = server.BaseUrls
+	}
+
+	var inflight chan struct{}
+	if server.MaxTotalConns > 0 {
+		inflight = make(chan struct{}, server.MaxTotalConns)
+	}
+
+	httpClient := &http.Client{Transport: transport}
+	if !server.FollowRedirects {
+		// The 3xx is the response: validated against expect, never followed.
+		httpClient.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	}
 
 	return &Suite{
 		ctx:        ctx,
-		httpClient: &http.Client{Transport: transport},
+		httpClient: httpClient,
 		transport:  transport,
 		server:     server,
-		baseURL:    strings.TrimRight(baseURL, "/"),
+		baseURL:    baseURL,
+		baseURLs:   baseURLs,
 		progress:   progress,
+		inflight:   inflight,
+		conns:      connCounter{maxSamples: server.MaxSamples, estimator: server.Estimator},
 	}
 }
 
+// nextBaseURL picks the host for the next request or sequence cycle.
+func (s *Suite) nextBaseURL() string {
+	if len(s.baseURLs) == 1 {
+		return s.baseURLs[0]
+	}
+	return s.baseURLs[(s.nextHost.Add(1)-1)%uint64(len(s.baseURLs))]
+}
+
 type EndpointResult struct {
-	Name          string     `json:"name"`
-	Path          string     `json:"path"`
-	Method        string     `json:"method"`
-	Database      string     `json:"database,omitempty"`
-	SequenceId    string     `json:"sequence_id,omitempty"`
-	Stats         *Stats     `json:"stats"`
-	Open          *OpenStats `json:"open,omitempty"` // open mode only
-	Error         string     `json:"error,omitempty"`
-	FailureCount  int        `json:"failure_count,omitempty"`
-	CanceledCount int        `json:"canceled_count,omitempty"`
-	LastError     string     `json:"last_error,omitempty"`
+	Name          string        `json:"name"`
+	Path          string        `json:"path"`
+	Method        string        `json:"method"`
+	Database      string        `json:"database,omitempty"`
+	SequenceId    string        `json:"sequence_id,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+	Warmup        *WarmupResult `json:"warmup,omitempty"` // warmup_until_stable only
+	Stats         *Stats        `json:"stats"`
+	Open          *OpenStats    `json:"open,omitempty"` // open mode only
+	Error         string        `json:"error,omitempty"`
+	FailureCount  int           `json:"failure_count,omitempty"`
+	CanceledCount int           `json:"canceled_count,omitempty"`
+	LastError     string        `json:"last_error,omitempty"`
+	StatusCounts  map[int]int   `json:"status_counts,omitempty"`  // every measured response by status code
+	DurationMs    int64         `json:"duration_ms,omitempty"`    // measured window actually run (the budget's length with requests_per_endpoint)
+	ResponseBytes int64         `json:"response_bytes,omitempty"` // body bytes read from every measured response
+	Phases        *PhaseStats   `json:"phases,omitempty"`         // --trace-phases only
+	BestWindow    *WindowStats  `json:"best_window,omitempty"`    // --best-window only
+	SSE           *SSEStats     `json:"sse,omitempty"`            // type "sse" endpoints only
+	WarmupCount   *WarmupCount  `json:"warmup_count,omitempty"`   // requests the warmup completed (nil without a warmup)
+	Expected      *Expected     `json:"expected,omitempty"`       // expected_avg/expected_p99 annotation
+	FailedFast    bool          `json:"failed_fast,omitzero"`     // stopped by fail_fast: every request so far had failed
+	// AnomalousCount is successful requests whose measured latency no real
+	// request could have (see anomalousLatency), left out of Stats.
+	AnomalousCount int `json:"anomalous_count,omitempty"`
+	// CachedCount is measured responses that look served from a cache (see
+	// LooksCached): their latency may be the cache's, not the server's.
+	CachedCount int `json:"cached_count,omitempty"`
+}
+
+// Expected is an endpoint's documented latency (expected_avg/expected_p99),
+// carried beside the measured stats for comparison only. A zero field was
+// not annotated.
+type Expected struct {
+	Avg time.Duration `json:"avg,omitempty"`
+	P99 time.Duration `json:"p99,omitempty"`
+}
+
+// expectedFor returns tc's latency annotation, or nil when it has none.
+func expectedFor(tc *config.Testcase) *Expected {
+	if tc.ExpectedAvg == 0 && tc.ExpectedP99 == 0 {
+		return nil
+	}
+	return &Expected{Avg: tc.ExpectedAvg, P99: tc.ExpectedP99}
 }
 
 // runOutcome is one endpoint run's raw result, shared by both load models.
@@ -72,65 +151,78 @@ type runOutcome struct {
 	stats          *Stats
 	open           *OpenStats // nil in closed mode
 	timedLatencies []TimedLatency
+	elapsed        time.Duration // measured window
 	failureCount   int
 	canceledCount  int
 	lastError      string
+	failedFast     bool // fail_fast stopped the window before any success
+	anomalousCount int  // successes dropped by anomalousLatency
 }
 
+// anomalousLatency reports a sample no real request could have produced:
+// negative, or beyond twice request_timeout, which bounds every request body
+// read included. A clock step or a stalled client leaves such samples; they
+// are counted instead of recorded so they can't corrupt the percentiles, and
+// the margin keeps legitimate tail latencies (all under the timeout) in.
+func (s *Suite) anomalousLatency(latency time.Duration) bool {
+	return latency < 0 || (s.server.RequestTimeout > 0 && latency > 2*s.server.RequestTimeout)
+}
+
+// Close drops the suite's idle connections. The transport itself is left
+// as it is: connections still being read, such as event streams, share it.
 func (s *Suite) Close() {
 	if s.transport != nil {
 		s.transport.CloseIdleConnections()
-		s.transport.DisableKeepAlives = true
 	}
 }
 
 func (s *Suite) RunAll() ([]EndpointResult, error) {
 	s.serverStartTime = time.Now()
 	s.timedResults = nil
+	s.warmupResults = nil
 
 	endpointTestcases := make(map[string][]*config.Testcase)
 	for _, tc := range s.server.Testcases {
 		endpointTestcases[tc.EndpointName] = append(endpointTestcases[tc.EndpointName], tc)
 	}
 
-	results := make([]EndpointResult, 0, len(endpointTestcases))
-	used := make(map[string]struct{}, len(endpointTestcases))
-	endpointsDone := 0
+	names := s.orderedEndpoints(endpointTestcases)
+	if s.server.MixedMode || s.server.Replay {
+		return s.runMixed(names, endpointTestcases), nil
+	}
 
-	for _, endpointName := range s.server.EndpointOrder {
+	results := make([]EndpointResult, 0, len(names))
+	endpointsDone := 0
+	for _, endpointName := range names {
 		if s.ctx.Err() != nil {
 			break
 		}
-		testcases, ok := endpointTestcases[endpointName]
-		if !ok || len(testcases) == 0 {
-			continue
+		endpointsDone = s.runEndpointWithWarmup(endpointTestcases[endpointName], endpointsDone, &results)
+	}
+
+	return results, nil //nolint:nilerr // context cancellation returns partial results, not an error
+}
+
+// orderedEndpoints returns the endpoints to run: config order first, then any
+// endpoints missing from the order, sorted by name.
+func (s *Suite) orderedEndpoints(endpointTestcases map[string][]*config.Testcase) []string {
+	names := make([]string, 0, len(endpointTestcases))
+	used := make(map[string]struct{}, len(endpointTestcases))
+	for _, endpointName := range s.server.EndpointOrder {
+		if testcases, ok := endpointTestcases[endpointName]; ok && len(testcases) > 0 {
+			names = append(names, endpointName)
+			used[endpointName] = struct{}{}
 		}
-		endpointsDone = s.runEndpointWithWarmup(testcases, endpointsDone, &results)
-		used[endpointName] = struct{}{}
 	}
 
-	if len(used) != len(endpointTestcases) && s.ctx.Err() == nil {
-		leftovers := make([]string, 0, len(endpointTestcases)-len(used))
-		for endpointName := range endpointTestcases {
-			if _, ok := used[endpointName]; ok {
-				continue
-			}
+	var leftovers []string
+	for endpointName, testcases := range endpointTestcases {
+		if _, ok := used[endpointName]; !ok && len(testcases) > 0 {
 			leftovers = append(leftovers, endpointName)
 		}
-		slices.Sort(leftovers)
-		for _, endpointName := range leftovers {
-			if s.ctx.Err() != nil {
-				break
-			}
-			testcases := endpointTestcases[endpointName]
-			if len(testcases) == 0 {
-				continue
-			}
-			endpointsDone = s.runEndpointWithWarmup(testcases, endpointsDone, &results)
-		}
 	}
-
-	return results, nil //nolint:nilerr // context cancellation returns partial results, not an error
+	slices.Sort(leftovers)
+	return append(names, leftovers...)
 }
 
 func (s *Suite) runEndpointWithWarmup(testcases []*config.Testcase, done int, results *[]EndpointResult) int {
@@ -143,8 +235,10 @@ func (s *Suite) runEndpointWithWarmup(testcases []*config.Testcase, done int, re
 		s.progress.OnEndpoint(first.Method, first.Path, done)
 	}
 
-	if s.server.WarmupDuration > 0 {
-		s.runWarmup(testcases)
+	var warmup *WarmupResult
+	var warmupCount *WarmupCount
+	if s.warmupEnabled() {
+		warmup, warmupCount = s.runWarmup(testcases)
 		if s.ctx.Err() != nil {
 			return done
 		}
@@ -153,10 +247,34 @@ func (s *Suite) runEndpointWithWarmup(testcases []*config.Testcase, done int, re
 		}
 	}
 
-	*results = append(*results, s.runEndpoint(first.EndpointName, first.Path, first.Method, testcases))
+	result := s.runEndpoint(first.EndpointName, first.Path, first.Method, testcases)
+	result.Warmup = warmup
+	result.WarmupCount = warmupCount
+	*results = append(*results, result)
+	s.endpointDone(&result)
 	return done + 1
 }
 
+// measureStart and measureEnd bracket a measured window for OnMeasureStart /
+// OnMeasureEnd, which the orchestrator forwards to its resource samplers.
+func (s *Suite) measureStart() {
+	if s.progress != nil && s.progress.OnMeasureStart != nil {
+		s.progress.OnMeasureStart()
+	}
+}
+
+func (s *Suite) measureEnd() {
+	if s.progress != nil && s.progress.OnMeasureEnd != nil {
+		s.progress.OnMeasureEnd()
+	}
+}
+
+func (s *Suite) endpointDone(result *EndpointResult) {
+	if s.progress != nil && s.progress.OnEndpointDone != nil {
+		s.progress.OnEndpointDone(result)
+	}
+}
+
 func (s *Suite) runEndpoint(name, path, method string, testcases []*config.Testcase) EndpointResult {
 	if len(testcases) == 0 {
 		return EndpointResult{
@@ -167,7 +285,16 @@ func (s *Suite) runEndpoint(name, path, method string, testcases []*config.Testc
 		}
 	}
 
+	s.statuses.reset()
+	s.bytes.reset()
+	s.cached.reset()
+	s.conns.reset()
+	s.phases.reset()
+	s.sse.reset()
+	s.measureStart()
 	outcome := s.runTestcases(testcases)
+	s.measureEnd()
+	s.conns.apply(name, outcome.stats)
 
 	s.timedResults = append(s.timedResults, TimedResult{
 		Endpoint:  name,
@@ -179,12 +306,32 @@ func (s *Suite) runEndpoint(name, path, method string, testcases []*config.Testc
 		Name:          name,
 		Path:          path,
 		Method:        method,
+		Tags:          testcases[0].Tags,
 		Stats:         outcome.stats,
 		Open:          outcome.open,
 		FailureCount:  outcome.failureCount,
 		CanceledCount: outcome.canceledCount,
 		LastError:     outcome.lastError,
+		StatusCounts:  s.statuses.take(name),
+		DurationMs:    outcome.elapsed.Milliseconds(),
+		ResponseBytes: s.bytes.take(name),
+		Phases:        s.phases.take(name),
+		BestWindow:    s.bestWindow(outcome.timedLatencies),
+		SSE:           s.sse.take(name),
+		Expected:      expectedFor(testcases[0]),
+		FailedFast:    outcome.failedFast,
+
+		AnomalousCount: outcome.anomalousCount,
+		CachedCount:    s.cached.take(name),
+	}
+}
+
+// bestWindow is the endpoint's quietest window under --best-window.
+func (s *Suite) bestWindow(latencies []TimedLatency) *WindowStats {
+	if !s.server.BestWindow {
+		return nil
 	}
+	return BestWindow(latencies, BestWindowWidth)
 }
 
 func (s *Suite) runTestcases(testcases []*config.Testcase) *runOutcome {
@@ -195,7 +342,17 @@ func (s *Suite) runTestcases(testcases []*config.Testcase) *runOutcome {
 	workers := s.server.Concurrency
 	endpointStartTime := time.Now()
 
-	ctx, cancel := context.WithTimeout(s.ctx, s.server.DurationPerEndpoint)
+	// With a request budget the window has no deadline: the collector cancels
+	// it once the budget is met, or once as many requests have failed so an
+	// endpoint that never succeeds can't stall the run.
+	budget := s.server.RequestsPerEndpoint
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if budget > 0 {
+		ctx, cancel = context.WithCancel(s.ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(s.ctx, s.server.EndpointWindow(testcases[0]))
+	}
 	defer cancel()
 
 	workCh := make(chan *config.Testcase)
@@ -246,11 +403,15 @@ func (s *Suite) runTestcases(testcases []*config.Testcase) *runOutcome {
 	}()
 
 	outcome := &runOutcome{}
-	var count int
-	latencies := make([]time.Duration, 0, 10000)
-	outcome.timedLatencies = make([]TimedLatency, 0, 10000)
+	reservoir := newLatencyReservoir(s.server.MaxSamples, s.server.Estimator)
+	var stoppedAt time.Time // budget mode: when the budget was met or abandoned
 
 	for r := range resultsCh {
+		if !stoppedAt.IsZero() {
+			// In flight when the budget closed; not part of the N requests.
+			outcome.canceledCount++
+			continue
+		}
 		if r.err != nil {
 			if isBenchmarkContextCancellation(ctx, r.err) {
 				outcome.canceledCount++
@@ -258,29 +419,70 @@ func (s *Suite) runTestcases(testcases []*config.Testcase) *runOutcome {
 			}
 			outcome.failureCount++
 			outcome.lastError = r.err.Error()
+			if budget > 0 && outcome.failureCount >= budget {
+				stoppedAt = time.Now()
+				cancel()
+			}
+			// fail_fast: the first N all failed, so the endpoint is most
+			// likely misconfigured. A single success disarms it for good.
+			if failFast := s.server.FailFast; failFast > 0 && reservoir.seen == 0 && outcome.failureCount >= failFast && stoppedAt.IsZero() {
+				stoppedAt = time.Now()
+				outcome.failedFast = true
+				cancel()
+			}
+			continue
+		}
+		if s.anomalousLatency(r.latency) {
+			outcome.anomalousCount++
 			continue
 		}
 
-		count++
-		latencies = append(latencies, r.latency)
-		outcome.timedLatencies = append(outcome.timedLatencies, TimedLatency{
+		reservoir.add(TimedLatency{
 			ServerOffset:   r.serverOffset,
 			EndpointOffset: r.endpointOffset,
 			Duration:       r.latency,
 		})
+		if budget > 0 && reservoir.seen >= budget {
+			stoppedAt = time.Now()
+			cancel()
+		}
 	}
 
-	elapsed := time.Since(endpointStartTime)
-	totalRequests := count + outcome.failureCount
-	outcome.stats = CalculateStats(latencies, count, totalRequests, elapsed)
+	if stoppedAt.IsZero() {
+		stoppedAt = time.Now()
+	}
+	elapsed := stoppedAt.Sub(endpointStartTime)
+	totalRequests := reservoir.seen + outcome.failureCount
+	outcome.elapsed = elapsed
+	reservoir.discardFirst(s.server.DiscardFirst)
+	outcome.stats = reservoir.stats(totalRequests, elapsed)
+	outcome.timedLatencies = reservoir.timed()
 	return outcome
 }
 
 func (s *Suite) executeTestcase(ctx context.Context, tc *config.Testcase) (time.Duration, error) {
-	ctx, cancel := context.WithTimeout(ctx, s.server.RequestTimeout)
+	// max_total_conns: wait for a slot before the request timeout and the
+	// latency clock start, so queueing behind the cap is not the server's time.
+	if s.inflight != nil {
+		select {
+		case s.inflight <- struct{}{}:
+			defer func() { <-s.inflight }()
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	ctx, cancel := withRequestTimeout(ctx, s.server.RequestTimeout)
 	defer cancel()
 
-	req, err := BuildRequest(ctx, s.baseURL, tc)
+	reqCtx := ctx
+	if s.server.ConnStats {
+		reqCtx = s.conns.trace(ctx, tc.EndpointName)
+	}
+	if s.server.TracePhases {
+		reqCtx = s.phases.trace(reqCtx, tc.EndpointName)
+	}
+	req, err := BuildRequest(reqCtx, s.nextBaseURL(), tc)
 	if err != nil {
 		return 0, err
 	}
@@ -288,13 +490,22 @@ func (s *Suite) executeTestcase(ctx context.Context, tc *config.Testcase) (time.
 	start := time.Now()
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		err = classifyConnectTimeout(ctx, fmt.Errorf("request failed: %w", err), s.server.ConnectTimeout)
+		return 0, classifyRequestTimeout(ctx, err, s.server.RequestTimeout)
+	}
+	s.statuses.record(tc.EndpointName, resp.StatusCode)
+	if LooksCached(tc, resp, s.server.CacheHeader) {
+		s.cached.record(tc.EndpointName)
+	}
+	if tc.SSE != nil {
+		return s.readEventStream(ctx, tc, resp, start)
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	body, truncated, err := readBody(resp.Body, s.server.MaxBodyBytes)
 	closeErr := resp.Body.Close()
+	s.bytes.record(tc.EndpointName, len(body))
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response: %w", err)
+		return 0, classifyRequestTimeout(ctx, fmt.Errorf("failed to read response: %w", err), s.server.RequestTimeout)
 	}
 	if closeErr != nil {
 		return 0, closeErr
@@ -302,6 +513,10 @@ func (s *Suite) executeTestcase(ctx context.Context, tc *config.Testcase) (time.
 
 	latency := time.Since(start)
 
+	if truncated && (tc.ExpectedBody != nil || tc.ExpectedText != "" || tc.ExpectValidJSON) {
+		return latency, bodyTooLargeError(s.server.MaxBodyBytes)
+	}
+
 	if err := ValidateResponse(tc, resp, body); err != nil {
 		return latency, err
 	}
@@ -333,12 +548,14 @@ type SequenceStepStats struct {
 	Count    int           `json:"count"`
 	Attempts int           `json:"attempts"`
 	Failures int           `json:"failures"`
+	Skipped  int           `json:"skipped,omitempty"` // cycles whose when condition skipped the step
 	Avg      time.Duration `json:"avg"`
 	Low      time.Duration `json:"low"`
 	High     time.Duration `json:"high"`
 	P50      time.Duration `json:"p50"`
 	P95      time.Duration `json:"p95"`
 	P99      time.Duration `json:"p99"`
+	StdDev   time.Duration `json:"stddev,omitempty"`
 }
 
 func (s *Suite) RunSequences() []SequenceStats {
@@ -351,7 +568,6 @@ func (s *Suite) RunSequences() []SequenceStats {
 	}
 
 	s.timedSequences = nil
-	baseUrl := s.baseURL
 	results := make([]SequenceStats, 0, len(s.server.Sequences))
 
 	for i, seq := range s.server.Sequences {
@@ -362,7 +578,9 @@ func (s *Suite) RunSequences() []SequenceStats {
 			}
 			s.progress.OnSequence(seqName, i)
 		}
-		stats := s.runSequence(baseUrl, seq)
+		s.measureStart()
+		stats := s.runSequence(seq)
+		s.measureEnd()
 		results = append(results, stats)
 	}
 
@@ -375,7 +593,9 @@ type timedSequenceResultItem struct {
 	sequenceOffset time.Duration
 }
 
-func (s *Suite) runSequence(baseURL string, seq *config.ResolvedSequence) SequenceStats {
+// runSequence keeps each cycle on one host, since later steps reuse what
+// earlier steps created.
+func (s *Suite) runSequence(seq *config.ResolvedSequence) SequenceStats {
 	workers := s.server.Concurrency
 	stepCount := len(seq.Endpoints)
 	sequenceStartTime := time.Now()
@@ -413,7 +633,7 @@ func (s *Suite) runSequence(baseURL string, seq *config.ResolvedSequence) Sequen
 				requestStart := time.Now()
 				serverOffset := requestStart.Sub(s.serverStartTime)
 				sequenceOffset := requestStart.Sub(sequenceStartTime)
-				result := RunSequence(ctx, s.httpClient, baseURL, seq, item.workerId, item.cycleNum, s.server.RequestTimeout)
+				result := RunSequence(ctx, s.httpClient, s.nextBaseURL(), seq, item.workerId, item.cycleNum, s.server.RequestTimeout, s.server.MaxBodyBytes)
 				resultsCh <- timedSequenceResultItem{
 					result:         result,
 					serverOffset:   serverOffset,
@@ -440,6 +660,7 @@ func (s *Suite) runSequence(baseURL string, seq *config.ResolvedSequence) Sequen
 	}
 	stepAttempts := make([]int, stepCount)
 	stepFailures := make([]int, stepCount)
+	stepSkips := make([]int, stepCount)
 
 	timedLatencies := make([]TimedLatency, 0, 10000)
 	stepTimedLatencies := make(map[string][]TimedLatency)
@@ -450,6 +671,10 @@ func (s *Suite) runSequence(baseURL string, seq *config.ResolvedSequence) Sequen
 	recordSteps := func(item timedSequenceResultItem, count int) {
 		var stepOffset time.Duration
 		for i := range count {
+			if item.result.Skipped != nil && item.result.Skipped[i] {
+				stepSkips[i]++
+				continue
+			}
 			d := item.result.StepDurations[i]
 			stepAttempts[i]++
 			stepDurations[i] = append(stepDurations[i], d)
@@ -540,9 +765,11 @@ func (s *Suite) runSequence(baseURL string, seq *config.ResolvedSequence) Sequen
 			steps[i].P50 = Percentile(stepDurations[i], 50)
 			steps[i].P95 = Percentile(stepDurations[i], 95)
 			steps[i].P99 = Percentile(stepDurations[i], 99)
+			steps[i].StdDev = StdDev(stepDurations[i], steps[i].Avg)
 		}
 		steps[i].Attempts = stepAttempts[i]
 		steps[i].Failures = stepFailures[i]
+		steps[i].Skipped = stepSkips[i]
 	}
 
 	return SequenceStats{
@@ -563,12 +790,42 @@ func (s *Suite) runSequence(baseURL string, seq *config.ResolvedSequence) Sequen
 	}
 }
 
-func (s *Suite) runWarmup(testcases []*config.Testcase) {
+// warmupEnabled reports whether endpoints get a warmup before measurement.
+func (s *Suite) warmupEnabled() bool {
+	return s.server.WarmupDuration > 0 || s.server.WarmupStable != nil
+}
+
+// runWarmup warms testcases for the fixed warmup_duration, or adaptively when
+// warmup_until_stable is set (the only case that returns a WarmupResult). The
+// count covers either kind.
+func (s *Suite) runWarmup(testcases []*config.Testcase) (*WarmupResult, *WarmupCount) {
 	if len(testcases) == 0 {
-		return
+		return nil, nil
 	}
 
-	ctx, cancel := context.WithTimeout(s.ctx, s.server.WarmupDuration)
+	warmupStart := time.Now()
+	count := &WarmupCount{}
+	if s.server.WarmupStable != nil {
+		result := s.warmupUntilStable(func(window time.Duration) (time.Duration, int) {
+			latencies, failures := s.runWarmupWindow(testcases, window, warmupStart)
+			count.add(len(latencies), failures)
+			slices.Sort(latencies)
+			return Percentile(latencies, 50), len(latencies)
+		})
+		return result, count
+	}
+
+	latencies, failures := s.runWarmupWindow(testcases, s.server.WarmupDuration, warmupStart)
+	count.add(len(latencies), failures)
+	return nil, count
+}
+
+// runWarmupWindow drives testcases for window and returns the latencies of
+// the successful requests and the number that failed (requests the window's
+// end canceled are neither); results are otherwise discarded unless
+// server.ExportWarmup keeps them, offset from warmupStart, for the metrics DB.
+func (s *Suite) runWarmupWindow(testcases []*config.Testcase, window time.Duration, warmupStart time.Time) ([]time.Duration, int) {
+	ctx, cancel := context.WithTimeout(s.ctx, window)
 	defer cancel()
 
 	workers := min(s.server.Concurrency, len(testcases))
@@ -576,6 +833,10 @@ func (s *Suite) runWarmup(testcases []*config.Testcase) {
 		workers = 1
 	}
 
+	keep := s.server.ExportWarmup
+	perWorker := make([][]time.Duration, workers)
+	perWorkerTimed := make([][]TimedLatency, workers)
+	failures := make([]int, workers)
 	var wg sync.WaitGroup
 	wg.Add(workers)
 	for workerId := range workers {
@@ -583,7 +844,20 @@ func (s *Suite) runWarmup(testcases []*config.Testcase) {
 			defer wg.Done()
 			index := id % len(testcases)
 			for ctx.Err() == nil {
-				_, _ = s.executeTestcase(ctx, testcases[index]) // Discard result
+				requestStart := time.Now()
+				latency, err := s.executeTestcase(ctx, testcases[index])
+				if err == nil {
+					perWorker[id] = append(perWorker[id], latency)
+					if keep {
+						perWorkerTimed[id] = append(perWorkerTimed[id], TimedLatency{
+							ServerOffset:   requestStart.Sub(s.serverStartTime),
+							EndpointOffset: requestStart.Sub(warmupStart),
+							Duration:       latency,
+						})
+					}
+				} else if !isBenchmarkContextCancellation(ctx, err) {
+					failures[id]++
+				}
 				index++
 				if index >= len(testcases) {
 					index = 0
@@ -593,6 +867,24 @@ func (s *Suite) runWarmup(testcases []*config.Testcase) {
 	}
 
 	wg.Wait()
+	if keep {
+		s.keepWarmup(testcases[0].EndpointName, testcases[0].Method, slices.Concat(perWorkerTimed...))
+	}
+	total := 0
+	for _, n := range failures {
+		total += n
+	}
+	return slices.Concat(perWorker...), total
+}
+
+// keepWarmup appends warmup latencies for endpoint, merging consecutive
+// adaptive-warmup windows into one entry.
+func (s *Suite) keepWarmup(endpoint, method string, latencies []TimedLatency) {
+	if n := len(s.warmupResults); n > 0 && s.warmupResults[n-1].Endpoint == endpoint {
+		s.warmupResults[n-1].Latencies = append(s.warmupResults[n-1].Latencies, latencies...)
+		return
+	}
+	s.warmupResults = append(s.warmupResults, TimedResult{Endpoint: endpoint, Method: method, Latencies: latencies})
 }
 
 func SequenceStepsToResults(sequences []SequenceStats) []EndpointResult {
@@ -628,7 +920,9 @@ func SequenceStepsToResults(sequences []SequenceStats) []EndpointResult {
 					P50:         step.P50,
 					P95:         step.P95,
 					P99:         step.P99,
+					StdDev:      step.StdDev,
 					SuccessRate: successRate,
+					TailRatio:   TailRatio(step.P50, step.P99),
 				},
 				FailureCount: step.Failures,
 				LastError:    lastError,
@@ -646,6 +940,18 @@ func (s *Suite) GetTimedSequences() []TimedSequenceResult {
 	return s.timedSequences
 }
 
+// GetWarmupResults returns the warmup requests kept with server.ExportWarmup;
+// nil otherwise. They never feed the measured stats.
+func (s *Suite) GetWarmupResults() []TimedResult {
+	return s.warmupResults
+}
+
+// MixedStats is the blended distribution over every endpoint's requests in
+// mixed mode; nil in the default sequential mode.
+func (s *Suite) MixedStats() *Stats {
+	return s.mixedStats
+}
+
 // StartTime is the wall-clock base every TimedLatency.ServerOffset is measured
 // from; base + offset reconstructs the request's real timestamp (PLAN §9.1).
 func (s *Suite) StartTime() time.Time {
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Log-normal latencies around 2ms (σ=0.6, so p99.9 lands near 13ms), the
+// usual shape of a server under steady load. Over 200k samples the digest's
+// p99 must land within 0.5% of the exact interpolated percentile and p99.9,
+// which rests on only 200 samples, within 2%.
+func TestTDigestMatchesExactTail(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	const n = 200_000
+	latencies := make([]time.Duration, n)
+	d := newTDigest()
+	for i := range latencies {
+		v := time.Duration(float64(2*time.Millisecond) * math.Exp(0.6*rng.NormFloat64()))
+		latencies[i] = v
+		d.add(v)
+	}
+	slices.Sort(latencies)
+
+	tests := []struct {
+		p       float64
+		maxDiff float64
+	}{
+		{50, 0.005},
+		{95, 0.005},
+		{99, 0.005},
+		{99.9, 0.02},
+	}
+	for _, tt := range tests {
+		exact := Percentile(latencies, tt.p)
+		got := d.percentile(tt.p)
+		if diff := rel(got, exact); diff > tt.maxDiff {
+			t.Errorf("p%v: got %v, want %v (±%.1f%%), off by %.2f%%", tt.p, got, exact, tt.maxDiff*100, diff*100)
+		}
+	}
+	if got := d.percentile(0); got != latencies[0] {
+		t.Errorf("p0: got %v, want exact min %v", got, latencies[0])
+	}
+	if got := d.percentile(100); got != latencies[n-1] {
+		t.Errorf("p100: got %v, want exact max %v", got, latencies[n-1])
+	}
+	if len(d.centroids) > 2*digestCompression {
+		t.Errorf("centroids: got %d, want <= %d", len(d.centroids), 2*digestCompression)
+	}
+}
+
+func TestLatencyReservoirDigestPercentiles(t *testing.T) {
+	t.Parallel()
+
+	const limit, total = 1000, 50_000
+	r := newLatencyReservoir(limit, "tdigest")
+	for i := range total {
+		r.add(TimedLatency{Duration: time.Duration(i+1) * time.Microsecond})
+	}
+
+	stats := r.stats(total, time.Second)
+	if stats.Estimator != "tdigest" || stats.Sampled != 0 {
+		t.Errorf("estimator/sampled: got %q/%d, want tdigest/0", stats.Estimator, stats.Sampled)
+	}
+	if want := 49_500 * time.Microsecond; rel(stats.P99, want) > 0.005 {
+		t.Errorf("p99: got %v, want ~%v", stats.P99, want)
+	}
+	if len(r.timed()) != limit {
+		t.Errorf("timed latencies: got %d, want reservoir of %d", len(r.timed()), limit)
+	}
+}
+
+func rel(got, want time.Duration) float64 {
+	diff := float64(got - want)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff / float64(want)
+}
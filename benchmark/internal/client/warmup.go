@@ -0,0 +1,92 @@
+package client
+
+import (
+	"slices"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+// WarmupResult reports how an adaptive (warmup_until_stable) warmup ended.
+// Fixed-duration warmups leave it nil.
+type WarmupResult struct {
+	Duration time.Duration `json:"duration"`
+	Windows  int           `json:"windows"` // windows run, including the stable ones
+	Stable   bool          `json:"stable"`  // false: max_duration or cancellation ended it
+}
+
+// LowWarmupRequests is the fewest successful warmup requests an endpoint may
+// complete without a warning: below it the server barely answered during
+// warmup (slow or erroring), so the measurement may start from a cold server.
+const LowWarmupRequests = 10
+
+// WarmupCount is how many requests an endpoint's warmup completed, fixed or
+// adaptive. Mixed mode counts each endpoint's share of the shared warmup.
+type WarmupCount struct {
+	Requests int  `json:"requests"` // successful warmup requests
+	Failures int  `json:"failures,omitempty"`
+	Low      bool `json:"low,omitzero"` // Requests below LowWarmupRequests
+}
+
+func (c *WarmupCount) add(successes, failures int) {
+	c.Requests += successes
+	c.Failures += failures
+	c.Low = c.Requests < LowWarmupRequests
+}
+
+// stabilityTracker keeps the P50s of the most recent windows and reports
+// stability once the required number agree within the threshold, measured
+// as (max - min) / min so a single slow window resets the streak.
+type stabilityTracker struct {
+	threshold float64
+	required  int
+	p50s      []time.Duration
+}
+
+func newStabilityTracker(cfg *config.WarmupStableConfig) *stabilityTracker {
+	return &stabilityTracker{threshold: cfg.Threshold, required: cfg.Windows}
+}
+
+// observe records one window. A window without successful requests carries
+// no latency signal, so it breaks the streak rather than counting toward it.
+func (t *stabilityTracker) observe(p50 time.Duration, samples int) bool {
+	if samples == 0 || p50 <= 0 {
+		t.p50s = t.p50s[:0]
+		return false
+	}
+	t.p50s = append(t.p50s, p50)
+	if len(t.p50s) > t.required {
+		t.p50s = t.p50s[1:]
+	}
+	if len(t.p50s) < t.required {
+		return false
+	}
+	lo, hi := slices.Min(t.p50s), slices.Max(t.p50s)
+	return float64(hi-lo)/float64(lo) <= t.threshold
+}
+
+// warmupUntilStable repeats runWindow until the tracker reports stability,
+// max_duration is spent, or the suite is canceled. The last window is
+// shortened so the cap is never overrun.
+func (s *Suite) warmupUntilStable(runWindow func(window time.Duration) (p50 time.Duration, samples int)) *WarmupResult {
+	cfg := s.server.WarmupStable
+	tracker := newStabilityTracker(cfg)
+	result := &WarmupResult{}
+	start := time.Now()
+
+	for s.ctx.Err() == nil {
+		remaining := cfg.MaxDuration - time.Since(start)
+		if remaining <= 0 {
+			break
+		}
+		p50, samples := runWindow(min(cfg.Window, remaining))
+		result.Windows++
+		if s.ctx.Err() == nil && tracker.observe(p50, samples) {
+			result.Stable = true
+			break
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
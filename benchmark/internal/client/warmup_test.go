@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/config"
+)
+
+func TestStabilityTracker(t *testing.T) {
+	t.Parallel()
+
+	ms := time.Millisecond
+	cases := []struct {
+		name       string
+		p50s       []time.Duration
+		samples    []int // nil means every window has samples
+		wantStable bool
+	}{
+		{name: "settles within threshold", p50s: []time.Duration{10 * ms, 5 * ms, 5 * ms, 5 * ms}, wantStable: true},
+		{name: "too few windows", p50s: []time.Duration{5 * ms, 5 * ms}},
+		{name: "still drifting", p50s: []time.Duration{8 * ms, 7 * ms, 6 * ms, 5 * ms}},
+		{
+			name:    "empty window breaks the streak",
+			p50s:    []time.Duration{5 * ms, 5 * ms, 0, 5 * ms},
+			samples: []int{10, 10, 0, 10},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tracker := newStabilityTracker(&config.WarmupStableConfig{Threshold: 0.05, Windows: 3})
+			var stable bool
+			for i, p50 := range tc.p50s {
+				samples := 10
+				if tc.samples != nil {
+					samples = tc.samples[i]
+				}
+				stable = tracker.observe(p50, samples)
+			}
+			if stable != tc.wantStable {
+				t.Errorf("stable: got %v, want %v", stable, tc.wantStable)
+			}
+		})
+	}
+}
+
+func TestWarmupUntilStableRespectsMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	suite, testcases := newTestSuite(t, okHandler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	// A zero-width threshold can only pass if every window P50 is identical;
+	// the cap must end the warmup either way.
+	suite.server.WarmupStable = &config.WarmupStableConfig{
+		Window:      20 * time.Millisecond,
+		Threshold:   0,
+		Windows:     50,
+		MaxDuration: 100 * time.Millisecond,
+	}
+
+	warmup, _ := suite.runWarmup(testcases)
+	if warmup == nil {
+		t.Fatal("adaptive warmup must report a result")
+	}
+	if warmup.Stable {
+		t.Error("50 windows cannot fit in a 100ms cap, warmup must not report stable")
+	}
+	if warmup.Windows < 1 || warmup.Duration > 500*time.Millisecond {
+		t.Errorf("warmup ran %d windows over %s, want the 100ms cap respected", warmup.Windows, warmup.Duration)
+	}
+
+	suite.server.WarmupStable = nil
+	suite.server.WarmupDuration = 10 * time.Millisecond
+	if got, _ := suite.runWarmup(testcases); got != nil {
+		t.Errorf("fixed warmup must not report a result, got %+v", got)
+	}
+}
+
+func TestWarmupUntilStableStopsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	slow := func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}
+	suite, testcases := newTestSuite(t, slow, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	suite.server.WarmupStable = &config.WarmupStableConfig{
+		Window:      20 * time.Millisecond,
+		Threshold:   0,
+		Windows:     50,
+		MaxDuration: time.Minute,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	suite.ctx = ctx
+	time.AfterFunc(60*time.Millisecond, cancel)
+
+	warmup, _ := suite.runWarmup(testcases)
+	if warmup.Stable || warmup.Duration > 5*time.Second {
+		t.Errorf("canceled warmup: got %+v, want a prompt unstable stop", warmup)
+	}
+}
+
+func TestWarmupCount(t *testing.T) {
+	t.Parallel()
+
+	suite, testcases := newTestSuite(t, okHandler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	suite.server.WarmupDuration = 50 * time.Millisecond
+	_, count := suite.runWarmup(testcases)
+	if count == nil || count.Requests < LowWarmupRequests || count.Failures != 0 || count.Low {
+		t.Errorf("healthy warmup: got %+v, want plenty of requests and no warning", count)
+	}
+
+	failing := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	suite, testcases = newTestSuite(t, failing, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	suite.server.WarmupDuration = 50 * time.Millisecond
+	_, count = suite.runWarmup(testcases)
+	if count == nil || count.Requests != 0 || count.Failures == 0 || !count.Low {
+		t.Errorf("erroring warmup: got %+v, want only failures and Low set", count)
+	}
+}
+
+func TestWarmupExportKeepsLatencies(t *testing.T) {
+	t.Parallel()
+
+	suite, testcases := newTestSuite(t, okHandler, config.LoadConfig{Mode: config.LoadModeClosed}, time.Second)
+	suite.server.WarmupDuration = 30 * time.Millisecond
+
+	suite.runWarmup(testcases)
+	if got := suite.GetWarmupResults(); got != nil {
+		t.Fatalf("warmup without ExportWarmup: got %d results, want none kept", len(got))
+	}
+
+	suite.server.ExportWarmup = true
+	suite.runWarmup(testcases)
+	got := suite.GetWarmupResults()
+	if len(got) != 1 {
+		t.Fatalf("warmup results: got %d entries, want 1", len(got))
+	}
+	if got[0].Endpoint != "root" || got[0].Method != "GET" || len(got[0].Latencies) == 0 {
+		t.Errorf("warmup result: got %s %s with %d latencies, want root GET with some", got[0].Method, got[0].Endpoint, len(got[0].Latencies))
+	}
+	for _, l := range got[0].Latencies {
+		if l.EndpointOffset < 0 || l.EndpointOffset > time.Second {
+			t.Fatalf("endpoint offset %s outside the warmup window", l.EndpointOffset)
+		}
+	}
+}
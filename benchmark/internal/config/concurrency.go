@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ConcurrencySpec is benchmark.concurrency as configured: a worker count (50)
+// or a multiple of the machine's CPUs ("4x"), so one config gives comparable
+// load on machines of different sizes. Load resolves it into
+// BenchmarkConfig.Concurrency.
+type ConcurrencySpec struct {
+	Count  int     // fixed worker count; 0 with PerCPU unset = default
+	PerCPU float64 // workers per CPU for an "Nx" value, 0 otherwise
+}
+
+// String renders the spec as configured ("50", "4x").
+func (c ConcurrencySpec) String() string {
+	if c.PerCPU != 0 {
+		return strconv.FormatFloat(c.PerCPU, 'f', -1, 64) + "x"
+	}
+	return strconv.Itoa(c.Count)
+}
+
+// resolve turns the spec into a worker count on a machine with cpus CPUs,
+// rounding a fractional multiple to the nearest worker but never below one.
+func (c ConcurrencySpec) resolve(cpus int) int {
+	if c.PerCPU == 0 {
+		if c.Count <= 0 {
+			return DefaultConfig.Benchmark.Concurrency
+		}
+		return c.Count
+	}
+	return max(1, int(math.Round(c.PerCPU*float64(cpus))))
+}
+
+func (c *ConcurrencySpec) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	switch dec.PeekKind() {
+	case 'n':
+		_, err := dec.ReadToken()
+		*c = ConcurrencySpec{}
+		return err
+	case '0':
+		var count int
+		if err := json.UnmarshalDecode(dec, &count); err != nil {
+			return err
+		}
+		*c = ConcurrencySpec{Count: count}
+		return nil
+	case '"':
+		var raw string
+		if err := json.UnmarshalDecode(dec, &raw); err != nil {
+			return err
+		}
+		perCPU, err := parseCPUMultiple(raw)
+		if err != nil {
+			return err
+		}
+		*c = ConcurrencySpec{PerCPU: perCPU}
+		return nil
+	default:
+		return errors.New(`benchmark concurrency must be a worker count or a CPU multiple like "4x"`)
+	}
+}
+
+func (c ConcurrencySpec) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if c.PerCPU != 0 {
+		return enc.WriteToken(jsontext.String(c.String()))
+	}
+	return enc.WriteToken(jsontext.Int(int64(c.Count)))
+}
+
+// parseCPUMultiple parses an "Nx" concurrency into its positive multiplier.
+func parseCPUMultiple(raw string) (float64, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	num, ok := strings.CutSuffix(s, "x")
+	if !ok {
+		return 0, fmt.Errorf(`benchmark concurrency must look like "4x" when a string, got %q`, raw)
+	}
+	perCPU, err := strconv.ParseFloat(num, 64)
+	if err != nil || math.IsInf(perCPU, 0) || math.IsNaN(perCPU) {
+		return 0, fmt.Errorf(`benchmark concurrency must look like "4x" when a string, got %q`, raw)
+	}
+	if perCPU <= 0 {
+		return 0, fmt.Errorf("benchmark concurrency multiplier must be positive, got %q", raw)
+	}
+	return perCPU, nil
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"runtime"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"benchmark-client/internal/cli"
@@ -43,10 +46,37 @@ type Testcase struct {
 	CachedContentType   string
 	CachedFormBody      string
 	CachedMultipartBody string
-	ExpectedStatus      int
-	ExpectedHeaders     map[string]string
+	ExpectedStatus      StatusMatcher
+	ExpectedHeaders     map[string]HeaderMatcher
 	ExpectedBody        any
 	ExpectedText        string
+	ExpectValidJSON     bool          // expect.valid_json: body must parse, structure unchecked
+	ChunkedRequest      bool          // chunked_request: send the body with Transfer-Encoding: chunked
+	Weight              int           // mixed_mode selection weight (>= 1)
+	Tags                []string      // endpoint tags, carried into results and metrics
+	ExpectedAvg         time.Duration // expected_avg annotation (0 = none); informational only
+	ExpectedP99         time.Duration // expected_p99 annotation (0 = none); informational only
+
+	// ExpectedJSONPaths is expect.json_path, compiled and sorted by path.
+	ExpectedJSONPaths []JSONPathAssertion
+	// Dynamic is the per-request body template of a dynamic: true endpoint
+	// (nil = send Body / CachedFormBody as is).
+	Dynamic *DynamicBody
+	// ContentType is content_type: the Content-Type of a JSON body ("" =
+	// application/json).
+	ContentType string
+	// SuccessWhen is expect.success_when, checked after the other
+	// expectations (nil = none).
+	SuccessWhen *SuccessRule
+	// AbsentHeaders is expect.absent_headers, canonicalized and sorted.
+	AbsentHeaders []string
+	// SSE bounds the event stream read of a type "sse" endpoint (nil = a
+	// plain request).
+	SSE *SSEConfig
+
+	// queryConflicts are the query keys both the path and the query map set
+	// to different values; resolve turns them into one warning per endpoint.
+	queryConflicts []string
 }
 
 type ResolvedServer struct {
@@ -54,21 +84,63 @@ type ResolvedServer struct {
 	ImageName           string
 	Port                int
 	BaseUrl             string
+	BaseUrls            []string // --target with base_urls: hosts requests round-robin across (first is the target)
 	RequestTimeout      time.Duration
+	ConnectTimeout      time.Duration // connect_timeout: dial and response-header bound (0 = off)
 	CpuLimit            float64
 	MemoryLimit         string
 	Concurrency         int
 	Load                LoadConfig
 	DurationPerEndpoint time.Duration
+	RequestsPerEndpoint int // > 0: each endpoint stops after this many successes instead of DurationPerEndpoint
 	Testcases           []*Testcase
 	EndpointOrder       []string
 	WarmupDuration      time.Duration
 	WarmupPause         time.Duration
+	WarmupStable        *WarmupStableConfig // nil means fixed WarmupDuration
 	Sequences           []*ResolvedSequence
+	SeedSequences       []*ResolvedSequence // benchmark.seed_flow, one per database; never measured
+	MixedMode           bool
+	Replay              bool // Testcases are benchmark.replay_file's lines, replayed in order in one window
+	MaxBodyBytes        int64
+	MaxSamples          int               // closed-loop latency reservoir cap per endpoint (0 = unbounded)
+	Estimator           string            // EstimatorExact or EstimatorTDigest
+	FailFast            int               // closed loop: stop an endpoint once its first FailFast requests all failed (0 = off)
+	ServerRetries       int               // container start + readiness retries before the server is recorded as failed
+	MaxConns            int               // per-host connection cap independent of workers (0 = sized to parallelism)
+	MaxTotalConns       int               // cap on requests in flight across all workers and hosts (0 = unlimited)
+	FollowRedirects     bool              // false: a 3xx is the response, validated as-is, not followed
+	CacheHeader         string            // response header whose "hit" value flags a possibly cached response
+	DiscardFirst        float64           // fraction of each endpoint's measured successes, by request order, dropped before stats
+	TotalDuration       time.Duration     // > 0: total_duration; DurationPerEndpoint is then one weight unit's share
+	ResetPath           string            // database reset route template with {database}
+	ConnStats           bool              // --conn-stats: trace new vs reused connections per endpoint
+	ExportWarmup        bool              // --export-warmup: keep warmup latencies for the metrics writer
+	TracePhases         bool              // --trace-phases: break latency into DNS/connect/TLS/TTFB per endpoint
+	BestWindow          bool              // --best-window: report each endpoint's lowest-P50 one-second window
+	Tags                []string          // from the server's bench.json manifest
+	Env                 map[string]string // manifest env: extra container environment
+	Cmd                 []string          // manifest cmd: replaces the image CMD when set
+	ReadyExec           []string          // container.ready_exec: exec probe replacing GET /health (nil = HTTP)
+	ExternalUrl         string            // manifest external_url: already-running server, no container
 }
 
 type RuntimeOptions struct {
 	Servers []string // empty means all servers
+	Tags    []string // empty means all endpoints; otherwise endpoints with any of these tags
+
+	// Run-size overrides from --profile, --duration and --concurrency; zero keeps the config value.
+	Profile     string // profile name, for display only
+	Duration    time.Duration
+	Concurrency int
+	Warmup      time.Duration // replaces warmup_until_stable with a fixed warmup
+	WarmupPause time.Duration
+	Cooldown    time.Duration
+
+	ConnStats    bool // --conn-stats
+	ExportWarmup bool // --export-warmup
+	TracePhases  bool // --trace-phases
+	BestWindow   bool // --best-window
 }
 
 func GetServerNames(servers []*ResolvedServer) []string {
@@ -85,15 +157,43 @@ func (cfg *Config) Print(serverCount int) {
 	const disabledStr = "disabled"
 
 	cli.KeyValue("Base URL", cfg.Benchmark.BaseUrl)
+	if len(cfg.Benchmark.BaseUrls) > 0 {
+		cli.KeyValue("Base URLs", strings.Join(cfg.Benchmark.BaseUrls, ", ")+" (--target only)")
+	}
 	cli.KeyValuePairs(
 		"Servers", strconv.Itoa(serverCount),
 		"Endpoints", strconv.Itoa(len(cfg.Endpoints)),
 	)
+	budgetKey, budgetStr := "Duration/Endpoint", cfg.Benchmark.DurationPerEndpoint.String()
+	if cfg.Benchmark.RequestsPerEndpoint > 0 {
+		budgetKey, budgetStr = "Requests/Endpoint", strconv.Itoa(cfg.Benchmark.RequestsPerEndpoint)+" successful"
+	}
+	if cfg.Benchmark.TotalDuration > 0 {
+		if cfg.Benchmark.DurationPerEndpoint == 0 {
+			budgetStr = "per server"
+		}
+		budgetStr += fmt.Sprintf(" × weight (total_duration %s)", cfg.Benchmark.TotalDuration)
+	}
 	cli.KeyValuePairs(
-		"Concurrency", strconv.Itoa(cfg.Benchmark.Concurrency),
-		"Duration/Endpoint", cfg.Benchmark.DurationPerEndpoint.String(),
+		"Concurrency", concurrencyString(&cfg.Benchmark),
+		budgetKey, budgetStr,
 		"Request Timeout", cfg.Benchmark.RequestTimeout.String(),
 	)
+	if cfg.Benchmark.ConnectTimeout > 0 {
+		cli.KeyValue("Connect Timeout", cfg.Benchmark.ConnectTimeout.String()+" (dial and response headers)")
+	}
+	if cfg.Benchmark.MaxConns > 0 {
+		cli.KeyValue("Max Conns", strconv.Itoa(cfg.Benchmark.MaxConns)+" per host (workers beyond it queue)")
+	}
+	if !cfg.Benchmark.FollowRedirects {
+		cli.KeyValue("Redirects", "not followed (3xx responses validated as-is)")
+	}
+	if cfg.Benchmark.CacheHeader != DefaultConfig.Benchmark.CacheHeader {
+		cli.KeyValue("Cache Header", cfg.Benchmark.CacheHeader+` ("hit" flags a possibly cached response)`)
+	}
+	if cfg.Benchmark.MaxTotalConns > 0 {
+		cli.KeyValue("Max Total Conns", strconv.Itoa(cfg.Benchmark.MaxTotalConns)+" in flight across all workers (the rest wait)")
+	}
 	if cfg.Benchmark.Load.Mode == LoadModeOpen {
 		rateStr := strconv.FormatFloat(cfg.Benchmark.Load.Rate, 'f', -1, 64) + " req/s"
 		if len(cfg.Benchmark.Load.Stages) > 0 {
@@ -109,16 +209,71 @@ func (cfg *Config) Print(serverCount int) {
 		"CPU Limit", strconv.FormatFloat(cfg.Container.CpuLimit, 'f', -1, 64),
 		"Memory Limit", cfg.Container.MemoryLimit,
 	)
+	if len(cfg.Container.ReadyExec) > 0 {
+		cli.KeyValue("Ready Exec", strings.Join(cfg.Container.ReadyExec, " "))
+	}
 
 	cooldownStr := disabledStr
 	if cfg.Benchmark.ServerCooldown > 0 {
 		cooldownStr = cfg.Benchmark.ServerCooldown.String()
 	}
+	if idle := cfg.Benchmark.CooldownUntilIdle; idle != nil {
+		cooldownStr = fmt.Sprintf("until database CPU below %s (max %s)", idle.CpuThresholdRaw, idle.MaxDuration)
+	}
+	warmupStr := cfg.Benchmark.WarmupDuration.String()
+	if stable := cfg.Benchmark.WarmupUntilStable; stable != nil {
+		warmupStr = fmt.Sprintf("until P50 within %s over %d×%s (max %s)",
+			stable.ThresholdRaw, stable.Windows, stable.Window, stable.MaxDuration)
+	}
 	cli.KeyValuePairs(
-		"Warmup", cfg.Benchmark.WarmupDuration.String(),
+		"Warmup", warmupStr,
 		"Warmup Pause", cfg.Benchmark.WarmupPause.String(),
 		"Server Cooldown", cooldownStr,
 	)
+	if cfg.Benchmark.FailFast > 0 {
+		cli.KeyValue("Fail Fast", "after "+strconv.Itoa(cfg.Benchmark.FailFast)+" failures before any success")
+	}
+	if cfg.Benchmark.ServerRetries > 0 {
+		cli.KeyValue("Server Retries", strconv.Itoa(cfg.Benchmark.ServerRetries)+" per server on start or readiness failure")
+	}
+	if cfg.Benchmark.UserAgent != "" {
+		cli.KeyValue("User-Agent", cfg.Benchmark.UserAgent)
+	}
+	if cfg.Benchmark.DefaultAccept != "" {
+		cli.KeyValue("Default Accept", cfg.Benchmark.DefaultAccept)
+	}
+	if cfg.Benchmark.Estimator == EstimatorTDigest {
+		cli.KeyValue("Estimator", "t-digest percentiles ("+strconv.Itoa(cfg.Benchmark.MaxSamples)+" latencies kept for histograms)")
+	} else if cfg.Benchmark.MaxSamples > 0 {
+		cli.KeyValue("Max Samples", strconv.Itoa(cfg.Benchmark.MaxSamples)+" per endpoint (percentiles sampled beyond)")
+	}
+	if cfg.Benchmark.DiscardFirst > 0 {
+		cli.KeyValue("Discard First", cfg.Benchmark.DiscardFirstRaw+" of measured samples per endpoint")
+	}
+	if cfg.Benchmark.AbortBelow > 0 {
+		cli.KeyValue("Abort Below", cfg.Benchmark.AbortBelowRaw+" success rate (stops the run)")
+	}
+	if cfg.Benchmark.MixedMode {
+		cli.KeyValue("Mixed Mode", "all endpoints concurrently, weighted")
+	}
+	if cfg.Benchmark.ReplayFile != "" {
+		cli.KeyValue("Replay", cfg.Benchmark.ReplayFile+" in order (replaces standalone endpoints)")
+	}
+	if cfg.Database.ResetPath != DefaultConfig.Database.ResetPath {
+		cli.KeyValue("Reset Path", "DELETE "+cfg.Database.ResetPath)
+	}
+	if cfg.Benchmark.SeedFlow != "" {
+		cli.KeyValue("Seed Flow", cfg.Benchmark.SeedFlow+" (not measured)")
+	}
+}
+
+// concurrencyString shows the resolved worker count, with the CPU multiple
+// it came from when concurrency was given as "Nx".
+func concurrencyString(b *BenchmarkConfig) string {
+	if b.ConcurrencyRaw.PerCPU == 0 {
+		return strconv.Itoa(b.Concurrency)
+	}
+	return fmt.Sprintf("%d (%s × %d CPUs)", b.Concurrency, b.ConcurrencyRaw, runtime.NumCPU())
 }
 
 func ApplyRuntimeOptions(servers []*ResolvedServer, opts *RuntimeOptions) (filtered []*ResolvedServer, invalidNames []string) {
@@ -141,3 +296,155 @@ func ApplyRuntimeOptions(servers []*ResolvedServer, opts *RuntimeOptions) (filte
 
 	return servers, nil
 }
+
+// ApplyRunOverrides writes the run-size overrides in opts onto cfg (so Print
+// shows what actually runs) and every resolved server, along with the
+// per-run instrumentation switches.
+func ApplyRunOverrides(cfg *Config, servers []*ResolvedServer, opts *RuntimeOptions) {
+	if opts.Duration > 0 {
+		cfg.Benchmark.DurationPerEndpoint = opts.Duration
+		cfg.Benchmark.RequestsPerEndpoint = 0
+		cfg.Benchmark.TotalDuration = 0
+	}
+	if opts.Concurrency > 0 {
+		cfg.Benchmark.Concurrency = opts.Concurrency
+		cfg.Benchmark.ConcurrencyRaw = ConcurrencySpec{Count: opts.Concurrency}
+	}
+	if opts.Warmup > 0 {
+		cfg.Benchmark.WarmupDuration = opts.Warmup
+		cfg.Benchmark.WarmupUntilStable = nil
+	}
+	if opts.WarmupPause > 0 {
+		cfg.Benchmark.WarmupPause = opts.WarmupPause
+	}
+	if opts.Cooldown > 0 {
+		cfg.Benchmark.ServerCooldown = opts.Cooldown
+	}
+
+	for _, s := range servers {
+		s.DurationPerEndpoint = cfg.Benchmark.DurationPerEndpoint
+		s.RequestsPerEndpoint = cfg.Benchmark.RequestsPerEndpoint
+		s.TotalDuration = cfg.Benchmark.TotalDuration
+		s.Concurrency = cfg.Benchmark.Concurrency
+		s.WarmupDuration = cfg.Benchmark.WarmupDuration
+		s.WarmupPause = cfg.Benchmark.WarmupPause
+		s.WarmupStable = cfg.Benchmark.WarmupUntilStable
+		s.ConnStats = opts.ConnStats
+		s.ExportWarmup = opts.ExportWarmup
+		s.TracePhases = opts.TracePhases
+		s.BestWindow = opts.BestWindow
+	}
+}
+
+// ApplyTagFilter narrows every server to the endpoints and sequences carrying
+// at least one of tags. Tags no endpoint declares are returned so the caller
+// can warn; they never fail the run on their own.
+func ApplyTagFilter(servers []*ResolvedServer, tags []string) (unknownTags []string) {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	matches := func(have []string) bool {
+		return slices.ContainsFunc(have, func(tag string) bool { return slices.Contains(tags, tag) })
+	}
+
+	known := make(map[string]bool)
+	for _, s := range servers {
+		testcases := make([]*Testcase, 0, len(s.Testcases))
+		for _, tc := range s.Testcases {
+			for _, tag := range tc.Tags {
+				known[tag] = true
+			}
+			if matches(tc.Tags) {
+				testcases = append(testcases, tc)
+			}
+		}
+		sequences := make([]*ResolvedSequence, 0, len(s.Sequences))
+		for _, seq := range s.Sequences {
+			for _, tag := range seq.Tags {
+				known[tag] = true
+			}
+			if matches(seq.Tags) {
+				sequences = append(sequences, seq)
+			}
+		}
+		s.Testcases = testcases
+		s.Sequences = sequences
+	}
+
+	for _, tag := range tags {
+		if !known[tag] {
+			unknownTags = append(unknownTags, tag)
+		}
+	}
+	return unknownTags
+}
+
+// HasWork reports whether the server has any endpoint or sequence left to run.
+func (s *ResolvedServer) HasWork() bool {
+	return len(s.Testcases) > 0 || len(s.Sequences) > 0
+}
+
+// MinEndpointSlice is the shortest window total_duration may leave an
+// endpoint; below it warm connections and percentiles rest on too little.
+const MinEndpointSlice = time.Second
+
+// ApplyTotalDuration splits benchmark.total_duration over what each server
+// will actually measure, so it runs after the tag filter and run overrides.
+// Every endpoint counts its weight in units and every sequence step one unit;
+// DurationPerEndpoint becomes one unit's share, so an endpoint runs for
+// weight × that (EndpointWindow) and a sequence for steps × that, as before.
+// It fails when a unit would fall below MinEndpointSlice. The config's
+// DurationPerEndpoint takes the unit only when every server shares it; when
+// their endpoint sets differ (tags, exclusions) it is 0 and each server keeps
+// its own.
+func ApplyTotalDuration(cfg *Config, servers []*ResolvedServer) error {
+	total := cfg.Benchmark.TotalDuration
+	if total <= 0 {
+		return nil
+	}
+	var shared time.Duration
+	uniform := true
+	for _, s := range servers {
+		units := 0
+		seen := make(map[string]bool, len(s.Testcases))
+		for _, tc := range s.Testcases {
+			if !seen[tc.EndpointName] {
+				seen[tc.EndpointName] = true
+				units += tc.Weight
+			}
+		}
+		for _, seq := range s.Sequences {
+			units += len(seq.Endpoints)
+		}
+		if units == 0 {
+			continue
+		}
+		unit := total / time.Duration(units)
+		if unit < MinEndpointSlice {
+			return fmt.Errorf("benchmark total_duration %s is too short: %d endpoints and %d sequences (%d weight units) need at least %s per unit, so %s in total",
+				total, len(seen), len(s.Sequences), units, MinEndpointSlice, MinEndpointSlice*time.Duration(units))
+		}
+		s.DurationPerEndpoint = unit
+		if shared != 0 && shared != unit {
+			uniform = false
+		}
+		shared = unit
+	}
+	switch {
+	case !uniform:
+		cfg.Benchmark.DurationPerEndpoint = 0
+	case shared != 0:
+		cfg.Benchmark.DurationPerEndpoint = shared
+	}
+	return nil
+}
+
+// EndpointWindow is how long the endpoint behind tc is measured:
+// DurationPerEndpoint, or under total_duration its weight's share.
+func (s *ResolvedServer) EndpointWindow(tc *Testcase) time.Duration {
+	if s.TotalDuration > 0 {
+		return s.DurationPerEndpoint * time.Duration(tc.Weight)
+	}
+	return s.DurationPerEndpoint
+}
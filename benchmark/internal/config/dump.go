@@ -0,0 +1,305 @@
+package config
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DumpResolved writes servers as JSON to path (--dump-resolved): what the run
+// will actually execute once defaults, route parsing, per_database expansion,
+// variations and the CLI overrides have all been applied. Durations print as
+// Go duration strings and matchers in their config syntax; upload contents and
+// the cached request bodies are left out.
+func DumpResolved(path string, servers []*ResolvedServer) error {
+	views := make([]resolvedServerView, len(servers))
+	for i, s := range servers {
+		views[i] = newResolvedServerView(s)
+	}
+	data, err := json.Marshal(views, jsontext.WithIndent("  "), json.Deterministic(true))
+	if err != nil {
+		return fmt.Errorf("marshal resolved config: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write resolved config: %w", err)
+	}
+	return nil
+}
+
+type resolvedServerView struct {
+	Name                string                 `json:"name"`
+	Image               string                 `json:"image,omitempty"`
+	Port                int                    `json:"port,omitzero"`
+	ExternalUrl         string                 `json:"external_url,omitempty"`
+	BaseUrl             string                 `json:"base_url"`
+	BaseUrls            []string               `json:"base_urls,omitempty"`
+	Tags                []string               `json:"tags,omitempty"`
+	Env                 map[string]string      `json:"env,omitempty"`
+	Cmd                 []string               `json:"cmd,omitempty"`
+	ReadyExec           []string               `json:"ready_exec,omitempty"`
+	CpuLimit            float64                `json:"cpu_limit"`
+	MemoryLimit         string                 `json:"memory_limit"`
+	Concurrency         int                    `json:"concurrency"`
+	Load                LoadConfig             `json:"load,omitzero"`
+	DurationPerEndpoint string                 `json:"duration_per_endpoint,omitempty"`
+	TotalDuration       string                 `json:"total_duration,omitempty"`
+	RequestsPerEndpoint int                    `json:"requests_per_endpoint,omitzero"`
+	RequestTimeout      string                 `json:"request_timeout"`
+	ConnectTimeout      string                 `json:"connect_timeout,omitempty"`
+	WarmupDuration      string                 `json:"warmup_duration"`
+	WarmupPause         string                 `json:"warmup_pause"`
+	WarmupStable        *WarmupStableConfig    `json:"warmup_until_stable,omitempty"`
+	MixedMode           bool                   `json:"mixed_mode,omitzero"`
+	Replay              bool                   `json:"replay,omitzero"`
+	MaxBodyBytes        int64                  `json:"max_body_bytes"`
+	MaxSamples          int                    `json:"max_samples,omitzero"`
+	Estimator           string                 `json:"estimator"`
+	FailFast            int                    `json:"fail_fast,omitzero"`
+	ServerRetries       int                    `json:"server_retries,omitzero"`
+	MaxConns            int                    `json:"max_conns,omitzero"`
+	MaxTotalConns       int                    `json:"max_total_conns,omitzero"`
+	FollowRedirects     bool                   `json:"follow_redirects"`
+	CacheHeader         string                 `json:"cache_header"`
+	DiscardFirst        float64                `json:"discard_first,omitzero"`
+	ResetPath           string                 `json:"reset_path"`
+	ConnStats           bool                   `json:"conn_stats,omitzero"`
+	ExportWarmup        bool                   `json:"export_warmup,omitzero"`
+	TracePhases         bool                   `json:"trace_phases,omitzero"`
+	BestWindow          bool                   `json:"best_window,omitzero"`
+	EndpointOrder       []string               `json:"endpoint_order"`
+	Testcases           []testcaseView         `json:"testcases"`
+	Sequences           []resolvedSequenceView `json:"sequences,omitempty"`
+	SeedSequences       []resolvedSequenceView `json:"seed_sequences,omitempty"`
+}
+
+type testcaseView struct {
+	Endpoint        string            `json:"endpoint"`
+	Name            string            `json:"name"`
+	Method          string            `json:"method"`
+	RequestURI      string            `json:"request_uri"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	RequestType     string            `json:"request_type,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	FormData        map[string]string `json:"form_data,omitempty"`
+	MultipartFields map[string]string `json:"multipart_fields,omitempty"`
+	File            *fileUploadView   `json:"file,omitempty"`
+	ChunkedRequest  bool              `json:"chunked_request,omitzero"`
+	Dynamic         bool              `json:"dynamic,omitzero"`
+	ContentType     string            `json:"content_type,omitempty"`
+	ExpectStatus    string            `json:"expect_status"`
+	ExpectHeaders   map[string]string `json:"expect_headers,omitempty"`
+	ExpectBody      any               `json:"expect_body,omitempty"`
+	ExpectText      string            `json:"expect_text,omitempty"`
+	ExpectValidJSON bool              `json:"expect_valid_json,omitzero"`
+	ExpectJSONPath  map[string]any    `json:"expect_json_path,omitempty"`
+	ExpectSuccess   string            `json:"expect_success_when,omitempty"`
+	ExpectAbsent    []string          `json:"expect_absent_headers,omitempty"`
+	Weight          int               `json:"weight,omitzero"`
+	Tags            []string          `json:"tags,omitempty"`
+	ExpectedAvg     string            `json:"expected_avg,omitempty"`
+	ExpectedP99     string            `json:"expected_p99,omitempty"`
+	SSE             *sseView          `json:"sse,omitempty"`
+}
+
+type sseView struct {
+	Events      int    `json:"events"`
+	MaxDuration string `json:"max_duration,omitempty"`
+}
+
+type fileUploadView struct {
+	Field       string `json:"field"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Bytes       int    `json:"bytes"`
+}
+
+type resolvedSequenceView struct {
+	Id       string                 `json:"id"`
+	Database string                 `json:"database,omitempty"`
+	Vars     map[string]VarConfig   `json:"vars,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
+	Steps    []sequenceEndpointView `json:"steps"`
+
+	DatabaseWeights map[string]float64 `json:"database_weights,omitempty"` // one flow picking a database per run
+}
+
+type sequenceEndpointView struct {
+	Name           string            `json:"name"`
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           any               `json:"body,omitempty"`
+	ChunkedRequest bool              `json:"chunked_request,omitzero"`
+	ExpectStatus   string            `json:"expect_status"`
+	ExpectBody     any               `json:"expect_body,omitempty"`
+	Capture        map[string]string `json:"capture,omitempty"`
+	When           []string          `json:"when,omitempty"`
+}
+
+func newResolvedServerView(s *ResolvedServer) resolvedServerView {
+	v := resolvedServerView{
+		Name:                s.Name,
+		Image:               s.ImageName,
+		Port:                s.Port,
+		ExternalUrl:         s.ExternalUrl,
+		BaseUrl:             s.BaseUrl,
+		BaseUrls:            s.BaseUrls,
+		Tags:                s.Tags,
+		Env:                 s.Env,
+		Cmd:                 s.Cmd,
+		ReadyExec:           s.ReadyExec,
+		CpuLimit:            s.CpuLimit,
+		MemoryLimit:         s.MemoryLimit,
+		Concurrency:         s.Concurrency,
+		Load:                s.Load,
+		RequestsPerEndpoint: s.RequestsPerEndpoint,
+		RequestTimeout:      s.RequestTimeout.String(),
+		WarmupDuration:      s.WarmupDuration.String(),
+		WarmupPause:         s.WarmupPause.String(),
+		WarmupStable:        s.WarmupStable,
+		MixedMode:           s.MixedMode,
+		Replay:              s.Replay,
+		MaxBodyBytes:        s.MaxBodyBytes,
+		MaxSamples:          s.MaxSamples,
+		Estimator:           s.Estimator,
+		FailFast:            s.FailFast,
+		ServerRetries:       s.ServerRetries,
+		MaxConns:            s.MaxConns,
+		MaxTotalConns:       s.MaxTotalConns,
+		FollowRedirects:     s.FollowRedirects,
+		CacheHeader:         s.CacheHeader,
+		DiscardFirst:        s.DiscardFirst,
+		ResetPath:           s.ResetPath,
+		ConnStats:           s.ConnStats,
+		ExportWarmup:        s.ExportWarmup,
+		TracePhases:         s.TracePhases,
+		BestWindow:          s.BestWindow,
+		EndpointOrder:       s.EndpointOrder,
+		Testcases:           make([]testcaseView, len(s.Testcases)),
+		Sequences:           sequenceViews(s.Sequences),
+		SeedSequences:       sequenceViews(s.SeedSequences),
+	}
+	if s.RequestsPerEndpoint == 0 {
+		v.DurationPerEndpoint = s.DurationPerEndpoint.String()
+	}
+	if s.ConnectTimeout > 0 {
+		v.ConnectTimeout = s.ConnectTimeout.String()
+	}
+	if s.TotalDuration > 0 {
+		v.TotalDuration = s.TotalDuration.String()
+	}
+	for i, tc := range s.Testcases {
+		v.Testcases[i] = newTestcaseView(tc)
+	}
+	return v
+}
+
+var requestTypeNames = map[RequestType]string{
+	RequestTypeJSON:      "json",
+	RequestTypeForm:      "form",
+	RequestTypeMultipart: "multipart",
+}
+
+func newTestcaseView(tc *Testcase) testcaseView {
+	v := testcaseView{
+		Endpoint:        tc.EndpointName,
+		Name:            tc.Name,
+		Method:          tc.Method,
+		RequestURI:      tc.RequestURI,
+		Headers:         tc.Headers,
+		RequestType:     requestTypeNames[tc.RequestType],
+		Body:            tc.Body,
+		FormData:        tc.FormData,
+		MultipartFields: tc.MultipartFields,
+		ChunkedRequest:  tc.ChunkedRequest,
+		Dynamic:         tc.Dynamic != nil,
+		ContentType:     tc.ContentType,
+		ExpectStatus:    tc.ExpectedStatus.String(),
+		ExpectBody:      tc.ExpectedBody,
+		ExpectText:      tc.ExpectedText,
+		ExpectValidJSON: tc.ExpectValidJSON,
+		Weight:          tc.Weight,
+		Tags:            tc.Tags,
+	}
+	if tc.SSE != nil {
+		v.SSE = &sseView{Events: tc.SSE.Events}
+		if tc.SSE.MaxDuration > 0 {
+			v.SSE.MaxDuration = tc.SSE.MaxDuration.String()
+		}
+	}
+	if tc.FileUpload != nil {
+		v.File = &fileUploadView{
+			Field:       tc.FileUpload.FieldName,
+			Filename:    tc.FileUpload.Filename,
+			ContentType: tc.FileUpload.ContentType,
+			Bytes:       len(tc.FileUpload.Content),
+		}
+	}
+	if len(tc.ExpectedHeaders) > 0 {
+		v.ExpectHeaders = make(map[string]string, len(tc.ExpectedHeaders))
+		for name, m := range tc.ExpectedHeaders {
+			v.ExpectHeaders[name] = m.String()
+		}
+	}
+	if tc.SuccessWhen != nil {
+		v.ExpectSuccess = tc.SuccessWhen.Expr
+	}
+	v.ExpectAbsent = tc.AbsentHeaders
+	if len(tc.ExpectedJSONPaths) > 0 {
+		v.ExpectJSONPath = make(map[string]any, len(tc.ExpectedJSONPaths))
+		for _, a := range tc.ExpectedJSONPaths {
+			v.ExpectJSONPath[a.Path] = a.Want
+		}
+	}
+	if tc.ExpectedAvg > 0 {
+		v.ExpectedAvg = tc.ExpectedAvg.String()
+	}
+	if tc.ExpectedP99 > 0 {
+		v.ExpectedP99 = tc.ExpectedP99.String()
+	}
+	return v
+}
+
+func sequenceViews(sequences []*ResolvedSequence) []resolvedSequenceView {
+	if len(sequences) == 0 {
+		return nil
+	}
+	views := make([]resolvedSequenceView, len(sequences))
+	for i, seq := range sequences {
+		views[i] = resolvedSequenceView{
+			Id:       seq.Id,
+			Database: seq.Database,
+			Vars:     seq.Vars,
+			Tags:     seq.Tags,
+			Steps:    make([]sequenceEndpointView, len(seq.Endpoints)),
+		}
+		if len(seq.DatabaseChoices) > 0 {
+			views[i].DatabaseWeights = make(map[string]float64, len(seq.DatabaseChoices))
+			for j, choice := range seq.DatabaseChoices {
+				views[i].DatabaseWeights[choice.Database] = seq.DatabaseWeights[j]
+			}
+		}
+		for j, ep := range seq.Endpoints {
+			views[i].Steps[j] = sequenceEndpointView{
+				Name:           ep.Name,
+				Method:         ep.Method,
+				Path:           ep.Path,
+				Headers:        ep.Headers,
+				Body:           ep.Body,
+				ChunkedRequest: ep.ChunkedRequest,
+				ExpectStatus:   ep.ExpectedStatus.String(),
+				ExpectBody:     ep.ExpectedBody,
+				Capture:        ep.Capture,
+				When:           ep.When,
+			}
+		}
+	}
+	return views
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDumpResolved(t *testing.T) {
+	t.Parallel()
+
+	const cfgJSON = `{
+		"benchmark": {"duration_per_endpoint": "3s", "request_timeout": "1s"},
+		"databases": ["postgres", "mongodb"],
+		"endpoints": {
+			"health": {"route": "GET /db/{database}/health", "per_database": true, "expect": {"status": "2xx"}},
+			"create": {
+				"route": "POST /items",
+				"body": {"email": "{email}"},
+				"expect": {"status": 201},
+				"sequence": {"id": "items", "capture": {"id": "id"}, "vars": {"email": {"type": "email"}}}
+			},
+			"read": {"route": "GET /items/{id}", "sequence": {"id": "items"}}
+		}
+	}`
+	_, server, err := loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", "resolved.json")
+	if err := DumpResolved(path, []*ResolvedServer{server}); err != nil {
+		t.Fatalf("DumpResolved: %v", err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // test temp file
+	if err != nil {
+		t.Fatalf("read dump: %v", err)
+	}
+	var got []resolvedServerView
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("dump is not valid JSON: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d servers, want 1", len(got))
+	}
+
+	s := got[0]
+	if s.DurationPerEndpoint != "3s" || s.RequestTimeout != "1s" {
+		t.Errorf("durations: got %q/%q, want 3s/1s", s.DurationPerEndpoint, s.RequestTimeout)
+	}
+	var uris []string
+	for _, tc := range s.Testcases {
+		uris = append(uris, tc.RequestURI)
+		if tc.ExpectStatus != "2xx" {
+			t.Errorf("testcase %q status: got %q, want 2xx", tc.Name, tc.ExpectStatus)
+		}
+	}
+	if len(uris) != 2 || uris[0] != "/db/postgres/health" || uris[1] != "/db/mongodb/health" {
+		t.Errorf("testcases: got %v, want the health route per database", uris)
+	}
+	if len(s.Sequences) != 1 || len(s.Sequences[0].Steps) != 2 {
+		t.Fatalf("sequences: got %+v, want one two-step flow", s.Sequences)
+	}
+	steps := s.Sequences[0].Steps
+	if steps[0].ExpectStatus != "201" || steps[0].Capture["id"] != "id" || steps[1].Path != "/items/{id}" {
+		t.Errorf("steps: got %+v", steps)
+	}
+	if s.Sequences[0].Vars["email"].Type != "email" {
+		t.Errorf("vars: got %+v, want email var kept", s.Sequences[0].Vars)
+	}
+}
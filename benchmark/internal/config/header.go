@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HeaderMatcher is the resolved form of one expect.headers value. A plain
+// value must match exactly (Content-Type keeps its historical substring
+// match so "application/json" accepts a charset suffix); "contains:..."
+// matches a substring and "regex:..." an RE2 pattern, compiled once at load.
+type HeaderMatcher struct {
+	exact    string
+	contains string
+	regex    *regexp.Regexp
+}
+
+const (
+	headerContainsPrefix = "contains:"
+	headerRegexPrefix    = "regex:"
+)
+
+func parseHeaderMatcher(name, value string) (HeaderMatcher, error) {
+	switch {
+	case strings.HasPrefix(value, headerRegexPrefix):
+		pattern := strings.TrimPrefix(value, headerRegexPrefix)
+		if pattern == "" {
+			return HeaderMatcher{}, errors.New("regex: pattern must not be empty")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return HeaderMatcher{}, fmt.Errorf("invalid regex: %w", err)
+		}
+		return HeaderMatcher{regex: re}, nil
+	case strings.HasPrefix(value, headerContainsPrefix):
+		return HeaderMatcher{contains: strings.TrimPrefix(value, headerContainsPrefix)}, nil
+	case strings.EqualFold(name, "Content-Type"):
+		return HeaderMatcher{contains: value}, nil
+	default:
+		return HeaderMatcher{exact: value}, nil
+	}
+}
+
+func (m HeaderMatcher) Matches(actual string) bool {
+	switch {
+	case m.regex != nil:
+		return m.regex.MatchString(actual)
+	case m.contains != "":
+		return strings.Contains(actual, m.contains)
+	default:
+		return actual == m.exact
+	}
+}
+
+// String renders the expectation for mismatch messages.
+func (m HeaderMatcher) String() string {
+	switch {
+	case m.regex != nil:
+		return fmt.Sprintf("match for regex %q", m.regex.String())
+	case m.contains != "":
+		return fmt.Sprintf("substring %q", m.contains)
+	default:
+		return fmt.Sprintf("%q", m.exact)
+	}
+}
+
+func resolveHeaderMatchers(headers map[string]string) (map[string]HeaderMatcher, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	matchers := make(map[string]HeaderMatcher, len(headers))
+	for name, value := range headers {
+		m, err := parseHeaderMatcher(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("expect.headers %s: %w", name, err)
+		}
+		matchers[name] = m
+	}
+	return matchers, nil
+}
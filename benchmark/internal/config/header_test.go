@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeaderMatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, value, actual string
+		want                bool
+	}{
+		{"X-Cache", "HIT", "HIT", true},
+		{"X-Cache", "HIT", "HIT from edge", false}, // plain values stay exact
+		{"Content-Type", "application/json", "application/json; charset=utf-8", true},
+		{"X-Cache", "contains:edge", "HIT from edge", true},
+		{"X-Cache", "contains:edge", "MISS", false},
+		{"Content-Type", `regex:^application/json(;\s*charset=utf-8)?$`, "application/json; charset=utf-8", true},
+		{"Content-Type", `regex:^application/json(;\s*charset=utf-8)?$`, "text/html; charset=utf-8", false},
+		{"X-Request-Id", "regex:^[0-9a-f-]{36}$", "3f2b8c1e-5d4a-4e8b-9c7d-1a2b3c4d5e6f", true},
+	}
+	for _, tt := range tests {
+		m, err := parseHeaderMatcher(tt.name, tt.value)
+		if err != nil {
+			t.Fatalf("parse %s %q: %v", tt.name, tt.value, err)
+		}
+		if got := m.Matches(tt.actual); got != tt.want {
+			t.Errorf("%s %q vs %q: got %v, want %v", tt.name, tt.value, tt.actual, got, tt.want)
+		}
+	}
+
+	m, _ := parseHeaderMatcher("X-Id", "regex:^[0-9]+$")
+	if got := m.String(); got != `match for regex "^[0-9]+$"` {
+		t.Errorf("regex String: got %s", got)
+	}
+}
+
+func TestResolveHeaderPatterns(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"root": {
+		"route": "GET /",
+		"expect": {"headers": {"content-type": "regex:^application/json", "x-cache": "contains:HIT"}}
+	}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	headers := server.Testcases[0].ExpectedHeaders
+	if !headers["Content-Type"].Matches("application/json; charset=utf-8") {
+		t.Error("canonicalized Content-Type regex should match")
+	}
+	if !headers["X-Cache"].Matches("HIT from edge") {
+		t.Error("X-Cache contains should match")
+	}
+
+	_, _, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /", "expect": {"headers": {"x-id": "regex:[0-9"}}}}}`)
+	if err == nil || !strings.Contains(err.Error(), "expect.headers X-Id: invalid regex") {
+		t.Errorf("bad regex: got %v", err)
+	}
+}
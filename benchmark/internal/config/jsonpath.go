@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JSONPathPresent is the expect.json_path value that only requires the path
+// to exist, whatever it holds (null included).
+const JSONPathPresent = "*"
+
+// JSONPathAssertion is one resolved expect.json_path entry: the compiled path
+// and the value it must hold, matched like expect.body (objects partially,
+// arrays exactly).
+type JSONPathAssertion struct {
+	Path    string // as written, for mismatch messages
+	Want    any    // ignored when Present
+	Present bool   // value was JSONPathPresent
+	steps   []pathStep
+}
+
+// pathStep is one member name or array index; exactly one is meaningful.
+type pathStep struct {
+	key   string
+	index int // -1 for a member step
+}
+
+// parseJSONPath compiles the small JSONPath subset expect.json_path accepts: a
+// root "$" followed by ".name", "['name']" / ["name"] and "[N]" steps. No
+// wildcards, slices, filters or recursive descent: each path names exactly
+// one value.
+func parseJSONPath(path string) ([]pathStep, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, errors.New(`must start with "$"`)
+	}
+	var steps []pathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, errors.New(`empty member name (recursive ".." is not supported)`)
+			}
+			if name == "*" {
+				return nil, errors.New("wildcard steps are not supported; use the value \"*\" to assert presence")
+			}
+			steps = append(steps, pathStep{key: name, index: -1})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.New(`unterminated "["`)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1], index: -1})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("[%s] must be a non-negative index or a quoted member name", inner)
+			}
+			steps = append(steps, pathStep{index: index})
+		default:
+			return nil, fmt.Errorf("unexpected %q after %q", rest[0], strings.TrimSuffix(path, rest))
+		}
+	}
+	return steps, nil
+}
+
+// NewJSONPathAssertion compiles one expect.json_path entry.
+func NewJSONPathAssertion(path string, want any) (JSONPathAssertion, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return JSONPathAssertion{}, err
+	}
+	return JSONPathAssertion{Path: path, Want: want, Present: want == JSONPathPresent, steps: steps}, nil
+}
+
+// Lookup walks value (a decoded JSON document) along the path, reporting
+// false when a member or index along the way doesn't exist.
+func (a *JSONPathAssertion) Lookup(value any) (any, bool) {
+	for _, step := range a.steps {
+		if step.index < 0 {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[step.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		array, ok := value.([]any)
+		if !ok || step.index >= len(array) {
+			return nil, false
+		}
+		value = array[step.index]
+	}
+	return value, true
+}
+
+// resolveJSONPaths compiles expect.json_path, sorted by path so a failing
+// run reports the same assertion every time.
+func resolveJSONPaths(paths map[string]any) ([]JSONPathAssertion, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	assertions := make([]JSONPathAssertion, 0, len(paths))
+	for path, want := range paths {
+		a, err := NewJSONPathAssertion(path, want)
+		if err != nil {
+			return nil, fmt.Errorf("expect.json_path %q: %w", path, err)
+		}
+		assertions = append(assertions, a)
+	}
+	slices.SortFunc(assertions, func(a, b JSONPathAssertion) int { return strings.Compare(a.Path, b.Path) })
+	return assertions, nil
+}
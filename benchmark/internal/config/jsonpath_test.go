@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string][]pathStep{
+		"$":                   nil,
+		"$.status":            {{key: "status", index: -1}},
+		"$.data[0].id":        {{key: "data", index: -1}, {index: 0}, {key: "id", index: -1}},
+		"$['a.b'][\"c\"][12]": {{key: "a.b", index: -1}, {key: "c", index: -1}, {index: 12}},
+	}
+	for path, want := range valid {
+		got, err := parseJSONPath(path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", path, err)
+			continue
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s step %d: got %v, want %v", path, i, got[i], want[i])
+			}
+		}
+	}
+
+	invalid := map[string]string{
+		"data.id":     `must start with "$"`,
+		"$..id":       "recursive",
+		"$.items.*":   "wildcard",
+		"$.items[*]":  "non-negative index",
+		"$.items[-1]": "non-negative index",
+		"$.items[0":   "unterminated",
+		"$x":          "unexpected",
+	}
+	for path, wantErr := range invalid {
+		if _, err := parseJSONPath(path); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: got %v, want error containing %q", path, err, wantErr)
+		}
+	}
+}
+
+func TestResolveJSONPath(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{
+		"endpoints": {
+			"list": {
+				"route": "GET /items",
+				"expect": {"json_path": {"$.status": "ok", "$.data[0].id": "*"}},
+				"variations": [{"expect": {"json_path": {"$.data[1].id": 2}}}]
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if len(server.Testcases) != 2 {
+		t.Fatalf("testcases: got %d, want 2", len(server.Testcases))
+	}
+	base := server.Testcases[0].ExpectedJSONPaths
+	if len(base) != 2 || base[0].Path != "$.data[0].id" || !base[0].Present || base[1].Path != "$.status" || base[1].Present {
+		t.Errorf("base assertions: got %+v, want $.data[0].id (present) then $.status", base)
+	}
+	if got := len(server.Testcases[1].ExpectedJSONPaths); got != 3 {
+		t.Errorf("variation assertions: got %d, want 3 (endpoint paths plus its own)", got)
+	}
+
+	errCases := map[string]string{
+		`{"route": "GET /x", "expect": {"json_path": {"status": "ok"}}}`:                 `expect.json_path "status": must start with "$"`,
+		`{"route": "GET /x", "expect": {"text": "ok", "json_path": {"$.status": "ok"}}}`: "cannot be combined with expect.text",
+	}
+	for endpoint, wantErr := range errCases {
+		_, _, err := loadTestTarget(t, `{"endpoints": {"x": `+endpoint+`}}`)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: got %v, want error containing %q", endpoint, err, wantErr)
+		}
+	}
+}
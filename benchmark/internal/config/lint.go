@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"benchmark-client/internal/roster"
+)
+
+// Lint loads and resolves filename as a run would, without a roster or
+// Docker, then reports what resolves cleanly but does nothing: endpoints that
+// never run, flows that never run, captures no later step reads, and
+// per_database endpoints without databases. A config that fails to load is
+// the error; the findings are for --lint to print as warnings.
+func Lint(filename string) ([]string, error) {
+	cfg, err := loadConfigFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolve(cfg, []roster.Entry{{Name: "lint"}}); err != nil {
+		return nil, fmt.Errorf("failed to resolve configuration: %w", err)
+	}
+	return lint(cfg), nil
+}
+
+func lint(cfg *Config) []string {
+	var findings []string
+	flowSteps := make(map[string][]string)
+	var flowIds []string
+	for _, name := range endpointOrder(cfg) {
+		endpoint := cfg.Endpoints[name]
+		if endpoint.PerDatabase && len(cfg.Databases) == 0 {
+			findings = append(findings, fmt.Sprintf("endpoint %q is per_database but no databases are configured; it runs once with {database} unfilled", name))
+		}
+		if endpoint.Sequence == nil {
+			if cfg.Benchmark.ReplayFile != "" {
+				findings = append(findings, fmt.Sprintf("endpoint %q never runs: benchmark.replay_file replaces the standalone endpoints", name))
+			}
+			continue
+		}
+		id := endpoint.Sequence.Id
+		if flowSteps[id] == nil {
+			flowIds = append(flowIds, id)
+		}
+		flowSteps[id] = append(flowSteps[id], name)
+	}
+
+	for _, id := range flowIds {
+		findings = append(findings, lintFlow(cfg, id, flowSteps[id])...)
+	}
+	return findings
+}
+
+// lintFlow checks one flow's steps, in order: a flow whose steps' exclusions
+// leave no database resolves to nothing, and a capture only pays for itself
+// when a later step's path, headers, body, expected body or when reads it.
+func lintFlow(cfg *Config, id string, steps []string) []string {
+	var findings []string
+	if len(cfg.Databases) > 0 && slices.ContainsFunc(steps, func(name string) bool { return cfg.Endpoints[name].PerDatabase }) {
+		live := slices.DeleteFunc(slices.Clone(cfg.Databases), func(db string) bool {
+			return slices.ContainsFunc(steps, func(name string) bool {
+				return slices.Contains(cfg.Endpoints[name].ExcludeDatabases, db)
+			})
+		})
+		if len(live) == 0 {
+			findings = append(findings, fmt.Sprintf("flow %q never runs: its steps' exclude_databases leave no database", id))
+		}
+	}
+
+	for i, name := range steps {
+		captures := cfg.Endpoints[name].Sequence.Capture
+		for _, varName := range slices.Sorted(maps.Keys(captures)) {
+			used := slices.ContainsFunc(steps[i+1:], func(later string) bool {
+				return slices.Contains(stepRefs(cfg.Endpoints[later]), varName)
+			})
+			if !used {
+				findings = append(findings, fmt.Sprintf("flow %q step %q: capture {%s} is never used by a later step", id, name, varName))
+			}
+		}
+	}
+	return findings
+}
+
+// stepRefs lists the {name} placeholders a flow step reads at run time.
+func stepRefs(ep EndpointConfig) []string {
+	var refs []string
+	visit := func(s string) {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+			refs = append(refs, match[1])
+		}
+	}
+	visit(ep.Path)
+	for _, value := range ep.Headers {
+		visit(value)
+	}
+	walkStrings(ep.Body, visit)
+	walkStrings(ep.Expect.Body, visit)
+	if ep.Sequence != nil {
+		visit(ep.Sequence.When)
+	}
+	return refs
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func lintTestConfig(t *testing.T, cfgJSON string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "trace.jsonl"), []byte(`{"path": "/users"}`+"\n"), 0o600); err != nil {
+		t.Fatalf("write trace: %v", err)
+	}
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	findings, err := Lint(path)
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	return findings
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfgJSON string
+		want    []string // substrings, one per expected finding
+	}{
+		{
+			name: "clean flow",
+			cfgJSON: `{"endpoints": {
+				"create": {"route": "POST /users", "body": {"name": "a"}, "sequence": {"id": "crud", "capture": {"id": "id"}}},
+				"read": {"route": "GET /users/{id}", "sequence": {"id": "crud"}}
+			}}`,
+		},
+		{
+			name: "endpoint replaced by replay_file",
+			cfgJSON: `{"benchmark": {"replay_file": "trace.jsonl"}, "endpoints": {
+				"health": {"route": "GET /health"}
+			}}`,
+			want: []string{`endpoint "health" never runs: benchmark.replay_file`},
+		},
+		{
+			name: "flow whose exclusions leave no database",
+			cfgJSON: `{"databases": ["postgres", "mongodb"], "endpoints": {
+				"insert": {"route": "POST /db/{database}/users", "per_database": true, "exclude_databases": ["postgres"], "sequence": {"id": "seed"}},
+				"count": {"route": "GET /db/{database}/users", "per_database": true, "exclude_databases": ["mongodb"], "sequence": {"id": "seed"}}
+			}}`,
+			want: []string{`flow "seed" never runs`},
+		},
+		{
+			name: "capture no later step reads",
+			cfgJSON: `{"endpoints": {
+				"create": {"route": "POST /users", "body": {"name": "a"}, "sequence": {"id": "crud", "capture": {"id": "id", "token": "token"}}},
+				"read": {"route": "GET /users/{id}", "sequence": {"id": "crud"}}
+			}}`,
+			want: []string{`flow "crud" step "create": capture {token} is never used`},
+		},
+		{
+			name: "capture read by a later when",
+			cfgJSON: `{"endpoints": {
+				"create": {"route": "POST /users", "body": {"name": "a"}, "sequence": {"id": "crud", "capture": {"id": "id"}}},
+				"cleanup": {"route": "DELETE /users", "sequence": {"id": "crud", "when": "{id}"}}
+			}}`,
+		},
+		{
+			name: "per_database without databases",
+			cfgJSON: `{"endpoints": {
+				"users": {"route": "GET /db/{database}/users", "per_database": true}
+			}}`,
+			want: []string{`endpoint "users" is per_database but no databases are configured`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			findings := lintTestConfig(t, tt.cfgJSON)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("got findings %q, want %d", findings, len(tt.want))
+			}
+			for _, want := range tt.want {
+				if !slices.ContainsFunc(findings, func(f string) bool { return strings.Contains(f, want) }) {
+					t.Errorf("got findings %q, want one containing %q", findings, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLintRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"endpoints": {"read": {"route": "GET /users/{id}"}}}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Lint(path); err == nil {
+		t.Error("got nil, want the unresolved {id} to fail resolution")
+	}
+}
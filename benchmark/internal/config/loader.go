@@ -6,9 +6,11 @@ import (
 	"encoding/json/v2"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
 	"time"
@@ -25,17 +27,38 @@ var DefaultConfig = Config{
 		SampleRateRaw:          "10%",
 		WarmupDurationRaw:      "1s",
 		WarmupPauseRaw:         "100ms",
+		MaxBodyBytes:           DefaultMaxBodyBytes,
+		CacheHeader:            "X-Cache",
 	},
 	Container: ContainerConfig{
 		CpuLimit:    1.0,
 		MemoryLimit: "512mb",
 	},
+	Database: DatabaseConfig{
+		ResetPath: "/db/{database}/reset",
+	},
 }
 
 const (
-	DefaultConfigFile = "../config/config.json"
-	DefaultMethod     = "GET"
-	DefaultStatus     = 200
+	DefaultConfigFile   = "../config/config.json"
+	DefaultMethod       = "GET"
+	DefaultStatus       = 200
+	DefaultMaxBodyBytes = 1 << 20 // response body read cap (1MB)
+	minMaxSamples       = 1000    // below this p99 rests on ~10 samples
+
+	EstimatorExact   = "exact"
+	EstimatorTDigest = "tdigest"
+	// DefaultDigestSamples is max_samples under the tdigest estimator when
+	// unset: percentiles no longer need the sample, only histograms do.
+	DefaultDigestSamples = 10000
+
+	DefaultStableWindow      = "1s"
+	DefaultStableThreshold   = "5%"
+	DefaultStableWindows     = 3
+	DefaultStableMaxDuration = "30s"
+
+	DefaultIdleCpuThreshold = "5%"
+	DefaultIdleMaxDuration  = "60s"
 
 	LoadModeClosed = "closed"
 	LoadModeOpen   = "open"
@@ -74,7 +97,9 @@ func Load(filename, serversDir string) (*Config, []*ResolvedServer, error) {
 // externally-managed server whose lifecycle the caller owns, so no roster
 // discovery and no container metadata. targetUrl replaces the config's
 // base_url so resolution (URI escaping) and the printed config reflect the
-// server actually being hit.
+// server actually being hit. With benchmark.base_urls the target URL is the
+// first of the hosts requests are spread across; it alone receives the
+// database resets and seeding.
 func LoadTarget(filename, targetUrl string) (*Config, *ResolvedServer, error) {
 	cfg, err := loadConfigFile(filename)
 	if err != nil {
@@ -87,11 +112,25 @@ func LoadTarget(filename, targetUrl string) (*Config, *ResolvedServer, error) {
 		return nil, nil, fmt.Errorf("failed to resolve configuration: %w", err)
 	}
 
-	return cfg, resolved[0], nil
+	target := resolved[0]
+	if len(cfg.Benchmark.BaseUrls) > 0 {
+		primary := strings.TrimRight(targetUrl, "/")
+		target.BaseUrls = []string{primary}
+		for _, host := range cfg.Benchmark.BaseUrls {
+			if host != primary {
+				target.BaseUrls = append(target.BaseUrls, host)
+			}
+		}
+	}
+
+	return cfg, target, nil
 }
 
 func loadConfigFile(filename string) (*Config, error) {
 	data, err := os.ReadFile(filename) //nolint:gosec // config file path is controlled
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("config file %q does not exist", filename)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -101,16 +140,19 @@ func loadConfigFile(filename string) (*Config, error) {
 		return nil, fmt.Errorf("unsupported config file format: %s", ext)
 	}
 
-	var cfg Config
-	if err = json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON config: %w", err)
-	}
-
+	// Key order first: it names a duplicated endpoint, where the strict
+	// Unmarshal below would only report a generic duplicate member.
 	order, err := extractKeyOrder(data, "endpoints")
 	if err != nil {
 		return nil, err
 	}
+
+	var cfg Config
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON config: %w", err)
+	}
 	cfg.EndpointOrder = order
+	cfg.Dir = filepath.Dir(filename)
 
 	if err = applyDefaults(&cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -122,9 +164,11 @@ func loadConfigFile(filename string) (*Config, error) {
 func extractKeyOrder(data []byte, key string) ([]string, error) {
 	// Deliberate: json/v2's default rejects duplicate top-level keys (v1 was
 	// last-wins). Stricter-and-better for this trusted, repo-owned config file —
-	// a duplicated key is a mistake we want surfaced, so no AllowDuplicateNames.
+	// a duplicated key is a mistake we want surfaced. This pass tolerates them
+	// only so a repeated key can be reported by name; the full Unmarshal that
+	// follows stays strict.
 	var root map[string]jsontext.Value
-	if err := json.Unmarshal(data, &root); err != nil {
+	if err := json.Unmarshal(data, &root, jsontext.AllowDuplicateNames(true)); err != nil {
 		return nil, fmt.Errorf("failed to parse config for %s order: %w", key, err)
 	}
 
@@ -133,7 +177,7 @@ func extractKeyOrder(data []byte, key string) ([]string, error) {
 		return nil, nil
 	}
 
-	dec := jsontext.NewDecoder(bytes.NewReader(raw))
+	dec := jsontext.NewDecoder(bytes.NewReader(raw), jsontext.AllowDuplicateNames(true))
 	tok, err := dec.ReadToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %w", key, err)
@@ -148,7 +192,11 @@ func extractKeyOrder(data []byte, key string) ([]string, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to read %s key: %w", key, err)
 		}
-		order = append(order, keyTok.String())
+		name := keyTok.String()
+		if slices.Contains(order, name) {
+			return nil, fmt.Errorf("%s: duplicate key %q", key, name)
+		}
+		order = append(order, name)
 		if err := dec.SkipValue(); err != nil {
 			return nil, fmt.Errorf("failed to skip %s value: %w", key, err)
 		}
@@ -172,9 +220,34 @@ func applyDefaults(cfg *Config) error {
 	if _, err := url.Parse(cfg.Benchmark.BaseUrl); err != nil {
 		return fmt.Errorf("benchmark base_url: %w", err)
 	}
+	seenHosts := make(map[string]bool, len(cfg.Benchmark.BaseUrls))
+	for i, raw := range cfg.Benchmark.BaseUrls {
+		host := strings.TrimRight(strings.TrimSpace(raw), "/")
+		u, err := url.Parse(host)
+		if err != nil {
+			return fmt.Errorf("benchmark base_urls[%d]: %w", i, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("benchmark base_urls[%d]: must be an http(s) URL, got %q", i, raw)
+		}
+		if seenHosts[host] {
+			return fmt.Errorf("benchmark base_urls[%d]: duplicate %q", i, host)
+		}
+		seenHosts[host] = true
+		cfg.Benchmark.BaseUrls[i] = host
+	}
 
-	if cfg.Benchmark.Concurrency <= 0 {
-		cfg.Benchmark.Concurrency = DefaultConfig.Benchmark.Concurrency
+	cfg.Benchmark.Concurrency = cfg.Benchmark.ConcurrencyRaw.resolve(runtime.NumCPU())
+
+	if cfg.Benchmark.RequestsPerEndpoint < 0 {
+		return errors.New("benchmark requests_per_endpoint must be >= 0")
+	}
+	if cfg.Benchmark.RequestsPerEndpoint > 0 && strings.TrimSpace(cfg.Benchmark.DurationPerEndpointRaw) != "" {
+		return errors.New("benchmark requests_per_endpoint and duration_per_endpoint are mutually exclusive")
+	}
+
+	if err := validateTotalDuration(&cfg.Benchmark); err != nil {
+		return err
 	}
 
 	var err error
@@ -194,6 +267,10 @@ func applyDefaults(cfg *Config) error {
 		return err
 	}
 
+	if err = validateConnectTimeout(&cfg.Benchmark); err != nil {
+		return err
+	}
+
 	defaultSampleRate, _ := parsePercent(DefaultConfig.Benchmark.SampleRateRaw, DefaultConfig.Benchmark.SampleRateRaw)
 	sampleRate, err := parsePercent(cfg.Benchmark.SampleRateRaw, DefaultConfig.Benchmark.SampleRateRaw)
 	if err != nil {
@@ -218,6 +295,12 @@ func applyDefaults(cfg *Config) error {
 		cfg.Benchmark.ServerCooldown = cooldown
 	}
 
+	if cfg.Benchmark.CooldownUntilIdle != nil {
+		if err = applyCooldownIdleDefaults(cfg.Benchmark.CooldownUntilIdle); err != nil {
+			return err
+		}
+	}
+
 	cfg.Benchmark.WarmupDuration, err = validateDuration(
 		&cfg.Benchmark.WarmupDurationRaw, DefaultConfig.Benchmark.WarmupDurationRaw,
 		"benchmark warmup_duration", true,
@@ -234,10 +317,104 @@ func applyDefaults(cfg *Config) error {
 		return err
 	}
 
+	if cfg.Benchmark.WarmupUntilStable != nil {
+		if err = applyWarmupStableDefaults(cfg.Benchmark.WarmupUntilStable); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(cfg.Benchmark.AbortBelowRaw) != "" {
+		abortBelow, abortErr := parsePercent(cfg.Benchmark.AbortBelowRaw, "0%")
+		if abortErr != nil {
+			return fmt.Errorf("benchmark abort_below_success_rate: %w", abortErr)
+		}
+		if abortBelow > 100 {
+			return errors.New("benchmark abort_below_success_rate must be <= 100%")
+		}
+		cfg.Benchmark.AbortBelow = abortBelow / 100
+	}
+
+	if strings.TrimSpace(cfg.Benchmark.DiscardFirstRaw) != "" {
+		discard, discardErr := parsePercent(cfg.Benchmark.DiscardFirstRaw, "0%")
+		if discardErr != nil {
+			return fmt.Errorf("benchmark discard_first_percent: %w", discardErr)
+		}
+		if discard >= 100 {
+			return errors.New("benchmark discard_first_percent must be below 100%")
+		}
+		cfg.Benchmark.DiscardFirst = discard / 100
+	}
+
+	cfg.Benchmark.SeedFlow = strings.TrimSpace(cfg.Benchmark.SeedFlow)
+
+	if cfg.Benchmark.MaxBodyBytes < 0 {
+		return errors.New("benchmark max_body_bytes must be > 0")
+	}
+	if cfg.Benchmark.MaxBodyBytes == 0 {
+		cfg.Benchmark.MaxBodyBytes = DefaultConfig.Benchmark.MaxBodyBytes
+	}
+
+	if cfg.Benchmark.MaxSamples < 0 {
+		return errors.New("benchmark max_samples must be >= 0")
+	}
+	if cfg.Benchmark.MaxSamples > 0 && cfg.Benchmark.MaxSamples < minMaxSamples {
+		return fmt.Errorf("benchmark max_samples must be 0 (unbounded) or >= %d", minMaxSamples)
+	}
+	switch cfg.Benchmark.Estimator = strings.ToLower(strings.TrimSpace(cfg.Benchmark.Estimator)); cfg.Benchmark.Estimator {
+	case "":
+		cfg.Benchmark.Estimator = EstimatorExact
+	case EstimatorExact:
+	case EstimatorTDigest:
+		if cfg.Benchmark.MaxSamples == 0 {
+			cfg.Benchmark.MaxSamples = DefaultDigestSamples
+		}
+	default:
+		return fmt.Errorf("benchmark estimator must be %q or %q, got %q", EstimatorExact, EstimatorTDigest, cfg.Benchmark.Estimator)
+	}
+
+	if cfg.Benchmark.FailFast < 0 {
+		return errors.New("benchmark fail_fast must be >= 0 (0 disables)")
+	}
+	if cfg.Benchmark.ServerRetries < 0 {
+		return errors.New("benchmark server_retries must be >= 0 (0 disables)")
+	}
+	cfg.Benchmark.FollowRedirects = cfg.Benchmark.FollowRedirectsRaw == nil || *cfg.Benchmark.FollowRedirectsRaw
+	cfg.Benchmark.CacheHeader = strings.TrimSpace(cfg.Benchmark.CacheHeader)
+	if cfg.Benchmark.CacheHeader == "" {
+		cfg.Benchmark.CacheHeader = DefaultConfig.Benchmark.CacheHeader
+	}
+
+	cfg.Benchmark.UserAgent = strings.TrimSpace(cfg.Benchmark.UserAgent)
+	cfg.Benchmark.DefaultAccept = strings.TrimSpace(cfg.Benchmark.DefaultAccept)
+	if strings.ContainsAny(cfg.Benchmark.UserAgent+cfg.Benchmark.DefaultAccept, "\r\n") {
+		return errors.New("benchmark user_agent and default_accept must be single-line header values")
+	}
+
+	if cfg.Benchmark.MaxConns < 0 || cfg.Benchmark.MaxConns > MaxInFlightCeiling {
+		return fmt.Errorf("benchmark max_conns must be between 0 (one per worker) and %d", MaxInFlightCeiling)
+	}
+	if cfg.Benchmark.MaxTotalConns < 0 || cfg.Benchmark.MaxTotalConns > MaxInFlightCeiling {
+		return fmt.Errorf("benchmark max_total_conns must be between 0 (unlimited) and %d", MaxInFlightCeiling)
+	}
+
 	err = applyLoadDefaults(&cfg.Benchmark.Load)
 	if err != nil {
 		return err
 	}
+	if cfg.Benchmark.MixedMode && cfg.Benchmark.Load.Mode != LoadModeClosed {
+		return errors.New(`benchmark mixed_mode requires load mode "closed"`)
+	}
+	if cfg.Benchmark.RequestsPerEndpoint > 0 {
+		if cfg.Benchmark.Load.Mode != LoadModeClosed {
+			return errors.New(`benchmark requests_per_endpoint requires load mode "closed"`)
+		}
+		if cfg.Benchmark.MixedMode {
+			return errors.New("benchmark requests_per_endpoint cannot be combined with mixed_mode")
+		}
+	}
+	if err := validateReplay(&cfg.Benchmark); err != nil {
+		return err
+	}
 
 	if cfg.Container.CpuLimit <= 0 {
 		cfg.Container.CpuLimit = DefaultConfig.Container.CpuLimit
@@ -251,22 +428,124 @@ func applyDefaults(cfg *Config) error {
 		return fmt.Errorf("container memory_limit: %w", err)
 	}
 	cfg.Container.MemoryLimit = normalizedMemory
+	if cfg.Container.ReadyExec != nil && (len(cfg.Container.ReadyExec) == 0 || strings.TrimSpace(cfg.Container.ReadyExec[0]) == "") {
+		return errors.New("container ready_exec must be a non-empty command")
+	}
 
-	if len(cfg.Endpoints) == 0 {
+	cfg.Database.ResetPath = strings.TrimSpace(cfg.Database.ResetPath)
+	if cfg.Database.ResetPath == "" {
+		cfg.Database.ResetPath = DefaultConfig.Database.ResetPath
+	}
+	if !strings.HasPrefix(cfg.Database.ResetPath, "/") {
+		return fmt.Errorf("database reset_path must start with /, got %q", cfg.Database.ResetPath)
+	}
+	if !strings.Contains(cfg.Database.ResetPath, "{database}") {
+		return fmt.Errorf("database reset_path %q must contain {database}", cfg.Database.ResetPath)
+	}
+
+	if len(cfg.Endpoints) == 0 && cfg.Benchmark.ReplayFile == "" {
 		return errors.New("no endpoints defined")
 	}
 
 	for name := range cfg.Endpoints {
 		endpoint := cfg.Endpoints[name]
+		if err := endpoint.loadExpectedBodies(testFilesDir(cfg.Dir)); err != nil {
+			return fmt.Errorf("endpoint %q: %w", name, err)
+		}
 		if err := applyEndpointDefaults(name, &endpoint); err != nil {
 			return fmt.Errorf("endpoint %q: %w", name, err)
 		}
+		if err := validateExcludedDatabases(&endpoint, cfg.Databases); err != nil {
+			return fmt.Errorf("endpoint %q: %w", name, err)
+		}
+		if endpoint.SSE != nil && endpoint.SSE.MaxDuration >= cfg.Benchmark.RequestTimeout {
+			return fmt.Errorf("endpoint %q: sse.max_duration %s must be below request_timeout %s",
+				name, endpoint.SSE.MaxDuration, cfg.Benchmark.RequestTimeout)
+		}
 		cfg.Endpoints[name] = endpoint
 	}
 
 	return nil
 }
 
+func validateExcludedDatabases(e *EndpointConfig, databases []string) error {
+	if len(e.ExcludeDatabases) == 0 {
+		return nil
+	}
+	if !e.PerDatabase {
+		return errors.New("exclude_databases requires per_database")
+	}
+	for _, db := range e.ExcludeDatabases {
+		if !slices.Contains(databases, db) {
+			return fmt.Errorf("exclude_databases: unknown database %q (have %s)", db, strings.Join(databases, ", "))
+		}
+	}
+	if len(databasesFor(e, databases)) == 0 {
+		return errors.New("exclude_databases excludes every database")
+	}
+	return nil
+}
+
+func applyWarmupStableDefaults(stable *WarmupStableConfig) error {
+	var err error
+	stable.Window, err = validateDuration(&stable.WindowRaw, DefaultStableWindow, "benchmark warmup_until_stable window", false)
+	if err != nil {
+		return err
+	}
+	stable.MaxDuration, err = validateDuration(
+		&stable.MaxDurationRaw, DefaultStableMaxDuration, "benchmark warmup_until_stable max_duration", false,
+	)
+	if err != nil {
+		return err
+	}
+	if stable.MaxDuration < stable.Window {
+		return errors.New("benchmark warmup_until_stable max_duration must be >= window")
+	}
+
+	threshold, err := parsePercent(stable.ThresholdRaw, DefaultStableThreshold)
+	if err != nil {
+		return fmt.Errorf("benchmark warmup_until_stable threshold: %w", err)
+	}
+	if threshold <= 0 || threshold > 100 {
+		return errors.New("benchmark warmup_until_stable threshold must be in (0%, 100%]")
+	}
+	if strings.TrimSpace(stable.ThresholdRaw) == "" {
+		stable.ThresholdRaw = DefaultStableThreshold
+	}
+	stable.Threshold = threshold / 100
+
+	if stable.Windows == 0 {
+		stable.Windows = DefaultStableWindows
+	}
+	if stable.Windows < 2 {
+		return errors.New("benchmark warmup_until_stable windows must be >= 2")
+	}
+	return nil
+}
+
+func applyCooldownIdleDefaults(idle *CooldownIdleConfig) error {
+	var err error
+	idle.MaxDuration, err = validateDuration(
+		&idle.MaxDurationRaw, DefaultIdleMaxDuration, "benchmark cooldown_until_idle max_duration", false,
+	)
+	if err != nil {
+		return err
+	}
+
+	threshold, err := parsePercent(idle.CpuThresholdRaw, DefaultIdleCpuThreshold)
+	if err != nil {
+		return fmt.Errorf("benchmark cooldown_until_idle cpu_threshold: %w", err)
+	}
+	if threshold <= 0 {
+		return errors.New("benchmark cooldown_until_idle cpu_threshold must be > 0%")
+	}
+	if strings.TrimSpace(idle.CpuThresholdRaw) == "" {
+		idle.CpuThresholdRaw = DefaultIdleCpuThreshold
+	}
+	idle.CpuThreshold = threshold
+	return nil
+}
+
 // applyLoadDefaults validates the load model selection. Closed mode must not
 // carry open-mode knobs — a rate set under closed mode is an operator mistake
 // we surface, not a silent no-op.
@@ -321,6 +600,46 @@ func applyLoadDefaults(load *LoadConfig) error {
 	return nil
 }
 
+// validateTotalDuration parses benchmark.total_duration. It replaces the
+// per-endpoint window, so it can't be combined with the other run budgets or
+// with modes whose window isn't split per endpoint (a replay, load stages).
+// Its split is checked once the endpoint set is final, in ApplyTotalDuration.
+func validateTotalDuration(b *BenchmarkConfig) error {
+	if strings.TrimSpace(b.TotalDurationRaw) == "" {
+		return nil
+	}
+	switch {
+	case strings.TrimSpace(b.DurationPerEndpointRaw) != "":
+		return errors.New("benchmark total_duration and duration_per_endpoint are mutually exclusive")
+	case b.RequestsPerEndpoint > 0:
+		return errors.New("benchmark total_duration and requests_per_endpoint are mutually exclusive")
+	case strings.TrimSpace(b.ReplayFile) != "":
+		return errors.New("benchmark total_duration is not supported with replay_file")
+	case len(b.Load.Stages) > 0:
+		return errors.New("benchmark total_duration is not supported with load stages, which set their own duration")
+	}
+	var err error
+	b.TotalDuration, err = validateDuration(&b.TotalDurationRaw, "", "benchmark total_duration", false)
+	return err
+}
+
+// validateConnectTimeout parses connect_timeout, which only means something
+// below request_timeout: the overall cap would otherwise fire first.
+func validateConnectTimeout(b *BenchmarkConfig) error {
+	if strings.TrimSpace(b.ConnectTimeoutRaw) == "" {
+		return nil
+	}
+	var err error
+	b.ConnectTimeout, err = validateDuration(&b.ConnectTimeoutRaw, "", "benchmark connect_timeout", false)
+	if err != nil {
+		return err
+	}
+	if b.ConnectTimeout >= b.RequestTimeout {
+		return fmt.Errorf("benchmark connect_timeout %s must be below request_timeout %s", b.ConnectTimeout, b.RequestTimeout)
+	}
+	return nil
+}
+
 // validateDuration parses a duration field, applies its default, and validates the result.
 // When allowZero is false, the duration must be > 0; when true, it must be >= 0.
 func validateDuration(raw *string, defaultRaw, fieldName string, allowZero bool) (time.Duration, error) {
@@ -369,12 +688,125 @@ func applyEndpointDefaults(name string, e *EndpointConfig) error {
 		return fmt.Errorf("invalid method %q", e.Method)
 	}
 
-	if e.Expect.Status == 0 {
-		e.Expect.Status = DefaultStatus
+	if e.Weight < 0 {
+		return errors.New("weight must be >= 0")
 	}
 
-	if e.Expect.Status < 100 || e.Expect.Status > 599 {
-		return errors.New("expect.status must be between 100 and 599")
+	for i, tag := range e.Tags {
+		e.Tags[i] = strings.TrimSpace(tag)
+		if e.Tags[i] == "" {
+			return fmt.Errorf("tags[%d] must not be empty", i)
+		}
+	}
+
+	for key := range e.PathVars {
+		if !placeholderName.MatchString(key) {
+			return fmt.Errorf("path_vars key %q must be a plain placeholder name", key)
+		}
+		if key == "database" {
+			return errors.New("path_vars cannot set {database}; use per_database")
+		}
+	}
+
+	for _, expected := range []struct {
+		raw   *string
+		value *time.Duration
+		name  string
+	}{
+		{&e.ExpectedAvgRaw, &e.ExpectedAvg, "expected_avg"},
+		{&e.ExpectedP99Raw, &e.ExpectedP99, "expected_p99"},
+	} {
+		if strings.TrimSpace(*expected.raw) == "" {
+			continue
+		}
+		d, err := validateDuration(expected.raw, "", expected.name, false)
+		if err != nil {
+			return err
+		}
+		*expected.value = d
+	}
+
+	if e.ChunkedRequest && e.Body == nil && len(e.FormData) == 0 && e.File == "" && !e.variationsHaveBody() {
+		return errors.New("chunked_request requires a body, form_data or file")
+	}
+	if e.Dynamic {
+		switch {
+		case e.Sequence != nil:
+			return errors.New("dynamic is not supported on sequence steps; use sequence vars")
+		case e.File != "":
+			return errors.New("dynamic does not support file uploads")
+		case e.Body == nil && len(e.FormData) == 0 && !e.variationsHaveBody():
+			return errors.New("dynamic requires a body or form_data")
+		}
+	}
+
+	e.ContentType = strings.TrimSpace(e.ContentType)
+	if e.ContentType != "" {
+		switch {
+		case strings.ContainsAny(e.ContentType, "\r\n"):
+			return errors.New("content_type must not contain line breaks")
+		case e.Sequence != nil:
+			return errors.New("content_type is not supported on sequence steps")
+		case e.File != "" || len(e.FormData) > 0:
+			return errors.New("content_type applies to a JSON body; form_data and file set their own")
+		case e.Body == nil && !e.variationsHaveBody():
+			return errors.New("content_type requires a body")
+		}
+	}
+
+	if err := e.validateSSE(); err != nil {
+		return err
+	}
+	if err := e.validateHead(); err != nil {
+		return err
+	}
+
+	if e.Expect.Status.IsZero() {
+		e.Expect.Status = ExactStatus(DefaultStatus)
+	}
+	if err := e.Expect.Status.validate(); err != nil {
+		return err
+	}
+	if err := e.Expect.validateValidJSON(); err != nil {
+		return err
+	}
+	if err := e.Expect.validateJSONPath(); err != nil {
+		return err
+	}
+	if err := e.Expect.validateOneOf(); err != nil {
+		return err
+	}
+	if len(e.Expect.JSONPath) > 0 && e.Sequence != nil {
+		return errors.New("expect.json_path is not supported on sequence steps")
+	}
+	if strings.TrimSpace(e.Expect.SuccessWhen) != "" && e.Sequence != nil {
+		return errors.New("expect.success_when is not supported on sequence steps")
+	}
+	if err := e.Expect.validateAbsentHeaders(); err != nil {
+		return err
+	}
+	if len(e.Expect.AbsentHeaders) > 0 && e.Sequence != nil {
+		return errors.New("expect.absent_headers is not supported on sequence steps")
+	}
+	for i, variation := range e.Variations {
+		if variation.Expect == nil {
+			continue
+		}
+		if err := variation.Expect.Status.validate(); err != nil {
+			return fmt.Errorf("variation %d: %w", i, err)
+		}
+		if err := variation.Expect.validateValidJSON(); err != nil {
+			return fmt.Errorf("variation %d: %w", i, err)
+		}
+		if err := variation.Expect.validateJSONPath(); err != nil {
+			return fmt.Errorf("variation %d: %w", i, err)
+		}
+		if err := variation.Expect.validateOneOf(); err != nil {
+			return fmt.Errorf("variation %d: %w", i, err)
+		}
+		if err := variation.Expect.validateAbsentHeaders(); err != nil {
+			return fmt.Errorf("variation %d: %w", i, err)
+		}
 	}
 
 	if e.Sequence != nil {
@@ -393,3 +825,182 @@ func applyEndpointDefaults(name string, e *EndpointConfig) error {
 
 	return nil
 }
+
+// validateSSE checks type and sse, and fills the sse defaults. An event
+// stream is a GET whose body is read event by event, so request bodies,
+// sequences and whole-body expectations don't apply to it.
+func (e *EndpointConfig) validateSSE() error {
+	switch e.Type {
+	case "":
+		if e.SSE != nil {
+			return fmt.Errorf("sse requires type %q", EndpointTypeSSE)
+		}
+		return nil
+	case EndpointTypeSSE:
+	default:
+		return fmt.Errorf("invalid type %q (want %q or none)", e.Type, EndpointTypeSSE)
+	}
+
+	switch {
+	case e.Method != "GET":
+		return fmt.Errorf("type %q requires GET, got %s", EndpointTypeSSE, e.Method)
+	case e.Sequence != nil:
+		return fmt.Errorf("type %q is not supported on sequence steps", EndpointTypeSSE)
+	case e.Body != nil || len(e.FormData) > 0 || e.File != "" || e.variationsHaveBody():
+		return fmt.Errorf("type %q does not send a request body", EndpointTypeSSE)
+	case e.Expect.checksBody():
+		return fmt.Errorf("type %q streams events; expect only status and headers", EndpointTypeSSE)
+	}
+	for i := range e.Variations {
+		if v := e.Variations[i].Expect; v != nil && v.checksBody() {
+			return fmt.Errorf("variation %d: type %q streams events; expect only status and headers", i, EndpointTypeSSE)
+		}
+	}
+
+	if e.SSE == nil {
+		e.SSE = &SSEConfig{}
+	}
+	if e.SSE.Events < 0 {
+		return errors.New("sse.events must be >= 0")
+	}
+	if e.SSE.Events == 0 {
+		e.SSE.Events = DefaultSSEEvents
+	}
+	if strings.TrimSpace(e.SSE.MaxDurationRaw) != "" {
+		d, err := validateDuration(&e.SSE.MaxDurationRaw, "", "sse.max_duration", false)
+		if err != nil {
+			return err
+		}
+		e.SSE.MaxDuration = d
+	}
+	return nil
+}
+
+// loadExpectedBodies fills expect.body from expect.body_file on the endpoint
+// and its variations, before applyEndpointDefaults validates the expectations,
+// so a file-loaded body is checked exactly like an inline one.
+func (e *EndpointConfig) loadExpectedBodies(filesDir string) error {
+	if err := e.Expect.loadBodyFile(filesDir); err != nil {
+		return err
+	}
+	for i := range e.Variations {
+		if v := e.Variations[i].Expect; v != nil {
+			if err := v.loadBodyFile(filesDir); err != nil {
+				return fmt.Errorf("variation %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadBodyFile parses expect.body_file into Body. The file is read through
+// loadFile, so it must stay inside the fixtures directory.
+func (e *ExpectConfig) loadBodyFile(filesDir string) error {
+	if strings.TrimSpace(e.BodyFile) == "" {
+		return nil
+	}
+	if e.Body != nil {
+		return errors.New("expect.body and expect.body_file are mutually exclusive")
+	}
+	file, err := loadFile(filesDir, e.BodyFile)
+	if err != nil {
+		return fmt.Errorf("expect.body_file: %w", err)
+	}
+	if err := json.Unmarshal(file.Content, &e.Body); err != nil {
+		return fmt.Errorf("expect.body_file %s: invalid JSON: %w", file.Filename, err)
+	}
+	if e.Body == nil {
+		return fmt.Errorf("expect.body_file %s: holds null, which expects nothing", file.Filename)
+	}
+	return nil
+}
+
+// validateHead rejects body expectations on a HEAD endpoint: the response
+// never has a body, so they could only fail. A success_when that tests just
+// the status still applies. OPTIONS responses may carry a body and are
+// validated like any other method.
+func (e *EndpointConfig) validateHead() error {
+	if e.Method != "HEAD" {
+		return nil
+	}
+	if e.Expect.readsBody() {
+		return errors.New("HEAD responses have no body; expect only status and headers")
+	}
+	for i := range e.Variations {
+		if v := e.Variations[i].Expect; v != nil && v.readsBody() {
+			return fmt.Errorf("variation %d: HEAD responses have no body; expect only status and headers", i)
+		}
+	}
+	return nil
+}
+
+// readsBody is checksBody without a success_when that only tests the status.
+// A success_when that doesn't parse counts as not reading it; resolve
+// reports the parse error.
+func (e *ExpectConfig) readsBody() bool {
+	if e.Body != nil || e.Text != "" || e.ValidJSON || len(e.JSONPath) > 0 {
+		return true
+	}
+	if strings.TrimSpace(e.SuccessWhen) == "" {
+		return false
+	}
+	rule, err := NewSuccessRule(e.SuccessWhen)
+	return err == nil && rule.NeedsBody()
+}
+
+// checksBody reports whether the expectation reads the response body.
+func (e *ExpectConfig) checksBody() bool {
+	return e.Body != nil || e.Text != "" || e.ValidJSON || len(e.JSONPath) > 0 || strings.TrimSpace(e.SuccessWhen) != ""
+}
+
+func (e *EndpointConfig) variationsHaveBody() bool {
+	for i := range e.Variations {
+		v := &e.Variations[i]
+		if v.Body != nil || len(v.FormData) > 0 || v.File != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateValidJSON rejects valid_json alongside a body or text expectation:
+// those already validate the body, so the combination is a config mistake.
+func (e *ExpectConfig) validateValidJSON() error {
+	if !e.ValidJSON {
+		return nil
+	}
+	if e.Body != nil {
+		return errors.New("expect.valid_json cannot be combined with expect.body")
+	}
+	if e.Text != "" {
+		return errors.New("expect.valid_json cannot be combined with expect.text")
+	}
+	return nil
+}
+
+// validateJSONPath rejects json_path alongside a text expectation, which
+// already pins the body to something that need not be JSON.
+func (e *ExpectConfig) validateJSONPath() error {
+	if len(e.JSONPath) > 0 && e.Text != "" {
+		return errors.New("expect.json_path cannot be combined with expect.text")
+	}
+	return nil
+}
+
+// validateAbsentHeaders trims expect.absent_headers and rejects blank names
+// and names that expect.headers requires to be present.
+func (e *ExpectConfig) validateAbsentHeaders() error {
+	for i, name := range e.AbsentHeaders {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("expect.absent_headers entries must not be empty")
+		}
+		for expected := range e.Headers {
+			if strings.EqualFold(strings.TrimSpace(expected), name) {
+				return fmt.Errorf("expect.absent_headers: %s is also listed in expect.headers", name)
+			}
+		}
+		e.AbsentHeaders[i] = name
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -157,3 +158,77 @@ func TestApplyLoadDefaults(t *testing.T) {
 		})
 	}
 }
+
+// Upload fixtures resolve relative to the config file's directory, not the
+// working directory, so an alternate --config profile finds its own files.
+func TestLoadResolvesFilesRelativeToConfig(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileDir := filepath.Join(root, "profiles")
+	filesDir := filepath.Join(root, "contract", "test-files")
+	for _, dir := range []string{profileDir, filesDir} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(filesDir, "upload.txt"), []byte("fixture"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(profileDir, "alt.json")
+	cfgJSON := `{"endpoints": {"upload": {"route": "POST /upload", "file": "upload.txt"}}}`
+	if err := os.WriteFile(path, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, target, err := LoadTarget(path, "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	upload := target.Testcases[0].FileUpload
+	if upload == nil || string(upload.Content) != "fixture" {
+		t.Fatalf("file not resolved relative to the config directory: %+v", upload)
+	}
+}
+
+func TestLoadMissingConfigFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing.json")
+	_, _, err := LoadTarget(path, "http://localhost:8080")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") || !strings.Contains(err.Error(), path) {
+		t.Errorf("missing config: got %v, want a does-not-exist error naming the path", err)
+	}
+}
+
+func TestConcurrencyPerCPU(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		spec ConcurrencySpec
+		cpus int
+		want int
+	}{
+		{ConcurrencySpec{PerCPU: 2}, 8, 16},
+		{ConcurrencySpec{PerCPU: 0.5}, 3, 2},
+		{ConcurrencySpec{PerCPU: 0.1}, 2, 1},
+		{ConcurrencySpec{Count: 20}, 8, 20},
+		{ConcurrencySpec{}, 8, DefaultConfig.Benchmark.Concurrency},
+	}
+	for _, tc := range cases {
+		if got := tc.spec.resolve(tc.cpus); got != tc.want {
+			t.Errorf("%s on %d CPUs: got %d, want %d", tc.spec, tc.cpus, got, tc.want)
+		}
+	}
+
+	cfg, target, err := loadTestTarget(t, `{"benchmark": {"concurrency": "2x"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if want := 2 * runtime.NumCPU(); cfg.Benchmark.Concurrency != want || target.Concurrency != want {
+		t.Errorf("loaded 2x: got %d (server %d), want %d", cfg.Benchmark.Concurrency, target.Concurrency, want)
+	}
+	if cfg.Benchmark.ConcurrencyRaw.String() != "2x" {
+		t.Errorf("spec: got %q, want 2x", cfg.Benchmark.ConcurrencyRaw)
+	}
+}
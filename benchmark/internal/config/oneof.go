@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// OneOfKey marks an expected value that accepts any of several values:
+// {"status": {"$oneOf": ["active", "pending"]}}. A plain array in an
+// expectation still means that exact array.
+const OneOfKey = "$oneOf"
+
+// OneOf returns the acceptable values when v is a {"$oneOf": [...]} wrapper.
+func OneOf(v any) ([]any, bool) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return nil, false
+	}
+	options, ok := m[OneOfKey].([]any)
+	return options, ok
+}
+
+// validateOneOf walks an expected value and rejects a $oneOf that isn't the
+// only key of its object or doesn't hold a non-empty array, so a typo can't
+// quietly turn into a literal field match.
+func validateOneOf(v any) error {
+	switch val := v.(type) {
+	case map[string]any:
+		if raw, ok := val[OneOfKey]; ok {
+			options, isArray := raw.([]any)
+			switch {
+			case len(val) != 1:
+				return fmt.Errorf("%s must be the only key of its object", OneOfKey)
+			case !isArray || len(options) == 0:
+				return fmt.Errorf("%s needs a non-empty array of values", OneOfKey)
+			}
+			for _, option := range options {
+				if err := validateOneOf(option); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for _, key := range slices.Sorted(maps.Keys(val)) {
+			if err := validateOneOf(val[key]); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			if err := validateOneOf(item); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateOneOf checks the $oneOf wrappers in expect.body and expect.json_path.
+func (e *ExpectConfig) validateOneOf() error {
+	if err := validateOneOf(e.Body); err != nil {
+		return fmt.Errorf("expect.body: %w", err)
+	}
+	for _, path := range slices.Sorted(maps.Keys(e.JSONPath)) {
+		if err := validateOneOf(e.JSONPath[path]); err != nil {
+			return fmt.Errorf("expect.json_path %q: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// replayEntry is one line of a benchmark.replay_file trace (JSON lines):
+//
+//	{"method": "GET", "path": "/users?page=2", "headers": {"Authorization": "Bearer x"}}
+//	{"method": "POST", "path": "/users", "body": {"name": "a"}, "status": 201}
+//
+// method defaults to GET; body is sent as JSON (a string body as is); status
+// takes expect.status syntax and defaults to any 2xx. Blank lines are skipped.
+type replayEntry struct {
+	Method  string            `json:"method,omitempty"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+	Status  StatusMatcher     `json:"status,omitzero"`
+}
+
+// replayTraceMaxLine bounds one trace line; larger bodies belong in a file.
+const replayTraceMaxLine = 1 << 20
+
+// resolveReplayFile turns benchmark.replay_file (relative to the config's
+// directory) into testcases in trace order, one per line. A line's endpoint
+// is "METHOD path" without the query string, so results aggregate by route.
+func resolveReplayFile(cfg *Config) ([]*Testcase, error) {
+	path := cfg.Benchmark.ReplayFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.Dir, path)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from the user's own config
+	if err != nil {
+		return nil, fmt.Errorf("benchmark replay_file: %w", err)
+	}
+
+	var testcases []*Testcase
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), replayTraceMaxLine)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		tc, err := replayTestcase(cfg.Benchmark.BaseUrl, line, lineNum)
+		if err != nil {
+			return nil, fmt.Errorf("benchmark replay_file %s line %d: %w", cfg.Benchmark.ReplayFile, lineNum, err)
+		}
+		testcases = append(testcases, tc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("benchmark replay_file %s: %w", cfg.Benchmark.ReplayFile, err)
+	}
+	if len(testcases) == 0 {
+		return nil, fmt.Errorf("benchmark replay_file %s has no requests", cfg.Benchmark.ReplayFile)
+	}
+	return testcases, nil
+}
+
+func replayTestcase(baseUrl string, line []byte, lineNum int) (*Testcase, error) {
+	var entry replayEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(entry.Path, "/") {
+		return nil, fmt.Errorf("path must start with /, got %q", entry.Path)
+	}
+	method := strings.ToUpper(strings.TrimSpace(entry.Method))
+	if method == "" {
+		method = "GET"
+	}
+	status := entry.Status
+	if status.IsZero() {
+		status = StatusMatcher{Class: 2}
+	}
+	if err := status.validate(); err != nil {
+		return nil, err
+	}
+
+	parsed, err := url.Parse(entry.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	requestURI, err := buildRequestURI(baseUrl, entry.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	route := method + " " + parsed.Path
+	tc := &Testcase{
+		EndpointName:   route,
+		Name:           fmt.Sprintf("line %d", lineNum),
+		Path:           parsed.Path,
+		RequestURI:     requestURI,
+		Method:         method,
+		Headers:        canonicalizeHeaders(entry.Headers),
+		ExpectedStatus: status,
+		Weight:         1,
+	}
+	if entry.Body != nil {
+		tc.RequestType = RequestTypeJSON
+		if tc.Body, err = serializeBody(entry.Body); err != nil {
+			return nil, err
+		}
+	}
+	return tc, nil
+}
+
+// validateReplay rejects the settings a trace replay can't honor: it is one
+// closed-loop window over the file in order, so there are no weights, no
+// arrival schedule and no per-endpoint request budget.
+func validateReplay(b *BenchmarkConfig) error {
+	b.ReplayFile = strings.TrimSpace(b.ReplayFile)
+	if b.ReplayFile == "" {
+		return nil
+	}
+	switch {
+	case b.MixedMode:
+		return errors.New("benchmark replay_file already interleaves its requests; drop mixed_mode")
+	case b.Load.Mode != LoadModeClosed:
+		return errors.New(`benchmark replay_file requires load mode "closed"`)
+	case b.RequestsPerEndpoint > 0:
+		return errors.New("benchmark replay_file runs for duration_per_endpoint; requests_per_endpoint is not supported")
+	}
+	return nil
+}
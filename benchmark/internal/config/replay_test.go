@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveReplayFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	trace := `{"method": "get", "path": "/users?page=2", "headers": {"authorization": "Bearer x"}}
+
+{"method": "POST", "path": "/users", "body": {"name": "a"}, "status": 201}
+{"path": "/users?page=3"}
+`
+	if err := os.WriteFile(filepath.Join(dir, "trace.jsonl"), []byte(trace), 0o600); err != nil {
+		t.Fatalf("write trace: %v", err)
+	}
+	cfgPath := filepath.Join(dir, "config.json")
+	cfgJSON := `{
+		"benchmark": {"replay_file": "trace.jsonl"},
+		"endpoints": {"ignored": {"route": "GET /ignored"}}
+	}`
+	if err := os.WriteFile(cfgPath, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, server, err := LoadTarget(cfgPath, "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+
+	if !server.Replay || len(server.Testcases) != 3 {
+		t.Fatalf("got replay %v with %d testcases, want the trace's 3 lines", server.Replay, len(server.Testcases))
+	}
+	first, second, third := server.Testcases[0], server.Testcases[1], server.Testcases[2]
+	if first.EndpointName != "GET /users" || first.RequestURI != "/users?page=2" || first.Headers["Authorization"] != "Bearer x" {
+		t.Errorf("line 1: got %s %s %v", first.EndpointName, first.RequestURI, first.Headers)
+	}
+	if !first.ExpectedStatus.Matches(204) || first.ExpectedStatus.Matches(404) {
+		t.Errorf("default status: got %s, want 2xx", first.ExpectedStatus)
+	}
+	if second.EndpointName != "POST /users" || second.Body != `{"name":"a"}` || !second.ExpectedStatus.Matches(201) || second.Name != "line 3" {
+		t.Errorf("line 3: got %s %q status %s name %q", second.EndpointName, second.Body, second.ExpectedStatus, second.Name)
+	}
+	if third.EndpointName != "GET /users" || third.RequestURI != "/users?page=3" {
+		t.Errorf("line 4: method should default to GET, got %s %s", third.EndpointName, third.RequestURI)
+	}
+}
+
+func TestReplayFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, trace, benchmark, wantErr string
+	}{
+		{"bad json", "{\"path\": \"/a\"}\n{oops\n", "", "trace.jsonl line 2"},
+		{"relative path", `{"path": "users"}`, "", "path must start with /"},
+		{"empty", "\n\n", "", "has no requests"},
+		{"mixed mode", `{"path": "/a"}`, `"mixed_mode": true,`, "drop mixed_mode"},
+		{"request budget", `{"path": "/a"}`, `"requests_per_endpoint": 10,`, "requests_per_endpoint is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "trace.jsonl"), []byte(tt.trace), 0o600); err != nil {
+				t.Fatalf("write trace: %v", err)
+			}
+			cfgPath := filepath.Join(dir, "config.json")
+			cfgJSON := `{"benchmark": {` + tt.benchmark + `"replay_file": "trace.jsonl"}}`
+			if err := os.WriteFile(cfgPath, []byte(cfgJSON), 0o600); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+			_, _, err := LoadTarget(cfgPath, "http://localhost:8080")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -19,14 +20,7 @@ import (
 
 func resolve(cfg *Config, entries []roster.Entry) ([]*ResolvedServer, error) {
 	var allTestcases []*Testcase
-	order := cfg.EndpointOrder
-	if len(order) == 0 {
-		order = make([]string, 0, len(cfg.Endpoints))
-		for name := range cfg.Endpoints {
-			order = append(order, name)
-		}
-		slices.Sort(order)
-	}
+	order := endpointOrder(cfg)
 
 	for _, endpointName := range order {
 		endpoint, ok := cfg.Endpoints[endpointName]
@@ -36,40 +30,152 @@ func resolve(cfg *Config, entries []roster.Entry) ([]*ResolvedServer, error) {
 		if endpoint.Sequence != nil {
 			continue
 		}
-		testcases, err := resolveEndpoint(cfg.Benchmark.BaseUrl, cfg.Databases, endpointName, &endpoint)
+		testcases, err := resolveEndpoint(cfg.Benchmark.BaseUrl, cfg.Databases, testFilesDir(cfg.Dir), endpointName, &endpoint)
 		if err != nil {
 			return nil, err
 		}
 		allTestcases = append(allTestcases, testcases...)
 	}
+	cfg.Warnings = append(cfg.Warnings, queryConflictWarnings(allTestcases)...)
+	if cfg.Benchmark.ReplayFile != "" {
+		// The trace replaces the standalone endpoints; sequences still run.
+		var err error
+		if allTestcases, err = resolveReplayFile(cfg); err != nil {
+			return nil, err
+		}
+	}
 
-	sequences := resolveSequences(cfg, order)
+	sequences, err := resolveSequences(cfg, order)
+	if err != nil {
+		return nil, err
+	}
+	applyRequestDefaults(&cfg.Benchmark, allTestcases, sequences)
+	sequences, seeds, err := splitSeedSequences(sequences, cfg.Benchmark.SeedFlow)
+	if err != nil {
+		return nil, err
+	}
 
 	servers := make([]*ResolvedServer, 0, len(entries))
 	for _, entry := range entries {
+		cpuLimit, memoryLimit, err := containerLimits(&cfg.Container, entry)
+		if err != nil {
+			return nil, err
+		}
 		servers = append(servers, &ResolvedServer{
 			Name:                entry.Name,
 			ImageName:           entry.Image,
 			Port:                entry.Port,
 			BaseUrl:             cfg.Benchmark.BaseUrl,
 			RequestTimeout:      cfg.Benchmark.RequestTimeout,
-			CpuLimit:            cfg.Container.CpuLimit,
-			MemoryLimit:         cfg.Container.MemoryLimit,
+			ConnectTimeout:      cfg.Benchmark.ConnectTimeout,
+			CpuLimit:            cpuLimit,
+			MemoryLimit:         memoryLimit,
 			Concurrency:         cfg.Benchmark.Concurrency,
 			Load:                cfg.Benchmark.Load,
 			DurationPerEndpoint: cfg.Benchmark.DurationPerEndpoint,
+			RequestsPerEndpoint: cfg.Benchmark.RequestsPerEndpoint,
+			TotalDuration:       cfg.Benchmark.TotalDuration,
 			Testcases:           allTestcases,
 			EndpointOrder:       order,
 			WarmupDuration:      cfg.Benchmark.WarmupDuration,
 			WarmupPause:         cfg.Benchmark.WarmupPause,
+			WarmupStable:        cfg.Benchmark.WarmupUntilStable,
 			Sequences:           sequences,
+			SeedSequences:       seeds,
+			MixedMode:           cfg.Benchmark.MixedMode,
+			Replay:              cfg.Benchmark.ReplayFile != "",
+			MaxBodyBytes:        cfg.Benchmark.MaxBodyBytes,
+			MaxSamples:          cfg.Benchmark.MaxSamples,
+			Estimator:           cfg.Benchmark.Estimator,
+			FailFast:            cfg.Benchmark.FailFast,
+			ServerRetries:       cfg.Benchmark.ServerRetries,
+			MaxConns:            cfg.Benchmark.MaxConns,
+			MaxTotalConns:       cfg.Benchmark.MaxTotalConns,
+			FollowRedirects:     cfg.Benchmark.FollowRedirects,
+			CacheHeader:         cfg.Benchmark.CacheHeader,
+			DiscardFirst:        cfg.Benchmark.DiscardFirst,
+			ResetPath:           cfg.Database.ResetPath,
+			Tags:                entry.Tags,
+			Env:                 entry.Env,
+			Cmd:                 entry.Cmd,
+			ReadyExec:           cfg.Container.ReadyExec,
+			ExternalUrl:         entry.ExternalUrl,
 		})
 	}
 
 	return servers, nil
 }
 
-func resolveSequences(cfg *Config, order []string) []*ResolvedSequence {
+// endpointOrder is the config file's endpoint order, or the names sorted for
+// a Config built without one.
+func endpointOrder(cfg *Config) []string {
+	if len(cfg.EndpointOrder) > 0 {
+		return cfg.EndpointOrder
+	}
+	return slices.Sorted(maps.Keys(cfg.Endpoints))
+}
+
+// applyRequestDefaults adds benchmark.user_agent and benchmark.default_accept
+// to every testcase and sequence step (seed flows included) whose headers
+// don't already set them, so endpoint headers keep precedence and
+// BuildRequest's derived Accept only applies without a default_accept.
+func applyRequestDefaults(bench *BenchmarkConfig, testcases []*Testcase, sequences []*ResolvedSequence) {
+	if bench.UserAgent == "" && bench.DefaultAccept == "" {
+		return
+	}
+	for _, tc := range testcases {
+		tc.Headers = withRequestDefaults(tc.Headers, bench)
+	}
+	for _, seq := range sequences {
+		for _, ep := range seq.Endpoints {
+			ep.Headers = withRequestDefaults(ep.Headers, bench)
+		}
+	}
+}
+
+// withRequestDefaults returns a copy of headers with the run's defaults added
+// where no header of that name (in any case) is set.
+func withRequestDefaults(headers map[string]string, bench *BenchmarkConfig) map[string]string {
+	result := maps.Clone(headers)
+	for name, value := range map[string]string{"User-Agent": bench.UserAgent, "Accept": bench.DefaultAccept} {
+		if value == "" || hasHeader(headers, name) {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[name] = value
+	}
+	return result
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(strings.TrimSpace(k), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerLimits is the server's effective container limits: its manifest's
+// cpu/memory when set, otherwise the global container config.
+func containerLimits(global *ContainerConfig, entry roster.Entry) (float64, string, error) {
+	cpuLimit, memoryLimit := global.CpuLimit, global.MemoryLimit
+	if entry.CpuLimit > 0 {
+		cpuLimit = entry.CpuLimit
+	}
+	if strings.TrimSpace(entry.MemoryLimit) != "" {
+		normalized, err := normalizeMemoryLimit(entry.MemoryLimit)
+		if err != nil {
+			return 0, "", fmt.Errorf("server %q memory: %w", entry.Name, err)
+		}
+		memoryLimit = normalized
+	}
+	return cpuLimit, memoryLimit, nil
+}
+
+func resolveSequences(cfg *Config, order []string) ([]*ResolvedSequence, error) {
 	seqEndpoints := make(map[string][]string)
 	seqVars := make(map[string]map[string]VarConfig)
 
@@ -102,48 +208,268 @@ func resolveSequences(cfg *Config, order []string) []*ResolvedSequence {
 
 		databases := []string{""}
 		if perDatabase && len(cfg.Databases) > 0 {
-			databases = cfg.Databases
+			// A flow runs whole, so a database any step excludes is dropped for all of them.
+			databases = slices.DeleteFunc(slices.Clone(cfg.Databases), func(db string) bool {
+				return slices.ContainsFunc(endpointNames, func(name string) bool {
+					return slices.Contains(cfg.Endpoints[name].ExcludeDatabases, db)
+				})
+			})
 		}
 
-		for _, db := range databases {
-			seq := &ResolvedSequence{
-				Id:        seqId,
-				Database:  db,
-				Vars:      seqVars[seqId],
-				Endpoints: make([]*ResolvedSequenceEndpoint, 0, len(endpointNames)),
-			}
-
-			for _, name := range endpointNames {
-				ep := cfg.Endpoints[name]
-				path := ep.Path
-				if db != "" {
-					path = strings.ReplaceAll(path, "{database}", db)
+		var tags []string
+		for _, name := range endpointNames {
+			for _, tag := range cfg.Endpoints[name].Tags {
+				if !slices.Contains(tags, tag) {
+					tags = append(tags, tag)
 				}
+			}
+		}
 
-				resolved := &ResolvedSequenceEndpoint{
-					Name:           name,
-					Method:         ep.Method,
-					Path:           path,
-					Body:           ep.Body,
-					Headers:        ep.Headers,
-					ExpectedStatus: ep.Expect.Status,
-					ExpectedBody:   ep.Expect.Body,
+		weights, err := sequenceDatabaseWeights(cfg, seqId, endpointNames, perDatabase, databases)
+		if err != nil {
+			return nil, err
+		}
+		if len(weights) > 0 {
+			// One flow whose runs each pick a database; the template keeps
+			// {database} in its paths for display.
+			seq, err := resolveSequence(cfg, seqId, endpointNames, seqVars[seqId], tags, "")
+			if err != nil {
+				return nil, err
+			}
+			for _, db := range databases {
+				if weights[db] <= 0 {
+					continue
 				}
-				if ep.Sequence != nil {
-					resolved.Capture = ep.Sequence.Capture
+				choice, err := resolveSequence(cfg, seqId, endpointNames, seqVars[seqId], tags, db)
+				if err != nil {
+					return nil, err
 				}
-				seq.Endpoints = append(seq.Endpoints, resolved)
+				seq.DatabaseChoices = append(seq.DatabaseChoices, choice)
+				seq.DatabaseWeights = append(seq.DatabaseWeights, weights[db])
 			}
+			sequences = append(sequences, seq)
+			continue
+		}
 
+		for _, db := range databases {
+			seq, err := resolveSequence(cfg, seqId, endpointNames, seqVars[seqId], tags, db)
+			if err != nil {
+				return nil, err
+			}
 			sequences = append(sequences, seq)
 		}
 	}
 
-	return sequences
+	return sequences, nil
+}
+
+// resolveSequence resolves flow seqId's steps against one database ("" for
+// a flow that isn't per_database).
+func resolveSequence(cfg *Config, seqId string, endpointNames []string, vars map[string]VarConfig, tags []string, db string) (*ResolvedSequence, error) {
+	// Steps may reference flow vars and anything captured by an earlier step.
+	runtimeVars := make(map[string]bool)
+	for varName := range vars {
+		runtimeVars[varName] = true
+	}
+
+	seq := &ResolvedSequence{
+		Id:        seqId,
+		Database:  db,
+		Vars:      vars,
+		Endpoints: make([]*ResolvedSequenceEndpoint, 0, len(endpointNames)),
+		Tags:      tags,
+	}
+
+	for _, name := range endpointNames {
+		ep := cfg.Endpoints[name]
+		path, err := substitutePath(ep.Path, db, ep.PathVars, runtimeVars)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: %w", name, err)
+		}
+
+		resolved := &ResolvedSequenceEndpoint{
+			Name:           name,
+			Method:         ep.Method,
+			Path:           path,
+			Body:           ep.Body,
+			Headers:        ep.Headers,
+			ExpectedStatus: ep.Expect.Status,
+			ExpectedBody:   ep.Expect.Body,
+			ChunkedRequest: ep.ChunkedRequest,
+		}
+		if ep.Sequence != nil && ep.Sequence.When != "" {
+			when, err := resolveWhen(seq.Endpoints, ep.Sequence.When, vars)
+			if err != nil {
+				return nil, fmt.Errorf("sequence %q step %q: %w", seqId, name, err)
+			}
+			resolved.When = when
+		}
+		if undefined := undefinedSequenceRefs(&ep, runtimeVars); len(undefined) > 0 {
+			return nil, fmt.Errorf("sequence %q step %q: {%s} is neither a sequence var nor captured by an earlier step",
+				seqId, name, strings.Join(undefined, "}, {"))
+		}
+		if ep.Sequence != nil {
+			resolved.Capture = ep.Sequence.Capture
+			for varName, field := range ep.Sequence.Capture {
+				if !placeholderName.MatchString(varName) || strings.TrimSpace(field) == "" {
+					return nil, fmt.Errorf("sequence %q step %q: capture %q -> %q needs a variable name and a response field",
+						seqId, name, varName, field)
+				}
+				runtimeVars[varName] = true
+			}
+		}
+		seq.Endpoints = append(seq.Endpoints, resolved)
+	}
+
+	return seq, nil
+}
+
+// sequenceDatabaseWeights returns the sequence.database_weights of flow
+// seqId, which one of its steps may set, checked against databases: the
+// flow's per_database expansion after exclusions. Nil keeps one flow per
+// database.
+func sequenceDatabaseWeights(cfg *Config, seqId string, endpointNames []string, perDatabase bool, databases []string) (map[string]float64, error) {
+	var weights map[string]float64
+	for _, name := range endpointNames {
+		ep := cfg.Endpoints[name]
+		if len(ep.Sequence.DatabaseWeights) == 0 {
+			continue
+		}
+		if weights != nil {
+			return nil, fmt.Errorf("sequence %q: database_weights is set on more than one step", seqId)
+		}
+		weights = ep.Sequence.DatabaseWeights
+	}
+	if weights == nil {
+		return nil, nil
+	}
+	if !perDatabase || len(cfg.Databases) == 0 {
+		return nil, fmt.Errorf("sequence %q: database_weights requires a per_database step and configured databases", seqId)
+	}
+	for _, db := range slices.Sorted(maps.Keys(weights)) {
+		if !slices.Contains(databases, db) {
+			return nil, fmt.Errorf("sequence %q: database_weights names %q, which the flow does not run against", seqId, db)
+		}
+		if weights[db] <= 0 {
+			return nil, fmt.Errorf("sequence %q: database_weights %q must be > 0", seqId, db)
+		}
+	}
+	return weights, nil
 }
 
-func resolveEndpoint(baseUrl string, databases []string, endpointName string, endpoint *EndpointConfig) ([]*Testcase, error) {
-	endpointFile, err := loadFile(endpoint.File)
+// undefinedSequenceRefs lists the {name} placeholders in a step's headers,
+// body and expected body that nothing defines yet. The path is checked by
+// substitutePath; here the runtime replacement would otherwise send the
+// literal "{name}" and fail, or worse pass, at request time.
+func undefinedSequenceRefs(ep *EndpointConfig, defined map[string]bool) []string {
+	var undefined []string
+	visit := func(s string) {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+			name := match[1]
+			if name == "database" || defined[name] || slices.Contains(undefined, name) {
+				continue
+			}
+			undefined = append(undefined, name)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(ep.Headers)) {
+		visit(ep.Headers[key])
+	}
+	walkStrings(ep.Body, visit)
+	walkStrings(ep.Expect.Body, visit)
+	return undefined
+}
+
+// resolveWhen returns the variable names a step's when condition tests,
+// checked against the sequence vars and the earlier steps. Each capture it
+// tests becomes optional on the steps that capture it: "only delete if create
+// returned an id" must not fail create when there is no id.
+func resolveWhen(earlier []*ResolvedSequenceEndpoint, when string, vars map[string]VarConfig) ([]string, error) {
+	matches := placeholderPattern.FindAllStringSubmatch(when, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("sequence.when %q must reference at least one {var}", when)
+	}
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		name := match[1]
+		if slices.Contains(names, name) {
+			continue
+		}
+		names = append(names, name)
+		if _, ok := vars[name]; ok {
+			continue
+		}
+		captured := false
+		for _, step := range earlier {
+			if _, ok := step.Capture[name]; !ok {
+				continue
+			}
+			if step.OptionalCaptures == nil {
+				step.OptionalCaptures = make(map[string]bool)
+			}
+			step.OptionalCaptures[name] = true
+			captured = true
+		}
+		if !captured {
+			return nil, fmt.Errorf("sequence.when {%s} is neither a sequence var nor captured by an earlier step", name)
+		}
+	}
+	return names, nil
+}
+
+// walkStrings calls visit for every string value nested in a decoded JSON value.
+func walkStrings(v any, visit func(string)) {
+	switch v := v.(type) {
+	case string:
+		visit(v)
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			walkStrings(v[key], visit)
+		}
+	case []any:
+		for _, item := range v {
+			walkStrings(item, visit)
+		}
+	}
+}
+
+// splitSeedSequences moves the seed flow's resolved sequences (one per
+// database when per_database) out of the measured set.
+func splitSeedSequences(sequences []*ResolvedSequence, seedFlow string) (measured, seeds []*ResolvedSequence, err error) {
+	if seedFlow == "" {
+		return sequences, nil, nil
+	}
+	for _, seq := range sequences {
+		if seq.Id == seedFlow {
+			if len(seq.DatabaseChoices) > 0 {
+				return nil, nil, fmt.Errorf("benchmark seed_flow %q seeds every database; drop its database_weights", seedFlow)
+			}
+			seeds = append(seeds, seq)
+		} else {
+			measured = append(measured, seq)
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, nil, fmt.Errorf("benchmark seed_flow %q does not match any sequence id", seedFlow)
+	}
+	return measured, seeds, nil
+}
+
+// databasesFor is the per_database expansion of endpoint, minus its
+// exclude_databases, in config order.
+func databasesFor(endpoint *EndpointConfig, databases []string) []string {
+	if len(endpoint.ExcludeDatabases) == 0 {
+		return databases
+	}
+	return slices.DeleteFunc(slices.Clone(databases), func(db string) bool {
+		return slices.Contains(endpoint.ExcludeDatabases, db)
+	})
+}
+
+func resolveEndpoint(
+	baseUrl string, databases []string, filesDir, endpointName string, endpoint *EndpointConfig,
+) ([]*Testcase, error) {
+	endpointFile, err := loadFile(filesDir, endpoint.File)
 	if err != nil {
 		return nil, fmt.Errorf("endpoint %q file: %w", endpointName, err)
 	}
@@ -155,7 +481,7 @@ func resolveEndpoint(baseUrl string, databases []string, endpointName string, en
 
 	var contexts []dbContext
 	if endpoint.PerDatabase && len(databases) > 0 {
-		for _, db := range databases {
+		for _, db := range databasesFor(endpoint, databases) {
 			contexts = append(contexts, dbContext{name: db, dbName: db})
 		}
 	} else {
@@ -174,7 +500,7 @@ func resolveEndpoint(baseUrl string, databases []string, endpointName string, en
 			variation := &endpoint.Variations[i]
 			file := endpointFile
 			if variation.File != "" {
-				file, tcErr = loadFile(variation.File)
+				file, tcErr = loadFile(filesDir, variation.File)
 				if tcErr != nil {
 					return nil, fmt.Errorf("endpoint %q variation %d file: %w", endpointName, i, tcErr)
 				}
@@ -205,8 +531,12 @@ func buildTestcase(baseUrl, endpointName, name string, endpoint *EndpointConfig,
 	formData := maps.Clone(endpoint.FormData)
 	expectedStatus := endpoint.Expect.Status
 	expectedHeaders := maps.Clone(endpoint.Expect.Headers)
+	expectValidJSON := endpoint.Expect.ValidJSON
 	expectedBody := endpoint.Expect.Body
 	expectedText := endpoint.Expect.Text
+	expectedJSONPaths := maps.Clone(endpoint.Expect.JSONPath)
+	successWhen := endpoint.Expect.SuccessWhen
+	absentHeaders := slices.Clone(endpoint.Expect.AbsentHeaders)
 
 	if variation != nil {
 		if variation.Path != "" {
@@ -234,7 +564,7 @@ func buildTestcase(baseUrl, endpointName, name string, endpoint *EndpointConfig,
 			maps.Copy(formData, variation.FormData)
 		}
 		if variation.Expect != nil {
-			if variation.Expect.Status != 0 {
+			if !variation.Expect.Status.IsZero() {
 				expectedStatus = variation.Expect.Status
 			}
 			if len(variation.Expect.Headers) > 0 {
@@ -249,18 +579,57 @@ func buildTestcase(baseUrl, endpointName, name string, endpoint *EndpointConfig,
 			if variation.Expect.Text != "" {
 				expectedText = variation.Expect.Text
 			}
+			if variation.Expect.ValidJSON {
+				expectValidJSON = true
+			}
+			if len(variation.Expect.JSONPath) > 0 {
+				if expectedJSONPaths == nil {
+					expectedJSONPaths = make(map[string]any)
+				}
+				maps.Copy(expectedJSONPaths, variation.Expect.JSONPath)
+			}
+			if strings.TrimSpace(variation.Expect.SuccessWhen) != "" {
+				successWhen = variation.Expect.SuccessWhen
+			}
+			absentHeaders = append(absentHeaders, variation.Expect.AbsentHeaders...)
 		}
 	}
 
-	if database != "" {
-		path = strings.ReplaceAll(path, "{database}", database)
+	path, err := substitutePath(path, database, endpoint.PathVars, nil)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %q: %w", endpointName, err)
 	}
 
-	requestURI, err := buildRequestURI(baseUrl, path, query)
+	merged, queryConflicts, err := mergePathQuery(path, query)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %q: %w", endpointName, err)
+	}
+	requestURI, err := buildRequestURI(baseUrl, merged, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	headerMatchers, err := resolveHeaderMatchers(canonicalizeHeaders(expectedHeaders))
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %q: %w", endpointName, err)
+	}
+	jsonPaths, err := resolveJSONPaths(expectedJSONPaths)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %q: %w", endpointName, err)
+	}
+	absentHeaders = canonicalizeHeaderNames(absentHeaders)
+	for _, name := range absentHeaders {
+		if _, ok := headerMatchers[name]; ok {
+			return nil, fmt.Errorf("endpoint %q: expect.absent_headers: %s is also listed in expect.headers", endpointName, name)
+		}
+	}
+	var successRule *SuccessRule
+	if strings.TrimSpace(successWhen) != "" {
+		if successRule, err = NewSuccessRule(successWhen); err != nil {
+			return nil, fmt.Errorf("endpoint %q: expect.success_when %w", endpointName, err)
+		}
+	}
+
 	tc := &Testcase{
 		EndpointName:    endpointName,
 		Name:            name,
@@ -269,9 +638,21 @@ func buildTestcase(baseUrl, endpointName, name string, endpoint *EndpointConfig,
 		Method:          method,
 		Headers:         canonicalizeHeaders(headers),
 		ExpectedStatus:  expectedStatus,
-		ExpectedHeaders: canonicalizeHeaders(expectedHeaders),
+		ExpectedHeaders: headerMatchers,
 		ExpectedBody:    expectedBody,
 		ExpectedText:    expectedText,
+		ExpectValidJSON: expectValidJSON,
+		ChunkedRequest:  endpoint.ChunkedRequest,
+		Weight:          max(endpoint.Weight, 1),
+		Tags:            endpoint.Tags,
+		ExpectedAvg:     endpoint.ExpectedAvg,
+		ExpectedP99:     endpoint.ExpectedP99,
+
+		ExpectedJSONPaths: jsonPaths,
+		SuccessWhen:       successRule,
+		AbsentHeaders:     absentHeaders,
+		SSE:               endpoint.SSE,
+		queryConflicts:    queryConflicts,
 	}
 
 	switch {
@@ -290,6 +671,7 @@ func buildTestcase(baseUrl, endpointName, name string, endpoint *EndpointConfig,
 		tc.CachedFormBody = encodeFormBody(formData)
 	case body != nil:
 		tc.RequestType = RequestTypeJSON
+		tc.ContentType = endpoint.ContentType
 		tc.Body, err = serializeBody(body)
 		if err != nil {
 			return nil, err
@@ -298,6 +680,13 @@ func buildTestcase(baseUrl, endpointName, name string, endpoint *EndpointConfig,
 		tc.RequestType = RequestTypeNone
 	}
 
+	if endpoint.Dynamic && tc.RequestType != RequestTypeNone {
+		tc.Dynamic, err = resolveDynamicBody(tc, body)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: %w", endpointName, err)
+		}
+	}
+
 	return tc, nil
 }
 
@@ -338,7 +727,14 @@ func buildMultipartBody(fields map[string]string, file *FileUpload) (body, conte
 	return buf.String(), writer.FormDataContentType(), nil
 }
 
-func loadFile(filename string) (*FileUpload, error) {
+// testFilesDir locates the upload fixtures relative to the config file's
+// directory (config/ and contract/ are siblings in the repo), so an alternate
+// --config resolves its own fixtures regardless of the working directory.
+func testFilesDir(configDir string) string {
+	return filepath.Join(configDir, "..", "contract", "test-files")
+}
+
+func loadFile(filesDir, filename string) (*FileUpload, error) {
 	filename = strings.TrimSpace(filename)
 	if filename == "" {
 		return nil, nil
@@ -348,10 +744,9 @@ func loadFile(filename string) (*FileUpload, error) {
 		return nil, errors.New("invalid filename: path traversal not allowed")
 	}
 
-	testFilesDir := filepath.Join("..", "contract", "test-files")
-	path := filepath.Join(testFilesDir, filename)
+	path := filepath.Join(filesDir, filename)
 
-	absTestFilesDir, err := filepath.Abs(testFilesDir)
+	absTestFilesDir, err := filepath.Abs(filesDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve test-files directory: %w", err)
 	}
@@ -376,6 +771,84 @@ func loadFile(filename string) (*FileUpload, error) {
 	}, nil
 }
 
+var (
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	placeholderName    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// substitutePath fills {database} and the endpoint's static path_vars. Any
+// placeholder left must be a runtime var (flow var or earlier capture) that
+// the sequence runner fills per request; otherwise the path is a config error.
+// An unfilled {database} is left as before for non-per_database endpoints.
+func substitutePath(path, database string, pathVars map[string]string, runtimeVars map[string]bool) (string, error) {
+	if database != "" {
+		path = strings.ReplaceAll(path, "{database}", database)
+	}
+	for key, value := range pathVars {
+		path = strings.ReplaceAll(path, "{"+key+"}", value)
+	}
+
+	var unresolved []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(path, -1) {
+		name := match[1]
+		if name == "database" || runtimeVars[name] || slices.Contains(unresolved, name) {
+			continue
+		}
+		unresolved = append(unresolved, name)
+	}
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("path %q has unresolved placeholders {%s} (set path_vars or capture them in an earlier sequence step)",
+			path, strings.Join(unresolved, "}, {"))
+	}
+	return path, nil
+}
+
+// mergePathQuery moves a query embedded in path ("/search?q=x") together with
+// the query map into one query string, encoded deterministically (sorted by
+// key), so the request never carries a parameter twice. The map wins a key
+// both set; conflicts lists those whose path value differed. A key repeated
+// in the path alone keeps all its values.
+func mergePathQuery(path string, query map[string]string) (merged string, conflicts []string, err error) {
+	pathPart, rawQuery, hasQuery := strings.Cut(path, "?")
+	if !hasQuery && len(query) == 0 {
+		return path, nil, nil
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid query in path %q: %w", path, err)
+	}
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		if have, ok := values[key]; ok && !slices.Equal(have, []string{query[key]}) {
+			conflicts = append(conflicts, key)
+		}
+		values.Set(key, query[key])
+	}
+	if len(values) == 0 {
+		return pathPart, nil, nil
+	}
+	return pathPart + "?" + values.Encode(), conflicts, nil
+}
+
+// queryConflictWarnings reports each endpoint whose path query and query map
+// disagree, once even when per_database or variations built several
+// testcases from it.
+func queryConflictWarnings(testcases []*Testcase) []string {
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, tc := range testcases {
+		if len(tc.queryConflicts) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("endpoint %q: query %s set in both the path and the query map; using the query map's value",
+			tc.EndpointName, strings.Join(tc.queryConflicts, ", "))
+		if !seen[msg] {
+			seen[msg] = true
+			warnings = append(warnings, msg)
+		}
+	}
+	return warnings
+}
+
 // buildRequestURI normalizes path + query into a relative request target
 // ("/path?encoded=query"). baseUrl is used only to resolve/escape the path via
 // net/url; its host and port are irrelevant because the caller prepends the
@@ -429,6 +902,22 @@ func canonicalizeHeaders(headers map[string]string) map[string]string {
 	return result
 }
 
+// canonicalizeHeaderNames returns names in canonical MIME form, sorted and
+// without duplicates.
+func canonicalizeHeaderNames(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if key := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name)); key != "" {
+			result = append(result, key)
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
 func serializeBody(body any) (string, error) {
 	if body == nil {
 		return "", nil
@@ -0,0 +1,1052 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/roster"
+)
+
+// loadTestTarget writes cfgJSON to a temp file and resolves it through
+// LoadTarget, which needs no roster on disk.
+func loadTestTarget(t *testing.T, cfgJSON string) (*Config, *ResolvedServer, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return LoadTarget(path, "http://localhost:8080")
+}
+
+func TestResolveSeedFlow(t *testing.T) {
+	t.Parallel()
+
+	const endpoints = `
+		"databases": ["postgres", "mongodb"],
+		"endpoints": {
+			"health": { "route": "GET /health" },
+			"seed_insert": {
+				"route": "POST /db/{database}/users",
+				"per_database": true,
+				"body": { "name": "seed" },
+				"expect": { "status": 201 },
+				"sequence": { "id": "seed" }
+			},
+			"crud_create": {
+				"route": "POST /db/{database}/users",
+				"per_database": true,
+				"expect": { "status": 201 },
+				"sequence": { "id": "crud" }
+			}
+		}`
+
+	_, server, err := loadTestTarget(t, `{"benchmark": {"seed_flow": "seed"},`+endpoints+`}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if len(server.SeedSequences) != 2 {
+		t.Fatalf("seed sequences: got %d, want one per database", len(server.SeedSequences))
+	}
+	for _, seq := range server.SeedSequences {
+		if seq.Id != "seed" || !strings.HasPrefix(seq.Endpoints[0].Path, "/db/"+seq.Database+"/") {
+			t.Errorf("seed sequence not resolved per database: %+v", seq)
+		}
+	}
+	for _, seq := range server.Sequences {
+		if seq.Id == "seed" {
+			t.Errorf("seed flow must not be measured, found in Sequences for %s", seq.Database)
+		}
+	}
+	if len(server.Sequences) != 2 {
+		t.Errorf("measured sequences: got %d, want crud per database", len(server.Sequences))
+	}
+
+	_, _, err = loadTestTarget(t, `{"benchmark": {"seed_flow": "missing"},`+endpoints+`}`)
+	if err == nil || !strings.Contains(err.Error(), `seed_flow "missing"`) {
+		t.Errorf("unknown seed_flow: got %v", err)
+	}
+}
+
+func TestResolveMixedMode(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{
+		"benchmark": {"mixed_mode": true},
+		"endpoints": {
+			"hot": { "route": "GET /hot", "weight": 4 },
+			"cold": { "route": "GET /cold" }
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if !server.MixedMode {
+		t.Error("mixed_mode not threaded to the resolved server")
+	}
+	weights := make(map[string]int)
+	for _, tc := range server.Testcases {
+		weights[tc.EndpointName] = tc.Weight
+	}
+	if weights["hot"] != 4 || weights["cold"] != 1 {
+		t.Errorf("weights: got %v, want hot=4 cold=1 (default)", weights)
+	}
+
+	_, _, err = loadTestTarget(t, `{
+		"benchmark": {"mixed_mode": true, "load": {"mode": "open", "rate": 10}},
+		"endpoints": { "hot": { "route": "GET /hot" } }
+	}`)
+	if err == nil || !strings.Contains(err.Error(), "mixed_mode requires") {
+		t.Errorf("mixed_mode with open load: got %v", err)
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.MaxBodyBytes != DefaultMaxBodyBytes {
+		t.Errorf("default max_body_bytes: got %d, want %d", server.MaxBodyBytes, DefaultMaxBodyBytes)
+	}
+
+	_, server, err = loadTestTarget(t, `{"benchmark": {"max_body_bytes": 4096}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.MaxBodyBytes != 4096 {
+		t.Errorf("max_body_bytes: got %d, want 4096", server.MaxBodyBytes)
+	}
+
+	_, _, err = loadTestTarget(t, `{"benchmark": {"max_body_bytes": -1}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err == nil || !strings.Contains(err.Error(), "max_body_bytes must be > 0") {
+		t.Errorf("negative max_body_bytes: got %v", err)
+	}
+}
+
+func TestMaxSamples(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"benchmark": {"max_samples": 100000}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.MaxSamples != 100000 {
+		t.Errorf("max_samples: got %d, want 100000", server.MaxSamples)
+	}
+
+	_, _, err = loadTestTarget(t, `{"benchmark": {"max_samples": 10}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err == nil || !strings.Contains(err.Error(), "max_samples must be 0 (unbounded) or >= 1000") {
+		t.Errorf("tiny max_samples: got %v", err)
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.Estimator != EstimatorExact || server.MaxSamples != 0 {
+		t.Errorf("default: got %q/%d, want exact/0", server.Estimator, server.MaxSamples)
+	}
+
+	_, server, err = loadTestTarget(t, `{"benchmark": {"estimator": "TDigest"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.Estimator != EstimatorTDigest || server.MaxSamples != DefaultDigestSamples {
+		t.Errorf("tdigest: got %q/%d, want tdigest/%d", server.Estimator, server.MaxSamples, DefaultDigestSamples)
+	}
+
+	_, _, err = loadTestTarget(t, `{"benchmark": {"estimator": "hdr"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err == nil || !strings.Contains(err.Error(), `estimator must be "exact" or "tdigest"`) {
+		t.Errorf("unknown estimator: got %v", err)
+	}
+}
+
+func TestMaxConns(t *testing.T) {
+	t.Parallel()
+
+	cfg, server, err := loadTestTarget(t, `{"benchmark": {"concurrency": 64, "max_conns": 8}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.MaxConns != 8 || cfg.Benchmark.Connections() != 8 {
+		t.Errorf("max_conns: got server %d, connections %d, want 8", server.MaxConns, cfg.Benchmark.Connections())
+	}
+
+	cfg, _, err = loadTestTarget(t, `{"benchmark": {"concurrency": 64}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if got := cfg.Benchmark.Connections(); got != 64 {
+		t.Errorf("default connections: got %d, want concurrency 64", got)
+	}
+
+	_, _, err = loadTestTarget(t, `{"benchmark": {"max_conns": -1}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err == nil || !strings.Contains(err.Error(), "max_conns") {
+		t.Errorf("negative max_conns: got %v, want max_conns error", err)
+	}
+}
+
+func TestRequestsPerEndpoint(t *testing.T) {
+	t.Parallel()
+
+	cfg, server, err := loadTestTarget(t, `{"benchmark": {"requests_per_endpoint": 5000}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.RequestsPerEndpoint != 5000 {
+		t.Errorf("requests_per_endpoint: got %d, want 5000", server.RequestsPerEndpoint)
+	}
+
+	// An explicit --duration switches back to the time budget.
+	ApplyRunOverrides(cfg, []*ResolvedServer{server}, &RuntimeOptions{Duration: 3 * time.Second})
+	if server.RequestsPerEndpoint != 0 || server.DurationPerEndpoint != 3*time.Second {
+		t.Errorf("--duration override: got %d requests, %s, want 0 requests, 3s", server.RequestsPerEndpoint, server.DurationPerEndpoint)
+	}
+
+	invalid := []struct {
+		name      string
+		benchmark string
+		want      string
+	}{
+		{"negative", `{"requests_per_endpoint": -1}`, "requests_per_endpoint must be >= 0"},
+		{"with duration", `{"requests_per_endpoint": 100, "duration_per_endpoint": "5s"}`, "mutually exclusive"},
+		{"open mode", `{"requests_per_endpoint": 100, "load": {"mode": "open", "rate": 100}}`, `requires load mode "closed"`},
+		{"mixed mode", `{"requests_per_endpoint": 100, "mixed_mode": true}`, "mixed_mode"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := loadTestTarget(t, `{"benchmark": `+tc.benchmark+`, "endpoints": {"root": {"route": "GET /"}}}`)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestResetPath(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.ResetPath != "/db/{database}/reset" {
+		t.Errorf("default reset_path: got %q", server.ResetPath)
+	}
+
+	_, server, err = loadTestTarget(t, `{"database": {"reset_path": "/admin/{database}/wipe"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.ResetPath != "/admin/{database}/wipe" {
+		t.Errorf("reset_path: got %q", server.ResetPath)
+	}
+
+	_, _, err = loadTestTarget(t, `{"database": {"reset_path": "/admin/wipe"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err == nil || !strings.Contains(err.Error(), "must contain {database}") {
+		t.Errorf("reset_path without placeholder: got %v", err)
+	}
+}
+
+func TestResolveValidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"dynamic": {
+		"route": "GET /dynamic",
+		"expect": {"valid_json": true},
+		"variations": [{"path": "/other"}]
+	}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	for _, tc := range server.Testcases {
+		if !tc.ExpectValidJSON || tc.ExpectedBody != nil {
+			t.Errorf("%s: got valid_json %v body %v, want valid_json only", tc.Name, tc.ExpectValidJSON, tc.ExpectedBody)
+		}
+	}
+
+	_, _, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /", "expect": {"valid_json": true, "body": {"ok": true}}}}}`)
+	if err == nil || !strings.Contains(err.Error(), "expect.valid_json cannot be combined with expect.body") {
+		t.Errorf("valid_json with body: got %v", err)
+	}
+}
+
+func TestResolveBaseUrls(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.BaseUrls != nil {
+		t.Errorf("without base_urls: got %v, want nil", server.BaseUrls)
+	}
+
+	_, server, err = loadTestTarget(t, `{
+		"benchmark": {"base_urls": ["http://localhost:8080/", "http://10.0.0.2:8080"]},
+		"endpoints": {"root": {"route": "GET /"}}
+	}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	want := []string{"http://localhost:8080", "http://10.0.0.2:8080"}
+	if !slices.Equal(server.BaseUrls, want) {
+		t.Errorf("base_urls: got %v, want %v (target first, no duplicate)", server.BaseUrls, want)
+	}
+
+	for _, bad := range []string{`["localhost:8080"]`, `["http://a:1", "http://a:1/"]`} {
+		_, _, err = loadTestTarget(t, `{"benchmark": {"base_urls": `+bad+`}, "endpoints": {"root": {"route": "GET /"}}}`)
+		if err == nil || !strings.Contains(err.Error(), "base_urls[") {
+			t.Errorf("base_urls %s: got %v, want validation error", bad, err)
+		}
+	}
+}
+
+func TestAbortBelowSuccessRate(t *testing.T) {
+	t.Parallel()
+
+	cfg, _, err := loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if cfg.Benchmark.AbortBelow != 0 {
+		t.Errorf("default abort_below_success_rate: got %v, want 0", cfg.Benchmark.AbortBelow)
+	}
+
+	cfg, _, err = loadTestTarget(t, `{"benchmark": {"abort_below_success_rate": "90%"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if cfg.Benchmark.AbortBelow != 0.9 {
+		t.Errorf("abort_below_success_rate: got %v, want 0.9", cfg.Benchmark.AbortBelow)
+	}
+
+	_, _, err = loadTestTarget(t, `{"benchmark": {"abort_below_success_rate": "150%"}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err == nil || !strings.Contains(err.Error(), "abort_below_success_rate must be <= 100%") {
+		t.Errorf("abort_below_success_rate over 100%%: got %v", err)
+	}
+}
+
+func TestResolvePathVars(t *testing.T) {
+	t.Parallel()
+
+	const cfgJSON = `{
+		"endpoints": {
+			"order": {
+				"route": "GET /users/{userId}/orders/{orderId}",
+				"path_vars": { "userId": "42", "orderId": "7" }
+			},
+			"create": {
+				"route": "POST /accounts/{accountId}/items",
+				"path_vars": { "accountId": "acme" },
+				"expect": { "status": 201 },
+				"sequence": { "id": "items", "capture": { "itemId": "id" } }
+			},
+			"read": {
+				"route": "GET /accounts/{accountId}/items/{itemId}",
+				"path_vars": { "accountId": "acme" },
+				"sequence": { "id": "items" }
+			}
+		}
+	}`
+
+	_, server, err := loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if got := server.Testcases[0].Path; got != "/users/42/orders/7" {
+		t.Errorf("testcase path: got %q, want /users/42/orders/7", got)
+	}
+	if got := server.Testcases[0].RequestURI; got != "/users/42/orders/7" {
+		t.Errorf("testcase request URI: got %q, want /users/42/orders/7", got)
+	}
+	steps := server.Sequences[0].Endpoints
+	if got := steps[0].Path; got != "/accounts/acme/items" {
+		t.Errorf("step 0 path: got %q, want /accounts/acme/items", got)
+	}
+	if got := steps[1].Path; got != "/accounts/acme/items/{itemId}" {
+		t.Errorf("step 1 path: got %q, want {itemId} left for the capture", got)
+	}
+
+	tests := []struct {
+		name    string
+		cfgJSON string
+		wantErr string
+	}{
+		{
+			name:    "missing path var",
+			cfgJSON: `{"endpoints": {"order": {"route": "GET /users/{userId}/orders/{orderId}", "path_vars": {"userId": "42"}}}}`,
+			wantErr: "unresolved placeholders {orderId}",
+		},
+		{
+			name: "capture from a later step",
+			cfgJSON: `{"endpoints": {
+				"read": {"route": "GET /items/{itemId}", "sequence": {"id": "items"}},
+				"create": {"route": "POST /items", "sequence": {"id": "items", "capture": {"itemId": "id"}}}
+			}}`,
+			wantErr: "unresolved placeholders {itemId}",
+		},
+		{
+			name:    "database key",
+			cfgJSON: `{"endpoints": {"health": {"route": "GET /db/{database}/health", "path_vars": {"database": "postgres"}}}}`,
+			wantErr: "path_vars cannot set {database}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := loadTestTarget(t, tt.cfgJSON)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolvePathQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint string
+		wantURI  string
+		wantWarn string // "" = no warning
+	}{
+		{
+			name:     "path alone",
+			endpoint: `{"route": "GET /search?q=x&limit=5&tag=a&tag=b"}`,
+			wantURI:  "/search?limit=5&q=x&tag=a&tag=b",
+		},
+		{
+			name:     "map alone",
+			endpoint: `{"route": "GET /search", "query": {"q": "x", "limit": "5"}}`,
+			wantURI:  "/search?limit=5&q=x",
+		},
+		{
+			name:     "both",
+			endpoint: `{"route": "GET /search?q=path&page=2&limit=5", "query": {"q": "map", "limit": "5"}}`,
+			wantURI:  "/search?limit=5&page=2&q=map",
+			wantWarn: `endpoint "search": query q set in both the path and the query map`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, server, err := loadTestTarget(t, `{"endpoints": {"search": `+tt.endpoint+`}}`)
+			if err != nil {
+				t.Fatalf("LoadTarget: %v", err)
+			}
+			if got := server.Testcases[0].RequestURI; got != tt.wantURI {
+				t.Errorf("request URI: got %q, want %q", got, tt.wantURI)
+			}
+			switch {
+			case tt.wantWarn == "" && len(cfg.Warnings) > 0:
+				t.Errorf("warnings: got %q, want none", cfg.Warnings)
+			case tt.wantWarn != "" && (len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], tt.wantWarn)):
+				t.Errorf("warnings: got %q, want one containing %q", cfg.Warnings, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestResolveConfigConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfgJSON string
+		wantErr string
+	}{
+		{
+			name: "duplicate endpoint key",
+			cfgJSON: `{"endpoints": {
+				"users": {"route": "GET /users"},
+				"users": {"route": "GET /users?page=2"}
+			}}`,
+			wantErr: `endpoints: duplicate key "users"`,
+		},
+		{
+			name: "body uses an undefined variable",
+			cfgJSON: `{"endpoints": {
+				"create": {"route": "POST /items", "sequence": {"id": "items", "capture": {"itemId": "id"}}},
+				"update": {"route": "PATCH /items", "body": {"id": "{itemId}", "owner": "{ownerId}"}, "sequence": {"id": "items"}}
+			}}`,
+			wantErr: `sequence "items" step "update": {ownerId} is neither a sequence var nor captured by an earlier step`,
+		},
+		{
+			name: "capture with an empty field",
+			cfgJSON: `{"endpoints": {
+				"create": {"route": "POST /items", "sequence": {"id": "items", "capture": {"itemId": ""}}}
+			}}`,
+			wantErr: `capture "itemId" -> "" needs a variable name and a response field`,
+		},
+		{
+			name: "oneOf beside other keys",
+			cfgJSON: `{"endpoints": {
+				"user": {"route": "GET /user", "expect": {"body": {"status": {"$oneOf": ["active"], "x": 1}}}}
+			}}`,
+			wantErr: `expect.body: status: $oneOf must be the only key of its object`,
+		},
+		{
+			name: "empty oneOf",
+			cfgJSON: `{"endpoints": {
+				"user": {"route": "GET /user", "expect": {"json_path": {"$.status": {"$oneOf": []}}}}
+			}}`,
+			wantErr: `expect.json_path "$.status": $oneOf needs a non-empty array of values`,
+		},
+		{
+			name:    "connect_timeout not below request_timeout",
+			cfgJSON: `{"benchmark": {"request_timeout": "2s", "connect_timeout": "2s"}, "endpoints": {"root": {"route": "GET /"}}}`,
+			wantErr: "benchmark connect_timeout 2s must be below request_timeout 2s",
+		},
+		{
+			name:    "sse with a request body",
+			cfgJSON: `{"endpoints": {"events": {"route": "POST /events", "type": "sse", "body": {"a": 1}}}}`,
+			wantErr: `type "sse" requires GET, got POST`,
+		},
+		{
+			name:    "sse with a body expectation",
+			cfgJSON: `{"endpoints": {"events": {"route": "GET /events", "type": "sse", "expect": {"text": "OK"}}}}`,
+			wantErr: `type "sse" streams events; expect only status and headers`,
+		},
+		{
+			name:    "sse settings without the type",
+			cfgJSON: `{"endpoints": {"events": {"route": "GET /events", "sse": {"events": 3}}}}`,
+			wantErr: `sse requires type "sse"`,
+		},
+		{
+			name:    "sse max_duration not below request_timeout",
+			cfgJSON: `{"benchmark": {"request_timeout": "2s"}, "endpoints": {"events": {"route": "GET /events", "type": "sse", "sse": {"max_duration": "2s"}}}}`,
+			wantErr: `endpoint "events": sse.max_duration 2s must be below request_timeout 2s`,
+		},
+		{
+			name:    "head with a body expectation",
+			cfgJSON: `{"endpoints": {"ping": {"route": "HEAD /ping", "expect": {"body": {"ok": true}}}}}`,
+			wantErr: `HEAD responses have no body; expect only status and headers`,
+		},
+		{
+			name:    "head variation reading the body",
+			cfgJSON: `{"endpoints": {"ping": {"route": "HEAD /ping", "variations": [{"expect": {"json_path": {"$.ok": true}}}]}}}`,
+			wantErr: `variation 0: HEAD responses have no body`,
+		},
+		{
+			name:    "expected body inline and from a file",
+			cfgJSON: `{"endpoints": {"users": {"route": "GET /users", "expect": {"body": {"id": 1}, "body_file": "users.json"}}}}`,
+			wantErr: `expect.body and expect.body_file are mutually exclusive`,
+		},
+		{
+			name:    "expected body file outside the fixtures",
+			cfgJSON: `{"endpoints": {"users": {"route": "GET /users", "variations": [{"expect": {"body_file": "../config.json"}}]}}}`,
+			wantErr: `variation 0: expect.body_file: invalid filename: path traversal not allowed`,
+		},
+		{
+			name:    "non-positive concurrency multiplier",
+			cfgJSON: `{"benchmark": {"concurrency": "0x"}, "endpoints": {"root": {"route": "GET /"}}}`,
+			wantErr: `benchmark concurrency multiplier must be positive, got "0x"`,
+		},
+		{
+			name:    "concurrency string without a multiplier",
+			cfgJSON: `{"benchmark": {"concurrency": "many"}, "endpoints": {"root": {"route": "GET /"}}}`,
+			wantErr: `benchmark concurrency must look like "4x" when a string, got "many"`,
+		},
+		{
+			name:    "empty ready_exec",
+			cfgJSON: `{"container": {"ready_exec": []}, "endpoints": {"root": {"route": "GET /"}}}`,
+			wantErr: "container ready_exec must be a non-empty command",
+		},
+		{
+			name:    "blank ready_exec command",
+			cfgJSON: `{"container": {"ready_exec": [" ", "-c"]}, "endpoints": {"root": {"route": "GET /"}}}`,
+			wantErr: "container ready_exec must be a non-empty command",
+		},
+		{
+			name:    "discard_first_percent of everything",
+			cfgJSON: `{"benchmark": {"discard_first_percent": "100%"}, "endpoints": {"root": {"route": "GET /"}}}`,
+			wantErr: "benchmark discard_first_percent must be below 100%",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := loadTestTarget(t, tt.cfgJSON)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	const validJSON = `{"endpoints": {
+		"create": {
+			"route": "POST /items",
+			"body": {"email": "{email}"},
+			"sequence": {"id": "items", "capture": {"itemId": "id"}, "vars": {"email": {"type": "email"}}}
+		},
+		"read": {
+			"route": "GET /items/{itemId}",
+			"headers": {"X-Owner": "{email}"},
+			"expect": {"body": {"id": "{itemId}"}},
+			"sequence": {"id": "items"}
+		}
+	}}`
+	if _, _, err := loadTestTarget(t, validJSON); err != nil {
+		t.Errorf("vars and earlier captures: got %v, want no error", err)
+	}
+}
+
+func TestResolveSequenceWhen(t *testing.T) {
+	t.Parallel()
+
+	const cfgJSON = `{"endpoints": {
+		"create": {"route": "POST /items", "sequence": {"id": "items", "capture": {"id": "id", "etag": "etag"}}},
+		"delete": {"route": "DELETE /items/{id}", "sequence": {"id": "items", "when": "{id}"}}
+	}}`
+	_, server, err := loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	steps := server.Sequences[0].Endpoints
+	if got := steps[1].When; !slices.Equal(got, []string{"id"}) {
+		t.Errorf("when: got %v, want [id]", got)
+	}
+	if !steps[0].OptionalCaptures["id"] || steps[0].OptionalCaptures["etag"] {
+		t.Errorf("optional captures: got %v, want only id", steps[0].OptionalCaptures)
+	}
+	if steps[0].When != nil {
+		t.Errorf("unconditional step: got when %v, want none", steps[0].When)
+	}
+
+	tests := []struct {
+		name    string
+		cfgJSON string
+		wantErr string
+	}{
+		{
+			name:    "undefined variable",
+			cfgJSON: `{"endpoints": {"delete": {"route": "DELETE /items", "sequence": {"id": "items", "when": "{id}"}}}}`,
+			wantErr: "sequence.when {id} is neither a sequence var nor captured by an earlier step",
+		},
+		{
+			name:    "no variable",
+			cfgJSON: `{"endpoints": {"delete": {"route": "DELETE /items", "sequence": {"id": "items", "when": "always"}}}}`,
+			wantErr: "must reference at least one {var}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := loadTestTarget(t, tt.cfgJSON)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveExcludeDatabases(t *testing.T) {
+	t.Parallel()
+
+	const cfgJSON = `{
+		"databases": ["postgres", "mongodb", "redis"],
+		"endpoints": {
+			"transfer": {
+				"route": "POST /db/{database}/transfer",
+				"per_database": true,
+				"exclude_databases": ["redis"],
+				"variations": [{"body": {"amount": 5}}]
+			},
+			"create": {"route": "POST /db/{database}/users", "per_database": true, "sequence": {"id": "users"}},
+			"read": {"route": "GET /db/{database}/users", "per_database": true, "exclude_databases": ["mongodb"], "sequence": {"id": "users"}}
+		}
+	}`
+	_, server, err := loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	var names []string
+	for _, tc := range server.Testcases {
+		names = append(names, tc.Name)
+	}
+	want := []string{"postgres", "postgres/variation_0", "mongodb", "mongodb/variation_0"}
+	if !slices.Equal(names, want) {
+		t.Errorf("testcases: got %v, want %v", names, want)
+	}
+	var flows []string
+	for _, seq := range server.Sequences {
+		flows = append(flows, seq.Database)
+	}
+	if !slices.Equal(flows, []string{"postgres", "redis"}) {
+		t.Errorf("flow databases: got %v, want the one a step excludes dropped", flows)
+	}
+
+	tests := []struct {
+		name    string
+		cfgJSON string
+		wantErr string
+	}{
+		{
+			name:    "unknown database",
+			cfgJSON: `{"databases": ["postgres"], "endpoints": {"a": {"route": "GET /db/{database}", "per_database": true, "exclude_databases": ["mysql"]}}}`,
+			wantErr: `exclude_databases: unknown database "mysql"`,
+		},
+		{
+			name:    "not per_database",
+			cfgJSON: `{"databases": ["postgres"], "endpoints": {"a": {"route": "GET /a", "exclude_databases": ["postgres"]}}}`,
+			wantErr: "exclude_databases requires per_database",
+		},
+		{
+			name:    "every database",
+			cfgJSON: `{"databases": ["postgres"], "endpoints": {"a": {"route": "GET /db/{database}", "per_database": true, "exclude_databases": ["postgres"]}}}`,
+			wantErr: "excludes every database",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := loadTestTarget(t, tt.cfgJSON)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveDatabaseWeights(t *testing.T) {
+	t.Parallel()
+
+	const cfgJSON = `{
+		"databases": ["postgres", "mongodb", "redis"],
+		"endpoints": {
+			"create": {
+				"route": "POST /db/{database}/users", "per_database": true,
+				"sequence": {"id": "users", "database_weights": {"postgres": 3, "mongodb": 1}}
+			},
+			"read": {"route": "GET /db/{database}/users", "per_database": true, "sequence": {"id": "users"}}
+		}
+	}`
+	_, server, err := loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if len(server.Sequences) != 1 {
+		t.Fatalf("sequences: got %d, want one weighted flow", len(server.Sequences))
+	}
+	seq := server.Sequences[0]
+	if seq.Database != "" || seq.Endpoints[0].Path != "/db/{database}/users" {
+		t.Errorf("template: got database %q path %q, want unbound", seq.Database, seq.Endpoints[0].Path)
+	}
+	var dbs []string
+	for _, choice := range seq.DatabaseChoices {
+		dbs = append(dbs, choice.Database)
+	}
+	if !slices.Equal(dbs, []string{"postgres", "mongodb"}) || !slices.Equal(seq.DatabaseWeights, []float64{3, 1}) {
+		t.Errorf("choices: got %v weights %v, want [postgres mongodb] [3 1]", dbs, seq.DatabaseWeights)
+	}
+	if got := seq.DatabaseChoices[1].Endpoints[1].Path; got != "/db/mongodb/users" {
+		t.Errorf("mongodb read path: got %q", got)
+	}
+
+	picks := []struct {
+		r    float64
+		want string
+	}{{0, "postgres"}, {0.74, "postgres"}, {0.75, "mongodb"}, {0.99, "mongodb"}}
+	for _, tc := range picks {
+		if got := seq.PickDatabase(tc.r).Database; got != tc.want {
+			t.Errorf("PickDatabase(%v): got %q, want %q", tc.r, got, tc.want)
+		}
+	}
+
+	bad := []struct{ name, weights, wantErr string }{
+		{"unknown database", `{"mysql": 1}`, `database_weights names "mysql"`},
+		{"zero weight", `{"postgres": 0}`, `database_weights "postgres" must be > 0`},
+	}
+	for _, tc := range bad {
+		cfg := strings.Replace(cfgJSON, `{"postgres": 3, "mongodb": 1}`, tc.weights, 1)
+		if _, _, err := loadTestTarget(t, cfg); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: got %v, want error containing %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestTotalDuration(t *testing.T) {
+	t.Parallel()
+
+	// heavy (weight 3) + light (weight 1) + a two-step sequence = 6 units of 10s.
+	cfg, server, err := loadTestTarget(t, `{
+		"benchmark": {"total_duration": "1m"},
+		"endpoints": {
+			"heavy": {"route": "GET /heavy", "weight": 3},
+			"light": {"route": "GET /light", "variations": [{"query": {"a": "1"}}, {"query": {"a": "2"}}]},
+			"create": {"route": "POST /items", "body": {}, "sequence": {"id": "flow"}},
+			"read": {"route": "GET /items", "sequence": {"id": "flow"}}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if err := ApplyTotalDuration(cfg, []*ResolvedServer{server}); err != nil {
+		t.Fatalf("ApplyTotalDuration: %v", err)
+	}
+	if server.DurationPerEndpoint != 10*time.Second || cfg.Benchmark.DurationPerEndpoint != 10*time.Second {
+		t.Errorf("unit: got %s (config %s), want 10s", server.DurationPerEndpoint, cfg.Benchmark.DurationPerEndpoint)
+	}
+	want := map[string]time.Duration{"heavy": 30 * time.Second, "light": 10 * time.Second}
+	for _, tc := range server.Testcases {
+		if got := server.EndpointWindow(tc); got != want[tc.EndpointName] {
+			t.Errorf("%s window: got %s, want %s", tc.EndpointName, got, want[tc.EndpointName])
+		}
+	}
+
+	// Fewer units to run (here no sequences) means longer slices.
+	sequences := server.Sequences
+	server.Sequences = nil
+	if err := ApplyTotalDuration(cfg, []*ResolvedServer{server}); err != nil || server.DurationPerEndpoint != 15*time.Second {
+		t.Errorf("without sequences: got %s, %v, want 15s", server.DurationPerEndpoint, err)
+	}
+
+	// Servers measuring different sets keep their own units; the config names none.
+	other := *server
+	other.Sequences = sequences
+	if err := ApplyTotalDuration(cfg, []*ResolvedServer{server, &other}); err != nil {
+		t.Fatalf("ApplyTotalDuration: %v", err)
+	}
+	if server.DurationPerEndpoint != 15*time.Second || other.DurationPerEndpoint != 10*time.Second || cfg.Benchmark.DurationPerEndpoint != 0 {
+		t.Errorf("differing servers: got %s and %s (config %s), want 15s and 10s (config 0)",
+			server.DurationPerEndpoint, other.DurationPerEndpoint, cfg.Benchmark.DurationPerEndpoint)
+	}
+
+	cfg, server, err = loadTestTarget(t, `{"benchmark": {"total_duration": "1500ms"}, "endpoints": {"a": {"route": "GET /a"}, "b": {"route": "GET /b"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if err := ApplyTotalDuration(cfg, []*ResolvedServer{server}); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Errorf("750ms slices: got %v, want too short error", err)
+	}
+
+	invalid := []struct {
+		name      string
+		benchmark string
+		want      string
+	}{
+		{"zero", `{"total_duration": "0s"}`, "total_duration must be > 0"},
+		{"with duration", `{"total_duration": "1m", "duration_per_endpoint": "5s"}`, "mutually exclusive"},
+		{"with requests", `{"total_duration": "1m", "requests_per_endpoint": 100}`, "mutually exclusive"},
+		{"stages", `{"total_duration": "1m", "load": {"mode": "open", "rate": 10, "stages": [{"target": 20, "duration": "10s"}]}}`, "load stages"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := loadTestTarget(t, `{"benchmark": `+tc.benchmark+`, "endpoints": {"root": {"route": "GET /"}}}`)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyTagFilter(t *testing.T) {
+	t.Parallel()
+
+	const cfgJSON = `{
+		"endpoints": {
+			"get_user": { "route": "GET /users/1", "tags": ["read"] },
+			"login": { "route": "POST /login", "tags": ["auth", " write "] },
+			"health": { "route": "GET /health" },
+			"crud_create": {
+				"route": "POST /users",
+				"expect": { "status": 201 },
+				"sequence": { "id": "crud", "capture": { "id": "id" } }
+			},
+			"crud_read": {
+				"route": "GET /users/{id}",
+				"tags": ["read"],
+				"sequence": { "id": "crud" }
+			}
+		}
+	}`
+
+	_, server, err := loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	unknown := ApplyTagFilter([]*ResolvedServer{server}, []string{"read", "missing"})
+	if len(unknown) != 1 || unknown[0] != "missing" {
+		t.Errorf("unknown tags: got %v, want [missing]", unknown)
+	}
+	if len(server.Testcases) != 1 || server.Testcases[0].EndpointName != "get_user" {
+		t.Errorf("filtered testcases: got %d, want only get_user", len(server.Testcases))
+	}
+	if len(server.Sequences) != 1 || server.Sequences[0].Id != "crud" {
+		t.Errorf("a tag on one step must keep the whole sequence, got %d sequences", len(server.Sequences))
+	}
+
+	_, server, err = loadTestTarget(t, cfgJSON)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	ApplyTagFilter([]*ResolvedServer{server}, []string{"write"})
+	if len(server.Testcases) != 1 || server.Testcases[0].EndpointName != "login" || len(server.Sequences) != 0 {
+		t.Errorf("write filter (tags are trimmed): got %d testcases, %d sequences", len(server.Testcases), len(server.Sequences))
+	}
+
+	ApplyTagFilter([]*ResolvedServer{server}, []string{"missing"})
+	if server.HasWork() {
+		t.Error("filter matching nothing must leave no work")
+	}
+
+	_, _, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /", "tags": [""]}}}`)
+	if err == nil || !strings.Contains(err.Error(), "tags[0] must not be empty") {
+		t.Errorf("empty tag: got %v", err)
+	}
+}
+
+func TestWarmupUntilStable(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"benchmark": {"warmup_until_stable": {}}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	stable := server.WarmupStable
+	if stable == nil {
+		t.Fatal("warmup_until_stable not threaded to the resolved server")
+	}
+	if stable.Window != time.Second || stable.Threshold != 0.05 || stable.Windows != 3 || stable.MaxDuration != 30*time.Second {
+		t.Errorf("defaults: got %+v", stable)
+	}
+
+	_, server, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if server.WarmupStable != nil {
+		t.Error("fixed-duration warmup must stay the default")
+	}
+
+	for _, bad := range []string{
+		`{"window": "2s", "max_duration": "1s"}`,
+		`{"windows": 1}`,
+		`{"threshold": "0%"}`,
+		`{"threshold": "5"}`,
+	} {
+		_, _, err = loadTestTarget(t, `{"benchmark": {"warmup_until_stable": `+bad+`}, "endpoints": {"root": {"route": "GET /"}}}`)
+		if err == nil || !strings.Contains(err.Error(), "warmup_until_stable") {
+			t.Errorf("%s: got %v, want warmup_until_stable error", bad, err)
+		}
+	}
+}
+
+func TestResolveCooldownUntilIdle(t *testing.T) {
+	t.Parallel()
+
+	cfg, _, err := loadTestTarget(t, `{"benchmark": {"cooldown_until_idle": {}}, "endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	idle := cfg.Benchmark.CooldownUntilIdle
+	if idle == nil || idle.CpuThreshold != 5 || idle.MaxDuration != time.Minute {
+		t.Errorf("defaults: got %+v, want 5%% for at most 1m", idle)
+	}
+
+	cfg, _, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if cfg.Benchmark.CooldownUntilIdle != nil {
+		t.Error("fixed-duration cooldown must stay the default")
+	}
+
+	for _, bad := range []string{
+		`{"cpu_threshold": "0%"}`,
+		`{"cpu_threshold": "5"}`,
+		`{"max_duration": "0s"}`,
+	} {
+		_, _, err = loadTestTarget(t, `{"benchmark": {"cooldown_until_idle": `+bad+`}, "endpoints": {"root": {"route": "GET /"}}}`)
+		if err == nil || !strings.Contains(err.Error(), "cooldown_until_idle") {
+			t.Errorf("%s: got %v, want cooldown_until_idle error", bad, err)
+		}
+	}
+}
+
+func TestResolveExpectedLatency(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /", "expected_avg": "2ms", "expected_p99": "10ms"}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if tc := server.Testcases[0]; tc.ExpectedAvg != 2*time.Millisecond || tc.ExpectedP99 != 10*time.Millisecond {
+		t.Errorf("got avg %s p99 %s, want 2ms and 10ms", tc.ExpectedAvg, tc.ExpectedP99)
+	}
+
+	for _, raw := range []string{`"expected_avg": "fast"`, `"expected_p99": "0s"`} {
+		_, _, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /", `+raw+`}}}`)
+		if err == nil || !strings.Contains(err.Error(), "expected_") {
+			t.Errorf("%s: got %v, want an expected_* error", raw, err)
+		}
+	}
+}
+
+func TestResolveChunkedRequest(t *testing.T) {
+	t.Parallel()
+
+	_, server, err := loadTestTarget(t, `{"endpoints": {"echo": {"route": "POST /echo", "body": {"k": "v"}, "chunked_request": true}}}`)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	if !server.Testcases[0].ChunkedRequest {
+		t.Error("chunked_request not threaded to the testcase")
+	}
+
+	_, _, err = loadTestTarget(t, `{"endpoints": {"root": {"route": "GET /", "chunked_request": true}}}`)
+	if err == nil || !strings.Contains(err.Error(), "chunked_request requires a body") {
+		t.Errorf("chunked_request without a body: got %v, want requires-a-body error", err)
+	}
+}
+
+func TestResolveServerContainerLimits(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfgJSON := `{"container": {"cpu_limit": 1, "memory_limit": "512mb"}, "endpoints": {"root": {"route": "GET /"}}}`
+	if err := os.WriteFile(path, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("loadConfigFile: %v", err)
+	}
+
+	servers, err := resolve(cfg, []roster.Entry{
+		{Name: "global", Image: "bench/global", Port: 8080},
+		{Name: "tuned", Image: "bench/tuned", Port: 8080, CpuLimit: 2, MemoryLimit: "1G"},
+		{Name: "cpu-only", Image: "bench/cpu-only", Port: 8080, CpuLimit: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := []struct {
+		cpu    float64
+		memory string
+	}{{1, "512mb"}, {2, "1gb"}, {0.5, "512mb"}}
+	for i, w := range want {
+		if got := servers[i]; got.CpuLimit != w.cpu || got.MemoryLimit != w.memory {
+			t.Errorf("%s: got %v/%s, want %v/%s", got.Name, got.CpuLimit, got.MemoryLimit, w.cpu, w.memory)
+		}
+	}
+
+	_, err = resolve(cfg, []roster.Entry{{Name: "bad", Image: "bench/bad", Port: 8080, MemoryLimit: "lots"}})
+	if err == nil || !strings.Contains(err.Error(), `server "bad" memory`) {
+		t.Errorf("bad memory override: got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StatusMatcher is the resolved form of expect.status. The config accepts a
+// single code (201), a class range ("2xx"), or a list of acceptable codes
+// ([200, 201, 204]); all three decode into the same matcher so validators
+// never care which form the config used.
+type StatusMatcher struct {
+	Codes []int // acceptable codes; a single-int config yields one entry
+	Class int   // 1-5 for an "Nxx" range, 0 otherwise
+}
+
+// ExactStatus matches exactly one status code — the pre-range behavior.
+func ExactStatus(code int) StatusMatcher {
+	return StatusMatcher{Codes: []int{code}}
+}
+
+// IsZero reports whether no status was configured (falls back to DefaultStatus).
+func (m StatusMatcher) IsZero() bool {
+	return m.Class == 0 && len(m.Codes) == 0
+}
+
+func (m StatusMatcher) Matches(code int) bool {
+	if m.Class != 0 {
+		return code/100 == m.Class
+	}
+	return slices.Contains(m.Codes, code)
+}
+
+// String renders the matcher for error messages ("200", "2xx", "one of 200, 201").
+func (m StatusMatcher) String() string {
+	if m.Class != 0 {
+		return fmt.Sprintf("%dxx", m.Class)
+	}
+	if len(m.Codes) == 1 {
+		return strconv.Itoa(m.Codes[0])
+	}
+	parts := make([]string, len(m.Codes))
+	for i, code := range m.Codes {
+		parts[i] = strconv.Itoa(code)
+	}
+	return "one of " + strings.Join(parts, ", ")
+}
+
+func (m StatusMatcher) validate() error {
+	if m.Class != 0 && (m.Class < 1 || m.Class > 5) {
+		return fmt.Errorf("expect.status range must be 1xx-5xx, got %dxx", m.Class)
+	}
+	for _, code := range m.Codes {
+		if code < 100 || code > 599 {
+			return errors.New("expect.status must be between 100 and 599")
+		}
+	}
+	return nil
+}
+
+func (m *StatusMatcher) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	switch dec.PeekKind() {
+	case 'n':
+		_, err := dec.ReadToken()
+		*m = StatusMatcher{}
+		return err
+	case '0':
+		var code int
+		if err := json.UnmarshalDecode(dec, &code); err != nil {
+			return err
+		}
+		// 0 keeps its historical meaning of "use the default status".
+		*m = StatusMatcher{}
+		if code != 0 {
+			*m = ExactStatus(code)
+		}
+		return nil
+	case '"':
+		var raw string
+		if err := json.UnmarshalDecode(dec, &raw); err != nil {
+			return err
+		}
+		class, err := parseStatusClass(raw)
+		if err != nil {
+			return err
+		}
+		*m = StatusMatcher{Class: class}
+		return nil
+	case '[':
+		var codes []int
+		if err := json.UnmarshalDecode(dec, &codes); err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return errors.New("expect.status list must not be empty")
+		}
+		*m = StatusMatcher{Codes: codes}
+		return nil
+	default:
+		return errors.New(`expect.status must be a code, a range like "2xx", or a list of codes`)
+	}
+}
+
+func (m StatusMatcher) MarshalJSONTo(enc *jsontext.Encoder) error {
+	switch {
+	case m.Class != 0:
+		return enc.WriteToken(jsontext.String(m.String()))
+	case len(m.Codes) == 1:
+		return enc.WriteToken(jsontext.Int(int64(m.Codes[0])))
+	default:
+		return json.MarshalEncode(enc, m.Codes)
+	}
+}
+
+// parseStatusClass parses an "Nxx" range into its leading digit.
+func parseStatusClass(raw string) (int, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if len(s) != 3 || s[1:] != "xx" || s[0] < '1' || s[0] > '5' {
+		return 0, fmt.Errorf(`expect.status range must look like "2xx", got %q`, raw)
+	}
+	return int(s[0] - '0'), nil
+}
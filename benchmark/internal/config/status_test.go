@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json/v2"
+	"strings"
+	"testing"
+)
+
+func TestStatusMatcherForms(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		raw     string
+		match   []int
+		reject  []int
+		want    string
+		wantErr string
+	}{
+		{name: "single int", raw: `201`, match: []int{201}, reject: []int{200, 204}, want: "201"},
+		{name: "class range", raw: `"2xx"`, match: []int{200, 201, 204, 299}, reject: []int{199, 301, 404}, want: "2xx"},
+		{name: "class range upper case", raw: `"4XX"`, match: []int{400, 422}, reject: []int{500}, want: "4xx"},
+		{name: "explicit list", raw: `[200, 201, 204]`, match: []int{200, 201, 204}, reject: []int{202, 500}, want: "one of 200, 201, 204"},
+		{name: "bad range", raw: `"2x"`, wantErr: `must look like "2xx"`},
+		{name: "out of range class", raw: `"6xx"`, wantErr: `must look like "2xx"`},
+		{name: "empty list", raw: `[]`, wantErr: "must not be empty"},
+		{name: "wrong type", raw: `true`, wantErr: "must be a code"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var m StatusMatcher
+			err := json.Unmarshal([]byte(tc.raw), &m)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error: got %v, want substring %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unmarshal %s: %v", tc.raw, err)
+			}
+			for _, code := range tc.match {
+				if !m.Matches(code) {
+					t.Errorf("%s should match %d", tc.raw, code)
+				}
+			}
+			for _, code := range tc.reject {
+				if m.Matches(code) {
+					t.Errorf("%s should not match %d", tc.raw, code)
+				}
+			}
+			if got := m.String(); got != tc.want {
+				t.Errorf("String: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyEndpointDefaultsStatus(t *testing.T) {
+	t.Parallel()
+
+	e := EndpointConfig{Route: "GET /"}
+	if err := applyEndpointDefaults("root", &e); err != nil {
+		t.Fatalf("applyEndpointDefaults: %v", err)
+	}
+	if !e.Expect.Status.Matches(DefaultStatus) || e.Expect.Status.Matches(201) {
+		t.Errorf("missing status should default to exactly %d, got %s", DefaultStatus, e.Expect.Status)
+	}
+
+	bad := EndpointConfig{Route: "GET /", Expect: ExpectConfig{Status: StatusMatcher{Codes: []int{200, 700}}}}
+	if err := applyEndpointDefaults("bad", &bad); err == nil || !strings.Contains(err.Error(), "between 100 and 599") {
+		t.Errorf("out-of-range list entry: got %v", err)
+	}
+
+	badVariation := EndpointConfig{
+		Route:      "GET /",
+		Variations: []VariationConfig{{Expect: &ExpectConfig{Status: ExactStatus(42)}}},
+	}
+	if err := applyEndpointDefaults("bad", &badVariation); err == nil || !strings.Contains(err.Error(), "variation 0") {
+		t.Errorf("out-of-range variation status: got %v", err)
+	}
+}
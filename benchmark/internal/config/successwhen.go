@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SuccessRule is a compiled expect.success_when: a response that passed the
+// other expect checks only counts as a success when the rule holds. The
+// syntax is deliberately small,
+//
+//	status == 200 && $.ok == true || status == 204
+//
+// comparisons joined by && (binding tighter) and ||, no parentheses. The left
+// side of a comparison is status or an expect.json_path path, the operator one
+// of == != < <= > >=, and the right side a JSON literal (number, "string",
+// true, false, null). Ordering operators need numbers on both sides. A path
+// missing from the body, or a body that isn't JSON, fails every comparison on
+// it, != included.
+type SuccessRule struct {
+	Expr     string                // as written, for failure messages
+	anyOf    [][]successComparison // OR of ANDs
+	needBody bool
+}
+
+type successComparison struct {
+	status bool              // left side is the status code
+	path   JSONPathAssertion // left side otherwise
+	op     string
+	want   any
+}
+
+var successOperators = []string{"==", "!=", "<=", ">=", "<", ">"} // two-char first so "<=" isn't read as "<"
+
+// NewSuccessRule compiles an expect.success_when expression.
+func NewSuccessRule(expr string) (*SuccessRule, error) {
+	rule := &SuccessRule{Expr: strings.TrimSpace(expr)}
+	if rule.Expr == "" {
+		return nil, errors.New("empty expression")
+	}
+	for _, alternative := range splitOutsideQuotes(rule.Expr, "||") {
+		var all []successComparison
+		for _, clause := range splitOutsideQuotes(alternative, "&&") {
+			c, err := parseSuccessComparison(strings.TrimSpace(clause))
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", strings.TrimSpace(clause), err)
+			}
+			rule.needBody = rule.needBody || !c.status
+			all = append(all, c)
+		}
+		rule.anyOf = append(rule.anyOf, all)
+	}
+	return rule, nil
+}
+
+func parseSuccessComparison(clause string) (successComparison, error) {
+	var c successComparison
+	at := -1
+	for _, op := range successOperators {
+		if i := indexOutsideQuotes(clause, op); i >= 0 && (at < 0 || i < at) {
+			at, c.op = i, op
+		}
+	}
+	if at < 0 {
+		return c, fmt.Errorf("want <status or $.path> <op> <value> with op one of %s", strings.Join(successOperators, " "))
+	}
+	left := strings.TrimSpace(clause[:at])
+	right := strings.TrimSpace(clause[at+len(c.op):])
+
+	if left == "status" {
+		c.status = true
+	} else {
+		path, err := NewJSONPathAssertion(left, nil)
+		if err != nil {
+			return c, fmt.Errorf("left side must be status or a $.path: %w", err)
+		}
+		c.path = path
+	}
+	if err := json.Unmarshal([]byte(right), &c.want); err != nil {
+		return c, fmt.Errorf("right side %q must be a JSON literal", right)
+	}
+	switch c.want.(type) {
+	case map[string]any, []any:
+		return c, fmt.Errorf("right side %q must be a number, string, boolean or null", right)
+	}
+	if _, isNumber := c.want.(float64); !isNumber && c.op != "==" && c.op != "!=" {
+		return c, fmt.Errorf("%s needs a number on the right", c.op)
+	}
+	if c.status {
+		if _, isNumber := c.want.(float64); !isNumber {
+			return c, errors.New("status compares against a number")
+		}
+	}
+	return c, nil
+}
+
+// NeedsBody reports whether any comparison reads the JSON body, so callers
+// can skip decoding it otherwise.
+func (r *SuccessRule) NeedsBody() bool {
+	return r.needBody
+}
+
+// Holds evaluates the rule against a response's status and its decoded JSON
+// body (nil when there was none or it didn't parse; hasBody tells those apart
+// from a literal null).
+func (r *SuccessRule) Holds(status int, document any, hasBody bool) bool {
+	for _, all := range r.anyOf {
+		ok := true
+		for i := range all {
+			if !all[i].holds(status, document, hasBody) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *successComparison) holds(status int, document any, hasBody bool) bool {
+	var got any = float64(status)
+	if !c.status {
+		if !hasBody {
+			return false
+		}
+		var present bool
+		if got, present = c.path.Lookup(document); !present {
+			return false
+		}
+	}
+
+	switch c.op {
+	case "==":
+		return got == c.want
+	case "!=":
+		return got != c.want
+	}
+	g, ok := got.(float64)
+	if !ok {
+		return false
+	}
+	w := c.want.(float64)
+	switch c.op {
+	case "<":
+		return g < w
+	case "<=":
+		return g <= w
+	case ">":
+		return g > w
+	default:
+		return g >= w
+	}
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside '...' or
+// "..." (string literals and quoted path members).
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	for {
+		i := indexOutsideQuotes(s, sep)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+}
+
+func indexOutsideQuotes(s, sub string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], sub):
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuccessRule(t *testing.T) {
+	t.Parallel()
+
+	doc := map[string]any{"ok": false, "count": 3.0, "error": nil, "data": map[string]any{"a||b": "x && y"}}
+	tests := []struct {
+		expr   string
+		status int
+		want   bool
+	}{
+		{"$.ok == true", 200, false},
+		{"$.ok == false", 200, true},
+		{"$.ok != true", 200, true},
+		{"status == 200 && $.ok == true", 200, false},
+		{"status == 200 && $.ok == true || status == 204", 204, true},
+		{"$.count >= 3 && $.count < 4", 200, true},
+		{"$.count > 3", 200, false},
+		{"$.error == null", 200, true},
+		{"$.missing != 1", 200, false}, // absent paths fail every comparison
+		{"$.ok > 1", 200, false},       // ordering a non-number fails
+		{`$.data['a||b'] == "x && y"`, 200, true},
+		{"status<300", 201, true},
+	}
+	for _, tt := range tests {
+		rule, err := NewSuccessRule(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := rule.Holds(tt.status, doc, true); got != tt.want {
+			t.Errorf("%s (status %d): got %v, want %v", tt.expr, tt.status, got, tt.want)
+		}
+	}
+
+	statusOnly, _ := NewSuccessRule("status == 200")
+	if statusOnly.NeedsBody() || !statusOnly.Holds(200, nil, false) {
+		t.Errorf("status-only rule: needs body %v, holds %v", statusOnly.NeedsBody(), statusOnly.Holds(200, nil, false))
+	}
+	if rule, _ := NewSuccessRule("$.ok == false"); rule.Holds(200, nil, false) {
+		t.Error("path comparison held without a JSON body")
+	}
+
+	invalid := map[string]string{
+		"":                "empty expression",
+		"$.ok":            "want <status or $.path>",
+		"ok == true":      "left side must be status",
+		"$.ok == yes":     "must be a JSON literal",
+		`$.name < "b"`:    "needs a number",
+		`status == "200"`: "status compares against a number",
+		"$.a == {}":       "must be a number, string, boolean or null",
+	}
+	for expr, want := range invalid {
+		if _, err := NewSuccessRule(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: got %v, want error containing %q", expr, err, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Tokens a dynamic: true endpoint may use in its body or form_data values.
+const (
+	TokenRandInt = "rand_int" // random integer in [0, 2^31)
+	TokenUUID    = "uuid"     // random version 4 UUID
+	TokenSeq     = "seq"      // per-testcase counter starting at 1, shared by its workers
+)
+
+var bodyTokens = []string{TokenRandInt, TokenUUID, TokenSeq}
+
+// DynamicBody is a dynamic endpoint's request body, compiled once at resolve
+// time and rendered per request instead of the cached Body / CachedFormBody.
+type DynamicBody struct {
+	JSON *BodyTemplate            // RequestTypeJSON
+	Form map[string]*BodyTemplate // RequestTypeForm, by field name
+	Seq  atomic.Uint64            // {{seq}} counter
+}
+
+// BodyTemplate is a string split around its {{token}}s, so rendering is a
+// single pass of appends.
+type BodyTemplate struct {
+	Parts []TemplatePart
+}
+
+// TemplatePart is literal text followed by an optional token ("" for the
+// trailing literal).
+type TemplatePart struct {
+	Literal string
+	Token   string
+}
+
+// parseBodyTemplate compiles s. With unquoteNumbers (a JSON body) a numeric
+// token that is an entire JSON string — "{{rand_int}}" — loses its quotes so
+// the field is sent as a number.
+func parseBodyTemplate(s string, unquoteNumbers bool) (*BodyTemplate, error) {
+	t := &BodyTemplate{}
+	rest := s
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			t.Parts = append(t.Parts, TemplatePart{Literal: rest})
+			return t, nil
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, errors.New(`unterminated "{{"`)
+		}
+		token := strings.TrimSpace(rest[start+2 : start+end])
+		if !slices.Contains(bodyTokens, token) {
+			return nil, fmt.Errorf("unknown token {{%s}} (want %s)", token, strings.Join(bodyTokens, ", "))
+		}
+		literal, after := rest[:start], rest[start+end+2:]
+		if unquoteNumbers && token != TokenUUID && strings.HasSuffix(literal, `"`) && strings.HasPrefix(after, `"`) {
+			literal, after = literal[:len(literal)-1], after[1:]
+		}
+		t.Parts = append(t.Parts, TemplatePart{Literal: literal, Token: token})
+		rest = after
+	}
+}
+
+// resolveDynamicBody compiles tc's body for dynamic: true. Multipart uploads
+// are rejected: their boundary-framed body can't be re-rendered cheaply.
+func resolveDynamicBody(tc *Testcase, rawBody any) (*DynamicBody, error) {
+	dynamic := &DynamicBody{}
+	switch tc.RequestType {
+	case RequestTypeJSON:
+		_, isString := rawBody.(string)
+		t, err := parseBodyTemplate(tc.Body, !isString)
+		if err != nil {
+			return nil, fmt.Errorf("dynamic body: %w", err)
+		}
+		dynamic.JSON = t
+	case RequestTypeForm:
+		dynamic.Form = make(map[string]*BodyTemplate, len(tc.FormData))
+		for _, field := range slices.Sorted(maps.Keys(tc.FormData)) {
+			t, err := parseBodyTemplate(tc.FormData[field], false)
+			if err != nil {
+				return nil, fmt.Errorf("dynamic form_data %q: %w", field, err)
+			}
+			dynamic.Form[field] = t
+		}
+	default:
+		return nil, errors.New("dynamic does not support file uploads")
+	}
+	return dynamic, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBodyTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		body           string
+		unquoteNumbers bool
+		want           []TemplatePart
+	}{
+		{`plain`, false, []TemplatePart{{Literal: "plain"}}},
+		{`{"n":"{{rand_int}}"}`, true, []TemplatePart{{Literal: `{"n":`, Token: TokenRandInt}, {Literal: "}"}}},
+		{`{"n":"{{rand_int}}"}`, false, []TemplatePart{{Literal: `{"n":"`, Token: TokenRandInt}, {Literal: `"}`}}},
+		{`{"id":"{{uuid}}"}`, true, []TemplatePart{{Literal: `{"id":"`, Token: TokenUUID}, {Literal: `"}`}}},
+		{`{"s":"a-{{ seq }}"}`, true, []TemplatePart{{Literal: `{"s":"a-`, Token: TokenSeq}, {Literal: `"}`}}},
+	}
+	for _, tt := range tests {
+		got, err := parseBodyTemplate(tt.body, tt.unquoteNumbers)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.body, err)
+			continue
+		}
+		if len(got.Parts) != len(tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.body, got.Parts, tt.want)
+			continue
+		}
+		for i := range got.Parts {
+			if got.Parts[i] != tt.want[i] {
+				t.Errorf("%s part %d: got %+v, want %+v", tt.body, i, got.Parts[i], tt.want[i])
+			}
+		}
+	}
+
+	for body, wantErr := range map[string]string{
+		`{"n":"{{rand}}"}`: "unknown token {{rand}}",
+		`{"n":"{{seq"}`:    `unterminated "{{"`,
+	} {
+		if _, err := parseBodyTemplate(body, true); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: got %v, want error containing %q", body, err, wantErr)
+		}
+	}
+}
+
+func TestResolveDynamicErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		`{"route": "GET /x", "dynamic": true}`:                                       "dynamic requires a body or form_data",
+		`{"route": "POST /x", "dynamic": true, "file": "a.txt"}`:                     "dynamic does not support file uploads",
+		`{"route": "POST /x", "dynamic": true, "body": {"id": "{{serial}}"}}`:        `endpoint "x": dynamic body: unknown token {{serial}}`,
+		`{"route": "POST /x", "dynamic": true, "body": {}, "sequence": {"id": "s"}}`: "not supported on sequence steps",
+	}
+	for endpoint, wantErr := range tests {
+		_, _, err := loadTestTarget(t, `{"endpoints": {"x": `+endpoint+`}}`)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: got %v, want error containing %q", endpoint, err, wantErr)
+		}
+	}
+}
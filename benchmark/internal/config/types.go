@@ -8,27 +8,71 @@ type Config struct {
 	Benchmark     BenchmarkConfig           `json:"benchmark"`
 	Container     ContainerConfig           `json:"container"`
 	Databases     []string                  `json:"databases"`
+	Database      DatabaseConfig            `json:"database,omitzero"`
 	Endpoints     map[string]EndpointConfig `json:"endpoints"`
 	EndpointOrder []string                  `json:"-"`
+	Dir           string                    `json:"-"` // directory of the loaded config file; upload files resolve against it
+	Warnings      []string                  `json:"-"` // non-fatal problems found while resolving, for the caller to print
 }
 
 type BenchmarkConfig struct {
-	BaseUrl                string     `json:"base_url"`
-	Concurrency            int        `json:"concurrency"`
-	DurationPerEndpointRaw string     `json:"duration_per_endpoint"`
-	RequestTimeoutRaw      string     `json:"request_timeout"`
-	SampleRateRaw          string     `json:"sample_rate,omitempty"`
-	ServerCooldownRaw      string     `json:"server_cooldown,omitempty"`
-	WarmupDurationRaw      string     `json:"warmup_duration,omitempty"`
-	WarmupPauseRaw         string     `json:"warmup_pause,omitempty"`
-	Load                   LoadConfig `json:"load,omitzero"`
+	BaseUrl                string              `json:"base_url"`
+	BaseUrls               []string            `json:"base_urls,omitempty"` // --target mode: extra hosts to spread requests across
+	ConcurrencyRaw         ConcurrencySpec     `json:"concurrency"`
+	DurationPerEndpointRaw string              `json:"duration_per_endpoint"`
+	RequestTimeoutRaw      string              `json:"request_timeout"`
+	SampleRateRaw          string              `json:"sample_rate,omitempty"`
+	ServerCooldownRaw      string              `json:"server_cooldown,omitempty"`
+	CooldownUntilIdle      *CooldownIdleConfig `json:"cooldown_until_idle,omitempty"` // wait for idle DB containers; replaces server_cooldown when set
+	WarmupDurationRaw      string              `json:"warmup_duration,omitempty"`
+	WarmupPauseRaw         string              `json:"warmup_pause,omitempty"`
+	WarmupUntilStable      *WarmupStableConfig `json:"warmup_until_stable,omitempty"` // adaptive warmup; replaces warmup_duration when set
+	Load                   LoadConfig          `json:"load,omitzero"`
+	SeedFlow               string              `json:"seed_flow,omitempty"`                // sequence id run once after reset, before warmup; never measured
+	MixedMode              bool                `json:"mixed_mode,omitempty"`               // run all endpoints at once from one weighted worker pool
+	MaxBodyBytes           int64               `json:"max_body_bytes,omitempty"`           // response read cap (default 1MB)
+	AbortBelowRaw          string              `json:"abort_below_success_rate,omitempty"` // e.g. "90%"; "" or "0%" disables
+	MaxSamples             int                 `json:"max_samples,omitempty"`              // per-endpoint latency reservoir size (0 = keep all)
+	MaxConns               int                 `json:"max_conns,omitempty"`                // connections per host, independent of workers (0 = one per worker)
+	MaxTotalConns          int                 `json:"max_total_conns,omitempty"`          // requests in flight at once across all workers and hosts (0 = unlimited)
+	RequestsPerEndpoint    int                 `json:"requests_per_endpoint,omitempty"`    // stop each endpoint after N successful requests; excludes duration_per_endpoint
+	Estimator              string              `json:"estimator,omitempty"`                // percentile estimator: "exact" (default) or "tdigest"
+	FailFast               int                 `json:"fail_fast,omitempty"`                // abort an endpoint whose first N requests all fail (0 = off)
+	UserAgent              string              `json:"user_agent,omitempty"`               // User-Agent on every request unless the endpoint sets one ("" = Go's default)
+	DefaultAccept          string              `json:"default_accept,omitempty"`           // Accept on every request unless the endpoint sets one; replaces the derived defaults
+	ReplayFile             string              `json:"replay_file,omitempty"`              // JSON-lines request trace replayed in order instead of the standalone endpoints
+	TotalDurationRaw       string              `json:"total_duration,omitempty"`           // measured-time budget split across endpoints by weight; replaces duration_per_endpoint
+	ConnectTimeoutRaw      string              `json:"connect_timeout,omitempty"`          // dial and wait-for-response-headers bound, under request_timeout
+	ServerRetries          int                 `json:"server_retries,omitempty"`           // extra container start + readiness attempts per server (0 = none)
+	FollowRedirectsRaw     *bool               `json:"follow_redirects,omitempty"`         // nil = true; false validates a 3xx as the response
+	CacheHeader            string              `json:"cache_header,omitempty"`             // response header whose "hit" value marks a cached response (default X-Cache)
+	DiscardFirstRaw        string              `json:"discard_first_percent,omitempty"`    // e.g. "10%": leading share of each endpoint's measured successes left out of the stats
 
+	Concurrency         int           `json:"-"` // workers, with a "4x" concurrency resolved against this machine's CPUs
 	DurationPerEndpoint time.Duration `json:"-"`
+	TotalDuration       time.Duration `json:"-"` // 0 = off; see ApplyTotalDuration
 	RequestTimeout      time.Duration `json:"-"`
+	ConnectTimeout      time.Duration `json:"-"` // 0 = off: a 5s dial cap, headers bounded only by RequestTimeout
 	SampleRatePct       float64       `json:"-"`
 	ServerCooldown      time.Duration `json:"-"`
 	WarmupDuration      time.Duration `json:"-"`
 	WarmupPause         time.Duration `json:"-"`
+	AbortBelow          float64       `json:"-"` // success-rate fraction that aborts the run; 0 disables
+	FollowRedirects     bool          `json:"-"` // follow_redirects with its default applied
+	DiscardFirst        float64       `json:"-"` // discard_first_percent as a fraction; 0 keeps every sample
+}
+
+// Connections is the per-host connection pool size: max_conns when set,
+// otherwise the mode's real parallelism (concurrency workers, or
+// max_in_flight in open mode). Workers beyond it queue for a connection.
+func (b *BenchmarkConfig) Connections() int {
+	if b.MaxConns > 0 {
+		return b.MaxConns
+	}
+	if b.Load.Mode == LoadModeOpen {
+		return b.Load.MaxInFlight
+	}
+	return b.Concurrency
 }
 
 // LoadConfig selects the load model (PLAN §7.1). "closed" (default) is the
@@ -54,6 +98,33 @@ type LoadConfig struct {
 	MaxInFlight int `json:"max_in_flight,omitempty"`
 }
 
+// WarmupStableConfig ends warmup once latency settles instead of after a fixed
+// duration: warmup runs in back-to-back windows and stops when the P50s of the
+// last Windows windows are within Threshold of the smallest of them, or when
+// MaxDuration elapses without that happening.
+type WarmupStableConfig struct {
+	WindowRaw      string `json:"window,omitempty"`       // default "1s"
+	ThresholdRaw   string `json:"threshold,omitempty"`    // default "5%"
+	Windows        int    `json:"windows,omitempty"`      // consecutive stable windows required (default 3, min 2)
+	MaxDurationRaw string `json:"max_duration,omitempty"` // default "30s"
+
+	Window      time.Duration `json:"-"`
+	Threshold   float64       `json:"-"` // fraction, e.g. 0.05
+	MaxDuration time.Duration `json:"-"`
+}
+
+// CooldownIdleConfig replaces the fixed server_cooldown sleep with a wait
+// until every database container's CPU is below CpuThreshold, capped at
+// MaxDuration. The server container is gone by then; the shared databases
+// are what carry load over into the next server's run.
+type CooldownIdleConfig struct {
+	CpuThresholdRaw string `json:"cpu_threshold,omitempty"` // default "5%" (of one core)
+	MaxDurationRaw  string `json:"max_duration,omitempty"`  // default "60s"
+
+	CpuThreshold float64       `json:"-"` // percent of one core, e.g. 5
+	MaxDuration  time.Duration `json:"-"`
+}
+
 type StageConfig struct {
 	Target      float64 `json:"target"`   // arrival rate at the end of the stage (req/sec)
 	DurationRaw string  `json:"duration"` // stage length, e.g. "30s"
@@ -61,9 +132,19 @@ type StageConfig struct {
 	Duration time.Duration `json:"-"`
 }
 
+// DatabaseConfig overrides how the client talks to a server's database routes.
+type DatabaseConfig struct {
+	ResetPath string `json:"reset_path,omitempty"` // DELETE route template with {database} (default "/db/{database}/reset")
+}
+
 type ContainerConfig struct {
 	CpuLimit    float64 `json:"cpu_limit"`
 	MemoryLimit string  `json:"memory_limit"`
+
+	// ReadyExec is a command run inside each server container (docker exec),
+	// polled until it exits 0, in place of the GET /health readiness check.
+	// Database health checks still follow it. Unset keeps GET /health.
+	ReadyExec []string `json:"ready_exec,omitempty"`
 }
 
 type EndpointConfig struct {
@@ -79,13 +160,75 @@ type EndpointConfig struct {
 	PerDatabase bool              `json:"per_database,omitempty"`
 	Variations  []VariationConfig `json:"variations,omitempty"`
 	Sequence    *SequenceConfig   `json:"sequence,omitempty"`
+	Weight      int               `json:"weight,omitempty"`    // mixed_mode share relative to other endpoints (default 1)
+	Tags        []string          `json:"tags,omitempty"`      // labels for grouping results and --tag-filter
+	PathVars    map[string]string `json:"path_vars,omitempty"` // static {name} path substitutions, applied at resolve time
+	// ChunkedRequest sends the request body with Transfer-Encoding: chunked
+	// and no Content-Length, to exercise a server's streaming-body handling.
+	ChunkedRequest bool `json:"chunked_request,omitempty"`
+	// Dynamic renders {{rand_int}}, {{uuid}} and {{seq}} in the body or
+	// form_data per request, so servers can't serve repeated bodies from a
+	// cache; other endpoints keep sending their body cached at resolve time.
+	Dynamic bool `json:"dynamic,omitempty"`
+	// ContentType replaces application/json on a JSON body, e.g. for APIs
+	// that want application/vnd.api+json; headers.Content-Type still wins.
+	ContentType string `json:"content_type,omitempty"`
+	// ExcludeDatabases drops these databases from the per_database expansion,
+	// for an endpoint that means nothing on some of them (e.g. transactions on redis).
+	ExcludeDatabases []string `json:"exclude_databases,omitempty"`
+	// ExpectedAvgRaw/ExpectedP99Raw document the latency an endpoint is
+	// expected to show; the summary prints measured against them. Purely
+	// informational: a miss never fails the endpoint or the run.
+	ExpectedAvgRaw string `json:"expected_avg,omitempty"`
+	ExpectedP99Raw string `json:"expected_p99,omitempty"`
+
+	// Type is EndpointTypeSSE for a Server-Sent Events stream, read per SSE;
+	// "" is a plain request whose whole body is read.
+	Type string     `json:"type,omitempty"`
+	SSE  *SSEConfig `json:"sse,omitempty"`
+
+	ExpectedAvg time.Duration `json:"-"`
+	ExpectedP99 time.Duration `json:"-"`
+}
+
+// EndpointTypeSSE marks an endpoint that answers with text/event-stream.
+const EndpointTypeSSE = "sse"
+
+// DefaultSSEEvents is how many events one request of an sse endpoint reads
+// when sse.events is unset.
+const DefaultSSEEvents = 10
+
+// SSEConfig bounds one read of an sse endpoint's event stream: the request
+// ends after Events events or MaxDuration, whichever comes first, and the
+// connection is closed. Its latency is the time to the first event; the gaps
+// between later events are reported separately as the steady-state rate.
+type SSEConfig struct {
+	Events         int    `json:"events,omitempty"`       // events per request (default DefaultSSEEvents)
+	MaxDurationRaw string `json:"max_duration,omitempty"` // read window, under request_timeout ("" = until Events arrive)
+
+	MaxDuration time.Duration `json:"-"`
 }
 
 type ExpectConfig struct {
-	Status  int               `json:"status,omitempty"`
+	Status  StatusMatcher     `json:"status,omitzero"` // 200, "2xx", or [200, 201, 204]
 	Body    any               `json:"body,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 	Text    string            `json:"text,omitempty"`
+	// ValidJSON only checks that the body parses as JSON, without comparing
+	// its structure; for endpoints whose JSON is dynamic.
+	ValidJSON bool `json:"valid_json,omitempty"`
+	// JSONPath asserts single nested values, e.g. {"$.data[0].id": "*"}; the
+	// value "*" only requires the path to exist.
+	JSONPath map[string]any `json:"json_path,omitempty"`
+	// SuccessWhen is an extra condition on status and JSON body, e.g.
+	// "$.ok == true", for APIs that report errors inside a 200 (SuccessRule).
+	SuccessWhen string `json:"success_when,omitempty"`
+	// AbsentHeaders lists response headers that must not be sent at all,
+	// e.g. ["Server"] to catch a leaked version string.
+	AbsentHeaders []string `json:"absent_headers,omitempty"`
+	// BodyFile names a JSON file in the upload fixtures directory whose
+	// contents become Body at load, for expected bodies too large to inline.
+	BodyFile string `json:"body_file,omitempty"`
 }
 
 type VariationConfig struct {
@@ -102,6 +245,11 @@ type SequenceConfig struct {
 	Id      string               `json:"id"`
 	Capture map[string]string    `json:"capture,omitempty"` // {"id": "id"} = capture response.id as {id}
 	Vars    map[string]VarConfig `json:"vars,omitempty"`    // variable definitions (only on first endpoint)
+	When    string               `json:"when,omitempty"`    // "{id}" = run this step only if every {var} here is set and non-empty
+	// DatabaseWeights turns a per_database flow into one flow whose every run
+	// picks a database by weight, e.g. {"postgres": 3, "mongodb": 1}. Set on
+	// one step; databases not listed are not used.
+	DatabaseWeights map[string]float64 `json:"database_weights,omitempty"`
 }
 
 type VarConfig struct {
@@ -116,15 +264,47 @@ type ResolvedSequence struct {
 	Database  string // empty if not per_database
 	Vars      map[string]VarConfig
 	Endpoints []*ResolvedSequenceEndpoint
+	Tags      []string // union of the step endpoints' tags; the flow is filtered as a whole
+
+	// DatabaseChoices are the per-database resolutions a database_weights
+	// flow picks from on each run, with DatabaseWeights aligned to them;
+	// Endpoints then keep {database} unfilled (empty = run as is).
+	DatabaseChoices []*ResolvedSequence
+	DatabaseWeights []float64
+}
+
+// PickDatabase returns the resolution a run of s uses: s itself, or for a
+// database_weights flow the choice that r, uniform in [0, 1), lands on.
+func (s *ResolvedSequence) PickDatabase(r float64) *ResolvedSequence {
+	if len(s.DatabaseChoices) == 0 {
+		return s
+	}
+	var total float64
+	for _, w := range s.DatabaseWeights {
+		total += w
+	}
+	target := r * total
+	for i, w := range s.DatabaseWeights {
+		if target < w {
+			return s.DatabaseChoices[i]
+		}
+		target -= w
+	}
+	return s.DatabaseChoices[len(s.DatabaseChoices)-1]
 }
 
 type ResolvedSequenceEndpoint struct {
 	Name           string
 	Method         string
-	Path           string // with {database} replaced, but {id} etc preserved
+	Path           string // with {database} and path_vars replaced, but {id} etc preserved
 	Body           any
 	Headers        map[string]string
-	ExpectedStatus int
+	ExpectedStatus StatusMatcher
 	ExpectedBody   any
 	Capture        map[string]string
+	ChunkedRequest bool     // chunked_request: stream the body without Content-Length
+	When           []string // sequence.when's {var} names, all of which must be set; empty runs unconditionally
+	// OptionalCaptures are the captures a later step's when tests: a response
+	// without the field leaves the variable unset instead of failing the step.
+	OptionalCaptures map[string]bool
 }
@@ -64,7 +64,7 @@ func Run(ctx context.Context, baseURL, contractDir, testFilesDir string, skipSui
 	suites, err := loadSuites(contractDir)
 	if err != nil {
 		cli.Failf("%v", err)
-		return 1
+		return cli.ExitConfig
 	}
 
 	cli.Header("Contract Conformance")
@@ -82,14 +82,14 @@ func Run(ctx context.Context, baseURL, contractDir, testFilesDir string, skipSui
 	// never a pass.
 	if passed+failed == 0 {
 		cli.Failf("no contract cases were executed — check --contract-dir, --skip-suite, and suite contents")
-		return 1
+		return cli.ExitConfig
 	}
 
 	printSummary(passed, failed, failures)
 	if failed > 0 {
-		return 1
+		return cli.ExitFailures
 	}
-	return 0
+	return cli.ExitOK
 }
 
 // runSuites executes every non-skipped suite's cases in order and tallies the
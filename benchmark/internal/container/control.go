@@ -2,6 +2,7 @@ package container
 
 import (
 	"context"
+	"fmt"
 	"os/exec"
 	"strings"
 )
@@ -13,7 +14,8 @@ import (
 // The preflight stays on the docker CLI on purpose: the benchmark requires
 // images to be pre-built (`just images`) and fails fast with that hint before
 // testcontainers would otherwise try to pull a local-only `bench/*` image from a
-// registry and produce a confusing error.
+// registry and produce a confusing error. Pulling is opt-in (--pull) and only
+// for images that are published to a registry.
 
 func ImageExists(ctx context.Context, imageName string) bool {
 	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", imageName) //nolint:gosec // imageName is from trusted config
@@ -27,6 +29,22 @@ func ImageExists(ctx context.Context, imageName string) bool {
 	return cmd.Run() == nil
 }
 
+// Pull fetches imageName with `docker pull`. The error carries docker's last
+// output line (e.g. "pull access denied"), which is what a local-only image
+// produces.
+func Pull(ctx context.Context, imageName string) error {
+	cmd := exec.CommandContext(ctx, "docker", "pull", "--quiet", imageName) //nolint:gosec // imageName is from trusted config
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return fmt.Errorf("docker pull %s: %s", imageName, last)
+	}
+	return fmt.Errorf("docker pull %s: %w", imageName, err)
+}
+
 func hasTagOrDigest(imageName string) bool {
 	if strings.Contains(imageName, "@") {
 		return true
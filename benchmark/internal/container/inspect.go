@@ -0,0 +1,83 @@
+package container
+
+import (
+	"context"
+	"encoding/json/v2"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// State is a container's run state as `docker inspect` reports it. The
+// benchmark inspects a server's container before stopping it, so a server
+// the kernel OOM-killed mid-run shows up as that rather than only as a wall
+// of connection errors.
+type State struct {
+	Status    string `json:"status"`
+	ExitCode  int    `json:"exit_code"`
+	OOMKilled bool   `json:"oom_killed,omitzero"`
+}
+
+// Issue describes a container that is no longer running as it should:
+// "container OOM-killed" or "exited with code N". Empty while it runs.
+func (s State) Issue() string {
+	switch {
+	case s.OOMKilled:
+		return "container OOM-killed"
+	case s.Status != "running":
+		return fmt.Sprintf("exited with code %d", s.ExitCode)
+	}
+	return ""
+}
+
+type dockerInspectAPI struct {
+	State struct {
+		Status    string `json:"Status"`
+		OOMKilled bool   `json:"OOMKilled"`
+		ExitCode  int    `json:"ExitCode"`
+	} `json:"State"`
+}
+
+// Inspect reads a container's state from the docker API.
+func Inspect(ctx context.Context, containerId string) (State, error) {
+	url := fmt.Sprintf("http://localhost/containers/%s/json", containerId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return State{}, err
+	}
+
+	resp, err := dockerStatsClient.Do(req)
+	if err != nil {
+		return State{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return State{}, fmt.Errorf("docker inspect %.12s: status %d", containerId, resp.StatusCode)
+	}
+
+	var inspect dockerInspectAPI
+	if err := json.UnmarshalRead(resp.Body, &inspect); err != nil {
+		return State{}, fmt.Errorf("docker inspect %.12s: %w", containerId, err)
+	}
+	return State{
+		Status:    inspect.State.Status,
+		ExitCode:  inspect.State.ExitCode,
+		OOMKilled: inspect.State.OOMKilled,
+	}, nil
+}
+
+// Exec runs cmd inside a running container with `docker exec` and returns nil
+// when it exits 0. The error carries the command's last output line, if any.
+func Exec(ctx context.Context, containerId string, cmd []string) error {
+	args := append([]string{"exec", containerId}, cmd...)
+	output, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput() //nolint:gosec // cmd is from trusted config
+	if err == nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return fmt.Errorf("docker exec %.12s: %w: %s", containerId, err, last)
+	}
+	return fmt.Errorf("docker exec %.12s: %w", containerId, err)
+}
@@ -0,0 +1,20 @@
+package container
+
+import "testing"
+
+func TestStateIssue(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{State{Status: "running"}, ""},
+		{State{Status: "exited", ExitCode: 2}, "exited with code 2"},
+		{State{Status: "exited", ExitCode: 137, OOMKilled: true}, "container OOM-killed"},
+		{State{Status: "running", OOMKilled: true}, "container OOM-killed"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.Issue(); got != tt.want {
+			t.Errorf("%+v.Issue() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/moby/moby/api/types/container"
 	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 // StartOptions describes one server-under-test container.
@@ -25,6 +24,14 @@ type StartOptions struct {
 	Network        string // docker network to join for DB service-name DNS
 	Databases      []string
 	StartupTimeout time.Duration
+	// Env and Cmd come from the manifest's env/cmd overrides. Env is added to
+	// the image's environment; a non-empty Cmd replaces the image CMD (the
+	// ENTRYPOINT, if any, still runs with it as arguments).
+	Env map[string]string
+	Cmd []string
+	// ReadyExec, when set, is run inside the container until it exits 0 in
+	// place of the GET /health readiness check (config container.ready_exec).
+	ReadyExec []string
 }
 
 // Server is a running server-under-test container with its dynamically mapped
@@ -34,6 +41,9 @@ type Server struct {
 	ID       string // full container id (for resource sampling)
 	HostPort int    // dynamically mapped host port
 	BaseURL  string // e.g. "http://localhost:54123" (no trailing slash)
+	// Startup is the time from `docker start` until every readiness check
+	// passed, so it includes up to one health-poll interval of slack.
+	Startup time.Duration
 }
 
 // Start launches the server image via testcontainers-go, applying CPU/memory
@@ -49,13 +59,6 @@ func Start(ctx context.Context, opts *StartOptions) (*Server, error) {
 		return nil, err
 	}
 
-	// Readiness: server first, then each DB dependency it exposes. Every
-	// strategy targets the single exposed port (default status matcher = 200).
-	strategies := make([]wait.Strategy, 0, len(opts.Databases)+1)
-	strategies = append(strategies, wait.ForHTTP("/health"))
-	for _, db := range opts.Databases {
-		strategies = append(strategies, wait.ForHTTP("/db/"+db+"/health"))
-	}
 	startupTimeout := opts.StartupTimeout
 	if startupTimeout <= 0 {
 		startupTimeout = 60 * time.Second
@@ -64,7 +67,11 @@ func Start(ctx context.Context, opts *StartOptions) (*Server, error) {
 	req := testcontainers.ContainerRequest{
 		Image:        opts.Image,
 		ExposedPorts: []string{portSpec},
-		WaitingFor:   wait.ForAll(strategies...).WithStartupTimeoutDefault(startupTimeout),
+		Env:          opts.Env,
+		Cmd:          opts.Cmd,
+		// Readiness: server first, then each DB dependency it exposes, all on
+		// the single exposed port (see readinessStrategy).
+		WaitingFor: &readinessStrategy{port: portSpec, checks: readinessChecks(opts.Databases, opts.ReadyExec), timeout: startupTimeout},
 		HostConfigModifier: func(hc *container.HostConfig) {
 			if opts.CpuLimit > 0 {
 				hc.NanoCPUs = int64(opts.CpuLimit * 1e9)
@@ -77,11 +84,19 @@ func Start(ctx context.Context, opts *StartOptions) (*Server, error) {
 	if opts.Network != "" {
 		req.Networks = []string{opts.Network}
 	}
+	var startedAt time.Time
+	req.LifecycleHooks = []testcontainers.ContainerLifecycleHooks{{
+		PreStarts: []testcontainers.ContainerHook{func(context.Context, testcontainers.Container) error {
+			startedAt = time.Now()
+			return nil
+		}},
+	}}
 
 	ctr, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: req,
 		Started:          true,
 	})
+	startup := time.Since(startedAt)
 	if err != nil {
 		// A failed wait still yields a created container; terminate it so the run
 		// doesn't leak one (ryuk would eventually reap it, but not before it
@@ -109,6 +124,7 @@ func Start(ctx context.Context, opts *StartOptions) (*Server, error) {
 		ID:       ctr.GetContainerID(),
 		HostPort: hostPort,
 		BaseURL:  "http://" + net.JoinHostPort(host, strconv.Itoa(hostPort)),
+		Startup:  startup,
 	}, nil
 }
 
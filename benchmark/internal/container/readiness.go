@@ -0,0 +1,227 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// Readiness polls start fast so quick servers aren't penalized in Startup,
+	// then back off exponentially so slow starters (JVM, cold caches) aren't
+	// hammered while they boot.
+	readyInitialInterval = 50 * time.Millisecond
+	readyMaxInterval     = 2 * time.Second
+	readyRequestTimeout  = 2 * time.Second
+	readyBodySnippet     = 200 // bytes of the last failing response body kept for the error
+)
+
+// readinessCheck is one route that must answer 200 before the server is ready,
+// or with exec set a command that must exit 0 inside the container.
+type readinessCheck struct {
+	label string // "server health" or "database <db> health", for diagnostics
+	path  string
+	exec  []string
+}
+
+// probed names what the check polls, for diagnostics.
+func (c *readinessCheck) probed() string {
+	if c.exec != nil {
+		return "exec " + strings.Join(c.exec, " ")
+	}
+	return c.path
+}
+
+// readinessChecks lists the server check, GET /health or readyExec when set,
+// then each database's health route.
+func readinessChecks(databases, readyExec []string) []readinessCheck {
+	checks := make([]readinessCheck, 0, len(databases)+1)
+	if len(readyExec) > 0 {
+		checks = append(checks, readinessCheck{label: "server health", exec: readyExec})
+	} else {
+		checks = append(checks, readinessCheck{label: "server health", path: "/health"})
+	}
+	for _, db := range databases {
+		checks = append(checks, readinessCheck{label: "database " + db + " health", path: "/db/" + db + "/health"})
+	}
+	return checks
+}
+
+// readinessStrategy is the testcontainers wait strategy for a server under test:
+// each check in order, polled with capped exponential backoff, all within one
+// overall timeout. On timeout the error names the check that never passed and
+// its last status and body, which the stock HTTP strategy doesn't surface.
+type readinessStrategy struct {
+	port    string // container port spec, e.g. "8080/tcp"
+	checks  []readinessCheck
+	timeout time.Duration
+}
+
+var _ wait.Strategy = (*readinessStrategy)(nil)
+
+func (s *readinessStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	host, err := target.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve container host: %w", err)
+	}
+	var mapped int
+	for {
+		port, portErr := target.MappedPort(ctx, s.port)
+		if portErr == nil {
+			mapped = int(port.Num())
+			break
+		}
+		if !sleepCtx(ctx, readyInitialInterval) {
+			return fmt.Errorf("map port %s within %s: %w", s.port, s.timeout, portErr)
+		}
+	}
+
+	alive := func(ctx context.Context) error {
+		state, err := target.State(ctx)
+		if err != nil {
+			return nil // transient inspect failure; keep polling and let the timeout decide
+		}
+		switch {
+		case state.OOMKilled:
+			return errors.New("container was OOM-killed during startup")
+		case !state.Running:
+			return fmt.Errorf("container exited with code %d during startup", state.ExitCode)
+		}
+		return nil
+	}
+	var probe execProbe
+	if slices.ContainsFunc(s.checks, func(c readinessCheck) bool { return c.exec != nil }) {
+		inspect, err := target.Inspect(ctx)
+		if err != nil {
+			return fmt.Errorf("inspect container for ready_exec: %w", err)
+		}
+		probe = func(ctx context.Context, cmd []string) error { return Exec(ctx, inspect.ID, cmd) }
+	}
+	return waitReady(ctx, "http://"+net.JoinHostPort(host, strconv.Itoa(mapped)), s.checks, probe, alive, s.timeout)
+}
+
+// readinessProbe records the most recent failed poll of a check.
+type readinessProbe struct {
+	polls  int
+	status int // 0 when the request itself failed
+	body   string
+	err    error
+}
+
+func (p *readinessProbe) String() string {
+	if p.polls == 0 {
+		return "never polled"
+	}
+	if p.status == 0 {
+		return fmt.Sprintf("%d polls, last error: %v", p.polls, p.err)
+	}
+	if p.body == "" {
+		return fmt.Sprintf("%d polls, last status %d", p.polls, p.status)
+	}
+	return fmt.Sprintf("%d polls, last status %d, body %q", p.polls, p.status, p.body)
+}
+
+// execProbe runs a command inside the server container; nil error is exit 0.
+type execProbe func(ctx context.Context, cmd []string) error
+
+// waitReady polls each check against baseURL until it returns 200, or through
+// exec until an exec check's command exits 0. The poll interval doubles after
+// every failure up to readyMaxInterval and resets for the next check; ctx
+// carries the overall deadline. alive is consulted between polls so a crashed
+// container fails immediately instead of at the timeout.
+func waitReady(
+	ctx context.Context, baseURL string, checks []readinessCheck, exec execProbe,
+	alive func(context.Context) error, timeout time.Duration,
+) error {
+	client := &http.Client{Timeout: readyRequestTimeout}
+	for _, check := range checks {
+		var probe readinessProbe
+		interval := readyInitialInterval
+		for {
+			probe.polls++
+			if check.exec != nil {
+				if pollExec(ctx, exec, check.exec, &probe) {
+					break
+				}
+			} else if pollReady(ctx, client, baseURL+check.path, &probe) {
+				break
+			}
+			if err := alive(ctx); err != nil {
+				return fmt.Errorf("%s (%s): %w; %s", check.label, check.probed(), err, &probe)
+			}
+			if !sleepCtx(ctx, interval) {
+				if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return ctx.Err()
+				}
+				return fmt.Errorf("%s (%s) not ready within %s: %s", check.label, check.probed(), timeout, &probe)
+			}
+			interval = min(interval*2, readyMaxInterval)
+		}
+	}
+	return nil
+}
+
+// pollReady issues one GET and reports whether it returned 200, recording the
+// failure detail in probe otherwise.
+func pollReady(ctx context.Context, client *http.Client, url string, probe *readinessProbe) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		probe.status, probe.body, probe.err = 0, "", err
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// A poll cut off by the overall deadline says nothing new; keep the
+		// previous failure so the timeout error shows what the server answered.
+		if ctx.Err() == nil || probe.polls == 1 {
+			probe.status, probe.body, probe.err = 0, "", err
+		}
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return true
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, readyBodySnippet))
+	probe.status, probe.body, probe.err = resp.StatusCode, strings.TrimSpace(string(snippet)), nil
+	return false
+}
+
+// pollExec runs cmd once with the same per-poll bound as an HTTP check and
+// reports whether it exited 0, recording the failure in probe otherwise.
+func pollExec(ctx context.Context, exec execProbe, cmd []string, probe *readinessProbe) bool {
+	pollCtx, cancel := context.WithTimeout(ctx, readyRequestTimeout)
+	defer cancel()
+	err := exec(pollCtx, cmd)
+	if err == nil {
+		return true
+	}
+	if ctx.Err() == nil || probe.polls == 1 {
+		probe.status, probe.body, probe.err = 0, "", err
+	}
+	return false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,131 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitReadyBecomesHealthyAfterPolls(t *testing.T) {
+	t.Parallel()
+
+	var healthPolls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" && healthPolls.Add(1) <= 4 {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	alive := func(context.Context) error { return nil }
+	if err := waitReady(ctx, srv.URL, readinessChecks([]string{"postgres"}, nil), nil, alive, 10*time.Second); err != nil {
+		t.Fatalf("waitReady: %v", err)
+	}
+	if got := healthPolls.Load(); got != 5 {
+		t.Errorf("got %d health polls, want 5", got)
+	}
+}
+
+func TestWaitReadyTimeoutNamesFailingCheck(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/db/mongodb/health" {
+			http.Error(w, "mongodb unreachable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	timeout := 500 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	alive := func(context.Context) error { return nil }
+	err := waitReady(ctx, srv.URL, readinessChecks([]string{"postgres", "mongodb"}, nil), nil, alive, timeout)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > timeout+250*time.Millisecond {
+		t.Errorf("took %s, want the %s timeout honored", elapsed, timeout)
+	}
+	for _, want := range []string{"database mongodb health", "/db/mongodb/health", "status 503", "mongodb unreachable"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+}
+
+func TestWaitReadyFailsFastWhenContainerDies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	alive := func(context.Context) error { return errors.New("container exited with code 1 during startup") }
+	err := waitReady(ctx, srv.URL, readinessChecks(nil, nil), nil, alive, 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exited with code 1") || !strings.Contains(err.Error(), "server health") {
+		t.Fatalf("got %v, want an exit error naming server health", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s, want an immediate failure", elapsed)
+	}
+}
+
+func TestWaitReadyExecProbeGatesReadiness(t *testing.T) {
+	t.Parallel()
+
+	var healthPolls, dbPolls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			healthPolls.Add(1)
+		case "/db/postgres/health":
+			dbPolls.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var execs atomic.Int32
+	exec := func(_ context.Context, cmd []string) error {
+		if strings.Join(cmd, " ") != "pgrep server" {
+			t.Errorf("exec got %q", cmd)
+		}
+		if dbPolls.Load() > 0 {
+			t.Error("database health polled before the exec probe passed")
+		}
+		if execs.Add(1) <= 3 {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	alive := func(context.Context) error { return nil }
+	checks := readinessChecks([]string{"postgres"}, []string{"pgrep", "server"})
+	if err := waitReady(ctx, srv.URL, checks, exec, alive, 10*time.Second); err != nil {
+		t.Fatalf("waitReady: %v", err)
+	}
+	if got := execs.Load(); got != 4 {
+		t.Errorf("got %d exec probes, want 4", got)
+	}
+	if healthPolls.Load() != 0 || dbPolls.Load() != 1 {
+		t.Errorf("got %d /health and %d database polls, want 0 and 1", healthPolls.Load(), dbPolls.Load())
+	}
+}
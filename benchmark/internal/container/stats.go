@@ -7,11 +7,25 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
 )
 
 const minReliableSamples = 3
 
+// A memory tail is "still rising" when the last memTrendWindow samples (~1/s)
+// fit an upward line that grows more than memRiseThreshold of the window's
+// first sample, with at least 3 in 4 steps flat or up. GC-driven sawtooths
+// fail the step check; a steady leak passes both.
+const (
+	memTrendWindow   = 10
+	memRiseThreshold = 0.05
+
+	// WarningMemoryRising marks a ResourceStats whose memory was still
+	// climbing when sampling stopped — a likely leak that avg/max hide.
+	WarningMemoryRising = "memory still rising"
+)
+
 var dockerStatsClient = &http.Client{
 	Transport: &http.Transport{
 		MaxIdleConns:        10,
@@ -22,11 +36,23 @@ var dockerStatsClient = &http.Client{
 	},
 }
 
+// ResourceStats covers the whole sampled run — warmup, pauses and sequences
+// included. Measured repeats memory and CPU over only the samples taken inside
+// measured windows (MarkMeasureStart/MarkMeasureEnd), which compares across
+// servers without warmup length skewing it; nil when no sample fell inside one.
 type ResourceStats struct {
-	Memory   MemoryStats `json:"memory"`
-	Cpu      CpuStats    `json:"cpu"`
-	Samples  int         `json:"samples"`
-	Warnings []string    `json:"warnings,omitempty"`
+	Memory   MemoryStats    `json:"memory"`
+	Cpu      CpuStats       `json:"cpu"`
+	Samples  int            `json:"samples"`
+	Warnings []string       `json:"warnings,omitempty"`
+	Measured *MeasuredStats `json:"measured,omitempty"`
+}
+
+// MeasuredStats is the measured-window share of a ResourceStats.
+type MeasuredStats struct {
+	Memory  MemoryStats `json:"memory"`
+	Cpu     CpuStats    `json:"cpu"`
+	Samples int         `json:"samples"`
 }
 
 type MemoryStats struct {
@@ -47,6 +73,9 @@ type ResourceSampler struct {
 	mu        sync.Mutex
 	memory    []uint64
 	cpu       []float64
+	measuring bool      // between MarkMeasureStart and MarkMeasureEnd
+	measured  []int     // indexes into memory sampled while measuring
+	cpuInside []float64 // cpu readings taken while measuring
 	running   bool
 	stopCh    chan struct{}
 	doneCh    chan struct{}
@@ -106,6 +135,22 @@ func (r *ResourceSampler) Stop() ResourceStats {
 	return r.aggregate()
 }
 
+// MarkMeasureStart opens a measured window: samples from now until
+// MarkMeasureEnd also count toward ResourceStats.Measured. The suite brackets
+// every measured endpoint window and sequence run, leaving warmup outside.
+func (r *ResourceSampler) MarkMeasureStart() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.measuring = true
+}
+
+// MarkMeasureEnd closes the window opened by MarkMeasureStart.
+func (r *ResourceSampler) MarkMeasureEnd() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.measuring = false
+}
+
 func (r *ResourceSampler) stream(ctx context.Context) {
 	defer close(r.doneCh)
 
@@ -149,8 +194,22 @@ func (r *ResourceSampler) processSample(stats *dockerStatsAPI) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if r.measuring {
+		r.measured = append(r.measured, len(r.memory))
+	}
 	r.memory = append(r.memory, stats.MemoryStats.Usage)
 
+	if cpuPercent, ok := stats.cpuPercent(); ok {
+		r.cpu = append(r.cpu, cpuPercent)
+		if r.measuring {
+			r.cpuInside = append(r.cpuInside, cpuPercent)
+		}
+	}
+}
+
+// cpuPercent is the container's CPU between the precpu and cpu readings, as a
+// percentage of one core; false when the pair cannot yield a delta.
+func (stats *dockerStatsAPI) cpuPercent() (float64, bool) {
 	currCpu := stats.CpuStats.CpuUsage.TotalUsage
 	prevCpu := stats.PreCpuStats.CpuUsage.TotalUsage
 	currSys := stats.CpuStats.SystemCpuUsage
@@ -160,68 +219,156 @@ func (r *ResourceSampler) processSample(stats *dockerStatsAPI) {
 		numCpus = 1
 	}
 
-	if currSys > prevSys && currCpu >= prevCpu {
-		cpuDelta := currCpu - prevCpu
-		sysDelta := currSys - prevSys
+	if currSys <= prevSys || currCpu < prevCpu {
+		return 0, false
+	}
+	cpuDelta := currCpu - prevCpu
+	sysDelta := currSys - prevSys
 
-		cpuPercent := (float64(cpuDelta) / float64(sysDelta)) * float64(numCpus) * 100.0
-		if cpuPercent > float64(numCpus)*100 {
-			cpuPercent = float64(numCpus) * 100
-		}
-		r.cpu = append(r.cpu, cpuPercent)
+	cpuPercent := (float64(cpuDelta) / float64(sysDelta)) * float64(numCpus) * 100.0
+	return min(cpuPercent, float64(numCpus)*100), true
+}
+
+// SampleCpu takes one CPU reading of a container. Docker fills precpu by
+// sampling twice about a second apart, so each call blocks for ~1s.
+func SampleCpu(ctx context.Context, containerId string) (float64, error) {
+	url := fmt.Sprintf("http://localhost/containers/%s/stats?stream=false", containerId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := dockerStatsClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("docker stats %.12s: status %d", containerId, resp.StatusCode)
+	}
+
+	var stats dockerStatsAPI
+	if err := json.UnmarshalRead(resp.Body, &stats); err != nil {
+		return 0, fmt.Errorf("docker stats %.12s: %w", containerId, err)
+	}
+	cpuPercent, ok := stats.cpuPercent()
+	if !ok {
+		return 0, fmt.Errorf("docker stats %.12s: no CPU delta", containerId)
 	}
+	return cpuPercent, nil
 }
 
 func (r *ResourceSampler) aggregate() ResourceStats {
 	r.mu.Lock()
 	memory := r.memory
 	cpu := r.cpu
+	measured := r.measured
+	cpuInside := r.cpuInside
 	r.mu.Unlock()
 
-	result := ResourceStats{}
-
-	if len(memory) > 0 {
-		minMem, maxMem := memory[0], memory[0]
-		var totalMem uint64
-		for _, m := range memory {
-			if m < minMem {
-				minMem = m
-			}
-			if m > maxMem {
-				maxMem = m
-			}
-			totalMem += m
-		}
-		result.Memory = MemoryStats{
-			MinBytes: float64(minMem),
-			AvgBytes: float64(totalMem) / float64(len(memory)),
-			MaxBytes: float64(maxMem),
-		}
-		result.Samples = len(memory)
-	}
-
-	if len(cpu) > 0 {
-		minCpu, maxCpu := cpu[0], cpu[0]
-		var totalCpu float64
-		for _, c := range cpu {
-			if c < minCpu {
-				minCpu = c
-			}
-			if c > maxCpu {
-				maxCpu = c
-			}
-			totalCpu += c
+	result := ResourceStats{
+		Memory:  memoryStats(memory),
+		Cpu:     cpuStats(cpu),
+		Samples: len(memory),
+	}
+	if len(measured) > 0 {
+		memoryInside := make([]uint64, len(measured))
+		for i, at := range measured {
+			memoryInside[i] = memory[at]
 		}
-		result.Cpu = CpuStats{
-			MinPercent: minCpu,
-			AvgPercent: totalCpu / float64(len(cpu)),
-			MaxPercent: maxCpu,
+		result.Measured = &MeasuredStats{
+			Memory:  memoryStats(memoryInside),
+			Cpu:     cpuStats(cpuInside),
+			Samples: len(memoryInside),
 		}
 	}
 
 	if result.Samples < minReliableSamples {
 		result.Warnings = []string{"low samples"}
 	}
+	if memoryRising(memory) {
+		result.Warnings = append(result.Warnings, WarningMemoryRising)
+	}
 
 	return result
 }
+
+func memoryStats(memory []uint64) MemoryStats {
+	if len(memory) == 0 {
+		return MemoryStats{}
+	}
+	var total uint64
+	for _, m := range memory {
+		total += m
+	}
+	return MemoryStats{
+		MinBytes: float64(slices.Min(memory)),
+		AvgBytes: float64(total) / float64(len(memory)),
+		MaxBytes: float64(slices.Max(memory)),
+	}
+}
+
+func cpuStats(cpu []float64) CpuStats {
+	if len(cpu) == 0 {
+		return CpuStats{}
+	}
+	var total float64
+	for _, c := range cpu {
+		total += c
+	}
+	return CpuStats{
+		MinPercent: slices.Min(cpu),
+		AvgPercent: total / float64(len(cpu)),
+		MaxPercent: slices.Max(cpu),
+	}
+}
+
+// Comparable is the memory and CPU to compare servers by: the measured
+// windows when any sample fell inside one, otherwise the whole run.
+func (r *ResourceStats) Comparable() (MemoryStats, CpuStats, int) {
+	if r.Measured != nil && r.Measured.Samples > 0 {
+		return r.Measured.Memory, r.Measured.Cpu, r.Measured.Samples
+	}
+	return r.Memory, r.Cpu, r.Samples
+}
+
+// HasWarning reports whether aggregate flagged warning.
+func (r *ResourceStats) HasWarning(warning string) bool {
+	return r != nil && slices.Contains(r.Warnings, warning)
+}
+
+// memoryRising applies the memTrendWindow / memRiseThreshold heuristic to the
+// tail of memory.
+func memoryRising(memory []uint64) bool {
+	if len(memory) < memTrendWindow || memory[len(memory)-memTrendWindow] == 0 {
+		return false
+	}
+	tail := memory[len(memory)-memTrendWindow:]
+
+	upSteps := 0
+	for i := 1; i < len(tail); i++ {
+		if tail[i] >= tail[i-1] {
+			upSteps++
+		}
+	}
+	if upSteps*4 < (len(tail)-1)*3 {
+		return false
+	}
+
+	// Least-squares slope over x = 0..n-1, in bytes per sample.
+	n := float64(len(tail))
+	meanX := (n - 1) / 2
+	var meanY float64
+	for _, m := range tail {
+		meanY += float64(m)
+	}
+	meanY /= n
+	var cov, varX float64
+	for i, m := range tail {
+		dx := float64(i) - meanX
+		cov += dx * (float64(m) - meanY)
+		varX += dx * dx
+	}
+	slope := cov / varX
+	return slope*(n-1)/float64(tail[0]) > memRiseThreshold
+}
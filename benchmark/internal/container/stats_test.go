@@ -0,0 +1,90 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAggregateMemoryRising(t *testing.T) {
+	t.Parallel()
+
+	const mb = 1 << 20
+	series := func(values ...uint64) []uint64 {
+		out := make([]uint64, len(values))
+		for i, v := range values {
+			out[i] = v * mb
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		memory []uint64
+		want   bool
+	}{
+		{name: "steady leak", memory: series(100, 100, 101, 103, 105, 107, 109, 111, 113, 115, 117, 119), want: true},
+		{name: "leak with one dip", memory: series(100, 102, 104, 103, 106, 108, 110, 112, 114, 116), want: true},
+		{name: "flat", memory: series(200, 200, 201, 200, 200, 201, 200, 200, 201, 200), want: false},
+		{name: "slow drift under threshold", memory: series(200, 200, 201, 201, 202, 202, 203, 203, 204, 204), want: false},
+		{name: "gc sawtooth", memory: series(100, 130, 100, 130, 100, 130, 100, 130, 100, 130), want: false},
+		{name: "too few samples", memory: series(100, 120, 140, 160, 180), want: false},
+		{name: "rose then settled", memory: series(100, 150, 200, 250, 300, 300, 300, 300, 300, 300, 300, 300, 300, 300), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := &ResourceSampler{memory: tt.memory}
+			stats := r.aggregate()
+			if got := slices.Contains(stats.Warnings, WarningMemoryRising); got != tt.want {
+				t.Errorf("memory still rising: got %v, want %v (warnings %v)", got, tt.want, stats.Warnings)
+			}
+		})
+	}
+}
+
+func TestAggregateMeasuredWindow(t *testing.T) {
+	t.Parallel()
+
+	const mb = 1 << 20
+	r := NewResourceSampler("test")
+	sample := func(memMb uint64) {
+		var s dockerStatsAPI
+		s.MemoryStats.Usage = memMb * mb
+		r.processSample(&s)
+	}
+
+	sample(100) // startup and warmup
+	sample(300)
+	r.MarkMeasureStart()
+	sample(200)
+	sample(220)
+	r.MarkMeasureEnd()
+	sample(500) // between endpoints: another warmup
+	r.MarkMeasureStart()
+	sample(240)
+	r.MarkMeasureEnd()
+
+	stats := r.aggregate()
+	if stats.Samples != 6 || stats.Memory.MaxBytes != 500*mb {
+		t.Errorf("whole run: got %d samples, max %v, want 6 and 500MB", stats.Samples, stats.Memory.MaxBytes)
+	}
+	if stats.Measured == nil {
+		t.Fatal("measured stats missing")
+	}
+	m := stats.Measured
+	if m.Samples != 3 || m.Memory.MinBytes != 200*mb || m.Memory.AvgBytes != 220*mb || m.Memory.MaxBytes != 240*mb {
+		t.Errorf("measured: got %+v over %d samples, want 200/220/240MB over 3", m.Memory, m.Samples)
+	}
+	if mem, _, samples := stats.Comparable(); mem != m.Memory || samples != 3 {
+		t.Errorf("comparable: got %+v/%d, want the measured window", mem, samples)
+	}
+
+	unmarked := NewResourceSampler("test")
+	var s dockerStatsAPI
+	s.MemoryStats.Usage = mb
+	unmarked.processSample(&s)
+	if got := unmarked.aggregate(); got.Measured != nil {
+		t.Errorf("no marks: got measured %+v, want nil", got.Measured)
+	}
+}
@@ -6,30 +6,39 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
-func ResetAll(ctx context.Context, serverURL string, databases []string) error {
+// DefaultResetPath is the reset route every contract server exposes.
+const DefaultResetPath = "/db/{database}/reset"
+
+// ResetAll DELETEs resetPath (a template with {database}) once per database.
+// Errors name the database and the path attempted so a wrong override is obvious.
+func ResetAll(ctx context.Context, serverURL, resetPath string, databases []string) error {
+	if resetPath == "" {
+		resetPath = DefaultResetPath
+	}
 	httpClient := &http.Client{Timeout: 10 * time.Second}
 	defer httpClient.CloseIdleConnections()
 
 	var errs []error
 	for _, db := range databases {
-		resetURL := fmt.Sprintf("%s/db/%s/reset", serverURL, db)
-		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, resetURL, http.NoBody)
+		path := strings.ReplaceAll(resetPath, "{database}", db)
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, serverURL+path, http.NoBody)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("reset %s: failed to create request: %w", db, err))
+			errs = append(errs, fmt.Errorf("reset %s (DELETE %s): failed to create request: %w", db, path, err))
 			continue
 		}
 		resp, err := httpClient.Do(req)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("reset %s: %w", db, err))
+			errs = append(errs, fmt.Errorf("reset %s (DELETE %s): %w", db, path, err))
 			continue
 		}
 		_, _ = io.Copy(io.Discard, resp.Body)
 		_ = resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
-			errs = append(errs, fmt.Errorf("reset %s: unexpected status %d", db, resp.StatusCode))
+			errs = append(errs, fmt.Errorf("reset %s (DELETE %s): unexpected status %d", db, path, resp.StatusCode))
 		}
 	}
 	return errors.Join(errs...)
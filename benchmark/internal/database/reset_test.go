@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestResetAllPath(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		resetPath string
+		want      []string
+	}{
+		{"default", "", []string{"/db/postgres/reset", "/db/redis/reset"}},
+		{"override", "/admin/{database}/truncate", []string{"/admin/postgres/truncate", "/admin/redis/truncate"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var got []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				got = append(got, r.Method+" "+r.URL.Path)
+				mu.Unlock()
+			}))
+			defer srv.Close()
+
+			if err := ResetAll(context.Background(), srv.URL, tc.resetPath, []string{"postgres", "redis"}); err != nil {
+				t.Fatalf("ResetAll: %v", err)
+			}
+			want := make([]string, len(tc.want))
+			for i, p := range tc.want {
+				want[i] = http.MethodDelete + " " + p
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("requests: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestResetAllErrorNamesPath(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "mongo") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	err := ResetAll(context.Background(), srv.URL, "/reset/{database}", []string{"postgres", "mongo"})
+	if err == nil {
+		t.Fatal("expected an error for the failing database")
+	}
+	if want := "reset mongo (DELETE /reset/mongo): unexpected status 404"; err.Error() != want {
+		t.Errorf("error: got %q, want %q", err, want)
+	}
+}
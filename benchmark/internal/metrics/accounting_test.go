@@ -62,7 +62,7 @@ func testClient(t *testing.T, f *fakeDb, sampleRate float64) *Client {
 }
 
 func oneRow() [][]any {
-	return [][]any{{time.Now(), "r", "srv", "ep", "", "endpoint", "", int64(0), int64(0), int64(1)}}
+	return [][]any{{time.Now(), "r", "srv", "ep", "", "endpoint", "", int64(0), int64(0), int64(1), "measured"}}
 }
 
 func TestNewClientAppliesSchema(t *testing.T) {
@@ -250,7 +250,8 @@ func TestNilClientIsSafe(t *testing.T) {
 	var c *Client
 	c.WriteEndpointLatencies("r", "srv", time.Now(), nil)
 	c.WriteSequenceLatencies("r", "srv", time.Now(), nil)
-	c.WriteEndpointStats("r", "srv", nil)
+	c.WriteEndpointStats("r", "srv", nil, nil)
+	c.WriteEndpointHistograms("r", "srv", nil)
 	c.WriteSequenceStats("r", "srv", nil)
 	c.WriteResourceStats("r", "srv", nil)
 	if err := c.Wait(); err != nil {
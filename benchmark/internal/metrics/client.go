@@ -7,7 +7,9 @@ package metrics
 
 import (
 	"context"
+	"crypto/rand"
 	_ "embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sync"
@@ -256,6 +258,10 @@ func (c *Client) Wait() error {
 	return nil
 }
 
+// RunId is the run's start time plus a short random suffix, so two runs
+// started in the same second don't share rows; ids still sort by start time.
 func RunId(t time.Time) string {
-	return t.UTC().Format("20060102-150405")
+	var suffix [3]byte
+	_, _ = rand.Read(suffix[:])
+	return t.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix[:])
 }
@@ -20,6 +20,13 @@ const (
 	sourceDatabase     = "database"
 )
 
+// request_events phase: measured requests, or warmup requests kept with
+// --export-warmup so Grafana can plot the warmup curve.
+const (
+	phaseMeasured = "measured"
+	phaseWarmup   = "warmup"
+)
+
 // Column names shared across tables: the real timestamp plus the former Influx
 // tags, now plain indexed columns (PLAN §9.1 decision 3).
 const (
@@ -32,12 +39,22 @@ const (
 
 var requestEventColumns = []string{
 	colTime, colRunId, colServer, "endpoint", "method", colSource, colDatabase,
-	"server_offset_ms", "endpoint_offset_ms", "latency_ns",
+	"server_offset_ms", "endpoint_offset_ms", "latency_ns", "phase",
 }
 
 // WriteEndpointLatencies streams the sampled raw endpoint events. Row time is
 // the real wall clock of the request: server run start + the request's offset.
 func (c *Client) WriteEndpointLatencies(runId, server string, start time.Time, results []client.TimedResult) {
+	c.writeEndpointEvents(runId, server, start, results, phaseMeasured)
+}
+
+// WriteWarmupLatencies streams the sampled warmup requests (--export-warmup)
+// as phase='warmup' endpoint events. They never reach the aggregate tables.
+func (c *Client) WriteWarmupLatencies(runId, server string, start time.Time, results []client.TimedResult) {
+	c.writeEndpointEvents(runId, server, start, results, phaseWarmup)
+}
+
+func (c *Client) writeEndpointEvents(runId, server string, start time.Time, results []client.TimedResult, phase string) {
 	if c == nil {
 		return
 	}
@@ -56,7 +73,7 @@ func (c *Client) WriteEndpointLatencies(runId, server string, start time.Time, r
 			}
 			rows = append(rows, []any{
 				start.Add(l.ServerOffset), runId, server, r.Endpoint, r.Method, sourceEndpoint, "",
-				l.ServerOffset.Milliseconds(), l.EndpointOffset.Milliseconds(), l.Duration.Nanoseconds(),
+				l.ServerOffset.Milliseconds(), l.EndpointOffset.Milliseconds(), l.Duration.Nanoseconds(), phase,
 			})
 			if len(rows) >= writeBatchSize {
 				c.writeEventRowsAsync(rows)
@@ -89,7 +106,7 @@ func (c *Client) WriteSequenceLatencies(runId, server string, start time.Time, r
 			}
 			rows = append(rows, []any{
 				start.Add(l.ServerOffset), runId, server, r.SequenceId, "", sourceSequence, r.Database,
-				l.ServerOffset.Milliseconds(), l.EndpointOffset.Milliseconds(), l.Duration.Nanoseconds(),
+				l.ServerOffset.Milliseconds(), l.EndpointOffset.Milliseconds(), l.Duration.Nanoseconds(), phaseMeasured,
 			})
 			if len(rows) >= writeBatchSize {
 				c.writeEventRowsAsync(rows)
@@ -107,7 +124,7 @@ func (c *Client) WriteSequenceLatencies(runId, server string, start time.Time, r
 				}
 				rows = append(rows, []any{
 					start.Add(l.ServerOffset), runId, server, stepName, "", sourceSequenceStep, r.Database,
-					l.ServerOffset.Milliseconds(), l.EndpointOffset.Milliseconds(), l.Duration.Nanoseconds(),
+					l.ServerOffset.Milliseconds(), l.EndpointOffset.Milliseconds(), l.Duration.Nanoseconds(), phaseMeasured,
 				})
 				if len(rows) >= writeBatchSize {
 					c.writeEventRowsAsync(rows)
@@ -132,12 +149,13 @@ var endpointStatColumns = []string{
 	"count", "rps", "avg_ns", "p50_ns", "p95_ns", "p99_ns", "p999_ns", "min_ns", "max_ns", "success_rate",
 	"target_rate", "offered_rate", "attempted", "dropped_iterations", "max_backlog",
 	"schedule_lag_p50_ns", "schedule_lag_p99_ns", "schedule_lag_max_ns",
+	"tags", "server_tags",
 }
 
 // WriteEndpointStats writes the exact per-endpoint aggregates, computed by the
 // client from the full in-memory result set before any event sampling (§9.1
 // decision 4) — never derived from the sampled request_events.
-func (c *Client) WriteEndpointStats(runId, server string, results []client.EndpointResult) {
+func (c *Client) WriteEndpointStats(runId, server string, serverTags []string, results []client.EndpointResult) {
 	if c == nil {
 		return
 	}
@@ -174,11 +192,57 @@ func (c *Client) WriteEndpointStats(runId, server string, results []client.Endpo
 			ep.Stats.P99.Nanoseconds(), ep.Stats.P999.Nanoseconds(),
 			ep.Stats.Low.Nanoseconds(), ep.Stats.High.Nanoseconds(), ep.Stats.SuccessRate,
 			targetRate, offeredRate, attempted, droppedIterations, maxBacklog, lagP50, lagP99, lagMax,
+			tagArray(ep.Tags), tagArray(serverTags),
 		})
 	}
 	_ = c.writeRows("endpoint_stats", endpointStatColumns, rows)
 }
 
+var histogramColumns = []string{
+	colTime, colRunId, colServer, "endpoint", "method", "bucket_le_ns", "count",
+}
+
+// WriteEndpointHistograms writes one row per latency bucket per endpoint
+// (client.HistogramBounds), computed from the kept latencies: every one by
+// default, or the benchmark.max_samples reservoir when the cap was hit, whose
+// counts then show the distribution's shape rather than exact request counts.
+// Either way Grafana heatmaps never need the raw request_events stream.
+func (c *Client) WriteEndpointHistograms(runId, server string, results []client.TimedResult) {
+	if c == nil || c.ctx.Err() != nil {
+		return
+	}
+	_ = c.writeRows("latency_histograms", histogramColumns, histogramRows(time.Now(), runId, server, results))
+}
+
+// histogramRows emits each endpoint's buckets in ascending bound order with
+// the overflow bucket (bucket_le_ns NULL) last; endpoints keep result order.
+func histogramRows(now time.Time, runId, server string, results []client.TimedResult) [][]any {
+	rows := make([][]any, 0, len(results)*(len(client.HistogramBounds)+1))
+	for i := range results {
+		r := &results[i]
+		if len(r.Latencies) == 0 {
+			continue
+		}
+		for _, b := range client.LatencyHistogram(r.Latencies) {
+			var le any
+			if b.Le > 0 {
+				le = b.Le.Nanoseconds()
+			}
+			rows = append(rows, []any{now, runId, server, r.Endpoint, r.Method, le, int64(b.Count)})
+		}
+	}
+	return rows
+}
+
+// tagArray maps untagged (nil) to an empty text[] so the NOT NULL tag columns
+// stay queryable with array operators instead of NULL checks.
+func tagArray(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
 var sequenceStatColumns = []string{
 	colTime, colRunId, colServer, "sequence_id", colDatabase,
 	"total_runs", "successes", "failures", "success_rate",
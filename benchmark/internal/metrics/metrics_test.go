@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/client"
+)
+
+func TestHistogramRowsStableOrder(t *testing.T) {
+	results := []client.TimedResult{
+		{Endpoint: "b", Method: "GET", Latencies: []client.TimedLatency{{Duration: time.Millisecond}, {Duration: time.Hour}}},
+		{Endpoint: "empty", Method: "GET"},
+		{Endpoint: "a", Method: "POST", Latencies: []client.TimedLatency{{Duration: 150 * time.Microsecond}}},
+	}
+
+	rows := histogramRows(time.Now(), "r", "srv", results)
+
+	perEndpoint := len(client.HistogramBounds) + 1
+	if len(rows) != 2*perEndpoint {
+		t.Fatalf("rows: got %d, want %d (empty endpoints skipped)", len(rows), 2*perEndpoint)
+	}
+	for i, row := range rows {
+		wantEndpoint := "b"
+		if i >= perEndpoint {
+			wantEndpoint = "a"
+		}
+		if row[3] != wantEndpoint {
+			t.Fatalf("row %d endpoint: got %v, want %s (result order kept)", i, row[3], wantEndpoint)
+		}
+		bucket := i % perEndpoint
+		if bucket == perEndpoint-1 {
+			if row[5] != nil {
+				t.Errorf("row %d: overflow bucket_le_ns must be NULL, got %v", i, row[5])
+			}
+			continue
+		}
+		if row[5] != client.HistogramBounds[bucket].Nanoseconds() {
+			t.Errorf("row %d bucket_le_ns: got %v, want %d", i, row[5], client.HistogramBounds[bucket].Nanoseconds())
+		}
+	}
+	if got := rows[perEndpoint-1][6]; got != int64(1) {
+		t.Errorf("overflow count for b: got %v, want 1", got)
+	}
+}
+
+func TestRunIdUniquePerSecond(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	a, b := RunId(start), RunId(start)
+	if !strings.HasPrefix(a, "20260102-030405-") || len(a) != len("20260102-030405-")+6 {
+		t.Errorf("got %q, want the start timestamp plus a 6-hex suffix", a)
+	}
+	if a == b {
+		t.Errorf("two ids for the same second: both %q", a)
+	}
+}
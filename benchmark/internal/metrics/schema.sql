@@ -35,6 +35,10 @@ CREATE TABLE IF NOT EXISTS request_events (
 
 CREATE INDEX IF NOT EXISTS request_events_run_idx ON request_events (run_id, server, endpoint);
 
+-- phase: 'measured' | 'warmup'. Warmup rows exist only with --export-warmup;
+-- filter on phase = 'measured' to keep them out of drilldowns.
+ALTER TABLE request_events ADD COLUMN IF NOT EXISTS phase text NOT NULL DEFAULT 'measured';
+
 -- Exact per-endpoint aggregates from the full result set. source: 'endpoint' |
 -- 'sequence_step'. Open-mode columns are NULL for closed-mode rows.
 CREATE TABLE IF NOT EXISTS endpoint_stats (
@@ -62,11 +66,34 @@ CREATE TABLE IF NOT EXISTS endpoint_stats (
     max_backlog         bigint,
     schedule_lag_p50_ns bigint,
     schedule_lag_p99_ns bigint,
-    schedule_lag_max_ns bigint
+    schedule_lag_max_ns bigint,
+    tags                text[] NOT NULL DEFAULT '{}',
+    server_tags         text[] NOT NULL DEFAULT '{}'
 );
 
+-- Endpoint/server tags (config tags, bench.json tags) postdate the table, so
+-- metrics DBs created earlier gain them here.
+ALTER TABLE endpoint_stats ADD COLUMN IF NOT EXISTS tags text[] NOT NULL DEFAULT '{}';
+ALTER TABLE endpoint_stats ADD COLUMN IF NOT EXISTS server_tags text[] NOT NULL DEFAULT '{}';
+
 CREATE INDEX IF NOT EXISTS endpoint_stats_run_idx ON endpoint_stats (run_id, server);
 
+-- Exact per-endpoint latency histograms from the full result set: one row per
+-- bucket (non-cumulative count of latencies in (previous bound, bucket_le_ns]),
+-- fixed 1-2-5 bounds from 100µs to 10s. bucket_le_ns is NULL for the overflow
+-- bucket above the last bound.
+CREATE TABLE IF NOT EXISTS latency_histograms (
+    time         timestamptz NOT NULL,
+    run_id       text NOT NULL,
+    server       text NOT NULL,
+    endpoint     text NOT NULL,
+    method       text NOT NULL DEFAULT '',
+    bucket_le_ns bigint,
+    count        bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS latency_histograms_run_idx ON latency_histograms (run_id, server, endpoint);
+
 -- Exact per-sequence aggregates (full-sequence durations) from the full result set.
 CREATE TABLE IF NOT EXISTS sequence_stats (
     time         timestamptz NOT NULL,
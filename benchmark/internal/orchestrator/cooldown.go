@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"benchmark-client/internal/cli"
+	"benchmark-client/internal/config"
+	"benchmark-client/internal/container"
+)
+
+// cooldown waits between two servers: the fixed server_cooldown, or with
+// cooldown_until_idle until the database containers settle. It returns false
+// when ctx is canceled during the wait.
+func (o *Orchestrator) cooldown(ctx context.Context) bool {
+	if idle := o.cfg.Benchmark.CooldownUntilIdle; idle != nil {
+		return waitUntilIdle(ctx, idle, o.dbContainers)
+	}
+	if o.cfg.Benchmark.ServerCooldown <= 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(o.cfg.Benchmark.ServerCooldown):
+		return true
+	}
+}
+
+// waitUntilIdle samples every database container's CPU until all are below
+// idle.CpuThreshold or idle.MaxDuration passes. Each round takes ~1s, the
+// length of one docker stats reading. Sampling errors count as busy so a
+// flaky stats call cannot end the cooldown early.
+func waitUntilIdle(ctx context.Context, idle *config.CooldownIdleConfig, dbContainers map[string]string) bool {
+	if len(dbContainers) == 0 {
+		return ctx.Err() == nil
+	}
+
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, idle.MaxDuration)
+	defer cancel()
+
+	busiest := "no reading yet"
+	for waitCtx.Err() == nil {
+		round, err := busiestDatabase(waitCtx, idle.CpuThreshold, dbContainers)
+		if waitCtx.Err() != nil {
+			break // max_duration expired mid-round
+		}
+		if err != nil {
+			// A failed stats call returns at once; pace retries like a reading.
+			busiest = fmt.Sprintf("%s (stats: %v)", round, err)
+			select {
+			case <-waitCtx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if round == "" {
+			cli.Infof("Databases idle after %s", cli.FormatDuration(time.Since(start)))
+			return true
+		}
+		busiest = round
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	cli.Warnf("Cooldown hit max_duration %s before idle: %s", idle.MaxDuration, busiest)
+	return true
+}
+
+// busiestDatabase takes one CPU reading per container and describes the
+// busiest one at or above threshold, or "" when all are idle. On a sampling
+// error it returns that database's name with the error.
+func busiestDatabase(ctx context.Context, threshold float64, dbContainers map[string]string) (string, error) {
+	var busiest string
+	var peak float64
+	for db, id := range dbContainers {
+		cpu, err := container.SampleCpu(ctx, id)
+		if err != nil {
+			return db, err
+		}
+		if cpu >= threshold && cpu >= peak {
+			peak, busiest = cpu, fmt.Sprintf("%s at %.1f%%", db, cpu)
+		}
+	}
+	return busiest, nil
+}
@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"benchmark-client/internal/cli"
+	"benchmark-client/internal/config"
+)
+
+// etaEstimator predicts the time left in the server matrix. Each server's
+// planned load time (windows, warmups, sequences) weights its share, and the
+// fixed per-server cost — container start, reset, seeding, export — is learned
+// from the servers that already finished.
+type etaEstimator struct {
+	servers  []*config.ResolvedServer
+	cooldown time.Duration
+	done     int
+	overhead time.Duration // summed actual minus planned time of finished servers
+}
+
+func newEtaEstimator(servers []*config.ResolvedServer, cooldown time.Duration) *etaEstimator {
+	return &etaEstimator{servers: servers, cooldown: cooldown}
+}
+
+// finish records that servers[done] took elapsed, excluding the cooldown.
+func (e *etaEstimator) finish(elapsed time.Duration) {
+	e.overhead += elapsed - plannedDuration(e.servers[e.done])
+	e.done++
+}
+
+// remaining estimates the time left for the servers not yet run, including the
+// cooldowns between them.
+func (e *etaEstimator) remaining() time.Duration {
+	left := len(e.servers) - e.done
+	if e.done == 0 || left == 0 {
+		return 0
+	}
+	// Overhead can average negative when adaptive warmups settle early.
+	avgOverhead := e.overhead / time.Duration(e.done)
+	total := e.cooldown * time.Duration(left)
+	for _, s := range e.servers[e.done:] {
+		total += plannedDuration(s) + avgOverhead
+	}
+	return max(total, 0)
+}
+
+// print writes the "N of M servers done" line between servers.
+func (e *etaEstimator) print() {
+	if e.done >= len(e.servers) {
+		return
+	}
+	cli.Infof("%d of %d servers done, ~%s remaining", e.done, len(e.servers), formatEta(e.remaining()))
+}
+
+// plannedDuration is the configured load time for one server: every endpoint
+// window plus its warmup and pause (adaptive warmup counts at its cap) and
+// each sequence's duration_per_endpoint × step count. A request budget has no
+// planned window, so its endpoint time is learned from the measured overhead.
+func plannedDuration(s *config.ResolvedServer) time.Duration {
+	var stagesWindow time.Duration
+	for _, stage := range s.Load.Stages {
+		stagesWindow += stage.Duration
+	}
+	window := func(tc *config.Testcase) time.Duration {
+		switch {
+		case s.RequestsPerEndpoint > 0:
+			return 0
+		case len(s.Load.Stages) > 0:
+			return stagesWindow
+		}
+		return s.EndpointWindow(tc)
+	}
+	warmup := s.WarmupDuration
+	if s.WarmupStable != nil {
+		warmup = s.WarmupStable.MaxDuration
+	}
+	if warmup > 0 {
+		warmup += s.WarmupPause
+	}
+
+	endpoints := make(map[string]*config.Testcase, len(s.Testcases))
+	for _, tc := range s.Testcases {
+		if _, ok := endpoints[tc.EndpointName]; !ok {
+			endpoints[tc.EndpointName] = tc
+		}
+	}
+
+	var total time.Duration
+	switch {
+	case s.Replay:
+		total = warmup + s.DurationPerEndpoint
+	case s.MixedMode:
+		total = warmup
+		for _, tc := range endpoints {
+			total += s.EndpointWindow(tc)
+		}
+	default:
+		for _, tc := range endpoints {
+			total += window(tc) + warmup
+		}
+	}
+	for _, seq := range s.Sequences {
+		total += s.DurationPerEndpoint * time.Duration(len(seq.Endpoints))
+	}
+	return total
+}
+
+// formatEta rounds to whole seconds or minutes, since the estimate is coarse.
+func formatEta(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	"benchmark-client/internal/cli"
+)
+
+// Leak check (--leak-check) is a debug aid for long matrices: a transport or
+// goroutine that outlives its server keeps connections open and skews every
+// later server's numbers. Counts are taken before and after each server; the
+// after-count is allowed to settle because closed transports tear down their
+// connection goroutines asynchronously.
+
+const (
+	leakSlack       = 5 // growth tolerated for runtime/background noise
+	leakSettleLimit = 2 * time.Second
+	leakSettlePoll  = 100 * time.Millisecond
+)
+
+type leakSnapshot struct {
+	goroutines int
+	fds        int // -1 where /proc/self/fd is unavailable (non-Linux)
+}
+
+func takeLeakSnapshot() leakSnapshot {
+	return leakSnapshot{goroutines: runtime.NumGoroutine(), fds: openFdCount()}
+}
+
+func openFdCount() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+func (s leakSnapshot) exceeds(before leakSnapshot) bool {
+	if s.goroutines-before.goroutines > leakSlack {
+		return true
+	}
+	return before.fds >= 0 && s.fds-before.fds > leakSlack
+}
+
+// checkLeaks waits for the counts to settle back under before+slack and warns
+// with the remaining growth if they do not.
+func checkLeaks(server string, before leakSnapshot) {
+	after := takeLeakSnapshot()
+	deadline := time.Now().Add(leakSettleLimit)
+	for after.exceeds(before) && time.Now().Before(deadline) {
+		time.Sleep(leakSettlePoll)
+		after = takeLeakSnapshot()
+	}
+	if !after.exceeds(before) {
+		return
+	}
+
+	if growth := after.goroutines - before.goroutines; growth > leakSlack {
+		cli.Warnf("Leak check (%s): goroutines grew by %d (%d -> %d)", server, growth, before.goroutines, after.goroutines)
+	}
+	if before.fds >= 0 {
+		if growth := after.fds - before.fds; growth > leakSlack {
+			cli.Warnf("Leak check (%s): open fds grew by %d (%d -> %d)", server, growth, before.fds, after.fds)
+		}
+	}
+}
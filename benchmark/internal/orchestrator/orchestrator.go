@@ -1,7 +1,6 @@
 package orchestrator
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -11,6 +10,7 @@ import (
 	"time"
 
 	"benchmark-client/internal/cli"
+	"benchmark-client/internal/client"
 	"benchmark-client/internal/config"
 	"benchmark-client/internal/container"
 	"benchmark-client/internal/database"
@@ -25,34 +25,75 @@ type Orchestrator struct {
 	writer         *summary.Writer
 	databases      []string
 	dbContainers   map[string]string // database service -> container ID, for resource sampling
+	input          *stdinLines       // terminal input for pausing between servers (nil = not interactive)
 	metrics        *metrics.Client
 	runId          string
 	runStart       time.Time
-	noMetrics      bool
+	opts           Options
 	exportFailures []string
+	failedServers  []string // errored or saw failed requests, for FailOnError
+	abortErr       error    // set when abort_below_success_rate stopped the run
+	metricsErr     error    // metrics DB unreachable at startup; the run continued without it
+}
+
+// Options are the run-level switches from the command line.
+type Options struct {
+	NoMetrics    bool   // run without the metrics DB (results JSON still written)
+	MarkdownPath string // also write the final summary as Markdown here (empty = off)
+	Ranking      summary.RankOptions
+	Pull         bool   // docker pull missing images before failing the preflight
+	Smoke        bool   // one request per testcase and flow per server, then stop (no load phase)
+	LeakCheck    bool   // warn when goroutines or open fds grow across a server's run
+	RawLatencies string // also write per-request latency CSVs here (empty = off)
+	Timeseries   string // also write per-second latency percentile CSVs here (empty = off)
+	CompactJSON  bool   // write result files without indentation
+	FailOnError  bool   // return ErrFailures when any server errored or saw failed requests
+
+	// Baselines are results.json copies in BaselineDir, one per name. After
+	// the run the summary is compared to CompareBaseline, then saved as
+	// SaveBaseline, replacing any baseline of that name (empty = off).
+	BaselineDir     string
+	SaveBaseline    string
+	CompareBaseline string
 }
 
 const cleanupTimeout = 30 * time.Second
 
-func New(cfg *config.Config, servers []*config.ResolvedServer, repoRoot, resultsDir string, noMetrics bool) *Orchestrator {
+func New(cfg *config.Config, servers []*config.ResolvedServer, repoRoot, resultsDir string, opts Options) *Orchestrator {
 	runStart := time.Now()
+	runId := metrics.RunId(runStart)
+	writer := summary.NewWriter(&cfg.Benchmark, resultsDir)
+	writer.SetCompactJSON(opts.CompactJSON)
+	writer.SetRunId(runId)
 	return &Orchestrator{
 		cfg:       cfg,
 		servers:   servers,
 		compose:   database.NewComposeManager(repoRoot),
-		writer:    summary.NewWriter(&cfg.Benchmark, resultsDir),
+		writer:    writer,
 		databases: cfg.Databases,
-		runId:     metrics.RunId(runStart),
+		runId:     runId,
 		runStart:  runStart,
-		noMetrics: noMetrics,
+		opts:      opts,
 	}
 }
 
 func (o *Orchestrator) Run(ctx context.Context) error {
-	if missing := o.checkImages(ctx); len(missing) > 0 {
+	missing := o.checkImages(ctx)
+	if len(missing) > 0 && o.opts.Pull {
+		missing = pullImages(ctx, missing)
+	}
+	if len(missing) > 0 {
 		return fmt.Errorf("missing Docker images: %s\nRun 'just images' to build them", strings.Join(missing, ", "))
 	}
 
+	if len(o.cfg.Benchmark.BaseUrls) > 0 {
+		cli.Warnf("benchmark.base_urls is ignored for container runs; it only applies with --target")
+	}
+
+	if o.opts.Smoke {
+		return o.runSmoke(ctx)
+	}
+
 	cli.Section("Infrastructure")
 
 	cli.Infof("Starting Grafana stack...")
@@ -61,16 +102,24 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 	}
 	cli.Successf("Grafana stack started")
 
-	if o.noMetrics {
+	if o.opts.NoMetrics {
 		cli.Warnf("Metrics disabled (--no-metrics): results JSON is still written, no metrics exported")
 	} else {
 		client, err := metrics.NewClient(ctx, o.cfg.Benchmark.SampleRatePct)
-		if err != nil {
+		switch {
+		case err == nil:
+			o.metrics = client
+			defer o.metrics.Close()
+		case ctx.Err() != nil:
 			o.cleanupGrafana() //nolint:contextcheck // cleanup uses fresh context
-			return fmt.Errorf("metrics DB unreachable (pass --no-metrics to run without it): %w", err)
+			return err
+		default:
+			// Degrade instead of aborting: the benchmark still runs and writes its
+			// JSON, and the run exits non-zero at the end so the missing
+			// metrics can't go unnoticed (pass --no-metrics to opt out).
+			cli.Warnf("Metrics DB unreachable, continuing without metrics (results JSON is still written): %v", err)
+			o.metricsErr = fmt.Errorf("metrics DB unreachable, no metrics exported (pass --no-metrics to run without it): %w", err)
 		}
-		o.metrics = client
-		defer o.metrics.Close()
 	}
 
 	cli.Infof("Starting database stack...")
@@ -101,6 +150,10 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 	}
 	o.dbContainers = dbContainers
 
+	o.input = interactiveStdin()
+	if o.input != nil && len(o.servers) > 1 {
+		cli.Infof("Type p and press Enter to pause before the next server")
+	}
 	interrupted := o.runBenchmarkLoop(ctx)
 
 	flushErr := o.finalizeMetrics() //nolint:contextcheck // uses stored context from Client
@@ -113,16 +166,32 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 		return err
 	}
 	cli.Infof("Meta results: %s", path)
-	summary.PrintFinalSummary(metaResults, servers)
+	summary.PrintFinalSummary(metaResults, servers, o.opts.Ranking)
+	summary.EmitRunCompleted(metaResults)
+	o.applyBaselines(metaResults, path)
+
+	if o.opts.MarkdownPath != "" {
+		if mdErr := summary.ExportMarkdown(metaResults, servers, o.opts.MarkdownPath); mdErr != nil {
+			cli.Failf("Failed to export Markdown summary: %v", mdErr)
+			o.exportFailures = append(o.exportFailures, "markdown summary")
+		} else {
+			cli.Infof("Markdown summary: %s", o.opts.MarkdownPath)
+		}
+	}
 
-	if !interrupted {
+	// An --ndjson consumer is a program, not a person at the terminal.
+	if !interrupted && !cli.NDJSON() {
 		o.waitForUserThenStopGrafana(ctx)
 	} else {
 		o.cleanupGrafana() //nolint:contextcheck // cleanup uses fresh context
 	}
 
 	// Surface dropped/failed exports as a non-zero exit AFTER results have printed.
-	return o.runFailure(flushErr)
+	runErr := o.runFailure(flushErr)
+	if interrupted {
+		return errors.Join(ErrInterrupted, runErr)
+	}
+	return runErr
 }
 
 // finalizeMetrics drains outstanding async writes, records the runs row with
@@ -149,48 +218,111 @@ func (o *Orchestrator) finalizeMetrics() error {
 }
 
 // runFailure combines the no-silent-drop failure modes into a single run error so
-// the process exits non-zero while the in-memory results still print.
+// the process exits non-zero while the in-memory results still print. Export
+// and flush failures are infrastructure and win over benchmark failures.
 func (o *Orchestrator) runFailure(flushErr error) error {
 	var msgs []string
+	if o.abortErr != nil {
+		msgs = append(msgs, o.abortErr.Error())
+	}
+	if o.opts.FailOnError && len(o.failedServers) > 0 {
+		msgs = append(msgs, "benchmark failures in: "+strings.Join(o.failedServers, ", "))
+	}
+	benchmarkOnly := len(msgs) > 0
 	if len(o.exportFailures) > 0 {
 		msgs = append(msgs, "failed to export results for: "+strings.Join(o.exportFailures, ", "))
+		benchmarkOnly = false
 	}
 	if flushErr != nil {
 		msgs = append(msgs, flushErr.Error())
+		benchmarkOnly = false
+	}
+	if o.metricsErr != nil {
+		msgs = append(msgs, o.metricsErr.Error())
+		benchmarkOnly = false
 	}
 	if len(msgs) == 0 {
 		return nil
 	}
-	return errors.New(strings.Join(msgs, "; "))
+	err := errors.New(strings.Join(msgs, "; "))
+	if benchmarkOnly {
+		return asFailures(err)
+	}
+	return err
+}
+
+// printInterimRankings shows the leaderboard of the done servers exported so
+// far; the final summary still ranks the whole run.
+func (o *Orchestrator) printInterimRankings(done int) {
+	servers, err := o.writer.CompletedServers()
+	if err != nil {
+		cli.Warnf("Failed to read results for interim rankings: %v", err)
+		return
+	}
+	summary.PrintInterimRankings(servers, o.opts.Ranking, done, len(o.servers))
 }
 
 func (o *Orchestrator) runBenchmarkLoop(ctx context.Context) (interrupted bool) {
+	// An idle-based cooldown has no fixed length; the ETA leaves it out.
 	cooldown := o.cfg.Benchmark.ServerCooldown
+	if o.cfg.Benchmark.CooldownUntilIdle != nil {
+		cooldown = 0
+	}
+	eta := newEtaEstimator(o.servers, cooldown)
 
 	for i, server := range o.servers {
 		if ctx.Err() != nil {
 			cli.Warnf("Interrupted, stopping...")
 			return true
 		}
+		serverStart := time.Now()
 
 		cli.ServerHeader(server.Name)
+		summary.EmitServerStarted(server.Name)
+
+		var leakBefore leakSnapshot
+		if o.opts.LeakCheck {
+			leakBefore = takeLeakSnapshot()
+		}
 
 		result, timedResults, timedSequences := RunServerBenchmark(ctx, server, o.databases, o.compose.NetworkName(), o.dbContainers)
 
+		if o.opts.LeakCheck {
+			checkLeaks(server.Name, leakBefore)
+		}
+
 		summary.PrintServerSummary(result)
+		summary.EmitServerCompleted(result)
 		path, err := o.writer.ExportServerResult(result)
 		if err == nil {
 			cli.Infof("Exported: %s", path)
+			if cpErr := o.writer.Checkpoint(); cpErr != nil {
+				cli.Warnf("Failed to checkpoint meta results: %v", cpErr)
+			}
+			if o.opts.Ranking.Interim && i < len(o.servers)-1 {
+				o.printInterimRankings(i + 1)
+			}
 		} else {
 			cli.Failf("Failed to export %s results: %v", server.Name, err)
 			o.exportFailures = append(o.exportFailures, server.Name)
 		}
 
+		if o.opts.RawLatencies != "" {
+			exportRawLatencies(o.opts.RawLatencies, server.Name, timedResults, timedSequences)
+		}
+		if o.opts.Timeseries != "" {
+			exportTimeseries(o.opts.Timeseries, server.Name, timedResults, timedSequences)
+		}
+
 		if o.metrics != nil {
 			o.metrics.WriteEndpointLatencies(o.runId, server.Name, result.StartTime, timedResults)   //nolint:contextcheck // uses stored context from Client
 			o.metrics.WriteSequenceLatencies(o.runId, server.Name, result.StartTime, timedSequences) //nolint:contextcheck // uses stored context from Client
-			o.metrics.WriteEndpointStats(o.runId, server.Name, result.Results)                       //nolint:contextcheck // uses stored context from Client
-			o.metrics.WriteSequenceStats(o.runId, server.Name, result.Sequences)                     //nolint:contextcheck // uses stored context from Client
+			if len(result.Warmup) > 0 {
+				o.metrics.WriteWarmupLatencies(o.runId, server.Name, result.StartTime, result.Warmup) //nolint:contextcheck // uses stored context from Client
+			}
+			o.metrics.WriteEndpointStats(o.runId, server.Name, server.Tags, result.Results) //nolint:contextcheck // uses stored context from Client
+			o.metrics.WriteEndpointHistograms(o.runId, server.Name, timedResults)           //nolint:contextcheck // uses stored context from Client
+			o.metrics.WriteSequenceStats(o.runId, server.Name, result.Sequences)            //nolint:contextcheck // uses stored context from Client
 			if result.Resources != nil {
 				o.metrics.WriteResourceStats(o.runId, server.Name, result.Resources) //nolint:contextcheck // uses stored context from Client
 			}
@@ -200,20 +332,40 @@ func (o *Orchestrator) runBenchmarkLoop(ctx context.Context) (interrupted bool)
 			cli.Infof("Exported metrics to metrics-postgres (run: %s)", o.runId)
 		}
 
+		successRate := result.SuccessRate()
+		if result.Error != "" || successRate < 1 {
+			o.failedServers = append(o.failedServers, server.Name)
+		}
 		result.Results = nil
+		result.Warmup = nil
 
 		if ctx.Err() != nil {
 			cli.Warnf("Interrupted, stopping...")
 			return true
 		}
 
-		if cooldown > 0 && i < len(o.servers)-1 {
-			select {
-			case <-ctx.Done():
-				cli.Warnf("Interrupted, stopping...")
-				return true
-			case <-time.After(cooldown):
+		if threshold := o.cfg.Benchmark.AbortBelow; threshold > 0 && successRate < threshold {
+			o.abortErr = fmt.Errorf("run aborted: %s success rate %s is below abort_below_success_rate %s",
+				server.Name, cli.FormatRate(successRate), cli.FormatRate(threshold))
+			o.writer.SetAborted(server.Name, successRate, threshold)
+			if remaining := len(o.servers) - i - 1; remaining > 0 {
+				cli.Failf("Aborting: %s success rate %s below %s, skipping %d remaining servers",
+					server.Name, cli.FormatRate(successRate), cli.FormatRate(threshold), remaining)
 			}
+			break
+		}
+
+		eta.finish(time.Since(serverStart))
+		eta.print()
+
+		if i < len(o.servers)-1 && !o.cooldown(ctx) {
+			cli.Warnf("Interrupted, stopping...")
+			return true
+		}
+
+		if i < len(o.servers)-1 && !o.pauseIfRequested(ctx, o.servers[i+1].Name) {
+			cli.Warnf("Interrupted, stopping...")
+			return true
 		}
 
 		if i < len(o.servers)-1 {
@@ -229,22 +381,65 @@ func (o *Orchestrator) runBenchmarkLoop(ctx context.Context) (interrupted bool)
 	return false
 }
 
+// exportRawLatencies writes the --raw-latencies CSVs for one server. A failure
+// only warns: the JSON results and metrics are the primary record.
+func exportRawLatencies(dir, server string, timedResults []client.TimedResult, timedSequences []client.TimedSequenceResult) {
+	rows, err := summary.ExportRawLatencies(dir, server, timedResults, timedSequences)
+	if err != nil {
+		cli.Warnf("Failed to write raw latencies for %s: %v", server, err)
+		return
+	}
+	cli.Infof("Raw latencies: %d rows in %s", rows, dir)
+	if rows > summary.RawLatencyWarnRows {
+		cli.Warnf("Raw latency dataset for %s is large (%d rows); consider a shorter duration or max_samples", server, rows)
+	}
+}
+
+// applyBaselines compares the run to --compare-baseline and stores it as
+// --save-baseline. A failed save counts as an export failure; a baseline that
+// can't be read only loses the comparison.
+func (o *Orchestrator) applyBaselines(metaResults *summary.MetaResults, resultsPath string) {
+	if name := o.opts.CompareBaseline; name != "" {
+		baseline, err := summary.LoadBaseline(o.opts.BaselineDir, name)
+		if err != nil {
+			cli.Warnf("Skipping baseline comparison: %v", err)
+		} else {
+			summary.PrintBaselineComparison(name, baseline, metaResults)
+		}
+	}
+	if name := o.opts.SaveBaseline; name != "" {
+		path, err := summary.SaveBaseline(o.opts.BaselineDir, name, resultsPath, true)
+		if err != nil {
+			cli.Failf("Failed to save baseline %s: %v", name, err)
+			o.exportFailures = append(o.exportFailures, "baseline "+name)
+			return
+		}
+		cli.Infof("Baseline %s: %s", name, path)
+	}
+}
+
+// exportTimeseries writes the --timeseries CSV for one server; like the raw
+// latencies, a failure only warns.
+func exportTimeseries(dir, server string, timedResults []client.TimedResult, timedSequences []client.TimedSequenceResult) {
+	path, err := summary.ExportTimeseries(dir, server, timedResults, timedSequences)
+	if err != nil {
+		cli.Warnf("Failed to write latency timeseries for %s: %v", server, err)
+		return
+	}
+	cli.Infof("Latency timeseries: %s", path)
+}
+
 func (o *Orchestrator) waitForUserThenStopGrafana(ctx context.Context) {
 	cli.Blank()
 	cli.Infof("Grafana is running at http://localhost:20090 (admin/123456)")
 	cli.Infof("Press Enter or Ctrl+C to stop Grafana and databases and exit...")
 
-	done := make(chan struct{})
-	go func() {
-		reader := bufio.NewReader(os.Stdin)
-		_, _ = reader.ReadString('\n')
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-	case <-done:
+	in := o.input
+	if in == nil {
+		in = startStdinLines(os.Stdin)
 	}
+	in.pauseRequested() // forget lines typed during the run, a stray p included
+	in.wait(ctx)
 
 	o.cleanupGrafana() //nolint:contextcheck // cleanup uses fresh context
 }
@@ -277,9 +472,27 @@ func (o *Orchestrator) cleanupStacks() {
 }
 
 func (o *Orchestrator) checkImages(ctx context.Context) []string {
-	imageNames := make([]string, len(o.servers))
-	for i, server := range o.servers {
-		imageNames[i] = server.ImageName
+	imageNames := make([]string, 0, len(o.servers))
+	for _, server := range o.servers {
+		if server.ExternalUrl == "" {
+			imageNames = append(imageNames, server.ImageName)
+		}
 	}
 	return container.CheckImages(ctx, imageNames)
 }
+
+// pullImages tries to pull each missing image and returns those still missing,
+// so local-only images fall through to the usual "just images" hint.
+func pullImages(ctx context.Context, images []string) []string {
+	var stillMissing []string
+	for i, image := range images {
+		cli.Infof("Pulling %s (%d/%d)...", image, i+1, len(images))
+		if err := container.Pull(ctx, image); err != nil {
+			cli.Warnf("Pull failed: %v", err)
+			stillMissing = append(stillMissing, image)
+			continue
+		}
+		cli.Successf("Pulled %s", image)
+	}
+	return stillMissing
+}
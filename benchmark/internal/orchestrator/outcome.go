@@ -0,0 +1,29 @@
+package orchestrator
+
+import "errors"
+
+// The run outcomes cmd maps to distinct exit codes. Any other error from Run,
+// RunTarget or RunSelfTest is infrastructure: Docker, compose, the metrics DB,
+// database resets or result exports.
+var (
+	// ErrInterrupted: SIGINT/SIGTERM stopped the run before every server ran.
+	ErrInterrupted = errors.New("run interrupted")
+	// ErrFailures: the run completed but the benchmark itself failed — a
+	// server errored or saw failed requests under Options.FailOnError, a
+	// success rate fell below abort_below_success_rate, or smoke checks failed.
+	ErrFailures = errors.New("benchmark failures")
+)
+
+// failuresError marks err as ErrFailures without changing its message.
+type failuresError struct{ error }
+
+func (e failuresError) Is(target error) bool { return target == ErrFailures }
+
+func (e failuresError) Unwrap() error { return e.error }
+
+func asFailures(err error) error {
+	if err == nil {
+		return nil
+	}
+	return failuresError{err}
+}
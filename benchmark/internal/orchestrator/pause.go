@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"benchmark-client/internal/cli"
+)
+
+// stdinLines reads stdin one line at a time in the background for the whole
+// run, so a pause request typed while a server is benchmarked is picked up at
+// the next gap and the final "press Enter" prompt reads from the same reader.
+type stdinLines struct {
+	lines chan string
+}
+
+// startStdinLines starts reading r; the channel closes at EOF or a read error.
+func startStdinLines(r io.Reader) *stdinLines {
+	in := &stdinLines{lines: make(chan string, 1)}
+	go func() {
+		defer close(in.lines)
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			in.lines <- strings.TrimSpace(line)
+		}
+	}()
+	return in
+}
+
+// interactiveStdin reads stdin when a person is at it: a terminal, and not an
+// --ndjson run. Otherwise nil, and the run never pauses.
+func interactiveStdin() *stdinLines {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 || cli.NDJSON() {
+		return nil
+	}
+	return startStdinLines(os.Stdin)
+}
+
+// pauseRequested drains the lines typed since the last call and reports
+// whether any of them was "p".
+func (in *stdinLines) pauseRequested() bool {
+	if in == nil {
+		return false
+	}
+	requested := false
+	for {
+		select {
+		case line, ok := <-in.lines:
+			if !ok {
+				return requested
+			}
+			requested = requested || strings.EqualFold(line, "p")
+		default:
+			return requested
+		}
+	}
+}
+
+// wait blocks until a line arrives, stdin ends, or ctx is canceled; it
+// returns false only for the cancellation.
+func (in *stdinLines) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-in.lines:
+		return true
+	}
+}
+
+// pauseIfRequested holds the run before next when the user typed p during
+// the previous server. Containers and databases stay up; SIGINT still aborts.
+// It runs before the between-server health check, so that check's timeout
+// starts after the resume rather than ticking through the pause. It returns
+// false when ctx is canceled while paused.
+func (o *Orchestrator) pauseIfRequested(ctx context.Context, next string) bool {
+	if !o.input.pauseRequested() {
+		return true
+	}
+	cli.Warnf("Paused before %s. Press Enter to resume (Ctrl+C aborts)...", next)
+	if !o.input.wait(ctx) {
+		return false
+	}
+	cli.Infof("Resuming")
+	return true
+}
@@ -13,65 +13,107 @@ import (
 	"benchmark-client/internal/summary"
 )
 
+// serverRetryDelay is the pause between server_retries start attempts.
+const serverRetryDelay = 2 * time.Second
+
+// externalNote marks external_url results, which carry no resource stats.
+const externalNote = "external (no container)"
+
 func RunServerBenchmark(
 	ctx context.Context, server *config.ResolvedServer,
 	databases []string, network string, dbContainers map[string]string,
 ) (*summary.ServerResult, []client.TimedResult, []client.TimedSequenceResult) {
 	result := &summary.ServerResult{
 		Name:      server.Name,
+		Tags:      server.Tags,
 		ImageName: server.ImageName,
 		Port:      server.Port,
 		StartTime: time.Now(),
 		Results:   make([]client.EndpointResult, 0),
 	}
+	if server.TotalDuration > 0 {
+		result.UnitDuration = server.DurationPerEndpoint
+	}
 
 	if ctx.Err() != nil {
 		result.SetError(ctx.Err())
 		return result, nil, nil
 	}
 
-	// testcontainers starts the container, joins the DB network, applies limits,
-	// waits for /health + each /db/<db>/health, and maps a dynamic host port.
-	srv, err := container.Start(ctx, &container.StartOptions{
-		Image:          server.ImageName,
-		ContainerPort:  server.Port,
-		CpuLimit:       server.CpuLimit,
-		MemoryLimit:    server.MemoryLimit,
-		Network:        network,
-		Databases:      databases,
-		StartupTimeout: 60 * time.Second,
-	})
-	if err != nil {
-		result.SetError(fmt.Errorf("failed to start container: %w", err))
-		return result, nil, nil
-	}
-	result.ContainerId = srv.ID
+	var serverUrl string
+	var sampler *container.ResourceSampler
+	if server.ExternalUrl != "" {
+		// An external_url server is already running and owned by someone
+		// else: no start/stop, and no container id to sample resources from.
+		serverUrl = server.ExternalUrl
+		result.Note = externalNote
+		cli.Warnf("External server at %s: no container lifecycle, resource sampling disabled", serverUrl)
+	} else {
+		// testcontainers starts the container, joins the DB network, applies limits,
+		// waits for /health + each /db/<db>/health, and maps a dynamic host port.
+		opts := &container.StartOptions{
+			Image:          server.ImageName,
+			ContainerPort:  server.Port,
+			CpuLimit:       server.CpuLimit,
+			MemoryLimit:    server.MemoryLimit,
+			Network:        network,
+			Databases:      databases,
+			StartupTimeout: 60 * time.Second,
+			Env:            server.Env,
+			Cmd:            server.Cmd,
+			ReadyExec:      server.ReadyExec,
+		}
+		srv, retries, err := startWithRetries(ctx, server.ServerRetries, serverRetryDelay, func(ctx context.Context) (*container.Server, error) {
+			return container.Start(ctx, opts)
+		})
+		result.Retries = retries
+		if err != nil {
+			if retries > 0 {
+				err = fmt.Errorf("after %d attempts: %w", retries+1, err)
+			}
+			result.SetError(fmt.Errorf("failed to start container: %w", err))
+			return result, nil, nil
+		}
+		result.ContainerId = srv.ID
+		result.Startup = srv.Startup
+		result.Limits = &summary.ContainerLimits{Cpu: server.CpuLimit, Memory: server.MemoryLimit}
 
-	sampler := container.NewResourceSampler(srv.ID)
+		sampler = container.NewResourceSampler(srv.ID)
 
-	defer stopContainer(srv) //nolint:contextcheck // intentionally uses fresh context for cleanup after cancellation
+		defer stopContainer(srv) //nolint:contextcheck // intentionally uses fresh context for cleanup after cancellation
+		// Deferred after the stop so it runs first, while the container is still there.
+		defer inspectContainer(srv.ID, result) //nolint:contextcheck // same fresh context as the stop
 
-	serverUrl := srv.BaseURL
-	cli.Successf("Ready at %s (container: %.12s)", serverUrl, srv.ID)
+		serverUrl = srv.BaseURL
+		cli.Successf("Ready at %s in %s (container: %.12s)", serverUrl, cli.FormatDuration(srv.Startup), srv.ID)
+	}
 
-	if err = database.ResetAll(ctx, serverUrl, databases); err != nil {
+	if err := database.ResetAll(ctx, serverUrl, server.ResetPath, databases); err != nil {
 		stopSampler(sampler, result)
 		result.SetError(fmt.Errorf("failed to reset databases: %w", err))
 		return result, nil, nil
 	}
 	cli.Infof("Reset all databases")
 
+	if err := seedDatabases(ctx, server, serverUrl); err != nil {
+		stopSampler(sampler, result)
+		result.SetError(err)
+		return result, nil, nil
+	}
+
 	if ctx.Err() != nil {
 		stopSampler(sampler, result)
 		result.SetError(ctx.Err())
 		return result, nil, nil
 	}
 
-	sampler.Start(ctx)
+	if sampler != nil {
+		sampler.Start(ctx)
+	}
 	dbSamplers := startDbSamplers(ctx, dbContainers)
 	result.StartTime = time.Now()
 
-	suiteOut, err := runSuite(ctx, server, serverUrl)
+	suiteOut, err := runSuite(ctx, server, serverUrl, measuredSamplers(sampler, dbSamplers))
 	stopSampler(sampler, result)
 	stopDbSamplers(dbSamplers, result)
 	if err != nil {
@@ -84,6 +126,8 @@ func RunServerBenchmark(
 	result.StartTime = suiteOut.startTime
 	result.Complete(suiteOut.allResults())
 	result.Sequences = suiteOut.sequences
+	result.Mixed = suiteOut.mixed
+	result.Warmup = suiteOut.warmup
 
 	return result, suiteOut.timedResults, suiteOut.timedSequences
 }
@@ -96,6 +140,8 @@ type suiteOutput struct {
 	sequences      []client.SequenceStats
 	timedResults   []client.TimedResult
 	timedSequences []client.TimedSequenceResult
+	warmup         []client.TimedResult // --export-warmup only
+	mixed          *client.Stats        // blended stats, mixed mode only
 }
 
 func (s *suiteOutput) allResults() []client.EndpointResult {
@@ -105,22 +151,35 @@ func (s *suiteOutput) allResults() []client.EndpointResult {
 }
 
 // runSuite drives the endpoint suite and sequences against serverUrl with a
-// progress spinner. It owns no container or sampler state — callers do.
-func runSuite(ctx context.Context, server *config.ResolvedServer, serverUrl string) (*suiteOutput, error) {
+// progress spinner. It owns no container or sampler state — callers do; it
+// only marks the measured windows on samplers.
+func runSuite(ctx context.Context, server *config.ResolvedServer, serverUrl string, samplers []*container.ResourceSampler) (*suiteOutput, error) {
 	endpointCount := countUniqueEndpoints(server.Testcases)
 	sequenceCount := len(server.Sequences)
 
-	progress := cli.NewProgressSpinner()
-	progress.Start(endpointCount, sequenceCount)
+	progress := cli.TrackProgress(server.Name, endpointCount, sequenceCount)
 	defer progress.Stop()
 
 	suite := client.NewSuite(ctx, server, serverUrl, &client.ProgressCallbacks{
 		OnEndpoint: func(method, path string, done int) {
 			progress.UpdateEndpoint(method, path, done)
 		},
+		OnEndpointDone: func(result *client.EndpointResult) {
+			summary.EmitEndpointCompleted(server.Name, result)
+		},
 		OnSequence: func(seqName string, done int) {
 			progress.UpdateSequence(seqName, done)
 		},
+		OnMeasureStart: func() {
+			for _, s := range samplers {
+				s.MarkMeasureStart()
+			}
+		},
+		OnMeasureEnd: func() {
+			for _, s := range samplers {
+				s.MarkMeasureEnd()
+			}
+		},
 	})
 	defer suite.Close()
 
@@ -141,9 +200,25 @@ func runSuite(ctx context.Context, server *config.ResolvedServer, serverUrl stri
 		sequences:      sequences,
 		timedResults:   suite.GetTimedResults(),
 		timedSequences: suite.GetTimedSequences(),
+		warmup:         suite.GetWarmupResults(),
+		mixed:          suite.MixedStats(),
 	}, nil
 }
 
+// seedDatabases runs the configured seed flow once after the reset so GET
+// endpoints in the measured phase read known reference data. It runs before
+// the samplers start and its requests never reach the measured stats.
+func seedDatabases(ctx context.Context, server *config.ResolvedServer, serverUrl string) error {
+	if len(server.SeedSequences) == 0 {
+		return nil
+	}
+	if err := client.RunSeedSequences(ctx, serverUrl, server.SeedSequences, server.RequestTimeout, server.MaxBodyBytes); err != nil {
+		return fmt.Errorf("failed to seed databases: %w", err)
+	}
+	cli.Infof("Seeded databases (%s)", server.SeedSequences[0].Id)
+	return nil
+}
+
 func countUniqueEndpoints(testcases []*config.Testcase) int {
 	seen := make(map[string]struct{})
 	for _, tc := range testcases {
@@ -152,6 +227,29 @@ func countUniqueEndpoints(testcases []*config.Testcase) int {
 	return len(seen)
 }
 
+// startWithRetries calls start up to retries more times after a failure, so
+// a transient docker hiccup during start or readiness doesn't fail the server.
+// container.Start removes a failed attempt's container, so each retry begins
+// clean. Only start is retried: once the container is up, benchmark errors
+// are the server's. It returns how many retries were used.
+func startWithRetries(
+	ctx context.Context, retries int, delay time.Duration,
+	start func(context.Context) (*container.Server, error),
+) (*container.Server, int, error) {
+	for attempt := 0; ; attempt++ {
+		srv, err := start(ctx)
+		if err == nil || attempt == retries || ctx.Err() != nil {
+			return srv, attempt, err
+		}
+		cli.Warnf("Container start failed (attempt %d of %d), retrying in %s: %v", attempt+1, retries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, attempt, err
+		case <-time.After(delay):
+		}
+	}
+}
+
 func stopContainer(srv *container.Server) {
 	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Minute)
 	defer stopCancel()
@@ -160,6 +258,36 @@ func stopContainer(srv *container.Server) {
 	}
 }
 
+// inspectContainer records on result a container that died during the run,
+// OOM-killed or exited, so the summary names the cause instead of leaving only
+// the connection errors it caused.
+func inspectContainer(id string, result *summary.ServerResult) {
+	inspectCtx, inspectCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer inspectCancel()
+	state, err := container.Inspect(inspectCtx, id)
+	if err != nil {
+		cli.Warnf("Failed to inspect container %.12s: %v", id, err)
+		return
+	}
+	if issue := state.Issue(); issue != "" {
+		result.Container = &state
+		cli.Failf("Server %s: %s", result.Name, issue)
+	}
+}
+
+// measuredSamplers lists the server sampler (nil for an external server) and
+// the database samplers whose measured windows runSuite marks.
+func measuredSamplers(sampler *container.ResourceSampler, dbSamplers map[string]*container.ResourceSampler) []*container.ResourceSampler {
+	samplers := make([]*container.ResourceSampler, 0, len(dbSamplers)+1)
+	if sampler != nil {
+		samplers = append(samplers, sampler)
+	}
+	for _, s := range dbSamplers {
+		samplers = append(samplers, s)
+	}
+	return samplers
+}
+
 func stopSampler(sampler *container.ResourceSampler, result *summary.ServerResult) {
 	if sampler != nil {
 		stats := sampler.Stop()
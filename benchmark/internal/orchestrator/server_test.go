@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"benchmark-client/internal/container"
+)
+
+func TestStartWithRetries(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("docker: connection reset")
+	flaky := func(failures int) (func(context.Context) (*container.Server, error), *int) {
+		calls := 0
+		return func(context.Context) (*container.Server, error) {
+			calls++
+			if calls <= failures {
+				return nil, errTransient
+			}
+			return &container.Server{ID: "abc"}, nil
+		}, &calls
+	}
+
+	start, calls := flaky(1)
+	srv, retries, err := startWithRetries(context.Background(), 2, 0, start)
+	if err != nil || srv == nil || srv.ID != "abc" {
+		t.Fatalf("transient failure: got %v, %v", srv, err)
+	}
+	if retries != 1 || *calls != 2 {
+		t.Errorf("transient failure: got %d retries over %d calls, want 1 over 2", retries, *calls)
+	}
+
+	start, calls = flaky(5)
+	_, retries, err = startWithRetries(context.Background(), 2, 0, start)
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("persistent failure: got %v, want %v", err, errTransient)
+	}
+	if retries != 2 || *calls != 3 {
+		t.Errorf("persistent failure: got %d retries over %d calls, want 2 over 3", retries, *calls)
+	}
+
+	start, calls = flaky(1)
+	if _, retries, err = startWithRetries(context.Background(), 0, 0, start); err == nil || retries != 0 || *calls != 1 {
+		t.Errorf("no retries: got err %v, %d retries over %d calls", err, retries, *calls)
+	}
+}
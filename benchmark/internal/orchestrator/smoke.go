@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"benchmark-client/internal/cli"
+	"benchmark-client/internal/client"
+	"benchmark-client/internal/config"
+	"benchmark-client/internal/container"
+	"benchmark-client/internal/database"
+)
+
+// Smoke mode (--smoke) brings each server up exactly as a benchmark would —
+// container, readiness, reset, seed — then sends one request per testcase and
+// one cycle per flow and stops. There is no warmup, load phase, resource
+// sampling, results export, or metrics: it only answers "is this config right
+// for this server?".
+
+// runSmoke is Run's smoke-mode path: databases only, no Grafana or metrics DB.
+func (o *Orchestrator) runSmoke(ctx context.Context) error {
+	cli.Section("Infrastructure")
+
+	cli.Infof("Starting database stack...")
+	if _, err := o.compose.EnsureDatabases(ctx); err != nil {
+		o.cleanupDatabases() //nolint:contextcheck // cleanup uses fresh context
+		return err
+	}
+	if err := o.compose.WaitHealthy(ctx, 2*time.Minute, o.databases); err != nil {
+		o.cleanupDatabases() //nolint:contextcheck // cleanup uses fresh context
+		return err
+	}
+	cli.Successf("All databases ready")
+
+	var failed []string
+	for _, server := range o.servers {
+		if ctx.Err() != nil {
+			cli.Warnf("Interrupted, stopping...")
+			break
+		}
+		cli.ServerHeader(server.Name)
+		if err := smokeServer(ctx, server, o.databases, o.compose.NetworkName()); err != nil {
+			cli.Failf("%v", err)
+			failed = append(failed, server.Name)
+		}
+	}
+
+	o.cleanupDatabases() //nolint:contextcheck // cleanup uses fresh context
+
+	if len(failed) > 0 {
+		return asFailures(fmt.Errorf("smoke test failed for: %s", strings.Join(failed, ", ")))
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func smokeServer(ctx context.Context, server *config.ResolvedServer, databases []string, network string) error {
+	if server.ExternalUrl != "" {
+		cli.Infof("External server at %s (no container)", server.ExternalUrl)
+		return smokeAt(ctx, server, server.ExternalUrl, databases)
+	}
+	srv, err := container.Start(ctx, &container.StartOptions{
+		Image:          server.ImageName,
+		ContainerPort:  server.Port,
+		CpuLimit:       server.CpuLimit,
+		MemoryLimit:    server.MemoryLimit,
+		Network:        network,
+		Databases:      databases,
+		StartupTimeout: 60 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	defer stopContainer(srv) //nolint:contextcheck // intentionally uses fresh context for cleanup after cancellation
+	cli.Successf("Ready at %s (container: %.12s)", srv.BaseURL, srv.ID)
+
+	return smokeAt(ctx, server, srv.BaseURL, databases)
+}
+
+// smokeAt resets and seeds like a real run, then prints one pass/fail line per
+// check. Failures carry the validator's message, which includes the response
+// snippet. Shared by the container path and --target.
+func smokeAt(ctx context.Context, server *config.ResolvedServer, serverUrl string, databases []string) error {
+	if len(databases) > 0 {
+		if err := database.ResetAll(ctx, serverUrl, server.ResetPath, databases); err != nil {
+			return fmt.Errorf("failed to reset databases: %w", err)
+		}
+	}
+	if err := seedDatabases(ctx, server, serverUrl); err != nil {
+		return err
+	}
+
+	suite := client.NewSuite(ctx, server, serverUrl, nil)
+	defer suite.Close()
+
+	checks := suite.Smoke() //nolint:contextcheck // context is stored in Suite struct
+	var failures int
+	for _, check := range checks {
+		if check.Error == "" {
+			cli.Successf("%s  %s", check.Name, cli.FormatLatency(check.Latency))
+			continue
+		}
+		failures++
+		cli.Failf("%s: %s", check.Name, check.Error)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failures > 0 {
+		return asFailures(fmt.Errorf("%d of %d smoke checks failed", failures, len(checks)))
+	}
+	cli.Successf("All %d smoke checks passed", len(checks))
+	return nil
+}
+
+// RunTargetSmoke is --smoke for --target: the same checks against an
+// externally-managed server.
+func RunTargetSmoke(ctx context.Context, cfg *config.Config, server *config.ResolvedServer, baseUrl string) error {
+	cli.ServerHeader(server.Name)
+	cli.Infof("Smoke testing external target %s", baseUrl)
+	err := smokeAt(ctx, server, baseUrl, cfg.Databases)
+	if errors.Is(err, context.Canceled) {
+		cli.Warnf("Interrupted, stopping...")
+	}
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"benchmark-client/internal/client"
 	"benchmark-client/internal/config"
 	"benchmark-client/internal/database"
+	"benchmark-client/internal/metrics"
 	"benchmark-client/internal/summary"
 )
 
@@ -16,40 +17,87 @@ import (
 // caller owns the server's lifecycle, so no containers, no compose stacks, no
 // resource sampling, and no metrics DB — the suite runs against baseUrl and
 // the result is exported as JSON only. Used by the oha calibration gate
-// (PLAN §7.6) and for ad-hoc runs against an already-running server.
-func RunTarget(ctx context.Context, cfg *config.Config, server *config.ResolvedServer, baseUrl, resultsDir string) error {
+// (PLAN §7.6) and for ad-hoc runs against an already-running server. Of opts
+// only the output switches (RawLatencies, Timeseries, CompactJSON) and
+// FailOnError apply.
+func RunTarget(ctx context.Context, cfg *config.Config, server *config.ResolvedServer, baseUrl, resultsDir string, opts Options) error {
+	return runTarget(ctx, cfg, server, baseUrl, resultsDir, opts, "")
+}
+
+// RunSelfTest is RunTarget against the in-process selftest server at baseUrl
+// (--self-test); the printed results carry a "self-test (no container)" note.
+func RunSelfTest(ctx context.Context, cfg *config.Config, server *config.ResolvedServer, baseUrl, resultsDir string, opts Options) error {
+	return runTarget(ctx, cfg, server, baseUrl, resultsDir, opts, selfTestNote)
+}
+
+const selfTestNote = "self-test (no container)"
+
+func runTarget(ctx context.Context, cfg *config.Config, server *config.ResolvedServer, baseUrl, resultsDir string, opts Options, note string) error {
 	writer := summary.NewWriter(&cfg.Benchmark, resultsDir)
+	writer.SetCompactJSON(opts.CompactJSON)
+	writer.SetRunId(metrics.RunId(time.Now()))
 
 	cli.ServerHeader(server.Name)
-	cli.Infof("Benchmarking external target %s", baseUrl)
+	if note != "" {
+		cli.Infof("Benchmarking %s at %s", note, baseUrl)
+	} else {
+		cli.Infof("Benchmarking external target %s", baseUrl)
+	}
+	summary.EmitServerStarted(server.Name)
 
 	result := &summary.ServerResult{
 		Name:      server.Name,
+		Note:      note,
 		StartTime: time.Now(),
 		Results:   make([]client.EndpointResult, 0),
 	}
 
 	if len(cfg.Databases) > 0 {
-		if err := database.ResetAll(ctx, baseUrl, cfg.Databases); err != nil {
+		if err := database.ResetAll(ctx, baseUrl, server.ResetPath, cfg.Databases); err != nil {
 			return fmt.Errorf("failed to reset databases: %w", err)
 		}
 		cli.Infof("Reset all databases")
 	}
 
-	suiteOut, runErr := runSuite(ctx, server, baseUrl)
+	if err := seedDatabases(ctx, server, baseUrl); err != nil {
+		return err
+	}
+
+	suiteOut, runErr := runSuite(ctx, server, baseUrl, nil)
 	if runErr != nil {
 		result.SetError(runErr)
 	} else {
 		result.Complete(suiteOut.allResults())
 		result.Sequences = suiteOut.sequences
+		result.Mixed = suiteOut.mixed
 	}
 
 	summary.PrintServerSummary(result)
+	summary.EmitServerCompleted(result)
+	defer writer.EmitTargetRunCompleted(result)
 	path, err := writer.ExportServerResult(result)
 	if err != nil {
 		return fmt.Errorf("failed to export %s results: %w", server.Name, err)
 	}
 	cli.Infof("Exported: %s", path)
 
-	return runErr
+	if runErr == nil && opts.RawLatencies != "" {
+		exportRawLatencies(opts.RawLatencies, server.Name, suiteOut.timedResults, suiteOut.timedSequences)
+	}
+	if runErr == nil && opts.Timeseries != "" {
+		exportTimeseries(opts.Timeseries, server.Name, suiteOut.timedResults, suiteOut.timedSequences)
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+	rate := result.SuccessRate()
+	if threshold := cfg.Benchmark.AbortBelow; threshold > 0 && rate < threshold {
+		return asFailures(fmt.Errorf("%s success rate %s is below abort_below_success_rate %s",
+			server.Name, cli.FormatRate(rate), cli.FormatRate(threshold)))
+	}
+	if opts.FailOnError && rate < 1 {
+		return asFailures(fmt.Errorf("benchmark failures in: %s (success rate %s)", server.Name, cli.FormatRate(rate)))
+	}
+	return nil
 }
@@ -9,34 +9,60 @@ package roster
 
 import (
 	"encoding/json/v2"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 )
 
 // Entry is the subset of a manifest the benchmark client needs: which image to
-// run, which container port it listens on, and whether the server implements the
-// web suite (mirrors scripts/lib.mts so both discoverers agree). Other manifest
-// fields (language/runtime/databases/etc.) are consumed by other tools.
+// run, which container port it listens on, whether the server implements the
+// web suite (mirrors scripts/lib.mts so both discoverers agree), the optional
+// tags carried into results and metrics for grouping, the optional env/cmd
+// overrides applied when the container starts, optional cpu/memory limits that
+// replace config.json's container limits for this server, and ExternalUrl for a server
+// that runs outside Docker (no image, no container lifecycle). Other manifest fields
+// (language/runtime/databases/etc.) are consumed by other tools.
 type Entry struct {
 	Name  string
 	Image string
 	Port  int
 	Web   bool
+	Tags  []string
+	Env   map[string]string
+	Cmd   []string
+
+	CpuLimit    float64 // 0 = config.json container.cpu_limit
+	MemoryLimit string  // "" = config.json container.memory_limit; normalized by config
+
+	ExternalUrl string
 }
 
 // manifest mirrors config/bench.schema.json. Unknown members are ignored by
 // json/v2's default, so listing only the fields the client uses is safe. Field
 // order matches Entry so the struct conversion in Discover stays valid.
 type manifest struct {
-	Name  string `json:"name"`
-	Image string `json:"image"`
-	Port  int    `json:"port"`
-	Web   bool   `json:"web"`
+	Name  string            `json:"name"`
+	Image string            `json:"image"`
+	Port  int               `json:"port"`
+	Web   bool              `json:"web"`
+	Tags  []string          `json:"tags"`
+	Env   map[string]string `json:"env"`
+	Cmd   []string          `json:"cmd"`
+
+	CpuLimit    float64 `json:"cpu"`
+	MemoryLimit string  `json:"memory"`
+
+	ExternalUrl string `json:"external_url"`
 }
 
+// envKeyPattern is a portable environment variable name.
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Discover scans serversDir with a fixed one-level walk (serversDir/<entry>/bench.json,
 // flat layout PLAN §2.1) — never a recursive scan, so installed dependency trees
 // (node_modules/.venv) can't inject a stray bench.json. It fails loud on a broken or
@@ -76,20 +102,31 @@ func Discover(serversDir string) ([]Entry, error) {
 		if strings.TrimSpace(m.Name) == "" {
 			return nil, fmt.Errorf("manifest %q: missing required field \"name\"", path)
 		}
-		if strings.TrimSpace(m.Image) == "" {
-			return nil, fmt.Errorf("manifest %q: missing required field \"image\"", path)
+		if m.ExternalUrl != "" {
+			if err := validateExternalUrl(&m); err != nil {
+				return nil, fmt.Errorf("manifest %q: %w", path, err)
+			}
+		} else {
+			if strings.TrimSpace(m.Image) == "" {
+				return nil, fmt.Errorf("manifest %q: missing required field \"image\"", path)
+			}
+			if m.Port < 1 || m.Port > 65535 {
+				return nil, fmt.Errorf("manifest %q: port must be between 1 and 65535, got %d", path, m.Port)
+			}
 		}
-		if m.Port < 1 || m.Port > 65535 {
-			return nil, fmt.Errorf("manifest %q: port must be between 1 and 65535, got %d", path, m.Port)
+		if err := validateOverrides(&m); err != nil {
+			return nil, fmt.Errorf("manifest %q: %w", path, err)
 		}
 		if prior, ok := seenName[m.Name]; ok {
 			return nil, fmt.Errorf("duplicate server name %q in %q and %q", m.Name, path, prior)
 		}
-		if prior, ok := seenImage[m.Image]; ok {
+		if prior, ok := seenImage[m.Image]; ok && m.Image != "" {
 			return nil, fmt.Errorf("duplicate image %q in %q and %q", m.Image, path, prior)
 		}
 		seenName[m.Name] = path
-		seenImage[m.Image] = path
+		if m.Image != "" {
+			seenImage[m.Image] = path
+		}
 
 		entries = append(entries, Entry(m))
 	}
@@ -101,3 +138,48 @@ func Discover(serversDir string) ([]Entry, error) {
 	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Name, b.Name) })
 	return entries, nil
 }
+
+// validateExternalUrl checks an external server's base URL and trims its
+// trailing slash. Container-only settings are rejected rather than silently
+// ignored, so a manifest can't look containerized while it isn't.
+func validateExternalUrl(m *manifest) error {
+	u, err := url.Parse(m.ExternalUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("external_url must be an http(s) URL, got %q", m.ExternalUrl)
+	}
+	if len(m.Env) > 0 || len(m.Cmd) > 0 {
+		return errors.New("env and cmd apply to containers and cannot be combined with external_url")
+	}
+	if m.CpuLimit != 0 || m.MemoryLimit != "" {
+		return errors.New("cpu and memory limit containers and cannot be combined with external_url")
+	}
+	m.ExternalUrl = strings.TrimRight(m.ExternalUrl, "/")
+	return nil
+}
+
+// validateOverrides checks the container env/cmd overrides. They reach Docker as
+// an argv and an environment list through the API — never through a shell — so
+// quoting is not a concern; what is rejected is what the kernel can't carry (NUL
+// bytes) and names that aren't valid variables.
+func validateOverrides(m *manifest) error {
+	for key, value := range m.Env {
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("env key %q must match %s", key, envKeyPattern)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("env %s contains a NUL byte", key)
+		}
+	}
+	if m.Cmd != nil && (len(m.Cmd) == 0 || strings.TrimSpace(m.Cmd[0]) == "") {
+		return fmt.Errorf("cmd must start with a non-empty executable")
+	}
+	for i, arg := range m.Cmd {
+		if strings.ContainsRune(arg, 0) {
+			return fmt.Errorf("cmd[%d] contains a NUL byte", i)
+		}
+	}
+	if m.CpuLimit < 0 {
+		return fmt.Errorf("cpu must be > 0, got %v", m.CpuLimit)
+	}
+	return nil
+}
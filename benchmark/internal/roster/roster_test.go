@@ -19,7 +19,7 @@ func writeManifest(t *testing.T, dir, name, body string) {
 
 func TestDiscoverSortsAndParses(t *testing.T) {
 	dir := t.TempDir()
-	writeManifest(t, dir, "go-chi", `{"name":"go-chi","language":"go","runtime":"go","image":"bench/go-chi","port":8080,"databases":["postgres"],"experimental":false,"dev_port":21002,"web":true}`)
+	writeManifest(t, dir, "go-chi", `{"name":"go-chi","language":"go","runtime":"go","image":"bench/go-chi","port":8080,"databases":["postgres"],"experimental":false,"dev_port":21002,"web":true,"tags":["compiled"]}`)
 	writeManifest(t, dir, "ts-express", `{"name":"ts-express","runtime":"node","image":"bench/ts-express","port":8080}`)
 	// A non-server dir without a manifest must be skipped, not error.
 	if err := os.MkdirAll(filepath.Join(dir, "shared"), 0o755); err != nil {
@@ -40,12 +40,63 @@ func TestDiscoverSortsAndParses(t *testing.T) {
 	if entries[0].Image != "bench/go-chi" || entries[0].Port != 8080 || !entries[0].Web {
 		t.Fatalf("wrong fields: %+v", entries[0])
 	}
+	if len(entries[0].Tags) != 1 || entries[0].Tags[0] != "compiled" || entries[1].Tags != nil {
+		t.Fatalf("wrong tags: %+v / %+v", entries[0].Tags, entries[1].Tags)
+	}
 	// web defaults to false when the manifest omits it (ts-express here).
 	if entries[1].Web {
 		t.Fatalf("expected ts-express web=false, got %+v", entries[1])
 	}
 }
 
+func TestDiscoverOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "go-chi", `{"name":"go-chi","image":"bench/go-chi","port":8080,"env":{"GOGC":"200","MODE":"a b; rm -rf /"},"cmd":["/server","--prefork"]}`)
+
+	entries, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	// Values are passed through as-is: no shell ever sees them.
+	if got := entries[0].Env; len(got) != 2 || got["GOGC"] != "200" || got["MODE"] != "a b; rm -rf /" {
+		t.Fatalf("wrong env: %+v", got)
+	}
+	if got := entries[0].Cmd; len(got) != 2 || got[0] != "/server" || got[1] != "--prefork" {
+		t.Fatalf("wrong cmd: %+v", got)
+	}
+}
+
+func TestDiscoverContainerLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "go-chi", `{"name":"go-chi","image":"bench/go-chi","port":8080,"cpu":0.5,"memory":"1gb"}`)
+
+	entries, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if entries[0].CpuLimit != 0.5 || entries[0].MemoryLimit != "1gb" {
+		t.Fatalf("wrong limits: %+v", entries[0])
+	}
+}
+
+func TestDiscoverExternalServer(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "local-rs", `{"name":"local-rs","runtime":"rust","external_url":"http://localhost:20100/"}`)
+	writeManifest(t, dir, "go-chi", `{"name":"go-chi","image":"bench/go-chi","port":8080}`)
+
+	entries, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	external := entries[1]
+	if external.Name != "local-rs" || external.ExternalUrl != "http://localhost:20100" || external.Image != "" {
+		t.Fatalf("wrong external entry: %+v", external)
+	}
+	if entries[0].ExternalUrl != "" {
+		t.Fatalf("container entry got external_url: %+v", entries[0])
+	}
+}
+
 func TestDiscoverErrors(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -60,6 +111,30 @@ func TestDiscoverErrors(t *testing.T) {
 			writeManifest(t, dir, "a", `{"name":"x","image":"bench/a","port":1}`)
 			writeManifest(t, dir, "b", `{"name":"x","image":"bench/b","port":2}`)
 		}},
+		{"bad env key", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","image":"i","port":1,"env":{"1BAD":"x"}}`)
+		}},
+		{"env NUL", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","image":"i","port":1,"env":{"MODE":"a\u0000b"}}`)
+		}},
+		{"empty cmd", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","image":"i","port":1,"cmd":[]}`)
+		}},
+		{"blank executable", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","image":"i","port":1,"cmd":[" ","--fast"]}`)
+		}},
+		{"external not http", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","external_url":"localhost:8080"}`)
+		}},
+		{"external with cmd", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","external_url":"http://localhost:8080","cmd":["/server"]}`)
+		}},
+		{"external with limits", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","external_url":"http://localhost:8080","cpu":2}`)
+		}},
+		{"negative cpu", func(t *testing.T, dir string) {
+			writeManifest(t, dir, "a", `{"name":"a","image":"i","port":1,"cpu":-1}`)
+		}},
 		{"dup image", func(t *testing.T, dir string) {
 			writeManifest(t, dir, "a", `{"name":"a","image":"bench/x","port":1}`)
 			writeManifest(t, dir, "b", `{"name":"b","image":"bench/x","port":2}`)
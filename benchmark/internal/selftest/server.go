@@ -0,0 +1,282 @@
+// Package selftest is a built-in, in-process implementation of the standard
+// endpoints (contract/), so --self-test can drive the whole client pipeline —
+// suite, stats, summary, export — without Docker. Databases are in-memory
+// maps; any {database} name is accepted.
+package selftest
+
+import (
+	"cmp"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerName is the resolved server name self-test results are exported under.
+const ServerName = "self-test"
+
+// maxFileBytes bounds multipart parsing, matching the servers' 1MB upload cap.
+const maxFileBytes = 1 << 20
+
+// Start serves Handler on a loopback port; the caller closes it.
+func Start() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
+
+// Handler routes the standard endpoints: /, /health, /params/*, the /events
+// stream, and the /db/{database} users CRUD, health, and reset routes.
+func Handler() http.Handler {
+	store := &userStore{users: make(map[string]map[string]*user)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"hello": "world"})
+	})
+	mux.HandleFunc("GET /health", writeOK)
+
+	mux.HandleFunc("GET /params/search", handleSearch)
+	mux.HandleFunc("GET /params/url/{dynamic}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"dynamic": r.PathValue("dynamic")})
+	})
+	mux.HandleFunc("GET /params/header", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"header": cmp.Or(strings.TrimSpace(r.Header.Get("X-Custom-Header")), "none")})
+	})
+	mux.HandleFunc("POST /params/body", handleBody)
+	mux.HandleFunc("GET /params/cookie", handleCookie)
+	mux.HandleFunc("POST /params/form", handleForm)
+	mux.HandleFunc("POST /params/file", handleFile)
+	mux.HandleFunc("GET /events", handleEvents)
+
+	mux.HandleFunc("GET /db/{database}/health", writeOK)
+	mux.HandleFunc("POST /db/{database}/users", store.create)
+	mux.HandleFunc("GET /db/{database}/users/{id}", store.read)
+	mux.HandleFunc("PATCH /db/{database}/users/{id}", store.update)
+	mux.HandleFunc("DELETE /db/{database}/users/{id}", store.delete)
+	mux.HandleFunc("DELETE /db/{database}/reset", store.reset)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.MarshalWrite(w, data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeOK(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("OK"))
+}
+
+func parseInt(value string, fallback int) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return n
+	}
+	return fallback
+}
+
+func handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"search": cmp.Or(strings.TrimSpace(query.Get("q")), "none"),
+		"limit":  parseInt(query.Get("limit"), 10),
+	})
+}
+
+func handleBody(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	if err := json.UnmarshalRead(r.Body, &body); err != nil || body == nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"body": body})
+}
+
+func handleCookie(w http.ResponseWriter, r *http.Request) {
+	value := "none"
+	if c, err := r.Cookie("foo"); err == nil {
+		value = cmp.Or(strings.TrimSpace(c.Value), "none")
+	}
+	http.SetCookie(w, &http.Cookie{Name: "bar", Value: "12345", MaxAge: 10, HttpOnly: true, Path: "/"})
+	writeJSON(w, http.StatusOK, map[string]string{"cookie": value})
+}
+
+func handleForm(w http.ResponseWriter, r *http.Request) {
+	// ParseMultipartForm parses urlencoded bodies before reporting ErrNotMultipart.
+	if err := r.ParseMultipartForm(maxFileBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		writeError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name": cmp.Or(strings.TrimSpace(r.FormValue("name")), "none"),
+		"age":  parseInt(r.FormValue("age"), 0),
+	})
+}
+
+// handleEvents streams ?count= Server-Sent Events (default 10), one every
+// ?interval_ms= (default 5), then ends the stream; a client that hangs up
+// early stops it.
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	query := r.URL.Query()
+	count := parseInt(query.Get("count"), 10)
+	interval := time.Duration(parseInt(query.Get("interval_ms"), 5)) * time.Millisecond
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for i := range count {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		_, _ = fmt.Fprintf(w, "id: %d\ndata: {\"n\": %d}\n\n", i, i)
+		flusher.Flush()
+	}
+}
+
+func handleFile(w http.ResponseWriter, r *http.Request) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid multipart form data")
+		return
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(io.LimitReader(file, maxFileBytes+1))
+	if err != nil || len(data) > maxFileBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, "file size exceeded")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"filename": header.Filename,
+		"size":     len(data),
+		"content":  string(data),
+	})
+}
+
+type user struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	FavoriteNumber *int   `json:"favoriteNumber,omitempty"`
+}
+
+type userPatch struct {
+	Name           *string `json:"name"`
+	Email          *string `json:"email"`
+	FavoriteNumber *int    `json:"favoriteNumber"`
+}
+
+// userStore keeps one map of users per database name.
+type userStore struct {
+	mu     sync.Mutex
+	nextId int
+	users  map[string]map[string]*user
+}
+
+func (s *userStore) create(w http.ResponseWriter, r *http.Request) {
+	var u user
+	if err := json.UnmarshalRead(r.Body, &u); err != nil || u.Name == "" || !strings.Contains(u.Email, "@") {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	s.nextId++
+	u.Id = strconv.Itoa(s.nextId)
+	db := r.PathValue("database")
+	if s.users[db] == nil {
+		s.users[db] = make(map[string]*user)
+	}
+	s.users[db][u.Id] = &u
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, &u)
+}
+
+func (s *userStore) read(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	u, ok := s.users[r.PathValue("database")][r.PathValue("id")]
+	var snapshot user
+	if ok {
+		snapshot = *u
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "user with id "+r.PathValue("id")+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &snapshot)
+}
+
+func (s *userStore) update(w http.ResponseWriter, r *http.Request) {
+	var patch userPatch
+	if err := json.UnmarshalRead(r.Body, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	u, ok := s.users[r.PathValue("database")][r.PathValue("id")]
+	var snapshot user
+	if ok {
+		if patch.Name != nil {
+			u.Name = *patch.Name
+		}
+		if patch.Email != nil {
+			u.Email = *patch.Email
+		}
+		if patch.FavoriteNumber != nil {
+			u.FavoriteNumber = patch.FavoriteNumber
+		}
+		snapshot = *u
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "user with id "+r.PathValue("id")+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &snapshot)
+}
+
+func (s *userStore) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	db, id := r.PathValue("database"), r.PathValue("id")
+	_, ok := s.users[db][id]
+	delete(s.users[db], id)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "user with id "+id+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *userStore) reset(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	delete(s.users, r.PathValue("database"))
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
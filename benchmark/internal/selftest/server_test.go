@@ -0,0 +1,85 @@
+package selftest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/client"
+	"benchmark-client/internal/config"
+)
+
+// TestSuiteAgainstSelfTestServer runs the shipped config end to end against the
+// in-process server: every endpoint and sequence must validate.
+func TestSuiteAgainstSelfTestServer(t *testing.T) {
+	t.Parallel()
+
+	srv := Start()
+	defer srv.Close()
+
+	_, server, err := config.LoadTarget("../../../config/config.json", srv.URL)
+	if err != nil {
+		t.Fatalf("LoadTarget: %v", err)
+	}
+	server.DurationPerEndpoint = 50 * time.Millisecond
+	server.Concurrency = 4
+	server.WarmupDuration = 0
+	server.WarmupStable = nil
+
+	suite := client.NewSuite(context.Background(), server, srv.URL, nil)
+	defer suite.Close()
+
+	endpoints, err := suite.RunAll()
+	if err != nil {
+		t.Fatalf("RunAll: %v", err)
+	}
+	for _, r := range endpoints {
+		if r.Stats == nil || r.Stats.Count == 0 || r.FailureCount > 0 {
+			t.Errorf("%s %s: %d failures (last: %s)", r.Method, r.Path, r.FailureCount, r.LastError)
+		}
+	}
+	for _, seq := range suite.RunSequences() {
+		if seq.Successes == 0 || seq.Failures > 0 {
+			t.Errorf("sequence %s/%s: %d failures (last: %s)", seq.SequenceId, seq.Database, seq.Failures, seq.LastError)
+		}
+	}
+}
+
+func TestEventsEndpoint(t *testing.T) {
+	t.Parallel()
+
+	srv := Start()
+	defer srv.Close()
+
+	server := &config.ResolvedServer{
+		Name:                ServerName,
+		RequestTimeout:      2 * time.Second,
+		Concurrency:         2,
+		DurationPerEndpoint: 50 * time.Millisecond,
+		MaxBodyBytes:        1 << 20,
+	}
+	server.Testcases = []*config.Testcase{{
+		EndpointName:   "events",
+		Name:           "events",
+		Path:           "/events",
+		RequestURI:     "/events?count=3&interval_ms=1",
+		Method:         "GET",
+		ExpectedStatus: config.ExactStatus(200),
+		SSE:            &config.SSEConfig{Events: 3},
+	}}
+	server.EndpointOrder = []string{"events"}
+
+	suite := client.NewSuite(context.Background(), server, srv.URL, nil)
+	defer suite.Close()
+
+	endpoints, err := suite.RunAll()
+	if err != nil {
+		t.Fatalf("RunAll: %v", err)
+	}
+	if len(endpoints) != 1 || endpoints[0].FailureCount > 0 || endpoints[0].SSE == nil {
+		t.Fatalf("events: got %+v, want streams without failures", endpoints)
+	}
+	if sse := endpoints[0].SSE; sse.Events != 3*sse.Streams || sse.InterEvent == nil {
+		t.Errorf("events: got %+v, want 3 events per stream", sse)
+	}
+}
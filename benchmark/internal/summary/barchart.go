@@ -0,0 +1,64 @@
+package summary
+
+import (
+	"strings"
+
+	"benchmark-client/internal/cli"
+)
+
+// barEighths are the partial blocks for a bar's last cell, one to seven
+// eighths wide; cli's ASCII mode turns full blocks into '#' and drops these.
+var barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+const (
+	barLabelWidth = 10 + 2 + 8 + 2 // server column, gap, latency, gap
+	barMinWidth   = 10
+	barMaxWidth   = 60
+)
+
+// barWidth is the cells a bar may use on a width-wide terminal.
+func barWidth(width int) int {
+	return min(max(width-len(cli.Indent)-barLabelWidth, barMinWidth), barMaxWidth)
+}
+
+// latencyBars renders one bar per successful shown server, proportional to
+// its avg latency against the slowest, which spans the full width: the
+// fastest server always has the shortest bar. Bars resolve to eighths of a
+// cell; a nonzero latency gets at least one eighth.
+func latencyBars(shown []rankedServer, width int) []string {
+	var slowest int64
+	for i := range shown {
+		if !shown[i].failed {
+			slowest = max(slowest, shown[i].avg)
+		}
+	}
+	bars := make([]string, len(shown))
+	if slowest <= 0 {
+		return bars
+	}
+	for i := range shown {
+		if shown[i].failed || shown[i].avg <= 0 {
+			continue
+		}
+		eighths := max(int(float64(shown[i].avg)/float64(slowest)*float64(width*8)+0.5), 1)
+		bars[i] = strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+	}
+	return bars
+}
+
+// printBarChart draws the shown servers' avg latencies as horizontal bars
+// (--bar-chart), in ranking order, scaled to the terminal width.
+func printBarChart(shown []rankedServer) {
+	bars := latencyBars(shown, barWidth(cli.TerminalWidth()))
+	cli.Linef("Avg Latency (bar = share of the slowest)")
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	for i := range shown {
+		s := &shown[i]
+		if s.failed {
+			cli.Printf("  %-10s  %8s  %s FAIL\n", s.name, "-", cli.SymbolFail)
+			continue
+		}
+		cli.Printf("  %-10s  %8s  %s\n", s.name, cli.FormatLatency(s.avg), bars[i])
+	}
+	cli.Blank()
+}
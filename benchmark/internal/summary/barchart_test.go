@@ -0,0 +1,42 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestLatencyBars(t *testing.T) {
+	t.Parallel()
+
+	shown := []rankedServer{
+		{name: "fast", avg: 1000},
+		{name: "mid", avg: 2500},
+		{name: "slow", avg: 4000},
+		{name: "down", failed: true},
+	}
+	const width = 20
+	bars := latencyBars(shown, width)
+
+	longest := 0
+	for i, bar := range bars {
+		if utf8.RuneCountInString(bar) > utf8.RuneCountInString(bars[longest]) {
+			longest = i
+		}
+	}
+	if shown[longest].name != "slow" || bars[longest] != strings.Repeat("█", width) {
+		t.Errorf("longest bar: got %s %q, want slow spanning all %d cells", shown[longest].name, bars[longest], width)
+	}
+	if bars[0] != strings.Repeat("█", 5) {
+		t.Errorf("fastest bar: got %q, want a quarter of the width", bars[0])
+	}
+	if bars[1] != strings.Repeat("█", 12)+"▌" {
+		t.Errorf("mid bar: got %q, want 12.5 cells", bars[1])
+	}
+	if bars[3] != "" {
+		t.Errorf("failed server: got %q, want no bar", bars[3])
+	}
+	if got := barWidth(0); got != barMinWidth {
+		t.Errorf("narrow terminal: got %d cells, want %d", got, barMinWidth)
+	}
+}
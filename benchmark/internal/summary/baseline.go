@@ -0,0 +1,147 @@
+package summary
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"benchmark-client/internal/cli"
+)
+
+// ErrBaselineExists is returned by SaveBaseline when the name is taken and
+// overwriting wasn't requested.
+var ErrBaselineExists = errors.New("baseline already exists")
+
+// baselineName keeps a baseline to one file directly inside the store.
+var baselineName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// BaselinePath is where the baseline called name lives in dir: the store is
+// just one results.json copy per name, <dir>/<name>.json.
+func BaselinePath(dir, name string) (string, error) {
+	if !baselineName.MatchString(name) {
+		return "", fmt.Errorf("invalid baseline name %q (letters, digits, '.', '_' and '-')", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveBaseline copies the run's results.json into dir as baseline name. An
+// existing baseline is replaced only with overwrite.
+func SaveBaseline(dir, name, resultsPath string, overwrite bool) (string, error) {
+	path, err := BaselinePath(dir, name)
+	if err != nil {
+		return "", err
+	}
+	if !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return "", fmt.Errorf("%w: %s", ErrBaselineExists, path)
+		}
+	}
+	data, err := os.ReadFile(resultsPath) //nolint:gosec // path is the results.json this run wrote
+	if err != nil {
+		return "", fmt.Errorf("failed to read results: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create baseline dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return path, nil
+}
+
+// LoadBaseline reads baseline name from dir.
+func LoadBaseline(dir, name string) (*MetaResults, error) {
+	path, err := BaselinePath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is confined to the baseline store by BaselinePath
+	if err != nil {
+		return nil, fmt.Errorf("baseline %q: %w", name, err)
+	}
+	var baseline MetaResults
+	if err := json.Unmarshal(data, &baseline, durationOpts); err != nil {
+		return nil, fmt.Errorf("baseline %q: %w", name, err)
+	}
+	return &baseline, nil
+}
+
+// baselineDelta pairs one server's aggregate stats in the baseline and the
+// current run; either side is nil when the server is missing there or failed.
+type baselineDelta struct {
+	server   string
+	baseline *StatsSummary
+	current  *StatsSummary
+}
+
+// compareBaseline matches servers by name, in the current run's order
+// followed by servers only the baseline has.
+func compareBaseline(baseline, current *MetaResults) []baselineDelta {
+	base := make(map[string]*StatsSummary, len(baseline.Servers))
+	for i := range baseline.Servers {
+		base[baseline.Servers[i].Name] = serverStats(&baseline.Servers[i])
+	}
+	deltas := make([]baselineDelta, 0, len(current.Servers))
+	seen := make(map[string]bool, len(current.Servers))
+	for i := range current.Servers {
+		s := &current.Servers[i]
+		seen[s.Name] = true
+		deltas = append(deltas, baselineDelta{server: s.Name, baseline: base[s.Name], current: serverStats(s)})
+	}
+	for i := range baseline.Servers {
+		if name := baseline.Servers[i].Name; !seen[name] {
+			deltas = append(deltas, baselineDelta{server: name, baseline: base[name]})
+		}
+	}
+	return deltas
+}
+
+func serverStats(s *ServerSummary) *StatsSummary {
+	if s.Error != "" {
+		return nil
+	}
+	return s.Stats
+}
+
+// percentChange formats the move from base to current, "-" without a base.
+func percentChange(base, current float64) string {
+	if base == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", (current-base)/base*100)
+}
+
+// PrintBaselineComparison shows each server's avg, p99 and rps against
+// baseline name. Lower latency and higher rps are improvements.
+func PrintBaselineComparison(name string, baseline, current *MetaResults) {
+	cli.Linef("Baseline comparison (%s, saved %s)", name, baseline.Meta.Timestamp.Format("2006-01-02 15:04"))
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	cli.Printf("  %-10s  %8s  %7s  %8s  %7s  %9s  %7s  %5s\n",
+		"Server", "Avg", "Δ", "P99", "Δ", "RPS", "Δ", "Rate")
+	for _, d := range compareBaseline(baseline, current) {
+		switch {
+		case d.current == nil && d.baseline == nil:
+			cli.Printf("  %-10s  failed in both runs\n", d.server)
+		case d.current == nil:
+			cli.Printf("  %-10s  not in this run or failed\n", d.server)
+		case d.baseline == nil:
+			cli.Printf("  %-10s  %8s  %7s  %8s  %7s  %9s  %7s  %5s  (not in baseline)\n",
+				d.server,
+				cli.FormatLatency(d.current.AvgNs), "-",
+				cli.FormatLatency(d.current.P99Ns), "-",
+				cli.FormatRps(d.current.Rps), "-",
+				cli.FormatRate(d.current.SuccessRate))
+		default:
+			cli.Printf("  %-10s  %8s  %7s  %8s  %7s  %9s  %7s  %5s\n",
+				d.server,
+				cli.FormatLatency(d.current.AvgNs), percentChange(float64(d.baseline.AvgNs), float64(d.current.AvgNs)),
+				cli.FormatLatency(d.current.P99Ns), percentChange(float64(d.baseline.P99Ns), float64(d.current.P99Ns)),
+				cli.FormatRps(d.current.Rps), percentChange(d.baseline.Rps, d.current.Rps),
+				cli.FormatRate(d.current.SuccessRate))
+		}
+	}
+	cli.Blank()
+}
@@ -0,0 +1,118 @@
+package summary
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoadBaseline(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := filepath.Join(dir, "baselines")
+	results := filepath.Join(dir, "results.json")
+	write := func(rps string) {
+		t.Helper()
+		data := `{"meta": {"timestamp": "2026-01-02T03:04:05Z", "config": {}}, "summary": {}, ` +
+			`"servers": [{"name": "go-chi", "duration_ms": 1, "stats": {"count": 1, "total_count": 1, "rps": ` + rps + `, "avg_ns": 1, "min_ns": 1, "max_ns": 1, "success_rate": 1}}]}`
+		if err := os.WriteFile(results, []byte(data), 0o600); err != nil {
+			t.Fatalf("write results: %v", err)
+		}
+	}
+
+	write("100")
+	if _, err := SaveBaseline(store, "main", results, false); err != nil {
+		t.Fatalf("SaveBaseline: %v", err)
+	}
+	write("200")
+	if _, err := SaveBaseline(store, "main", results, false); !errors.Is(err, ErrBaselineExists) {
+		t.Fatalf("second save: got %v, want ErrBaselineExists", err)
+	}
+	baseline, err := LoadBaseline(store, "main")
+	if err != nil {
+		t.Fatalf("LoadBaseline: %v", err)
+	}
+	if got := baseline.Servers[0].Stats.Rps; got != 100 {
+		t.Errorf("kept baseline rps: got %v, want 100", got)
+	}
+
+	if _, err := SaveBaseline(store, "main", results, true); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	if baseline, err = LoadBaseline(store, "main"); err != nil {
+		t.Fatalf("LoadBaseline: %v", err)
+	}
+	if got := baseline.Servers[0].Stats.Rps; got != 200 {
+		t.Errorf("overwritten baseline rps: got %v, want 200", got)
+	}
+
+	if _, err := LoadBaseline(store, "missing"); err == nil {
+		t.Error("missing baseline: got nil error")
+	}
+}
+
+func TestBaselinePath(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"main", false},
+		{"v1.2_pre-release", false},
+		{"", true},
+		{"../main", true},
+		{"a/b", true},
+		{".hidden", true},
+	}
+	for _, tc := range cases {
+		path, err := BaselinePath("store", tc.name)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: got err %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if err == nil && path != filepath.Join("store", tc.name+".json") {
+			t.Errorf("%q: got path %q", tc.name, path)
+		}
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	t.Parallel()
+
+	baseline := &MetaResults{Servers: []ServerSummary{
+		{Name: "a", Stats: &StatsSummary{AvgNs: 100}},
+		{Name: "gone", Stats: &StatsSummary{AvgNs: 100}},
+	}}
+	current := &MetaResults{Servers: []ServerSummary{
+		{Name: "new", Stats: &StatsSummary{AvgNs: 50}},
+		{Name: "a", Stats: &StatsSummary{AvgNs: 120}},
+	}}
+
+	deltas := compareBaseline(baseline, current)
+	want := []struct {
+		server              string
+		hasBase, hasCurrent bool
+	}{
+		{"new", false, true},
+		{"a", true, true},
+		{"gone", true, false},
+	}
+	if len(deltas) != len(want) {
+		t.Fatalf("deltas: got %d, want %d", len(deltas), len(want))
+	}
+	for i, w := range want {
+		d := deltas[i]
+		if d.server != w.server || (d.baseline != nil) != w.hasBase || (d.current != nil) != w.hasCurrent {
+			t.Errorf("delta %d: got %s base=%v current=%v, want %+v", i, d.server, d.baseline != nil, d.current != nil, w)
+		}
+	}
+	if got := percentChange(float64(deltas[1].baseline.AvgNs), float64(deltas[1].current.AvgNs)); got != "+20.0%" {
+		t.Errorf("avg change: got %q, want +20.0%%", got)
+	}
+	if got := percentChange(0, 5); got != "-" {
+		t.Errorf("change from zero: got %q, want -", got)
+	}
+}
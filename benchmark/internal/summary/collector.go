@@ -4,9 +4,11 @@ import (
 	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"benchmark-client/internal/client"
@@ -45,17 +47,29 @@ var durationOpts = json.JoinOptions(
 
 type ServerResult struct {
 	Name        string                              `json:"name"`
+	Tags        []string                            `json:"-"`
 	ContainerId string                              `json:"-"`
 	ImageName   string                              `json:"-"`
 	Port        int                                 `json:"-"`
 	StartTime   time.Time                           `json:"-"`
 	EndTime     time.Time                           `json:"-"`
 	Duration    time.Duration                       `json:"-"`
+	Startup     time.Duration                       `json:"-"` // container start until readiness passed (0 in target mode)
+	Retries     int                                 `json:"-"` // server_retries start retries used, whether or not one came up
+	Limits      *ContainerLimits                    `json:"-"` // effective container limits; nil without a container
+	Container   *container.State                    `json:"-"` // set when the container had died by the end of the run
+	Note        string                              `json:"-"` // shown beside the duration line, e.g. the self-test marker
 	Results     []client.EndpointResult             `json:"-"`
 	Sequences   []client.SequenceStats              `json:"-"`
+	Mixed       *client.Stats                       `json:"-"` // blended stats across endpoints, mixed mode only
 	Error       string                              `json:"-"`
 	Resources   *container.ResourceStats            `json:"-"`
 	DbResources map[string]*container.ResourceStats `json:"-"` // database service -> stats during this server's run
+	Warmup      []client.TimedResult                `json:"-"` // warmup requests, --export-warmup only
+
+	// UnitDuration is the server's total_duration share per weight unit,
+	// which differs between servers whose endpoint sets do.
+	UnitDuration time.Duration `json:"-"`
 }
 
 type MetaResults struct {
@@ -65,47 +79,163 @@ type MetaResults struct {
 }
 
 type ResultMeta struct {
+	RunId     string       `json:"run_id,omitempty"` // matches the metrics DB run_id when metrics are exported
 	Timestamp time.Time    `json:"timestamp"`
+	Host      *HostInfo    `json:"host,omitempty"`
+	GitSha    string       `json:"git_sha,omitempty"` // BENCH_GIT_SHA, else git rev-parse HEAD
 	Config    ResultConfig `json:"config"`
 }
 
 type ResultConfig struct {
 	BaseUrl             string `json:"base_url"`
-	Concurrency         int    `json:"concurrency"`
+	Concurrency         int    `json:"concurrency"` // workers
+	Connections         int    `json:"connections"` // per-host connection pool: max_conns, else sized to the workers
 	DurationPerEndpoint string `json:"duration_per_endpoint"`
+	RequestsPerEndpoint int    `json:"requests_per_endpoint,omitempty"` // request-budget mode; duration_per_endpoint then only bounds sequences
+	TotalDuration       string `json:"total_duration,omitempty"`        // total_duration budget; duration_per_endpoint is then one weight unit's share
 	RequestTimeout      string `json:"request_timeout"`
+	ConnectTimeout      string `json:"connect_timeout,omitempty"`     // dial and response-header bound under request_timeout
+	WarmupDuration      string `json:"warmup_duration,omitempty"`     // fixed warmup per endpoint; unused with warmup_until_stable
+	WarmupUntilStable   bool   `json:"warmup_until_stable,omitempty"` // per-endpoint outcome in results[].warmup
+	UserAgent           string `json:"user_agent,omitempty"`          // benchmark.user_agent; empty = Go's default
+	DefaultAccept       string `json:"default_accept,omitempty"`      // benchmark.default_accept
+	ConcurrencyPerCPU   string `json:"concurrency_per_cpu,omitempty"` // "4x" the concurrency was resolved from, against host.cpus
+	MaxTotalConns       int    `json:"max_total_conns,omitempty"`     // benchmark.max_total_conns: in-flight cap across all workers
+}
+
+// endpointBudget describes what bounded each endpoint run, for summary headers.
+func (c *ResultConfig) endpointBudget() string {
+	if c.RequestsPerEndpoint > 0 {
+		return fmt.Sprintf("Requests: %d/endpoint", c.RequestsPerEndpoint)
+	}
+	if c.TotalDuration != "" {
+		return fmt.Sprintf("Duration: %s × weight/endpoint (total %s)", c.DurationPerEndpoint, c.TotalDuration)
+	}
+	return "Duration: " + c.DurationPerEndpoint
 }
 
 type BenchmarkSummary struct {
-	TotalServers      int   `json:"total_servers"`
-	SuccessfulServers int   `json:"successful_servers"`
-	FailedServers     int   `json:"failed_servers"`
-	TotalDurationMs   int64 `json:"total_duration_ms"`
+	TotalServers      int           `json:"total_servers"`
+	SuccessfulServers int           `json:"successful_servers"`
+	FailedServers     int           `json:"failed_servers"`
+	TotalDurationMs   int64         `json:"total_duration_ms"`
+	TotalRequests     int           `json:"total_requests"`
+	ResponseBytes     int64         `json:"response_bytes,omitempty"` // body bytes across every server's measured requests
+	BytesPerSec       float64       `json:"bytes_per_sec,omitempty"`  // ResponseBytes over the run time of the servers that returned any
+	Aborted           *AbortSummary `json:"aborted,omitempty"`        // set when abort_below_success_rate stopped the run
+	Partial           bool          `json:"partial,omitempty"`        // a checkpoint written mid-run; the final write clears it
+}
+
+// AbortSummary names the server whose success rate stopped the run early.
+type AbortSummary struct {
+	Server      string  `json:"server"`
+	SuccessRate float64 `json:"success_rate"`
+	Threshold   float64 `json:"threshold"`
 }
 
 type ServerSummary struct {
-	Name        string                              `json:"name"`
-	DurationMs  int64                               `json:"duration_ms"`
-	Error       string                              `json:"error,omitempty"`
-	Stats       *StatsSummary                       `json:"stats,omitempty"`
-	Results     []EndpointSummary                   `json:"results,omitempty"`
-	Sequences   []client.SequenceStats              `json:"sequences,omitempty"`
-	Resources   *container.ResourceStats            `json:"resources,omitempty"`
-	DbResources map[string]*container.ResourceStats `json:"db_resources,omitempty"`
+	Name          string                              `json:"name"`
+	Tags          []string                            `json:"tags,omitempty"`
+	DurationMs    int64                               `json:"duration_ms"`
+	StartupMs     int64                               `json:"startup_ms,omitempty"`     // container start until readiness passed
+	StartRetries  int                                 `json:"start_retries,omitempty"`  // server_retries start retries used
+	Limits        *ContainerLimits                    `json:"limits,omitempty"`         // the container's effective cpu/memory limits
+	Container     *container.State                    `json:"container,omitempty"`      // OOM kill or exit seen before the container was stopped
+	ResponseBytes int64                               `json:"response_bytes,omitempty"` // sum of the endpoints' response_bytes
+	Error         string                              `json:"error,omitempty"`
+	Stats         *StatsSummary                       `json:"stats,omitempty"`
+	Mixed         *StatsSummary                       `json:"mixed,omitempty"` // blended distribution, mixed mode only
+	Results       []EndpointSummary                   `json:"results,omitempty"`
+	ByDatabase    map[string][]EndpointSummary        `json:"by_database,omitempty"` // Results that ran against a database, bucketed by it
+	Sequences     []client.SequenceStats              `json:"sequences,omitempty"`
+	Resources     *container.ResourceStats            `json:"resources,omitempty"`
+	DbResources   map[string]*container.ResourceStats `json:"db_resources,omitempty"`
+
+	// DurationPerEndpoint is this server's total_duration share per weight
+	// unit; config.duration_per_endpoint says "per server" when they differ.
+	DurationPerEndpoint string `json:"duration_per_endpoint,omitempty"`
+}
+
+// ContainerLimits is the CPU and memory a server's container ran under: its
+// manifest's cpu/memory when set, otherwise config.json's container limits.
+type ContainerLimits struct {
+	Cpu    float64 `json:"cpu"`
+	Memory string  `json:"memory"`
 }
 
 type EndpointSummary struct {
-	Name          string        `json:"name"`
-	Path          string        `json:"path"`
-	Method        string        `json:"method"`
-	Database      string        `json:"database,omitempty"`
-	SequenceId    string        `json:"sequence_id,omitempty"`
-	Error         string        `json:"error,omitempty"`
-	Stats         *StatsSummary `json:"stats,omitempty"`
-	Open          *OpenSummary  `json:"open,omitempty"` // open-model mode only
-	FailureCount  int           `json:"failure_count,omitempty"`
-	CanceledCount int           `json:"canceled_count,omitempty"`
-	LastError     string        `json:"last_error,omitempty"`
+	Name          string           `json:"name"`
+	Path          string           `json:"path"`
+	Method        string           `json:"method"`
+	Database      string           `json:"database,omitempty"`
+	SequenceId    string           `json:"sequence_id,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Warmup        *WarmupSummary   `json:"warmup,omitempty"` // warmup_until_stable only
+	Error         string           `json:"error,omitempty"`
+	Stats         *StatsSummary    `json:"stats,omitempty"`
+	Open          *OpenSummary     `json:"open,omitempty"` // open-model mode only
+	FailureCount  int              `json:"failure_count,omitempty"`
+	CanceledCount int              `json:"canceled_count,omitempty"`
+	LastError     string           `json:"last_error,omitempty"`
+	StatusCounts  map[int]int      `json:"status_counts,omitempty"`
+	DurationMs    int64            `json:"duration_ms,omitempty"` // measured window actually run
+	BestWindow    *WindowSummary   `json:"best_window,omitempty"` // --best-window only
+	ResponseBytes int64            `json:"response_bytes,omitempty"`
+	Phases        *PhasesSummary   `json:"phases,omitempty"`     // --trace-phases only
+	SSE           *SSESummary      `json:"sse,omitempty"`        // type "sse" endpoints only
+	Expected      *ExpectedSummary `json:"expected,omitempty"`   // expected_avg/expected_p99 annotation
+	FailedFast    bool             `json:"failed_fast,omitzero"` // stopped early by fail_fast
+	// AnomalousCount is successes whose latency sample was implausible
+	// (negative, or over twice request_timeout) and left out of Stats.
+	AnomalousCount int `json:"anomalous_count,omitempty"`
+	// WarmupCount is the requests the endpoint's warmup completed; Low marks
+	// fewer than client.LowWarmupRequests (nil without a warmup).
+	WarmupCount *client.WarmupCount `json:"warmup_count,omitempty"`
+	// CachedCount is responses that were 304 or a cache-header hit for an
+	// endpoint expecting 200: its latency may be the cache's, not the server's.
+	CachedCount int `json:"cached_count,omitempty"`
+}
+
+// ExpectedSummary is an endpoint's documented latency, exported for
+// traceability beside the measured stats; it never affects pass/fail.
+type ExpectedSummary struct {
+	AvgNs int64 `json:"avg_ns,omitempty"`
+	P99Ns int64 `json:"p99_ns,omitempty"`
+}
+
+// SSESummary is the event timing of a type "sse" endpoint: first-event
+// latency (also its stats) and the steady-state gap between events.
+type SSESummary struct {
+	Streams    int           `json:"streams"`
+	Events     int           `json:"events"`
+	FirstEvent *PhaseSummary `json:"first_event,omitempty"`
+	InterEvent *PhaseSummary `json:"inter_event,omitempty"`
+}
+
+// WindowSummary is an endpoint's --best-window stat: the one-second window of
+// its measured run with the lowest P50, StartMs into the run.
+type WindowSummary struct {
+	StartMs int64 `json:"start_ms"`
+	Count   int   `json:"count"`
+	P50Ns   int64 `json:"p50_ns"`
+	P95Ns   int64 `json:"p95_ns"`
+	P99Ns   int64 `json:"p99_ns"`
+}
+
+// PhasesSummary is the --trace-phases latency breakdown of one endpoint.
+type PhasesSummary struct {
+	DNS     *PhaseSummary `json:"dns,omitempty"`
+	Connect *PhaseSummary `json:"connect,omitempty"`
+	TLS     *PhaseSummary `json:"tls,omitempty"`
+	TTFB    *PhaseSummary `json:"ttfb,omitempty"`
+}
+
+type PhaseSummary struct {
+	Count  int   `json:"count"`
+	AvgNs  int64 `json:"avg_ns"`
+	P50Ns  int64 `json:"p50_ns"`
+	P99Ns  int64 `json:"p99_ns"`
+	HighNs int64 `json:"high_ns"`
 }
 
 type StatsSummary struct {
@@ -119,7 +249,23 @@ type StatsSummary struct {
 	P999Ns      int64   `json:"p999_ns,omitempty"`
 	MinNs       int64   `json:"min_ns"`
 	MaxNs       int64   `json:"max_ns"`
+	StdDevNs    int64   `json:"stddev_ns,omitempty"` // latency standard deviation (pooled across endpoints for a server)
 	SuccessRate float64 `json:"success_rate"`
+	Sampled     int     `json:"sampled,omitempty"`   // percentiles estimated from this many latencies (max_samples)
+	Estimator   string  `json:"estimator,omitempty"` // "tdigest" when percentiles come from the streaming digest
+	NewConns    int     `json:"new_conns,omitempty"`
+	ReusedConns int     `json:"reused_conns,omitempty"`
+	TailRatio   float64 `json:"tail_ratio,omitempty"` // p99/p50; endpoints only, servers have no pooled percentiles
+
+	QueueDelayP99Ns int64 `json:"queue_delay_p99_ns,omitempty"` // --conn-stats: p99 wait for a client connection
+}
+
+// WarmupSummary records how an adaptive warmup ended: how long it ran and
+// whether the latency settled before max_duration.
+type WarmupSummary struct {
+	DurationMs int64 `json:"duration_ms"`
+	Windows    int   `json:"windows"`
+	Stable     bool  `json:"stable"`
 }
 
 // OpenSummary is the export shape of client.OpenStats: open-model backpressure
@@ -139,9 +285,15 @@ type OpenSummary struct {
 }
 
 type Writer struct {
+	mu         sync.Mutex // serializes the results.json writes and the state they read
 	startTime  time.Time
 	config     *config.BenchmarkConfig
 	resultsDir string
+	aborted    *AbortSummary
+	compact    bool // --compact-json: no indentation in the result files
+	runId      string
+	host       *HostInfo
+	gitSha     string
 }
 
 func NewWriter(cfg *config.BenchmarkConfig, resultsDir string) *Writer {
@@ -149,13 +301,15 @@ func NewWriter(cfg *config.BenchmarkConfig, resultsDir string) *Writer {
 		startTime:  time.Now(),
 		config:     cfg,
 		resultsDir: resultsDir,
+		host:       collectHostInfo(),
+		gitSha:     gitSha(),
 	}
 }
 
 func (w *Writer) ExportServerResult(result *ServerResult) (string, error) {
 	summary := serverSummaryFromResult(result)
 
-	data, err := json.Marshal(summary, jsontext.WithIndent("  "), durationOpts)
+	data, err := w.marshal(summary)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal server results: %w", err)
 	}
@@ -166,13 +320,36 @@ func (w *Writer) ExportServerResult(result *ServerResult) (string, error) {
 
 	path := filepath.Join(w.resultsDir, result.Name+".json")
 	if err = os.WriteFile(path, data, 0o600); err != nil {
-		return "", fmt.Errorf("failed to write server results: %w", err)
+		return "", writeFailed("server results", err)
 	}
 
 	return path, nil
 }
 
 func (w *Writer) ExportMetaResults() (*MetaResults, []ServerSummary, string, error) {
+	return w.exportMeta(false)
+}
+
+// Checkpoint refreshes results.json from the server files exported so far,
+// marked partial, so a crash mid-run still leaves a valid meta file behind.
+func (w *Writer) Checkpoint() error {
+	_, _, _, err := w.exportMeta(true)
+	return err
+}
+
+// CompletedServers reads back the server files exported so far, for the
+// --interim-rankings leaderboard.
+func (w *Writer) CompletedServers() ([]ServerSummary, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	servers, _, _, err := readServerSummaries(w.resultsDir)
+	return servers, err
+}
+
+func (w *Writer) exportMeta(partial bool) (*MetaResults, []ServerSummary, string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	if err := os.MkdirAll(w.resultsDir, 0o750); err != nil {
 		return nil, nil, "", fmt.Errorf("failed to create results dir: %w", err)
 	}
@@ -185,13 +362,22 @@ func (w *Writer) ExportMetaResults() (*MetaResults, []ServerSummary, string, err
 	serverSummaries := make([]ServerSummary, 0, len(servers))
 	for _, s := range servers {
 		serverSummaries = append(serverSummaries, ServerSummary{
-			Name:        s.Name,
-			DurationMs:  s.DurationMs,
-			Error:       s.Error,
-			Stats:       s.Stats,
-			Sequences:   s.Sequences,
-			Resources:   s.Resources,
-			DbResources: s.DbResources,
+			Name:          s.Name,
+			Tags:          s.Tags,
+			DurationMs:    s.DurationMs,
+			StartupMs:     s.StartupMs,
+			StartRetries:  s.StartRetries,
+			Limits:        s.Limits,
+			Container:     s.Container,
+			ResponseBytes: s.ResponseBytes,
+			Error:         s.Error,
+			Stats:         s.Stats,
+			Mixed:         s.Mixed,
+			Sequences:     s.Sequences,
+			Resources:     s.Resources,
+			DbResources:   s.DbResources,
+
+			DurationPerEndpoint: s.DurationPerEndpoint,
 		})
 	}
 
@@ -201,7 +387,10 @@ func (w *Writer) ExportMetaResults() (*MetaResults, []ServerSummary, string, err
 		SuccessfulServers: successCount,
 		FailedServers:     failCount,
 		TotalDurationMs:   time.Since(w.startTime).Milliseconds(),
+		Aborted:           w.aborted,
+		Partial:           partial,
 	}
+	summary.addTotals(servers)
 
 	metaResults := &MetaResults{
 		Meta:    meta,
@@ -209,19 +398,65 @@ func (w *Writer) ExportMetaResults() (*MetaResults, []ServerSummary, string, err
 		Servers: serverSummaries,
 	}
 
-	data, err := json.Marshal(metaResults, jsontext.WithIndent("  "), durationOpts)
+	data, err := w.marshal(metaResults)
 	if err != nil {
 		return nil, nil, "", fmt.Errorf("failed to marshal meta results: %w", err)
 	}
 
+	// Write and rename, so a crash mid-write keeps the previous checkpoint.
 	path := filepath.Join(w.resultsDir, "results.json")
-	if err = os.WriteFile(path, data, 0o600); err != nil {
-		return nil, nil, "", fmt.Errorf("failed to write meta results: %w", err)
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return nil, nil, "", writeFailed("meta results", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return nil, nil, "", writeFailed("meta results", err)
 	}
 
 	return metaResults, servers, path, nil
 }
 
+// SetCompactJSON switches the exported result files between two-space
+// indentation (the default, for reading) and compact output (--compact-json),
+// which matters once raw latencies or histograms make them large.
+func (w *Writer) SetCompactJSON(compact bool) {
+	w.compact = compact
+}
+
+// SetRunId records the run's id in the result metadata, so a results.json
+// can be joined with the metrics DB rows of the same run.
+func (w *Writer) SetRunId(runId string) {
+	w.runId = runId
+}
+
+func (w *Writer) marshal(v any) ([]byte, error) {
+	if w.compact {
+		return json.Marshal(v, durationOpts)
+	}
+	return json.Marshal(v, jsontext.WithIndent("  "), durationOpts)
+}
+
+// SetAborted records that the run stopped after server fell below the
+// abort_below_success_rate threshold; ExportMetaResults carries it into the summary.
+func (w *Writer) SetAborted(server string, successRate, threshold float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.aborted = &AbortSummary{Server: server, SuccessRate: successRate, Threshold: threshold}
+}
+
+// SuccessRate is the aggregate success rate across every endpoint. A server
+// that failed outright (or produced no results) counts as 0.
+func (r *ServerResult) SuccessRate() float64 {
+	if r.Error != "" {
+		return 0
+	}
+	stats := aggregateStats(r.Results)
+	if stats == nil {
+		return 0
+	}
+	return stats.SuccessRate
+}
+
 func (r *ServerResult) Complete(results []client.EndpointResult) {
 	r.EndTime = time.Now()
 	r.Duration = r.EndTime.Sub(r.StartTime)
@@ -236,16 +471,52 @@ func (r *ServerResult) SetError(err error) {
 
 func (w *Writer) meta() ResultMeta {
 	return ResultMeta{
+		RunId:     w.runId,
 		Timestamp: w.startTime,
+		Host:      w.host,
+		GitSha:    w.gitSha,
 		Config: ResultConfig{
 			BaseUrl:             w.config.BaseUrl,
 			Concurrency:         w.config.Concurrency,
-			DurationPerEndpoint: w.config.DurationPerEndpoint.String(),
+			ConcurrencyPerCPU:   perCPUString(w.config.ConcurrencyRaw),
+			MaxTotalConns:       w.config.MaxTotalConns,
+			Connections:         w.config.Connections(),
+			DurationPerEndpoint: durationPerEndpoint(w.config),
+			TotalDuration:       optionalDuration(w.config.TotalDuration),
+			RequestsPerEndpoint: w.config.RequestsPerEndpoint,
 			RequestTimeout:      w.config.RequestTimeout.String(),
+			ConnectTimeout:      optionalDuration(w.config.ConnectTimeout),
+			WarmupDuration:      optionalDuration(w.config.WarmupDuration),
+			WarmupUntilStable:   w.config.WarmupUntilStable != nil,
+			UserAgent:           w.config.UserAgent,
+			DefaultAccept:       w.config.DefaultAccept,
 		},
 	}
 }
 
+func perCPUString(c config.ConcurrencySpec) string {
+	if c.PerCPU == 0 {
+		return ""
+	}
+	return c.String()
+}
+
+// durationPerEndpoint is the config's duration_per_endpoint, or "per server"
+// when total_duration gave servers different shares (see ServerSummary).
+func durationPerEndpoint(b *config.BenchmarkConfig) string {
+	if b.TotalDuration > 0 && b.DurationPerEndpoint == 0 {
+		return "per server"
+	}
+	return b.DurationPerEndpoint.String()
+}
+
+func optionalDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
 func readServerSummaries(dir string) (servers []ServerSummary, successCount, failCount int, err error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -281,35 +552,162 @@ func readServerSummaries(dir string) (servers []ServerSummary, successCount, fai
 	return servers, successCount, failCount, nil
 }
 
+// addTotals sums request counts and response bytes across the servers that
+// ran, matching the rankings' request total. The data rate divides by the run
+// time of only the servers that returned bytes, so a failed server adds
+// neither volume nor time.
+func (b *BenchmarkSummary) addTotals(servers []ServerSummary) {
+	var transferMs int64
+	for i := range servers {
+		s := &servers[i]
+		if s.Error != "" {
+			continue
+		}
+		if s.Stats != nil {
+			b.TotalRequests += s.Stats.TotalCount
+		}
+		if s.ResponseBytes > 0 {
+			b.ResponseBytes += s.ResponseBytes
+			transferMs += s.DurationMs
+		}
+	}
+	if transferMs > 0 {
+		b.BytesPerSec = float64(b.ResponseBytes) / (float64(transferMs) / 1000)
+	}
+}
+
 func serverSummaryFromResult(result *ServerResult) ServerSummary {
 	results := make([]EndpointSummary, 0, len(result.Results))
+	var byDatabase map[string][]EndpointSummary
+	var bytes int64
 	for i := range result.Results {
-		ep := &result.Results[i]
-		results = append(results, EndpointSummary{
-			Name:          ep.Name,
-			Path:          ep.Path,
-			Method:        ep.Method,
-			Database:      ep.Database,
-			SequenceId:    ep.SequenceId,
-			Error:         ep.Error,
-			Stats:         statsFromClient(ep.Stats),
-			Open:          openFromClient(ep.Open),
-			FailureCount:  ep.FailureCount,
-			CanceledCount: ep.CanceledCount,
-			LastError:     ep.LastError,
-		})
+		ep := endpointSummaryFromResult(&result.Results[i])
+		results = append(results, ep)
+		bytes += result.Results[i].ResponseBytes
+		// The flat list stays for existing consumers; the buckets spare them
+		// from parsing "[db]" out of names to pivot by database.
+		if ep.Database != "" {
+			if byDatabase == nil {
+				byDatabase = make(map[string][]EndpointSummary)
+			}
+			byDatabase[ep.Database] = append(byDatabase[ep.Database], ep)
+		}
 	}
 
 	return ServerSummary{
-		Name:        result.Name,
-		DurationMs:  result.Duration.Milliseconds(),
-		Error:       result.Error,
-		Stats:       aggregateStats(result.Results),
-		Results:     results,
-		Sequences:   result.Sequences,
-		Resources:   result.Resources,
-		DbResources: result.DbResources,
+		Name:          result.Name,
+		Tags:          result.Tags,
+		DurationMs:    result.Duration.Milliseconds(),
+		StartupMs:     result.Startup.Milliseconds(),
+		StartRetries:  result.Retries,
+		Limits:        result.Limits,
+		Container:     result.Container,
+		ResponseBytes: bytes,
+		Error:         result.Error,
+		Stats:         aggregateStats(result.Results),
+		Mixed:         statsFromClient(result.Mixed),
+		Results:       results,
+		ByDatabase:    byDatabase,
+		Sequences:     result.Sequences,
+		Resources:     result.Resources,
+		DbResources:   result.DbResources,
+
+		DurationPerEndpoint: optionalDuration(result.UnitDuration),
+	}
+}
+
+func endpointSummaryFromResult(ep *client.EndpointResult) EndpointSummary {
+	return EndpointSummary{
+		Name:          ep.Name,
+		Path:          ep.Path,
+		Method:        ep.Method,
+		Database:      ep.Database,
+		SequenceId:    ep.SequenceId,
+		Tags:          ep.Tags,
+		Warmup:        warmupFromClient(ep.Warmup),
+		Error:         ep.Error,
+		Stats:         statsFromClient(ep.Stats),
+		Open:          openFromClient(ep.Open),
+		FailureCount:  ep.FailureCount,
+		CanceledCount: ep.CanceledCount,
+		LastError:     ep.LastError,
+		StatusCounts:  ep.StatusCounts,
+		DurationMs:    ep.DurationMs,
+		ResponseBytes: ep.ResponseBytes,
+		Phases:        phasesFromClient(ep.Phases),
+		BestWindow:    windowFromClient(ep.BestWindow),
+		SSE:           sseFromClient(ep.SSE),
+		Expected:      expectedFromClient(ep.Expected),
+		FailedFast:    ep.FailedFast,
+
+		AnomalousCount: ep.AnomalousCount,
+		CachedCount:    ep.CachedCount,
+		WarmupCount:    ep.WarmupCount,
+	}
+}
+
+func expectedFromClient(e *client.Expected) *ExpectedSummary {
+	if e == nil {
+		return nil
 	}
+	return &ExpectedSummary{AvgNs: int64(e.Avg), P99Ns: int64(e.P99)}
+}
+
+func sseFromClient(s *client.SSEStats) *SSESummary {
+	if s == nil {
+		return nil
+	}
+	return &SSESummary{
+		Streams:    s.Streams,
+		Events:     s.Events,
+		FirstEvent: phaseFromClient(s.FirstEvent),
+		InterEvent: phaseFromClient(s.InterEvent),
+	}
+}
+
+func windowFromClient(w *client.WindowStats) *WindowSummary {
+	if w == nil {
+		return nil
+	}
+	return &WindowSummary{
+		StartMs: w.Start.Milliseconds(),
+		Count:   w.Count,
+		P50Ns:   int64(w.P50),
+		P95Ns:   int64(w.P95),
+		P99Ns:   int64(w.P99),
+	}
+}
+
+func phasesFromClient(p *client.PhaseStats) *PhasesSummary {
+	if p == nil {
+		return nil
+	}
+	return &PhasesSummary{
+		DNS:     phaseFromClient(p.DNS),
+		Connect: phaseFromClient(p.Connect),
+		TLS:     phaseFromClient(p.TLS),
+		TTFB:    phaseFromClient(p.TTFB),
+	}
+}
+
+func phaseFromClient(t *client.PhaseTiming) *PhaseSummary {
+	if t == nil {
+		return nil
+	}
+	return &PhaseSummary{
+		Count:  t.Count,
+		AvgNs:  int64(t.Avg),
+		P50Ns:  int64(t.P50),
+		P99Ns:  int64(t.P99),
+		HighNs: int64(t.High),
+	}
+}
+
+func warmupFromClient(w *client.WarmupResult) *WarmupSummary {
+	if w == nil {
+		return nil
+	}
+	return &WarmupSummary{DurationMs: w.Duration.Milliseconds(), Windows: w.Windows, Stable: w.Stable}
 }
 
 func aggregateStats(results []client.EndpointResult) *StatsSummary {
@@ -324,6 +722,7 @@ func aggregateStats(results []client.EndpointResult) *StatsSummary {
 		totalSuccesses int
 		totalRequests  int
 		resultCount    int
+		sumSquares     float64 // Σ n·(σ² + μ²) in ns², for the pooled stddev
 	)
 
 	for i := range results {
@@ -333,6 +732,8 @@ func aggregateStats(results []client.EndpointResult) *StatsSummary {
 		}
 		resultCount++
 		totalLatency += time.Duration(ep.Stats.Count) * ep.Stats.Avg
+		mean, sd := float64(ep.Stats.Avg), float64(ep.Stats.StdDev)
+		sumSquares += float64(ep.Stats.Count) * (sd*sd + mean*mean)
 		if ep.Stats.Low > 0 && ep.Stats.Low < minLatency {
 			minLatency = ep.Stats.Low
 		}
@@ -348,8 +749,10 @@ func aggregateStats(results []client.EndpointResult) *StatsSummary {
 	}
 
 	var avg time.Duration
+	var stdDev float64
 	if totalSuccesses > 0 {
 		avg = totalLatency / time.Duration(totalSuccesses)
+		stdDev = math.Sqrt(max(sumSquares/float64(totalSuccesses)-float64(avg)*float64(avg), 0))
 	}
 	if minLatency == time.Hour {
 		minLatency = 0
@@ -373,6 +776,7 @@ func aggregateStats(results []client.EndpointResult) *StatsSummary {
 		AvgNs:       avg.Nanoseconds(),
 		MinNs:       minLatency.Nanoseconds(),
 		MaxNs:       maxLatency.Nanoseconds(),
+		StdDevNs:    int64(stdDev),
 		SuccessRate: successRate,
 	}
 }
@@ -393,7 +797,15 @@ func statsFromClient(stats *client.Stats) *StatsSummary {
 		P999Ns:      stats.P999.Nanoseconds(),
 		MinNs:       stats.Low.Nanoseconds(),
 		MaxNs:       stats.High.Nanoseconds(),
+		StdDevNs:    stats.StdDev.Nanoseconds(),
 		SuccessRate: stats.SuccessRate,
+		Sampled:     stats.Sampled,
+		Estimator:   stats.Estimator,
+		NewConns:    stats.NewConns,
+		ReusedConns: stats.ReusedConns,
+		TailRatio:   stats.TailRatio,
+
+		QueueDelayP99Ns: stats.QueueDelayP99.Nanoseconds(),
 	}
 }
 
@@ -3,7 +3,9 @@ package summary
 import (
 	"encoding/json/v2"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -116,3 +118,184 @@ func TestExportServerResultMarshalsDurations(t *testing.T) {
 		t.Errorf("round-trip lost nested open response stats: %+v", ep.Open.Response)
 	}
 }
+
+func TestCompactJSONRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w := NewWriter(&config.BenchmarkConfig{}, dir)
+	w.SetCompactJSON(true)
+	result := &ServerResult{
+		Name: "compact",
+		Results: []client.EndpointResult{{
+			Name: "root", Path: "/", Method: "GET",
+			Stats: &client.Stats{Count: 4, TotalCount: 5, Avg: 3 * time.Millisecond, P99: 7 * time.Millisecond, SuccessRate: 0.8},
+		}},
+		Sequences: []client.SequenceStats{{SequenceId: "crud", TotalRuns: 2, AvgDuration: 11 * time.Millisecond, StepCount: 1}},
+	}
+
+	path, err := w.ExportServerResult(result)
+	if err != nil {
+		t.Fatalf("ExportServerResult: %v", err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // test temp dir
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "\n") {
+		t.Errorf("compact output spans lines:\n%s", data)
+	}
+
+	servers, ok, failed, err := readServerSummaries(dir)
+	if err != nil {
+		t.Fatalf("readServerSummaries: %v", err)
+	}
+	if len(servers) != 1 || ok != 1 || failed != 0 {
+		t.Fatalf("got %d servers (%d ok, %d failed), want 1 ok", len(servers), ok, failed)
+	}
+	got := servers[0]
+	if got.Name != "compact" || len(got.Results) != 1 || got.Results[0].Stats.P99Ns != int64(7*time.Millisecond) {
+		t.Errorf("round trip: got %+v", got)
+	}
+	if len(got.Sequences) != 1 || got.Sequences[0].AvgDuration != 11*time.Millisecond {
+		t.Errorf("sequence durations: got %+v, want avg 11ms", got.Sequences)
+	}
+}
+
+func TestServerResultSuccessRate(t *testing.T) {
+	t.Parallel()
+
+	result := &ServerResult{Results: []client.EndpointResult{
+		{Stats: &client.Stats{Count: 90}, FailureCount: 10},
+		{Stats: &client.Stats{Count: 100}},
+	}}
+	if got := result.SuccessRate(); got != 0.95 {
+		t.Errorf("success rate: got %v, want 0.95", got)
+	}
+
+	failed := &ServerResult{Error: "failed to start container"}
+	if got := failed.SuccessRate(); got != 0 {
+		t.Errorf("failed server success rate: got %v, want 0", got)
+	}
+}
+
+func TestBenchmarkSummaryTotals(t *testing.T) {
+	t.Parallel()
+
+	servers := []ServerSummary{
+		serverSummaryFromResult(&ServerResult{
+			Duration: 2 * time.Second,
+			Results: []client.EndpointResult{
+				{Stats: &client.Stats{Count: 100, TotalCount: 100}, ResponseBytes: 3000},
+				{Stats: &client.Stats{Count: 50, TotalCount: 50}, ResponseBytes: 1000},
+			},
+		}),
+		// Failed to start: its time must not dilute the rate.
+		serverSummaryFromResult(&ServerResult{Duration: 30 * time.Second, Error: "failed to start container"}),
+	}
+
+	var summary BenchmarkSummary
+	summary.addTotals(servers)
+	if summary.TotalRequests != 150 || summary.ResponseBytes != 4000 {
+		t.Errorf("totals: got %d reqs / %d bytes, want 150 / 4000", summary.TotalRequests, summary.ResponseBytes)
+	}
+	if summary.BytesPerSec != 2000 {
+		t.Errorf("rate: got %v B/s, want 2000 (errored server excluded)", summary.BytesPerSec)
+	}
+	if got, want := formatTotals(summary.TotalRequests, &summary), "150 reqs, 4KB transferred, 2KB/s"; got != want {
+		t.Errorf("footer: got %q, want %q", got, want)
+	}
+}
+
+func TestServerSummaryByDatabase(t *testing.T) {
+	t.Parallel()
+
+	summary := serverSummaryFromResult(&ServerResult{Results: []client.EndpointResult{
+		{Name: "health", Path: "/health", Stats: &client.Stats{Count: 10}},
+		{Name: "create", Path: "/db/postgres/users", Database: "postgres", SequenceId: "crud"},
+		{Name: "create", Path: "/db/mongodb/users", Database: "mongodb", SequenceId: "crud"},
+		{Name: "read", Path: "/db/postgres/users/1", Database: "postgres", SequenceId: "crud"},
+	}})
+
+	if len(summary.Results) != 4 {
+		t.Errorf("flat results: got %d, want all 4 kept", len(summary.Results))
+	}
+	if len(summary.ByDatabase) != 2 {
+		t.Fatalf("buckets: got %v, want postgres and mongodb only", summary.ByDatabase)
+	}
+	postgres := summary.ByDatabase["postgres"]
+	if len(postgres) != 2 || postgres[0].Path != "/db/postgres/users" || postgres[1].Path != "/db/postgres/users/1" {
+		t.Errorf("postgres bucket: got %+v, want create then read", postgres)
+	}
+	if mongo := summary.ByDatabase["mongodb"]; len(mongo) != 1 || mongo[0].Database != "mongodb" {
+		t.Errorf("mongodb bucket: got %+v, want its one create", mongo)
+	}
+
+	if none := serverSummaryFromResult(&ServerResult{Results: []client.EndpointResult{{Name: "health"}}}); none.ByDatabase != nil {
+		t.Errorf("no database results: got %v, want by_database omitted", none.ByDatabase)
+	}
+}
+
+// Not parallel: sets BENCH_GIT_SHA.
+func TestResultMetaDescribesRun(t *testing.T) {
+	t.Setenv(gitShaEnv, "0123abc")
+
+	w := NewWriter(&config.BenchmarkConfig{}, t.TempDir())
+	w.SetRunId("20260101-120000-a1b2c3")
+	meta := w.meta()
+	if meta.RunId != "20260101-120000-a1b2c3" || meta.GitSha != "0123abc" {
+		t.Errorf("run id / git sha: got %q / %q, want the set id and BENCH_GIT_SHA", meta.RunId, meta.GitSha)
+	}
+	if meta.Host == nil || meta.Host.OS == "" || meta.Host.CPUs < 1 {
+		t.Errorf("host: got %+v, want os and cpu count", meta.Host)
+	}
+}
+
+func TestCheckpointWritesPartialMeta(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w := NewWriter(&config.BenchmarkConfig{}, dir)
+	readMeta := func() MetaResults {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "results.json")) //nolint:gosec // test temp dir
+		if err != nil {
+			t.Fatalf("read results.json: %v", err)
+		}
+		var meta MetaResults
+		if err := json.Unmarshal(data, &meta, durationOpts); err != nil {
+			t.Fatalf("parse results.json: %v", err)
+		}
+		return meta
+	}
+
+	if _, err := w.ExportServerResult(&ServerResult{Name: "first"}); err != nil {
+		t.Fatalf("ExportServerResult: %v", err)
+	}
+	// Checkpoints from several goroutines serialize on the writer.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if err := w.Checkpoint(); err != nil {
+				t.Errorf("Checkpoint: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if meta := readMeta(); !meta.Summary.Partial || len(meta.Servers) != 1 {
+		t.Errorf("checkpoint: got partial=%v with %d servers, want partial with 1", meta.Summary.Partial, len(meta.Servers))
+	}
+	if done, err := w.CompletedServers(); err != nil || len(done) != 1 || done[0].Name != "first" {
+		t.Errorf("completed servers: got %v, %v, want just first", done, err)
+	}
+
+	if _, err := w.ExportServerResult(&ServerResult{Name: "second"}); err != nil {
+		t.Fatalf("ExportServerResult: %v", err)
+	}
+	if _, _, _, err := w.ExportMetaResults(); err != nil {
+		t.Fatalf("ExportMetaResults: %v", err)
+	}
+	if meta := readMeta(); meta.Summary.Partial || len(meta.Servers) != 2 {
+		t.Errorf("final: got partial=%v with %d servers, want complete with 2", meta.Summary.Partial, len(meta.Servers))
+	}
+}
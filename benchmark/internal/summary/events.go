@@ -0,0 +1,67 @@
+package summary
+
+import (
+	"time"
+
+	"benchmark-client/internal/cli"
+	"benchmark-client/internal/client"
+)
+
+// The --ndjson events reuse the exported result structs, so a wrapper sees
+// the same shapes as the results directory. Each is a no-op without --ndjson.
+
+type serverEvent struct {
+	Server string `json:"server"`
+}
+
+type endpointEvent struct {
+	Server   string          `json:"server"`
+	Endpoint EndpointSummary `json:"endpoint"`
+}
+
+type runEvent struct {
+	Meta    ResultMeta       `json:"meta"`
+	Summary BenchmarkSummary `json:"summary"`
+}
+
+func EmitServerStarted(server string) {
+	cli.Event("server_started", serverEvent{Server: server})
+}
+
+func EmitEndpointCompleted(server string, ep *client.EndpointResult) {
+	if !cli.NDJSON() {
+		return
+	}
+	cli.Event("endpoint_completed", endpointEvent{Server: server, Endpoint: endpointSummaryFromResult(ep)}, durationOpts)
+}
+
+func EmitServerCompleted(result *ServerResult) {
+	if !cli.NDJSON() {
+		return
+	}
+	cli.Event("server_completed", serverSummaryFromResult(result), durationOpts)
+}
+
+// EmitRunCompleted carries the run metadata and totals; per-server detail
+// already went out in server_completed.
+func EmitRunCompleted(meta *MetaResults) {
+	cli.Event("run_completed", runEvent{Meta: meta.Meta, Summary: meta.Summary}, durationOpts)
+}
+
+// EmitTargetRunCompleted is run_completed for --target, which writes no
+// results.json: the totals cover the single target server.
+func (w *Writer) EmitTargetRunCompleted(result *ServerResult) {
+	if !cli.NDJSON() {
+		return
+	}
+	summary := BenchmarkSummary{
+		TotalServers:    1,
+		TotalDurationMs: time.Since(w.startTime).Milliseconds(),
+	}
+	if result.Error != "" {
+		summary.FailedServers = 1
+	} else {
+		summary.SuccessfulServers = 1
+	}
+	cli.Event("run_completed", runEvent{Meta: w.meta(), Summary: summary}, durationOpts)
+}
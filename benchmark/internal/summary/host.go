@@ -0,0 +1,76 @@
+package summary
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HostInfo describes the machine that ran the client, so stored runs can be
+// compared knowing where they came from. Every field is best-effort: one that
+// can't be read is left empty.
+type HostInfo struct {
+	Hostname      string `json:"hostname,omitempty"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	CPUs          int    `json:"cpus"`
+	TotalMemBytes int64  `json:"total_mem_bytes,omitempty"` // Linux only (/proc/meminfo)
+}
+
+const gitShaEnv = "BENCH_GIT_SHA"
+
+const gitTimeout = 2 * time.Second
+
+func collectHostInfo() *HostInfo {
+	hostname, _ := os.Hostname()
+	return &HostInfo{
+		Hostname:      hostname,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		CPUs:          runtime.NumCPU(),
+		TotalMemBytes: totalMemBytes(),
+	}
+}
+
+// totalMemBytes reads MemTotal from /proc/meminfo; 0 where it doesn't exist.
+func totalMemBytes() int64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		rest, ok := strings.CutPrefix(scanner.Text(), "MemTotal:")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(rest), " kB"), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
+
+// gitSha is BENCH_GIT_SHA when set (CI, or a tree without .git), otherwise
+// the checkout's HEAD; empty when neither is available.
+func gitSha() string {
+	if sha := strings.TrimSpace(os.Getenv(gitShaEnv)); sha != "" {
+		return sha
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
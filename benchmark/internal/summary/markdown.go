@@ -0,0 +1,213 @@
+package summary
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"benchmark-client/internal/cli"
+)
+
+const (
+	markdownPass = "✅"
+	markdownFail = "❌"
+)
+
+// ExportMarkdown writes the final summary as GitHub-flavored Markdown for
+// pasting into pull requests: the server rankings, a per-endpoint avg-latency
+// comparison across servers, and the resource samples in a collapsible block.
+// Numbers come from the same rankServers/cli formatters as PrintFinalSummary;
+// unlike the fixed-width terminal tables, paths are never truncated.
+func ExportMarkdown(meta *MetaResults, servers []ServerSummary, path string) error {
+	var b strings.Builder
+	writeMarkdownSummary(&b, meta, servers)
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create markdown dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write markdown summary: %w", err)
+	}
+	return nil
+}
+
+func writeMarkdownSummary(b *strings.Builder, meta *MetaResults, servers []ServerSummary) {
+	ranked, totalReqs := rankServers(servers)
+	duration := time.Duration(meta.Summary.TotalDurationMs) * time.Millisecond
+
+	b.WriteString("## Benchmark Summary\n\n")
+	fmt.Fprintf(b, "Base: `%s` · Concurrency: %d · %s · Timeout: %s\n\n",
+		meta.Meta.Config.BaseUrl,
+		meta.Meta.Config.Concurrency,
+		meta.Meta.Config.endpointBudget(),
+		meta.Meta.Config.RequestTimeout)
+
+	status := fmt.Sprintf("%s %d passed", markdownPass, meta.Summary.SuccessfulServers)
+	if meta.Summary.FailedServers > 0 {
+		status += fmt.Sprintf(" · %s %d failed", markdownFail, meta.Summary.FailedServers)
+	}
+	fmt.Fprintf(b, "%d servers · %s · %s · Total: %s reqs\n\n",
+		meta.Summary.TotalServers, cli.FormatDuration(duration), status, cli.FormatReqs(totalReqs))
+	if abort := meta.Summary.Aborted; abort != nil {
+		fmt.Fprintf(b, "%s **Run aborted after `%s`:** success rate %s below abort_below_success_rate %s\n\n",
+			markdownFail, abort.Server, cli.FormatRate(abort.SuccessRate), cli.FormatRate(abort.Threshold))
+	}
+
+	if len(ranked) == 0 {
+		b.WriteString("No benchmarks to display.\n")
+		return
+	}
+
+	b.WriteString("### Server Rankings (by avg latency, all requests)\n\n")
+	writeMarkdownRow(b, "#", "Server", "Avg", "Min", "Max", "Tail", "Mem", "CPU", "Reqs", "Rate", "Status")
+	writeMarkdownRow(b, "--:", ":--", "--:", "--:", "--:", "--:", "--:", "--:", "--:", "--:", ":-:")
+	for i, s := range ranked {
+		rank := strconv.Itoa(i + 1)
+		if s.failed {
+			writeMarkdownRow(b, rank, s.name, "-", "-", "-", "-", "-", "-", "-", "-", markdownFail+" FAIL")
+			continue
+		}
+		memStr, cpuStr := "-", "-"
+		if s.hasMem {
+			memStr = cli.FormatMemory(s.mem)
+			cpuStr = fmt.Sprintf("%.0f%%", s.cpu)
+		}
+		writeMarkdownRow(b, rank, s.name,
+			cli.FormatLatency(s.avg), cli.FormatLatency(s.min), cli.FormatLatency(s.max), formatTailRatio(s.tailRatio),
+			memStr, cpuStr, cli.FormatReqs(s.totalReqs), cli.FormatRate(s.successRate),
+			markdownStatusLabel(s.successRate >= 1.0))
+	}
+	b.WriteString("\n")
+
+	writeMarkdownEndpoints(b, ranked, servers)
+	writeMarkdownResources(b, ranked, servers)
+}
+
+// writeMarkdownEndpoints emits one row per endpoint (first-seen order) and one
+// avg-latency column per successful server, in ranking order.
+func writeMarkdownEndpoints(b *strings.Builder, ranked []rankedServer, servers []ServerSummary) {
+	byName := make(map[string]*ServerSummary, len(servers))
+	for i := range servers {
+		byName[servers[i].Name] = &servers[i]
+	}
+
+	var columns []string
+	var rows []string
+	cells := make(map[string]map[string]string)
+	for _, rs := range ranked {
+		s := byName[rs.name]
+		if rs.failed || s == nil {
+			continue
+		}
+		columns = append(columns, rs.name)
+		for i := range s.Results {
+			ep := &s.Results[i]
+			key := fmt.Sprintf("`%s %s`", ep.Method, ep.Path)
+			if cells[key] == nil {
+				cells[key] = make(map[string]string)
+				rows = append(rows, key)
+			}
+			cells[key][rs.name] = markdownEndpointCell(ep)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	b.WriteString("### Endpoints (avg latency)\n\n")
+	writeMarkdownRow(b, slices.Concat([]string{"Endpoint"}, columns)...)
+	align := []string{":--"}
+	for range columns {
+		align = append(align, "--:")
+	}
+	writeMarkdownRow(b, align...)
+	for _, key := range rows {
+		row := []string{key}
+		for _, name := range columns {
+			cell, ok := cells[key][name]
+			if !ok {
+				cell = "-"
+			}
+			row = append(row, cell)
+		}
+		writeMarkdownRow(b, row...)
+	}
+	b.WriteString("\n")
+}
+
+func markdownEndpointCell(ep *EndpointSummary) string {
+	if ep.Error != "" || ep.Stats == nil {
+		return markdownFail
+	}
+	return cli.FormatLatency(ep.Stats.AvgNs) + " " + markdownStatus(ep.Stats.SuccessRate >= 1.0)
+}
+
+func writeMarkdownResources(b *strings.Builder, ranked []rankedServer, servers []ServerSummary) {
+	byName := make(map[string]*ServerSummary, len(servers))
+	for i := range servers {
+		byName[servers[i].Name] = &servers[i]
+	}
+
+	var rows [][]string
+	for _, rs := range ranked {
+		s := byName[rs.name]
+		if s == nil || s.Resources == nil || s.Resources.Samples == 0 {
+			continue
+		}
+		r := s.Resources
+		measuredMem, measuredCpu := "-", "-"
+		if r.Measured != nil {
+			measuredMem = cli.FormatMemory(r.Measured.Memory.AvgBytes)
+			measuredCpu = fmt.Sprintf("%.0f%%", r.Measured.Cpu.AvgPercent)
+		}
+		rows = append(rows, []string{
+			s.Name,
+			measuredMem, measuredCpu,
+			cli.FormatMemory(r.Memory.AvgBytes), cli.FormatMemory(r.Memory.MaxBytes),
+			fmt.Sprintf("%.0f%%", r.Cpu.AvgPercent), fmt.Sprintf("%.0f%%", r.Cpu.MaxPercent),
+			strconv.Itoa(r.Samples),
+			strings.Join(r.Warnings, "; "),
+		})
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	b.WriteString("<details>\n<summary>Resources</summary>\n\n")
+	writeMarkdownRow(b, "Server", "Mem (measured)", "CPU (measured)", "Mem avg", "Mem max", "CPU avg", "CPU max", "Samples", "Warnings")
+	writeMarkdownRow(b, ":--", "--:", "--:", "--:", "--:", "--:", "--:", "--:", ":--")
+	for _, row := range rows {
+		writeMarkdownRow(b, row...)
+	}
+	b.WriteString("\n</details>\n")
+}
+
+func markdownStatus(ok bool) string {
+	if ok {
+		return markdownPass
+	}
+	return markdownFail
+}
+
+func markdownStatusLabel(ok bool) string {
+	if ok {
+		return markdownPass + " OK"
+	}
+	return markdownFail + " FAIL"
+}
+
+func writeMarkdownRow(b *strings.Builder, cells ...string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		b.WriteString(" ")
+		b.WriteString(strings.ReplaceAll(cell, "|", `\|`))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,82 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/container"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	t.Parallel()
+
+	longPath := "/db/postgres/users/with/a/path/far/longer/than/the/terminal/column"
+	meta := &MetaResults{
+		Meta: ResultMeta{Timestamp: time.Now(), Config: ResultConfig{
+			BaseUrl: "http://localhost:8080", Concurrency: 50, DurationPerEndpoint: "10s", RequestTimeout: "10s",
+		}},
+		Summary: BenchmarkSummary{
+			TotalServers: 3, SuccessfulServers: 2, FailedServers: 1, TotalDurationMs: 60000,
+			Aborted: &AbortSummary{Server: "broken", SuccessRate: 0, Threshold: 0.9},
+		},
+	}
+	servers := []ServerSummary{
+		{
+			Name:  "slow",
+			Stats: &StatsSummary{TotalCount: 100, AvgNs: int64(2 * time.Millisecond), SuccessRate: 0.5},
+			Results: []EndpointSummary{
+				{Method: "GET", Path: longPath, Stats: &StatsSummary{AvgNs: int64(2 * time.Millisecond), SuccessRate: 0.5}},
+			},
+		},
+		{
+			Name:  "fast",
+			Stats: &StatsSummary{TotalCount: 100, AvgNs: int64(time.Millisecond), SuccessRate: 1},
+			Results: []EndpointSummary{
+				{Method: "GET", Path: longPath, Stats: &StatsSummary{AvgNs: int64(time.Millisecond), SuccessRate: 1}},
+				{Method: "GET", Path: "/a|b", Stats: &StatsSummary{AvgNs: int64(time.Millisecond), SuccessRate: 1}},
+			},
+			Resources: &container.ResourceStats{
+				Samples: 5,
+				Memory:  container.MemoryStats{AvgBytes: 10 << 20, MaxBytes: 12 << 20},
+				Cpu:     container.CpuStats{AvgPercent: 40, MaxPercent: 80},
+			},
+		},
+		{Name: "broken", Error: "failed to start container"},
+	}
+
+	path := filepath.Join(t.TempDir(), "out", "summary.md")
+	if err := ExportMarkdown(meta, servers, path); err != nil {
+		t.Fatalf("ExportMarkdown: %v", err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // test temp file
+	if err != nil {
+		t.Fatalf("read markdown: %v", err)
+	}
+	md := string(data)
+
+	for _, want := range []string{
+		"| 1 | fast |",
+		"| 2 | slow |",
+		"| 3 | broken |",
+		"✅ OK",
+		"❌ FAIL",
+		"`GET " + longPath + "`", // never truncated
+		"| Endpoint | fast | slow |",
+		`/a\|b`, // pipes escaped
+		"<details>\n<summary>Resources</summary>",
+		"</details>",
+		"**Run aborted after `broken`:** success rate 0.00% below abort_below_success_rate 90.0%",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	for _, unwanted := range []string{"✓", "✗", "..."} {
+		if strings.Contains(md, unwanted) {
+			t.Errorf("markdown contains terminal-only %q:\n%s", unwanted, md)
+		}
+	}
+}
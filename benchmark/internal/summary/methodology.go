@@ -0,0 +1,100 @@
+package summary
+
+import (
+	"fmt"
+	"time"
+
+	"benchmark-client/internal/cli"
+)
+
+// methodologyRow documents how one endpoint's numbers were produced, for the
+// --show-methodology footnotes.
+type methodologyRow struct {
+	server   string
+	endpoint string
+	requests int    // measured requests, failures included
+	samples  int    // latencies the percentiles were computed from
+	method   string // "exact", "reservoir" or "t-digest"
+	warmup   string
+	measured time.Duration
+}
+
+// methodologyRows lists every endpoint that produced stats, in result order.
+// Failed servers and endpoints without stats have no numbers to explain.
+func methodologyRows(config *ResultConfig, servers []ServerSummary) []methodologyRow {
+	var rows []methodologyRow
+	for i := range servers {
+		s := &servers[i]
+		if s.Error != "" {
+			continue
+		}
+		for j := range s.Results {
+			ep := &s.Results[j]
+			if ep.Stats == nil {
+				continue
+			}
+			row := methodologyRow{
+				server:   s.Name,
+				endpoint: ep.Method + " " + ep.Path,
+				requests: ep.Stats.TotalCount,
+				samples:  ep.Stats.Count,
+				method:   "exact",
+				warmup:   warmupMethod(config, ep),
+				measured: time.Duration(ep.DurationMs) * time.Millisecond,
+			}
+			switch {
+			case ep.Stats.Estimator != "":
+				row.method = "t-digest"
+			case ep.Stats.Sampled > 0:
+				row.method = "reservoir"
+				row.samples = ep.Stats.Sampled
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// warmupMethod is the warmup an endpoint actually got: adaptive with its
+// outcome, the fixed duration, or none; plus the requests it completed.
+func warmupMethod(config *ResultConfig, ep *EndpointSummary) string {
+	warmup := "none"
+	switch {
+	case ep.Warmup != nil && ep.Warmup.Stable:
+		warmup = "stable after " + cli.FormatDuration(time.Duration(ep.Warmup.DurationMs)*time.Millisecond)
+	case ep.Warmup != nil:
+		warmup = "unstable at " + cli.FormatDuration(time.Duration(ep.Warmup.DurationMs)*time.Millisecond)
+	case config.WarmupDuration != "":
+		warmup = config.WarmupDuration
+	}
+	if ep.WarmupCount != nil {
+		warmup += fmt.Sprintf(" (%d reqs)", ep.WarmupCount.Requests)
+	}
+	return warmup
+}
+
+// printMethodology footnotes the tables above with, per endpoint, what each
+// number rests on, so a published run can be checked for how it was measured.
+func printMethodology(config *ResultConfig, servers []ServerSummary) {
+	rows := methodologyRows(config, servers)
+	if len(rows) == 0 {
+		return
+	}
+	cli.Linef("Methodology")
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	cli.Printf("  %-10s  %-30s  %9s  %9s  %-11s  %-22s  %s\n",
+		"Server", "Endpoint", "Reqs", "Samples", "Percentiles", "Warmup", "Measured")
+	for _, row := range rows {
+		cli.Printf("  %-10s  %-30s  %9s  %9s  %-11s  %-22s  %s\n",
+			cli.Truncate(row.server, 10),
+			cli.Truncate(row.endpoint, 30),
+			cli.FormatReqs(row.requests),
+			cli.FormatReqs(row.samples),
+			row.method,
+			cli.Truncate(row.warmup, 22),
+			cli.FormatDuration(row.measured))
+	}
+	cli.Linef("Samples are the successful latencies percentiles were read from: all of them when exact, the")
+	cli.Linef("max_samples reservoir when sampled, or a streaming t-digest over all of them when estimated.")
+	cli.Blank()
+}
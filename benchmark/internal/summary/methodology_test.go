@@ -0,0 +1,94 @@
+package summary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/client"
+	"benchmark-client/internal/config"
+)
+
+func TestMethodologySampleCounts(t *testing.T) {
+	t.Parallel()
+
+	config := &ResultConfig{WarmupDuration: "5s"}
+	servers := []ServerSummary{
+		{Name: "a", Results: []EndpointSummary{
+			{Method: "GET", Path: "/exact", DurationMs: 10_000, Stats: &StatsSummary{Count: 120, TotalCount: 125},
+				WarmupCount: &client.WarmupCount{Requests: 40}},
+			{Method: "GET", Path: "/reservoir", Stats: &StatsSummary{Count: 5000, TotalCount: 5000, Sampled: 1000}},
+			{Method: "GET", Path: "/digest", Stats: &StatsSummary{Count: 7000, TotalCount: 7010, Estimator: "tdigest"},
+				Warmup: &WarmupSummary{DurationMs: 3000, Stable: true}},
+			{Method: "GET", Path: "/failed", Error: "boom"},
+		}},
+		{Name: "down", Error: "failed to start container"},
+	}
+
+	rows := methodologyRows(config, servers)
+	want := []struct {
+		endpoint          string
+		requests, samples int
+		method, warmup    string
+	}{
+		{"GET /exact", 125, 120, "exact", "5s (40 reqs)"},
+		{"GET /reservoir", 5000, 1000, "reservoir", "5s"},
+		{"GET /digest", 7010, 7000, "t-digest", "stable after 3.00s"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows: got %d, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.endpoint != w.endpoint || r.requests != w.requests || r.samples != w.samples || r.method != w.method || r.warmup != w.warmup {
+			t.Errorf("row %d: got %+v, want %+v", i, r, w)
+		}
+	}
+	if rows[0].measured.Seconds() != 10 {
+		t.Errorf("measured: got %s, want 10s", rows[0].measured)
+	}
+}
+
+func TestMethodologyMixedModeMeasured(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(srv.Close)
+	testcase := func(name string) *config.Testcase {
+		return &config.Testcase{EndpointName: name, Name: name, Path: "/" + name, RequestURI: "/" + name,
+			Method: http.MethodGet, ExpectedStatus: config.ExactStatus(200), Weight: 1}
+	}
+	server := &config.ResolvedServer{
+		Name:                "mixed",
+		RequestTimeout:      2 * time.Second,
+		Concurrency:         2,
+		Load:                config.LoadConfig{Mode: config.LoadModeClosed},
+		DurationPerEndpoint: 100 * time.Millisecond,
+		MaxBodyBytes:        1 << 20,
+		MixedMode:           true,
+		Testcases:           []*config.Testcase{testcase("a"), testcase("b")},
+	}
+	suite := client.NewSuite(context.Background(), server, srv.URL, nil)
+	t.Cleanup(suite.Close)
+	results, err := suite.RunAll()
+	if err != nil {
+		t.Fatalf("RunAll: %v", err)
+	}
+
+	summary := ServerSummary{Name: server.Name}
+	for i := range results {
+		summary.Results = append(summary.Results, endpointSummaryFromResult(&results[i]))
+	}
+	rows := methodologyRows(&ResultConfig{}, []ServerSummary{summary})
+	if len(rows) != 2 {
+		t.Fatalf("rows: got %d, want 2", len(rows))
+	}
+	// Both endpoints share the one 200ms window (100ms per endpoint).
+	for _, r := range rows {
+		if r.measured < 150*time.Millisecond || r.measured > 2*time.Second {
+			t.Errorf("%s measured: got %s, want the shared 200ms window", r.endpoint, r.measured)
+		}
+	}
+}
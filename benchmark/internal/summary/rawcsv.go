@@ -0,0 +1,91 @@
+package summary
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"benchmark-client/internal/client"
+)
+
+// RawLatencyWarnRows is the per-server row count past which the raw CSVs get
+// big enough (hundreds of MB) to be worth a warning.
+const RawLatencyWarnRows = 5_000_000
+
+const rawLatencyHeader = "server_offset_ms,endpoint_offset_ms,latency_ns\n"
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportRawLatencies writes every retained request latency (--raw-latencies)
+// as one CSV per endpoint, <server>__<endpoint>.csv, and one per flow,
+// <server>__flow_<id>[_<database>].csv holding whole-flow durations. It reuses
+// the suite's timed results, so with max_samples set the files hold the
+// reservoir sample. Returns the number of data rows written.
+func ExportRawLatencies(dir, server string, endpoints []client.TimedResult, sequences []client.TimedSequenceResult) (int, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create raw latencies dir: %w", err)
+	}
+
+	var rows int
+	for _, r := range endpoints {
+		n, err := writeRawLatencyFile(dir, server, r.Endpoint, r.Latencies)
+		rows += n
+		if err != nil {
+			return rows, err
+		}
+	}
+	for _, seq := range sequences {
+		name := "flow_" + seq.SequenceId
+		if seq.Database != "" {
+			name += "_" + seq.Database
+		}
+		n, err := writeRawLatencyFile(dir, server, name, seq.Latencies)
+		rows += n
+		if err != nil {
+			return rows, err
+		}
+	}
+	return rows, nil
+}
+
+func writeRawLatencyFile(dir, server, name string, latencies []client.TimedLatency) (int, error) {
+	fileName := rawLatencyFileName(server, name)
+	f, err := os.Create(filepath.Join(dir, fileName)) //nolint:gosec // path built from sanitized config names
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", fileName, err)
+	}
+
+	w := bufio.NewWriter(f)
+	_, _ = w.WriteString(rawLatencyHeader)
+	buf := make([]byte, 0, 64)
+	for _, l := range latencies {
+		buf = strconv.AppendFloat(buf[:0], offsetMs(l.ServerOffset), 'f', 3, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, offsetMs(l.EndpointOffset), 'f', 3, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendInt(buf, l.Duration.Nanoseconds(), 10)
+		buf = append(buf, '\n')
+		_, _ = w.Write(buf)
+	}
+
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return 0, fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	if err = f.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	return len(latencies), nil
+}
+
+func rawLatencyFileName(server, name string) string {
+	return unsafeFileChars.ReplaceAllString(server, "_") + "__" + unsafeFileChars.ReplaceAllString(name, "_") + ".csv"
+}
+
+func offsetMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/client"
+)
+
+func TestExportRawLatencies(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "raw")
+	endpoints := []client.TimedResult{{
+		Endpoint: "get_user",
+		Method:   "GET",
+		Latencies: []client.TimedLatency{
+			{ServerOffset: 1500 * time.Microsecond, EndpointOffset: 500 * time.Microsecond, Duration: 1234},
+			{ServerOffset: 2 * time.Millisecond, EndpointOffset: time.Millisecond, Duration: 5678},
+		},
+	}}
+	sequences := []client.TimedSequenceResult{{
+		SequenceId: "crud",
+		Database:   "postgres",
+		Latencies:  []client.TimedLatency{{ServerOffset: 3 * time.Millisecond, Duration: 9 * time.Millisecond}},
+	}}
+
+	rows, err := ExportRawLatencies(dir, "go/chi", endpoints, sequences)
+	if err != nil {
+		t.Fatalf("ExportRawLatencies: %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("rows: got %d, want 3", rows)
+	}
+
+	want := map[string]string{
+		"go_chi__get_user.csv":           rawLatencyHeader + "1.500,0.500,1234\n2.000,1.000,5678\n",
+		"go_chi__flow_crud_postgres.csv": rawLatencyHeader + "3.000,0.000,9000000\n",
+	}
+	for name, content := range want {
+		data, readErr := os.ReadFile(filepath.Join(dir, name)) //nolint:gosec // test temp file
+		if readErr != nil {
+			t.Errorf("read %s: %v", name, readErr)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s: got %q, want %q", name, data, content)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package summary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// CheckResultsDir creates dir and proves it takes a write, by creating and
+// removing a probe file, so a read-only or full filesystem fails the run
+// before the benchmark rather than at the first export.
+func CheckResultsDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create results dir: %w", err)
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return writeFailed("results dir", err)
+	}
+	_, err = probe.Write([]byte("ok"))
+	if closeErr := probe.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(probe.Name()); err == nil {
+		err = removeErr
+	}
+	if err != nil {
+		return writeFailed("results dir", err)
+	}
+	return nil
+}
+
+// writeFailed wraps a failed results write, naming the usual cause when the
+// filesystem is out of space: the error alone reads like a bug otherwise.
+func writeFailed(what string, err error) error {
+	if errors.Is(err, syscall.ENOSPC) {
+		return fmt.Errorf("failed to write %s: %w (the filesystem is full; free some space and rerun)", what, err)
+	}
+	return fmt.Errorf("failed to write %s: %w", what, err)
+}
@@ -0,0 +1,49 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestCheckResultsDir(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "results", "run")
+	if err := CheckResultsDir(dir); err != nil {
+		t.Fatalf("writable dir: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("probe left behind: %v", entries)
+	}
+
+	readOnly := filepath.Join(root, "read-only")
+	if err := os.Mkdir(readOnly, 0o500); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if probe, err := os.CreateTemp(readOnly, "probe"); err == nil {
+		_ = probe.Close()
+		t.Skip("permissions are not enforced for this user")
+	}
+	if err := CheckResultsDir(readOnly); err == nil {
+		t.Error("read-only dir: got nil error")
+	}
+	if err := CheckResultsDir(filepath.Join(readOnly, "run")); err == nil {
+		t.Error("dir under read-only parent: got nil error")
+	}
+}
+
+func TestWriteFailedNamesFullDisk(t *testing.T) {
+	t.Parallel()
+
+	err := writeFailed("meta results", &os.PathError{Op: "write", Path: "results.json", Err: syscall.ENOSPC})
+	if !strings.Contains(err.Error(), "filesystem is full") {
+		t.Errorf("got %q, want a disk-full hint", err)
+	}
+	if err := writeFailed("meta results", os.ErrPermission); strings.Contains(err.Error(), "filesystem is full") {
+		t.Errorf("got %q, want no disk-full hint", err)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"cmp"
 	"fmt"
 	"maps"
+	"math"
 	"slices"
+	"strings"
 	"time"
 
 	"benchmark-client/internal/cli"
 	"benchmark-client/internal/client"
+	"benchmark-client/internal/container"
 )
 
 func PrintServerSummary(result *ServerResult) {
@@ -21,11 +24,25 @@ func PrintServerSummary(result *ServerResult) {
 
 	memStr := "n/a"
 	cpuStr := "n/a"
-	if result.Resources != nil && result.Resources.Samples > 0 {
-		memStr = cli.FormatMemory(result.Resources.Memory.AvgBytes)
-		cpuStr = cli.FormatCpu(result.Resources.Cpu.AvgPercent, result.Resources.Samples)
+	runStr := ""
+	if r := result.Resources; r != nil && r.Samples > 0 {
+		mem, cpu, samples := r.Comparable()
+		memStr = cli.FormatMemory(mem.AvgBytes)
+		cpuStr = cli.FormatCpu(cpu.AvgPercent, samples)
+		if r.Measured != nil {
+			// Measured windows only; the whole run (warmup included) for context.
+			runStr = fmt.Sprintf("  (whole run: %s, %s)", cli.FormatMemory(r.Memory.AvgBytes), cli.FormatCpu(r.Cpu.AvgPercent, r.Samples))
+		}
+	}
+	line := fmt.Sprintf("Duration: %s  Memory: %s  CPU: %s%s", cli.FormatDuration(result.Duration), memStr, cpuStr, runStr)
+	if result.Note != "" {
+		line += "  [" + result.Note + "]"
+	}
+	if result.Retries > 0 {
+		line += fmt.Sprintf("  [started after %d retries]", result.Retries)
 	}
-	cli.Linef("Duration: %s  Memory: %s  CPU: %s", cli.FormatDuration(result.Duration), memStr, cpuStr)
+	cli.Linef("%s", line)
+	warnMemoryRising(result)
 	cli.Blank()
 
 	var endpointIdx []int
@@ -36,8 +53,8 @@ func PrintServerSummary(result *ServerResult) {
 	}
 
 	cli.Linef("Endpoints")
-	fmt.Println("  ─────────────────────────────────────────────────────────────────────────────────────────────────")
-	fmt.Printf("  %-6s  %-27s  %8s  %8s  %8s  %8s  %8s  %5s  %s\n",
+	cli.Println("  ─────────────────────────────────────────────────────────────────────────────────────────────────")
+	cli.Printf("  %-6s  %-27s  %8s  %8s  %8s  %8s  %8s  %5s  %s\n",
 		"Method", "Path", "Reqs", "RPS", "Avg", "P50", "P95", "Rate", "Status")
 
 	var totalReqs, totalSuccesses int
@@ -53,12 +70,22 @@ func PrintServerSummary(result *ServerResult) {
 		}
 	}
 
+	if m := result.Mixed; m != nil {
+		cli.Printf("  %-6s  %-27s  %8s  %8s  %8s  %8s  %8s  %5s\n",
+			"MIXED", "(blended, all endpoints)",
+			cli.FormatReqs(m.TotalCount), cli.FormatRps(m.Rps),
+			cli.FormatLatency(m.Avg), cli.FormatLatency(m.P50), cli.FormatLatency(m.P95),
+			cli.FormatRate(m.SuccessRate))
+	}
+	printUnstableWarmups(result.Results)
+	printLowWarmups(result.Results)
+
 	var totalSeqRuns, totalSeqSuccesses int
 	if len(result.Sequences) > 0 {
 		cli.Blank()
 		cli.Linef("Sequences")
-		fmt.Println("  ─────────────────────────────────────────────────────────────────────────────────────────────────")
-		fmt.Printf("  %-18s  %8s  %10s  %10s  %10s  %5s  %s\n",
+		cli.Println("  ─────────────────────────────────────────────────────────────────────────────────────────────────")
+		cli.Printf("  %-18s  %8s  %10s  %10s  %10s  %5s  %s\n",
 			"Name", "Runs", "Avg", "P50", "P95", "Rate", "Status")
 
 		for i := range result.Sequences {
@@ -78,7 +105,7 @@ func PrintServerSummary(result *ServerResult) {
 				status = fmt.Sprintf("FAIL (%d)", seq.Failures)
 			}
 
-			fmt.Printf("  %-18s  %8s  %10s  %10s  %10s  %5s  %s %s\n",
+			cli.Printf("  %-18s  %8s  %10s  %10s  %10s  %5s  %s %s\n",
 				cli.Truncate(seqName, 18),
 				cli.FormatReqs(seq.TotalRuns),
 				cli.FormatLatency(seq.AvgDuration),
@@ -89,20 +116,20 @@ func PrintServerSummary(result *ServerResult) {
 
 			for j := range seq.Steps {
 				step := &seq.Steps[j]
-				fmt.Printf("    %-6s %-27s  %10s\n",
+				cli.Printf("    %-6s %-27s  %10s\n",
 					step.Method,
 					cli.TruncatePath(step.Path, 27),
 					cli.FormatLatency(step.Avg))
 			}
 
 			if seq.LastError != "" {
-				fmt.Printf("    └─ last (step %d): %s\n", seq.FailedStep, cli.Truncate(seq.LastError, 65))
+				cli.Printf("    └─ last (step %d): %s\n", seq.FailedStep, cli.Truncate(seq.LastError, 65))
 			}
 		}
 	}
 
 	cli.Blank()
-	fmt.Println("  ─────────────────────────────────────────────────────────────────────────────────────────────────")
+	cli.Println("  ─────────────────────────────────────────────────────────────────────────────────────────────────")
 
 	var successRate float64
 	if totalReqs > 0 {
@@ -110,18 +137,26 @@ func PrintServerSummary(result *ServerResult) {
 	}
 
 	if len(result.Sequences) > 0 {
-		fmt.Printf("  Total: %s reqs │ %s seqs │ Success: %s\n",
+		cli.Printf("  Total: %s reqs │ %s seqs │ Success: %s\n",
 			cli.FormatReqs(totalReqs),
 			cli.FormatReqs(totalSeqRuns),
 			cli.FormatRate(successRate))
 	} else {
-		fmt.Printf("  Total: %s reqs │ Success: %s\n",
+		cli.Printf("  Total: %s reqs │ Success: %s\n",
 			cli.FormatReqs(totalReqs),
 			cli.FormatRate(successRate))
 	}
 	cli.Blank()
 }
 
+// lowConnReuse is the --conn-stats reuse share below which the idle pool is
+// likely undersized for the concurrency.
+const lowConnReuse = 0.9
+
+// highQueueDelay is the share of an endpoint's p99 spent queued for a client
+// connection above which the client transport, not the server, is the limit.
+const highQueueDelay = 0.25
+
 func printResultRow(ep *client.EndpointResult, totalReqs, totalSuccesses int) (updatedReqs, updatedSuccesses int) {
 	path := cli.TruncatePath(ep.Path, 27)
 	reqs := "-"
@@ -156,61 +191,362 @@ func printResultRow(ep *client.EndpointResult, totalReqs, totalSuccesses int) (u
 		}
 	}
 
-	fmt.Printf("  %-6s  %-27s  %8s  %8s  %8s  %8s  %8s  %5s  %s %s\n",
+	cli.Printf("  %-6s  %-27s  %8s  %8s  %8s  %8s  %8s  %5s  %s %s\n",
 		ep.Method, path, reqs, rps, avg, p50, p95, rate, statusSymbol, status)
 
 	if ep.Open != nil {
 		o := ep.Open
-		fmt.Printf("    └─ open: target %s → offered %s req/s │ drops %d │ backlog %d │ lag p99 %s\n",
+		cli.Printf("    └─ open: target %s → offered %s req/s │ drops %d │ backlog %d │ lag p99 %s\n",
 			cli.FormatRps(o.TargetRate), cli.FormatRps(o.OfferedRate),
 			o.DroppedIterations, o.MaxBacklog, cli.FormatLatency(o.ScheduleLagP99))
 	}
 
+	if ep.Stats != nil {
+		if reuse := ep.Stats.ConnReuse(); reuse >= 0 {
+			hint := ""
+			if reuse < lowConnReuse {
+				hint = " — pool churn, check MaxIdleConnsPerHost"
+			}
+			cli.Printf("    └─ conns: %d new, %d reused (%s reuse)%s\n",
+				ep.Stats.NewConns, ep.Stats.ReusedConns, cli.FormatRate(reuse), hint)
+		}
+		if queue := ep.Stats.QueueDelayP99; queue > 0 {
+			hint := ""
+			if ep.Stats.P99 > 0 && float64(queue) >= highQueueDelay*float64(ep.Stats.P99) {
+				hint = " — client-side contention, raise max_conns or lower concurrency"
+			}
+			cli.Printf("    └─ queue: p99 %s waiting for a connection%s\n", cli.FormatLatency(queue), hint)
+		}
+	}
+
+	if ep.Expected != nil && ep.Stats != nil && ep.Stats.Count > 0 {
+		cli.Printf("    └─ expected: %s\n", formatExpected(ep.Expected, ep.Stats))
+	}
+
+	if len(ep.StatusCounts) > 1 || (ep.FailureCount > 0 && len(ep.StatusCounts) > 0) {
+		cli.Printf("    └─ status: %s\n", formatStatusCounts(ep.StatusCounts))
+	}
+
+	if ep.AnomalousCount > 0 {
+		cli.Printf("    └─ anomalous: %d implausible latency samples left out of the stats (clock step or client stall)\n", ep.AnomalousCount)
+	}
+	if ep.CachedCount > 0 {
+		cli.Printf("    └─ possibly cached: %d responses were 304 or cache hits, latency may be the cache's, not the server's\n", ep.CachedCount)
+	}
+	if ep.FailedFast {
+		cli.Printf("    └─ failed fast: first %d requests all failed, endpoint stopped early\n", ep.FailureCount)
+	}
 	if ep.Error != "" {
-		fmt.Printf("    └─ %s\n", cli.Truncate(ep.Error, 75))
+		cli.Printf("    └─ %s\n", cli.Truncate(ep.Error, 75))
 	} else if ep.LastError != "" {
-		fmt.Printf("    └─ last: %s\n", cli.Truncate(ep.LastError, 70))
+		cli.Printf("    └─ last: %s\n", cli.Truncate(ep.LastError, 70))
 	}
 
 	return totalReqs, totalSuccesses
 }
 
-func PrintFinalSummary(meta *MetaResults, servers []ServerSummary) {
+// formatExpected renders each annotated latency as measured vs expected with
+// the relative delta, e.g. "avg 2.3ms vs 2.0ms (+15%) │ p99 4.1ms vs 5.0ms (-18%)".
+func formatExpected(expected *client.Expected, stats *client.Stats) string {
+	var parts []string
+	for _, m := range []struct {
+		name               string
+		measured, expected time.Duration
+	}{
+		{"avg", stats.Avg, expected.Avg},
+		{"p99", stats.P99, expected.P99},
+	} {
+		if m.expected <= 0 {
+			continue
+		}
+		delta := (float64(m.measured) - float64(m.expected)) / float64(m.expected) * 100
+		parts = append(parts, fmt.Sprintf("%s %s vs %s (%+.0f%%)",
+			m.name, strings.TrimSpace(cli.FormatLatency(m.measured)), strings.TrimSpace(cli.FormatLatency(m.expected)), delta))
+	}
+	return strings.Join(parts, " │ ")
+}
+
+// formatStatusCounts renders a status distribution compactly in code order,
+// e.g. "200:950 500:50".
+func formatStatusCounts(counts map[int]int) string {
+	codes := slices.Sorted(maps.Keys(counts))
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%d:%d", code, counts[code])
+	}
+	return strings.Join(parts, " ")
+}
+
+// RankOptions reorder the terminal Server Rankings table (--sort-by,
+// --sort-desc, --top). The exported JSON and Markdown keep the avg ordering.
+type RankOptions struct {
+	SortBy string // one of cli.SortKeys; empty means avg
+	Desc   bool   // force descending; otherwise the key's best-first order
+	Asc    bool   // force ascending
+	Top    int    // 0 shows every server
+
+	Methodology bool // --show-methodology: footnotes after the tables
+	Interim     bool // --interim-rankings: the leaderboard so far after each server
+	BarChart    bool // --bar-chart: avg latency bars after the rankings
+}
+
+func PrintFinalSummary(meta *MetaResults, servers []ServerSummary, opts RankOptions) {
 	cli.Header("BENCHMARK SUMMARY")
 
 	duration := time.Duration(meta.Summary.TotalDurationMs) * time.Millisecond
 
 	cli.Linef("Config")
-	fmt.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
-	cli.Linef("Base: %s  Concurrency: %d  Duration: %s  Timeout: %s",
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	cli.Linef("Base: %s  Concurrency: %d  %s  Timeout: %s",
 		meta.Meta.Config.BaseUrl,
 		meta.Meta.Config.Concurrency,
-		meta.Meta.Config.DurationPerEndpoint,
+		meta.Meta.Config.endpointBudget(),
 		meta.Meta.Config.RequestTimeout)
 	cli.Blank()
 
-	type rankedServer struct {
-		name        string
-		avg         int64
-		min         int64
-		max         int64
-		mem         float64
-		cpu         float64
-		hasMem      bool
-		totalReqs   int
-		successRate float64
-		failed      bool
+	ranked, totalReqs := rankServers(servers)
+	issues := collectIssues(servers)
+
+	if len(ranked) == 0 {
+		cli.Linef("No benchmarks to display.")
+		return
+	}
+
+	shown := sortAndTrim(ranked, opts)
+
+	cli.Linef("Server Rankings (%s)", rankingLabel(opts, len(shown), len(ranked)))
+	printRankingRows(shown, opts)
+	cli.Blank()
+
+	if opts.BarChart {
+		printBarChart(shown)
+	}
+	printEfficiency(shown)
+	printSequenceRankings(servers)
+
+	if len(issues) > 0 {
+		cli.Linef("Issues")
+		cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+		for _, issue := range issues {
+			if issue.endpoint == "" {
+				cli.Printf("  %-10s  %s\n", issue.server, issue.lastError)
+				continue
+			}
+			cli.Printf("  %-10s  %-30s  %d failed  last: %s\n",
+				issue.server,
+				cli.Truncate(issue.endpoint, 30),
+				issue.failures,
+				cli.Truncate(issue.lastError, 35))
+		}
+		cli.Blank()
+	}
+
+	if opts.Methodology {
+		printMethodology(&meta.Meta.Config, servers)
 	}
 
-	var ranked []rankedServer
-	var totalReqs int
-	var issues []struct {
-		server    string
-		endpoint  string
-		failures  int
-		lastError string
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	statusStr := fmt.Sprintf("%s %d passed", cli.SymbolPass, meta.Summary.SuccessfulServers)
+	if meta.Summary.FailedServers > 0 {
+		statusStr += fmt.Sprintf("  %s %d failed", cli.SymbolFail, meta.Summary.FailedServers)
 	}
+	cli.Printf("  %d servers │ %s │ %s │ Total: %s\n",
+		meta.Summary.TotalServers,
+		cli.FormatDuration(duration),
+		statusStr,
+		formatTotals(totalReqs, &meta.Summary))
+	if abort := meta.Summary.Aborted; abort != nil {
+		cli.Failf("Run aborted after %s: success rate %s below abort_below_success_rate %s",
+			abort.Server, cli.FormatRate(abort.SuccessRate), cli.FormatRate(abort.Threshold))
+	}
+	cli.Linef("Results: %s", meta.Meta.Timestamp.Format("results/20060102-150405/"))
+	cli.Blank()
 
+	cli.Printf("# servers=%d passed=%d failed=%d duration_ms=%d total_reqs=%d total_bytes=%d\n",
+		meta.Summary.TotalServers,
+		meta.Summary.SuccessfulServers,
+		meta.Summary.FailedServers,
+		meta.Summary.TotalDurationMs,
+		totalReqs,
+		meta.Summary.ResponseBytes)
+}
+
+// formatTotals is the footer's volume line: "1.2M reqs, 340.5MB transferred,
+// 2.8MB/s", leaving the bytes out when no server returned any.
+func formatTotals(totalReqs int, summary *BenchmarkSummary) string {
+	reqs := cli.FormatReqs(totalReqs) + " reqs"
+	if summary.ResponseBytes == 0 {
+		return reqs
+	}
+	return fmt.Sprintf("%s, %s transferred, %s/s", reqs,
+		cli.FormatMemory(float64(summary.ResponseBytes)), cli.FormatMemory(summary.BytesPerSec))
+}
+
+type rankedServer struct {
+	name        string
+	avg         int64
+	p95         int64   // mean of the endpoint p95s
+	p99         int64   // mean of the endpoint p99s
+	rps         float64 // mean endpoint RPS (no server-level rollup exists)
+	tailRatio   float64 // mean endpoint p99/p50 (0 = no endpoint had a p50)
+	rpsPerMB    float64 // rps per MB of avg memory (0 = no resource samples)
+	rpsPerCpu   float64 // rps per avg CPU percent (0 = no resource samples or idle CPU)
+	min         int64
+	max         int64
+	mem         float64
+	cpu         float64
+	hasMem      bool
+	startupMs   int64
+	totalReqs   int
+	successRate float64
+	failed      bool
+	count       int   // successful requests behind avg
+	stdDev      int64 // pooled latency standard deviation (0 = unknown)
+}
+
+// printRankingRows prints the Server Rankings table under its title line,
+// shared by the final summary and --interim-rankings.
+func printRankingRows(shown []rankedServer, opts RankOptions) {
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	cli.Printf("  %2s  %-10s  %8s  %8s  %8s  %5s  %6s  %5s  %7s  %9s  %5s  %s\n",
+		"#", "Server", "Avg", "Min", "Max", "Tail", "Mem", "CPU", "Startup", "Reqs", "Rate", "Status")
+
+	tied := tiedWithPrevious(shown, opts)
+	for i, s := range shown {
+		rank := fmt.Sprintf("%2d", i+1)
+
+		if s.failed {
+			cli.Printf("  %s  %-10s  %8s  %8s  %8s  %5s  %6s  %5s  %7s  %9s  %5s  %s FAIL\n",
+				rank, s.name, "-", "-", "-", "-", "-", "-", "-", "-", "-", cli.SymbolFail)
+			continue
+		}
+
+		memStr := "-"
+		cpuStr := "-"
+		if s.hasMem {
+			memStr = cli.FormatMemory(s.mem)
+			cpuStr = fmt.Sprintf("%.0f%%", s.cpu)
+		}
+
+		startupStr := "-"
+		if s.startupMs > 0 {
+			startupStr = cli.FormatDuration(time.Duration(s.startupMs) * time.Millisecond)
+		}
+
+		status := cli.SymbolPass + " OK"
+		if s.successRate < 1.0 {
+			status = cli.SymbolFail + " FAIL"
+		}
+		if tied[i] {
+			status += "  ≈ tied with " + shown[i-1].name
+		}
+
+		cli.Printf("  %s  %-10s  %8s  %8s  %8s  %5s  %6s  %5s  %7s  %9s  %5s  %s\n",
+			rank, s.name,
+			cli.FormatLatency(s.avg),
+			cli.FormatLatency(s.min),
+			cli.FormatLatency(s.max),
+			formatTailRatio(s.tailRatio),
+			memStr, cpuStr, startupStr,
+			cli.FormatReqs(s.totalReqs),
+			cli.FormatRate(s.successRate),
+			status)
+	}
+	if slices.Contains(tied, true) {
+		cli.Linef("≈ tied: avg differs from the server above by less than its 95%% noise margin (Welch z-test on mean and stddev)")
+	}
+}
+
+// PrintInterimRankings prints the leaderboard of the servers finished so far
+// (--interim-rankings), ranked and trimmed like the final summary's table.
+func PrintInterimRankings(servers []ServerSummary, opts RankOptions, done, total int) {
+	ranked, _ := rankServers(servers)
+	if len(ranked) == 0 {
+		return
+	}
+	shown := sortAndTrim(ranked, opts)
+	cli.Linef("Interim Rankings (%d of %d servers, %s)", done, total, rankingLabel(opts, len(shown), len(ranked)))
+	printRankingRows(shown, opts)
+	cli.Blank()
+}
+
+// printEfficiency lists the shown servers' throughput per unit of memory and
+// CPU, in ranking order; servers without resource samples are left out.
+func printEfficiency(shown []rankedServer) {
+	var rows []*rankedServer
+	for i := range shown {
+		if !shown[i].failed && shown[i].rpsPerMB > 0 {
+			rows = append(rows, &shown[i])
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	cli.Linef("Efficiency (mean endpoint RPS per resource)")
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	cli.Printf("  %-10s  %9s  %6s  %9s  %5s  %9s\n", "Server", "RPS", "Mem", "RPS/MB", "CPU", "RPS/CPU%")
+	for _, s := range rows {
+		perCpu := "-"
+		if s.rpsPerCpu > 0 {
+			perCpu = fmt.Sprintf("%.1f", s.rpsPerCpu)
+		}
+		cli.Printf("  %-10s  %9s  %6s  %9.1f  %5s  %9s\n",
+			s.name, cli.FormatRps(s.rps), cli.FormatMemory(s.mem), s.rpsPerMB, fmt.Sprintf("%.0f%%", s.cpu), perCpu)
+	}
+	cli.Blank()
+}
+
+// printUnstableWarmups flags endpoints whose warmup_until_stable hit
+// max_duration: their measurement may still include warm-up effects.
+func printUnstableWarmups(results []client.EndpointResult) {
+	var unstable []string
+	seen := make(map[*client.WarmupResult]bool)
+	for i := range results {
+		w := results[i].Warmup
+		if w == nil || w.Stable || seen[w] {
+			continue
+		}
+		seen[w] = true // mixed mode shares one warmup across endpoints
+		unstable = append(unstable, fmt.Sprintf("%s %s (%s)", results[i].Method, results[i].Path, cli.FormatDuration(w.Duration)))
+	}
+	if len(unstable) > 0 {
+		cli.Warnf("Warmup did not stabilize before max_duration: %s", strings.Join(unstable, ", "))
+	}
+}
+
+// printLowWarmups flags endpoints whose warmup completed fewer than
+// client.LowWarmupRequests requests: the server barely answered while warming,
+// so it may still have been cold when measurement began.
+func printLowWarmups(results []client.EndpointResult) {
+	var low []string
+	for i := range results {
+		c := results[i].WarmupCount
+		if c == nil || !c.Low {
+			continue
+		}
+		entry := fmt.Sprintf("%s %s (%d ok", results[i].Method, results[i].Path, c.Requests)
+		if c.Failures > 0 {
+			entry += fmt.Sprintf(", %d failed", c.Failures)
+		}
+		low = append(low, entry+")")
+	}
+	if len(low) > 0 {
+		cli.Warnf("Warmup completed fewer than %d requests: %s", client.LowWarmupRequests, strings.Join(low, ", "))
+	}
+}
+
+// serverIssue is an endpoint's failures, or with no endpoint a problem with
+// the server's container as a whole.
+type serverIssue struct {
+	server    string
+	endpoint  string
+	failures  int
+	lastError string
+}
+
+// rankServers orders servers by avg latency with failed servers last. Shared
+// by the terminal summary and the Markdown export so both show the same numbers.
+func rankServers(servers []ServerSummary) (ranked []rankedServer, totalReqs int) {
 	for i := range servers {
 		s := &servers[i]
 		if s.Error != "" {
@@ -228,42 +564,22 @@ func PrintFinalSummary(meta *MetaResults, servers []ServerSummary) {
 			max:         s.Stats.MaxNs,
 			totalReqs:   s.Stats.TotalCount,
 			successRate: s.Stats.SuccessRate,
+			startupMs:   s.StartupMs,
+			count:       s.Stats.Count,
+			stdDev:      s.Stats.StdDevNs,
 		}
 		totalReqs += s.Stats.TotalCount
+		rs.p95, rs.p99, rs.rps = endpointMeans(s.Results)
+		rs.tailRatio = endpointTailRatio(s.Results)
 
 		if s.Resources != nil && s.Resources.Samples >= 1 {
-			rs.mem = s.Resources.Memory.AvgBytes
-			rs.cpu = s.Resources.Cpu.AvgPercent
+			mem, cpu, _ := s.Resources.Comparable()
+			rs.mem = mem.AvgBytes
+			rs.cpu = cpu.AvgPercent
 			rs.hasMem = true
+			rs.rpsPerMB, rs.rpsPerCpu = efficiency(rs.rps, rs.mem, rs.cpu)
 		}
 		ranked = append(ranked, rs)
-
-		// Collect issues
-		for j := range s.Results {
-			ep := &s.Results[j]
-			if ep.FailureCount > 0 || ep.Error != "" {
-				errMsg := ep.LastError
-				if ep.Error != "" {
-					errMsg = ep.Error
-				}
-				issues = append(issues, struct {
-					server    string
-					endpoint  string
-					failures  int
-					lastError string
-				}{
-					server:    s.Name,
-					endpoint:  fmt.Sprintf("%s %s", ep.Method, ep.Path),
-					failures:  ep.FailureCount,
-					lastError: errMsg,
-				})
-			}
-		}
-	}
-
-	if len(ranked) == 0 {
-		cli.Linef("No benchmarks to display.")
-		return
 	}
 
 	slices.SortFunc(ranked, func(a, b rankedServer) int {
@@ -276,78 +592,241 @@ func PrintFinalSummary(meta *MetaResults, servers []ServerSummary) {
 		return cmp.Compare(a.avg, b.avg)
 	})
 
-	cli.Linef("Server Rankings (by avg latency, all requests)")
-	fmt.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
-	fmt.Printf("  %2s  %-10s  %8s  %8s  %8s  %6s  %5s  %9s  %5s  %s\n",
-		"#", "Server", "Avg", "Min", "Max", "Mem", "CPU", "Reqs", "Rate", "Status")
+	return ranked, totalReqs
+}
 
-	for i, s := range ranked {
-		rank := fmt.Sprintf("%2d", i+1)
+// efficiency is rps per MB of memory and per CPU percent, so a fast server
+// that needs twice the memory doesn't outrank a lean one on speed alone. A
+// zero resource reading yields 0 for that ratio instead of dividing by it.
+func efficiency(rps, memBytes, cpuPercent float64) (perMB, perCpu float64) {
+	if mb := memBytes / 1024 / 1024; mb > 0 {
+		perMB = rps / mb
+	}
+	if cpuPercent > 0 {
+		perCpu = rps / cpuPercent
+	}
+	return perMB, perCpu
+}
 
-		if s.failed {
-			fmt.Printf("  %s  %-10s  %8s  %8s  %8s  %6s  %5s  %9s  %5s  %s FAIL\n",
-				rank, s.name, "-", "-", "-", "-", "-", "-", "-", cli.SymbolFail)
+// endpointMeans averages the per-endpoint p95, p99, and RPS. Percentiles
+// cannot be merged exactly and endpoint RPS does not sum, but every server
+// runs the same endpoints, so the means rank servers consistently.
+func endpointMeans(results []EndpointSummary) (p95, p99 int64, rps float64) {
+	var n int64
+	for i := range results {
+		stats := results[i].Stats
+		if stats == nil {
 			continue
 		}
+		p95 += stats.P95Ns
+		p99 += stats.P99Ns
+		rps += stats.Rps
+		n++
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return p95 / n, p99 / n, rps / float64(n)
+}
 
-		memStr := "-"
-		cpuStr := "-"
-		if s.hasMem {
-			memStr = cli.FormatMemory(s.mem)
-			cpuStr = fmt.Sprintf("%.0f%%", s.cpu)
+// endpointTailRatio averages the endpoint p99/p50 ratios, skipping endpoints
+// without one (p50 of 0, or results written before the ratio was recorded).
+// Averaging the ratios rather than dividing the mean p99 by the mean p50 keeps
+// one slow endpoint from deciding a server's predictability.
+func endpointTailRatio(results []EndpointSummary) float64 {
+	var sum float64
+	var n int
+	for i := range results {
+		stats := results[i].Stats
+		if stats == nil || stats.TailRatio <= 0 {
+			continue
 		}
+		sum += stats.TailRatio
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
 
-		status := cli.SymbolPass + " OK"
-		if s.successRate < 1.0 {
-			status = cli.SymbolFail + " FAIL"
+// formatTailRatio shows a tail ratio as "3.2x", "-" when unknown.
+func formatTailRatio(ratio float64) string {
+	if ratio <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fx", ratio)
+}
+
+var rankingLabels = map[string]string{
+	"avg": "avg latency, all requests",
+	"p95": "mean endpoint p95",
+	"p99": "mean endpoint p99",
+	"rps": "mean endpoint RPS",
+	"mem": "avg memory",
+	"cpu": "avg CPU",
+
+	"tail-ratio": "mean endpoint p99/p50",
+	"efficiency": "mean endpoint RPS per MB of avg memory",
+}
+
+// higherIsBetter are the SortBy keys that rank descending by default, so
+// --top keeps the fastest and most efficient servers.
+var higherIsBetter = map[string]bool{"rps": true, "efficiency": true}
+
+// descending reports whether opts ranks highest first: --sort-desc or
+// --sort-asc when given, else the key's best-first order.
+func descending(opts RankOptions) bool {
+	switch {
+	case opts.Desc:
+		return true
+	case opts.Asc:
+		return false
+	default:
+		return higherIsBetter[opts.SortBy]
+	}
+}
+
+func rankingLabel(opts RankOptions, shown, total int) string {
+	key := cmp.Or(opts.SortBy, "avg")
+	label := "by " + rankingLabels[key]
+	if desc := descending(opts); desc != higherIsBetter[key] {
+		if desc {
+			label += ", descending"
+		} else {
+			label += ", ascending"
 		}
+	}
+	if shown < total {
+		label += fmt.Sprintf(", top %d of %d", shown, total)
+	}
+	return label
+}
 
-		fmt.Printf("  %s  %-10s  %8s  %8s  %8s  %6s  %5s  %9s  %5s  %s\n",
-			rank, s.name,
-			cli.FormatLatency(s.avg),
-			cli.FormatLatency(s.min),
-			cli.FormatLatency(s.max),
-			memStr, cpuStr,
-			cli.FormatReqs(s.totalReqs),
-			cli.FormatRate(s.successRate),
-			status)
+// rankValue is the SortBy metric of a server; ok is false when the server has
+// no value for it (no resource samples for mem/cpu/efficiency, no p50 for
+// tail-ratio).
+func rankValue(s *rankedServer, key string) (value float64, ok bool) {
+	switch key {
+	case "p95":
+		return float64(s.p95), true
+	case "p99":
+		return float64(s.p99), true
+	case "rps":
+		return s.rps, true
+	case "mem":
+		return s.mem, s.hasMem
+	case "cpu":
+		return s.cpu, s.hasMem
+	case "tail-ratio":
+		return s.tailRatio, s.tailRatio > 0
+	case "efficiency":
+		return s.rpsPerMB, s.rpsPerMB > 0
+	default:
+		return float64(s.avg), true
 	}
-	cli.Blank()
+}
 
-	printSequenceRankings(servers)
+// sortAndTrim applies opts' order and --top to rankServers' output and
+// returns the servers to show.
+func sortAndTrim(ranked []rankedServer, opts RankOptions) []rankedServer {
+	sortRanked(ranked, opts)
+	if opts.Top > 0 && opts.Top < len(ranked) {
+		return ranked[:opts.Top]
+	}
+	return ranked
+}
 
-	if len(issues) > 0 {
-		cli.Linef("Issues")
-		fmt.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
-		for _, issue := range issues {
-			fmt.Printf("  %-10s  %-30s  %d failed  last: %s\n",
-				issue.server,
-				cli.Truncate(issue.endpoint, 30),
-				issue.failures,
-				cli.Truncate(issue.lastError, 35))
-		}
-		cli.Blank()
+// sortRanked reorders rankServers' output by opts.SortBy, in descending's
+// order. Failed servers and servers missing the metric stay last either way;
+// ties fall back to avg latency.
+func sortRanked(ranked []rankedServer, opts RankOptions) {
+	desc := descending(opts)
+	if opts.SortBy == "" && !desc {
+		return
 	}
+	slices.SortStableFunc(ranked, func(a, b rankedServer) int {
+		if a.failed != b.failed {
+			if a.failed {
+				return 1
+			}
+			return -1
+		}
+		av, aok := rankValue(&a, opts.SortBy)
+		bv, bok := rankValue(&b, opts.SortBy)
+		if aok != bok {
+			if aok {
+				return -1
+			}
+			return 1
+		}
+		c := cmp.Compare(av, bv)
+		if desc {
+			c = -c
+		}
+		return cmp.Or(c, cmp.Compare(a.avg, b.avg))
+	})
+}
 
-	fmt.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
-	statusStr := fmt.Sprintf("%s %d passed", cli.SymbolPass, meta.Summary.SuccessfulServers)
-	if meta.Summary.FailedServers > 0 {
-		statusStr += fmt.Sprintf("  %s %d failed", cli.SymbolFail, meta.Summary.FailedServers)
+// tieZ is the two-sided 95% critical value of the standard normal.
+const tieZ = 1.96
+
+// tiedWithPrevious marks each shown server whose avg latency is not
+// significantly different from the server ranked just above it, so a few
+// nanoseconds of noise aren't read as a win. The test is Welch's z-test on the
+// means: with n successful requests and pooled standard deviation σ per
+// server, the difference is noise when
+//
+//	|avg_a - avg_b| < 1.96 · sqrt(σ_a²/n_a + σ_b²/n_b)
+//
+// Latencies within a run are autocorrelated, so this understates the real
+// noise: a tie is a strong hint, a non-tie is not proof. Ties are only checked
+// when ranking by avg, and never against failed servers or ones without a
+// stddev (results from before it was recorded).
+func tiedWithPrevious(shown []rankedServer, opts RankOptions) []bool {
+	tied := make([]bool, len(shown))
+	if cmp.Or(opts.SortBy, "avg") != "avg" {
+		return tied
 	}
-	fmt.Printf("  %d servers │ %s │ %s │ Total: %s reqs\n",
-		meta.Summary.TotalServers,
-		cli.FormatDuration(duration),
-		statusStr,
-		cli.FormatReqs(totalReqs))
-	cli.Linef("Results: %s", meta.Meta.Timestamp.Format("results/20060102-150405/"))
-	cli.Blank()
+	for i := 1; i < len(shown); i++ {
+		a, b := &shown[i-1], &shown[i]
+		if a.failed || b.failed || a.stdDev == 0 || b.stdDev == 0 || a.count < 2 || b.count < 2 {
+			continue
+		}
+		sa, sb := float64(a.stdDev), float64(b.stdDev)
+		margin := tieZ * math.Sqrt(sa*sa/float64(a.count)+sb*sb/float64(b.count))
+		tied[i] = math.Abs(float64(a.avg-b.avg)) < margin
+	}
+	return tied
+}
 
-	fmt.Printf("# servers=%d passed=%d failed=%d duration_ms=%d total_reqs=%d\n",
-		meta.Summary.TotalServers,
-		meta.Summary.SuccessfulServers,
-		meta.Summary.FailedServers,
-		meta.Summary.TotalDurationMs,
-		totalReqs)
+func collectIssues(servers []ServerSummary) []serverIssue {
+	var issues []serverIssue
+	for i := range servers {
+		s := &servers[i]
+		if s.Container != nil {
+			issues = append(issues, serverIssue{server: s.Name, lastError: s.Container.Issue()})
+		}
+		if s.Error != "" || s.Stats == nil {
+			continue
+		}
+		for j := range s.Results {
+			ep := &s.Results[j]
+			if ep.FailureCount > 0 || ep.Error != "" {
+				errMsg := ep.LastError
+				if ep.Error != "" {
+					errMsg = ep.Error
+				}
+				issues = append(issues, serverIssue{
+					server:    s.Name,
+					endpoint:  fmt.Sprintf("%s %s", ep.Method, ep.Path),
+					failures:  ep.FailureCount,
+					lastError: errMsg,
+				})
+			}
+		}
+	}
+	return issues
 }
 
 type seqRankingData struct {
@@ -450,7 +929,7 @@ func sortSeqRankingData(data []seqRankingData) {
 
 func printSeqRankingTable(seqId string, dbList []string, serverData []seqRankingData) {
 	cli.Linef("Sequence Rankings (%s - by avg duration)", seqId)
-	fmt.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
+	cli.Println("  ───────────────────────────────────────────────────────────────────────────────────────")
 
 	printSeqRankingHeader(dbList)
 
@@ -462,34 +941,48 @@ func printSeqRankingTable(seqId string, dbList []string, serverData []seqRanking
 
 func printSeqRankingHeader(dbList []string) {
 	if len(dbList) == 0 {
-		fmt.Printf("  %2s  %-10s  %10s  %5s\n", "#", "Server", "Avg", "Rate")
+		cli.Printf("  %2s  %-10s  %10s  %5s\n", "#", "Server", "Avg", "Rate")
 		return
 	}
 
-	fmt.Printf("  %2s  %-10s", "#", "Server")
+	cli.Printf("  %2s  %-10s", "#", "Server")
 	for _, db := range dbList {
-		fmt.Printf("  %10s", db)
+		cli.Printf("  %10s", db)
 	}
-	fmt.Printf("  %10s  %5s\n", "avg", "Rate")
+	cli.Printf("  %10s  %5s\n", "avg", "Rate")
 }
 
 func printSeqRankingRow(rank int, dbList []string, data seqRankingData) {
 	if len(dbList) == 0 {
-		fmt.Printf("  %2d  %-10s  %10s  %5s\n",
+		cli.Printf("  %2d  %-10s  %10s  %5s\n",
 			rank, data.name,
 			cli.FormatLatency(data.avgDuration),
 			cli.FormatRate(data.successRate))
 		return
 	}
 
-	fmt.Printf("  %2d  %-10s", rank, data.name)
+	cli.Printf("  %2d  %-10s", rank, data.name)
 	for _, db := range dbList {
 		dur, ok := data.dbDurations[db]
 		if ok {
-			fmt.Printf("  %10s", cli.FormatLatency(dur))
+			cli.Printf("  %10s", cli.FormatLatency(dur))
 		} else {
-			fmt.Printf("  %10s", "-")
+			cli.Printf("  %10s", "-")
+		}
+	}
+	cli.Printf("  %10s  %5s\n", cli.FormatLatency(data.avgDuration), cli.FormatRate(data.successRate))
+}
+
+// warnMemoryRising calls out containers whose memory was still climbing when
+// sampling stopped; the avg/max columns alone would hide a leak.
+func warnMemoryRising(result *ServerResult) {
+	if result.Resources.HasWarning(container.WarningMemoryRising) {
+		cli.Warnf("%s memory still rising at run end (max %s) — possible leak",
+			result.Name, cli.FormatMemory(result.Resources.Memory.MaxBytes))
+	}
+	for _, db := range slices.Sorted(maps.Keys(result.DbResources)) {
+		if stats := result.DbResources[db]; stats.HasWarning(container.WarningMemoryRising) {
+			cli.Warnf("%s memory still rising at run end (max %s)", db, cli.FormatMemory(stats.Memory.MaxBytes))
 		}
 	}
-	fmt.Printf("  %10s  %5s\n", cli.FormatLatency(data.avgDuration), cli.FormatRate(data.successRate))
 }
@@ -0,0 +1,235 @@
+package summary
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/cli"
+	"benchmark-client/internal/client"
+	"benchmark-client/internal/container"
+)
+
+func TestSortRanked(t *testing.T) {
+	t.Parallel()
+
+	base := []rankedServer{
+		{name: "a", avg: 300, p95: 100, p99: 900, rps: 50, mem: 30, cpu: 10, hasMem: true},
+		{name: "b", avg: 100, p95: 300, p99: 700, rps: 90, mem: 10, cpu: 30, hasMem: true},
+		{name: "c", avg: 200, p95: 200, p99: 800, rps: 70},
+		{name: "broken", failed: true},
+	}
+
+	tests := []struct {
+		opts RankOptions
+		want []string
+	}{
+		{RankOptions{}, []string{"a", "b", "c", "broken"}}, // default keeps rankServers order
+		{RankOptions{SortBy: "avg"}, []string{"b", "c", "a", "broken"}},
+		{RankOptions{SortBy: "p95"}, []string{"a", "c", "b", "broken"}},
+		{RankOptions{SortBy: "p99"}, []string{"b", "c", "a", "broken"}},
+		{RankOptions{SortBy: "rps"}, []string{"b", "c", "a", "broken"}}, // higher is better: descending by default
+		{RankOptions{SortBy: "rps", Desc: true}, []string{"b", "c", "a", "broken"}},
+		{RankOptions{SortBy: "rps", Asc: true}, []string{"a", "c", "b", "broken"}},
+		{RankOptions{SortBy: "mem"}, []string{"b", "a", "c", "broken"}}, // no samples sorts after measured
+		{RankOptions{SortBy: "cpu"}, []string{"a", "b", "c", "broken"}},
+		{RankOptions{SortBy: "cpu", Desc: true}, []string{"b", "a", "c", "broken"}},
+	}
+
+	for _, tt := range tests {
+		ranked := slices.Clone(base)
+		sortRanked(ranked, tt.opts)
+		got := make([]string, len(ranked))
+		for i, s := range ranked {
+			got[i] = s.name
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
+
+// --sort-by rps --top 1 keeps the fastest server, not the slowest.
+func TestSortAndTrimTopByRps(t *testing.T) {
+	t.Parallel()
+
+	ranked := []rankedServer{
+		{name: "slow", avg: 100, rps: 50},
+		{name: "fast", avg: 300, rps: 90},
+		{name: "middle", avg: 200, rps: 70},
+	}
+	shown := sortAndTrim(ranked, RankOptions{SortBy: "rps", Top: 1})
+	if len(shown) != 1 || shown[0].name != "fast" {
+		t.Errorf("got %+v, want only fast", shown)
+	}
+	if got := rankingLabel(RankOptions{SortBy: "rps", Top: 1}, 1, 3); got != "by mean endpoint RPS, top 1 of 3" {
+		t.Errorf("label: got %q", got)
+	}
+	if got := rankingLabel(RankOptions{SortBy: "rps", Asc: true}, 3, 3); got != "by mean endpoint RPS, ascending" {
+		t.Errorf("ascending label: got %q", got)
+	}
+}
+
+// Servers with the same median but a longer tail rank lower by tail-ratio,
+// and a server without a p50 has no ratio, so it sorts after the rest.
+func TestRankByTailRatio(t *testing.T) {
+	t.Parallel()
+
+	server := func(name string, p50, p99 time.Duration) ServerSummary {
+		return ServerSummary{
+			Name:  name,
+			Stats: &StatsSummary{AvgNs: p50.Nanoseconds(), Count: 1, TotalCount: 1, SuccessRate: 1},
+			Results: []EndpointSummary{{Stats: &StatsSummary{
+				P50Ns:     p50.Nanoseconds(),
+				P99Ns:     p99.Nanoseconds(),
+				TailRatio: client.TailRatio(p50, p99),
+			}}},
+		}
+	}
+	servers := []ServerSummary{
+		server("spiky", 10*time.Millisecond, 80*time.Millisecond),
+		server("steady", 10*time.Millisecond, 20*time.Millisecond),
+		server("unknown", 0, 5*time.Millisecond),
+	}
+
+	ranked, _ := rankServers(servers)
+	sortRanked(ranked, RankOptions{SortBy: "tail-ratio"})
+	got := make([]string, len(ranked))
+	for i, s := range ranked {
+		got[i] = s.name
+	}
+	if want := []string{"steady", "spiky", "unknown"}; !slices.Equal(got, want) {
+		t.Errorf("order: got %v, want %v", got, want)
+	}
+	if ranked[0].tailRatio != 2 || ranked[1].tailRatio != 8 {
+		t.Errorf("ratios: got %v and %v, want 2 and 8", ranked[0].tailRatio, ranked[1].tailRatio)
+	}
+	if got := formatTailRatio(ranked[2].tailRatio); got != "-" {
+		t.Errorf("missing ratio: got %q, want -", got)
+	}
+}
+
+func TestRankByEfficiency(t *testing.T) {
+	t.Parallel()
+
+	server := func(name string, memMB, cpu float64) ServerSummary {
+		return ServerSummary{
+			Name:      name,
+			Stats:     &StatsSummary{AvgNs: 1000, Count: 1, TotalCount: 1, SuccessRate: 1},
+			Results:   []EndpointSummary{{Stats: &StatsSummary{Rps: 1000}}},
+			Resources: &container.ResourceStats{Samples: 5, Memory: container.MemoryStats{AvgBytes: memMB * 1024 * 1024}, Cpu: container.CpuStats{AvgPercent: cpu}},
+		}
+	}
+	servers := []ServerSummary{
+		server("hungry", 200, 50),
+		server("lean", 20, 50),
+		{Name: "unsampled", Stats: &StatsSummary{AvgNs: 1000, Count: 1, TotalCount: 1, SuccessRate: 1}},
+	}
+
+	ranked, _ := rankServers(servers)
+	sortRanked(ranked, RankOptions{SortBy: "efficiency", Desc: true})
+	got := make([]string, len(ranked))
+	for i, s := range ranked {
+		got[i] = s.name
+	}
+	if want := []string{"lean", "hungry", "unsampled"}; !slices.Equal(got, want) {
+		t.Errorf("order: got %v, want %v", got, want)
+	}
+	if ranked[0].rpsPerMB != 50 || ranked[1].rpsPerMB != 5 {
+		t.Errorf("rps/MB: got %v and %v, want 50 and 5", ranked[0].rpsPerMB, ranked[1].rpsPerMB)
+	}
+	if ranked[0].rpsPerCpu != 20 || ranked[1].rpsPerCpu != 20 {
+		t.Errorf("rps/CPU%%: got %v and %v, want 20 for both", ranked[0].rpsPerCpu, ranked[1].rpsPerCpu)
+	}
+	if perMB, perCpu := efficiency(1000, 0, 0); perMB != 0 || perCpu != 0 {
+		t.Errorf("zero resources: got %v, %v, want 0, 0", perMB, perCpu)
+	}
+}
+
+func TestRankingLabelCoversSortKeys(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range cli.SortKeys {
+		if _, ok := rankingLabels[key]; !ok {
+			t.Errorf("sort key %q has no ranking label", key)
+		}
+	}
+	if got := rankingLabel(RankOptions{SortBy: "p99", Desc: true}, 2, 5); got != "by mean endpoint p99, descending, top 2 of 5" {
+		t.Errorf("label: got %q", got)
+	}
+}
+
+func TestFormatStatusCounts(t *testing.T) {
+	t.Parallel()
+
+	if got := formatStatusCounts(map[int]int{500: 50, 200: 950, 404: 3}); got != "200:950 404:3 500:50" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFormatExpected(t *testing.T) {
+	t.Parallel()
+
+	stats := &client.Stats{Avg: 2300 * time.Microsecond, P99: 4 * time.Millisecond}
+	got := formatExpected(&client.Expected{Avg: 2 * time.Millisecond, P99: 5 * time.Millisecond}, stats)
+	if want := "avg 2.30ms vs 2.00ms (+15%) │ p99 4.00ms vs 5.00ms (-20%)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// Only annotated latencies are compared.
+	if got := formatExpected(&client.Expected{P99: 4 * time.Millisecond}, stats); got != "p99 4.00ms vs 4.00ms (+0%)" {
+		t.Errorf("p99 only: got %q", got)
+	}
+}
+
+func TestTiedWithPrevious(t *testing.T) {
+	t.Parallel()
+
+	// a and b draw from heavily overlapping distributions (1-1000µs vs
+	// 2-1001µs); c sits well clear of both.
+	spread := func(from time.Duration) []time.Duration {
+		latencies := make([]time.Duration, 1000)
+		for i := range latencies {
+			latencies[i] = from + time.Duration(i)*time.Microsecond
+		}
+		return latencies
+	}
+	server := func(name string, latencies []time.Duration) ServerSummary {
+		stats := client.CalculateStats(latencies, len(latencies), len(latencies), time.Second)
+		return ServerSummary{
+			Name:    name,
+			Stats:   aggregateStats([]client.EndpointResult{{Stats: stats}}),
+			Results: []EndpointSummary{{Stats: statsFromClient(stats)}},
+		}
+	}
+	servers := []ServerSummary{
+		server("c", spread(5000*time.Microsecond)),
+		server("b", spread(2*time.Microsecond)),
+		server("a", spread(time.Microsecond)),
+	}
+
+	ranked, _ := rankServers(servers)
+	tests := []struct {
+		opts RankOptions
+		want []bool
+	}{
+		{RankOptions{}, []bool{false, true, false}}, // a, b ≈ tied, c
+		{RankOptions{SortBy: "avg", Desc: true}, []bool{false, false, true}},
+		{RankOptions{SortBy: "p99"}, []bool{false, false, false}}, // only avg rankings are checked
+	}
+	for _, tt := range tests {
+		shown := slices.Clone(ranked)
+		sortRanked(shown, tt.opts)
+		if got := tiedWithPrevious(shown, tt.opts); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+
+	// Without a recorded stddev nothing is called a tie.
+	noSpread := slices.Clone(ranked)
+	for i := range noSpread {
+		noSpread[i].stdDev = 0
+	}
+	if got := tiedWithPrevious(noSpread, RankOptions{}); slices.Contains(got, true) {
+		t.Errorf("no stddev: got %v, want no ties", got)
+	}
+}
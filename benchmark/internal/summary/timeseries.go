@@ -0,0 +1,111 @@
+package summary
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"benchmark-client/internal/client"
+)
+
+// TimeseriesBucket is the width of one --timeseries bucket.
+const TimeseriesBucket = time.Second
+
+const timeseriesHeader = "endpoint,bucket_start_s,count,p50_ns,p95_ns,p99_ns,max_ns\n"
+
+// latencyBucket is the latencies of the requests whose endpoint offset falls in
+// [start, start+TimeseriesBucket).
+type latencyBucket struct {
+	start              time.Duration
+	count              int
+	p50, p95, p99, max time.Duration
+}
+
+// ExportTimeseries writes latency over time (--timeseries) for one server as
+// <server>__timeseries.csv: per endpoint and flow, one row per second of its
+// run with the request count and p50/p95/p99/max of that second, so a warmup
+// ramp or mid-run degradation shows up without the metrics DB. Rows are
+// keyed by the endpoint offset, seconds into the endpoint's own window; a
+// second with no requests has no row. Like --raw-latencies it reads the
+// suite's timed results, so with max_samples set it charts the reservoir
+// sample. Returns the path written.
+func ExportTimeseries(dir, server string, endpoints []client.TimedResult, sequences []client.TimedSequenceResult) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create timeseries dir: %w", err)
+	}
+	fileName := unsafeFileChars.ReplaceAllString(server, "_") + "__timeseries.csv"
+	path := filepath.Join(dir, fileName)
+	f, err := os.Create(path) //nolint:gosec // path built from sanitized config names
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", fileName, err)
+	}
+
+	w := bufio.NewWriter(f)
+	_, _ = w.WriteString(timeseriesHeader)
+	for _, r := range endpoints {
+		writeTimeseriesRows(w, r.Endpoint, r.Latencies)
+	}
+	for _, seq := range sequences {
+		name := "flow_" + seq.SequenceId
+		if seq.Database != "" {
+			name += "_" + seq.Database
+		}
+		writeTimeseriesRows(w, name, seq.Latencies)
+	}
+
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	if err = f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	return path, nil
+}
+
+func writeTimeseriesRows(w *bufio.Writer, name string, latencies []client.TimedLatency) {
+	buf := make([]byte, 0, 128)
+	for _, b := range bucketLatencies(latencies, TimeseriesBucket) {
+		buf = append(buf[:0], name...)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, b.start.Seconds(), 'f', -1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendInt(buf, int64(b.count), 10)
+		for _, d := range []time.Duration{b.p50, b.p95, b.p99, b.max} {
+			buf = append(buf, ',')
+			buf = strconv.AppendInt(buf, d.Nanoseconds(), 10)
+		}
+		buf = append(buf, '\n')
+		_, _ = w.Write(buf)
+	}
+}
+
+// bucketLatencies groups latencies by EndpointOffset into width-wide buckets,
+// in time order, skipping empty ones.
+func bucketLatencies(latencies []client.TimedLatency, width time.Duration) []latencyBucket {
+	byBucket := make(map[int64][]time.Duration)
+	for _, l := range latencies {
+		i := int64(max(l.EndpointOffset, 0) / width)
+		byBucket[i] = append(byBucket[i], l.Duration)
+	}
+
+	buckets := make([]latencyBucket, 0, len(byBucket))
+	for _, i := range slices.Sorted(maps.Keys(byBucket)) {
+		durations := byBucket[i]
+		slices.Sort(durations)
+		buckets = append(buckets, latencyBucket{
+			start: time.Duration(i) * width,
+			count: len(durations),
+			p50:   client.Percentile(durations, 50),
+			p95:   client.Percentile(durations, 95),
+			p99:   client.Percentile(durations, 99),
+			max:   durations[len(durations)-1],
+		})
+	}
+	return buckets
+}
@@ -0,0 +1,74 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"benchmark-client/internal/client"
+)
+
+func TestBucketLatencies(t *testing.T) {
+	t.Parallel()
+
+	ms := time.Millisecond
+	latencies := []client.TimedLatency{
+		{EndpointOffset: 0, Duration: 4 * ms},
+		{EndpointOffset: 999 * ms, Duration: 2 * ms}, // last instant of bucket 0
+		{EndpointOffset: time.Second, Duration: 9 * ms},
+		{EndpointOffset: 3500 * ms, Duration: 7 * ms}, // bucket 2 stays empty
+	}
+
+	got := bucketLatencies(latencies, time.Second)
+	want := []latencyBucket{
+		{start: 0, count: 2, p50: 3 * ms, p95: 3900 * time.Microsecond, p99: 3980 * time.Microsecond, max: 4 * ms},
+		{start: time.Second, count: 1, p50: 9 * ms, p95: 9 * ms, p99: 9 * ms, max: 9 * ms},
+		{start: 3 * time.Second, count: 1, p50: 7 * ms, p95: 7 * ms, p99: 7 * ms, max: 7 * ms},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("buckets: got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("bucket %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExportTimeseries(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "ts")
+	endpoints := []client.TimedResult{{
+		Endpoint: "get_user",
+		Latencies: []client.TimedLatency{
+			{EndpointOffset: 200 * time.Millisecond, Duration: 1000},
+			{EndpointOffset: 1200 * time.Millisecond, Duration: 3000},
+		},
+	}}
+	sequences := []client.TimedSequenceResult{{
+		SequenceId: "crud",
+		Database:   "postgres",
+		Latencies:  []client.TimedLatency{{EndpointOffset: 2 * time.Second, Duration: 5000}},
+	}}
+
+	path, err := ExportTimeseries(dir, "go/chi", endpoints, sequences)
+	if err != nil {
+		t.Fatalf("ExportTimeseries: %v", err)
+	}
+	if want := filepath.Join(dir, "go_chi__timeseries.csv"); path != want {
+		t.Errorf("path: got %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // test temp file
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := timeseriesHeader +
+		"get_user,0,1,1000,1000,1000,1000\n" +
+		"get_user,1,1,3000,3000,3000,3000\n" +
+		"flow_crud_postgres,2,1,5000,5000,5000,5000\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
@@ -102,12 +102,18 @@ type Manifest = {
   name: string;
   language: string;
   runtime: string;
-  image: string;
+  image?: string; // absent for an external_url server
   port: number;
   databases: string[];
   web: boolean;
   experimental: boolean;
   dev_port: number;
+  tags?: string[];
+  env?: Record<string, string>;
+  cmd?: string[];
+  cpu?: number; // overrides config.json container.cpu_limit for this server
+  memory?: string; // overrides config.json container.memory_limit for this server
+  external_url?: string; // already-running server outside Docker; no image to build
 };
 
 function fatal(msg: string): never {
@@ -149,7 +155,8 @@ function discoverServers(): Server[] {
     } catch (err) {
       fatal(`malformed manifest ${rel}: ${err instanceof Error ? err.message : String(err)}`);
     }
-    for (const key of ["name", "runtime", "image", "port"] as const) {
+    const required = m.external_url ? (["name", "runtime"] as const) : (["name", "runtime", "image", "port"] as const);
+    for (const key of required) {
       if (m[key] === undefined || m[key] === null) fatal(`manifest ${rel}: missing required field "${key}"`);
     }
     const eco = RUNTIME_ECO[m.runtime];
@@ -159,15 +166,17 @@ function discoverServers(): Server[] {
     // Keep this acceptance predicate in sync with benchmark/internal/roster —
     // the two discoverers must agree on what a valid manifest is.
     if (m.name.trim() === "") fatal(`manifest ${rel}: "name" must be non-empty`);
-    if (!Number.isInteger(m.port) || m.port < 1 || m.port > 65535) {
+    if (!m.external_url && (!Number.isInteger(m.port) || m.port < 1 || m.port > 65535)) {
       fatal(`manifest ${rel}: port must be between 1 and 65535, got ${m.port}`);
     }
     const prior = seen.get(m.name);
     if (prior) fatal(`duplicate server name "${m.name}" in ${rel} and ${prior}`);
     seen.set(m.name, rel);
-    const priorImage = seen.get(`image:${m.image}`);
-    if (priorImage) fatal(`duplicate image "${m.image}" in ${rel} and ${priorImage}`);
-    seen.set(`image:${m.image}`, rel);
+    if (m.image) {
+      const priorImage = seen.get(`image:${m.image}`);
+      if (priorImage) fatal(`duplicate image "${m.image}" in ${rel} and ${priorImage}`);
+      seen.set(`image:${m.image}`, rel);
+    }
     // Gradle project path mirrors the settings.gradle.kts include (`:<name>`); its
     // dev command runs the committed wrapper from the repo root (../../gradlew — the
     // flat servers/<name> layout, PLAN §2.1, is always two levels deep) so `just dev`